@@ -0,0 +1,17 @@
+package audit
+
+import "context"
+
+type requestIDKey struct{}
+
+// ContextWithRequestID carries the request ID down to whoever appends an
+// entry, so usecases do not need to know about HTTP.
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext returns the request ID, or "" outside a request.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
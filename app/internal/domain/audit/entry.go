@@ -0,0 +1,89 @@
+package audit
+
+import (
+	"reflect"
+	"time"
+)
+
+// Action names what happened, e.g. "auth.account_locked".
+type Action string
+
+const (
+	ActionAccountLocked   Action = "auth.account_locked"
+	ActionIPLocked        Action = "auth.ip_locked"
+	ActionAccountUnlocked Action = "auth.account_unlocked"
+
+	ActionUserCreated         Action = "user.created"
+	ActionUserUpdated         Action = "user.updated"
+	ActionUserDeleted         Action = "user.deleted"
+	ActionRoleCreated         Action = "role.created"
+	ActionRoleUpdated         Action = "role.updated"
+	ActionRoleDeleted         Action = "role.deleted"
+	ActionPermissionGranted   Action = "role.permission_granted"
+	ActionPermissionRevoked   Action = "role.permission_revoked"
+	ActionCategoryCreated     Action = "category.created"
+	ActionCategoryUpdated     Action = "category.updated"
+	ActionCategoryDeleted     Action = "category.deleted"
+	ActionProductCreated      Action = "product.created"
+	ActionProductUpdated      Action = "product.updated"
+	ActionProductDeleted      Action = "product.deleted"
+	ActionProductPriceSet     Action = "product.price_set"
+	ActionProductPriceDeleted Action = "product.price_deleted"
+	ActionOrderStatusUpdated  Action = "order.status_updated"
+)
+
+// Entry is one append-only audit record. ActorID is nil for actions the
+// system takes on its own, such as locking an account.
+type Entry struct {
+	ID         int64
+	ActorID    *int64
+	Action     Action
+	TargetType string
+	TargetID   string
+	IP         string
+	// RequestID ties the entry to the HTTP request that caused it.
+	RequestID string
+	Details   map[string]any
+	// Changes holds only the fields that differ between before and after.
+	Changes   map[string]Change
+	CreatedAt time.Time
+}
+
+// Change is the value of one field before and after a mutation. Before is
+// nil for creations, After is nil for deletions.
+type Change struct {
+	Before any `json:"before"`
+	After  any `json:"after"`
+}
+
+// Diff returns the fields whose value differs between the two snapshots.
+// A nil snapshot stands for "did not exist".
+func Diff(before, after map[string]any) map[string]Change {
+	changes := make(map[string]Change)
+	for k, b := range before {
+		a, ok := after[k]
+		if !ok || !reflect.DeepEqual(a, b) {
+			changes[k] = Change{Before: b, After: a}
+		}
+	}
+	for k, a := range after {
+		if _, ok := before[k]; !ok {
+			changes[k] = Change{After: a}
+		}
+	}
+	return changes
+}
+
+// ListFilter narrows audit queries; zero values match everything.
+type ListFilter struct {
+	ActorID    *int64
+	Action     *Action
+	TargetType *string
+	TargetID   *string
+	RequestID  *string
+	From       *time.Time
+	To         *time.Time
+}
+
+// SortFields are the fields an audit log list can be sorted by.
+var SortFields = []string{"id"}
@@ -0,0 +1,5 @@
+package audit
+
+import "errors"
+
+var ErrInvalidTimeRange = errors.New("audit log 'from' must be before 'to'")
@@ -0,0 +1,14 @@
+package audit
+
+import (
+	"context"
+
+	"example.com/my-golang-sample/app/internal/domain/page"
+)
+
+// Repository only appends; entries are never updated or deleted.
+type Repository interface {
+	Append(ctx context.Context, e *Entry) error
+	// List returns matching entries, newest first.
+	List(ctx context.Context, filter ListFilter, p page.Request) ([]*Entry, page.Info, error)
+}
@@ -0,0 +1,12 @@
+package auth
+
+import "errors"
+
+var (
+	ErrRefreshTokenNotFound = errors.New("refresh token not found")
+	ErrInvalidRefreshToken  = errors.New("invalid refresh token")
+	ErrInvalidOneTimeToken  = errors.New("invalid or expired token")
+	ErrMFANotEnrolled       = errors.New("two-factor authentication is not enabled")
+	ErrMFAAlreadyEnabled    = errors.New("two-factor authentication is already enabled")
+	ErrInvalidMFACode       = errors.New("invalid two-factor code")
+)
@@ -0,0 +1,67 @@
+package auth
+
+import (
+	"context"
+	"time"
+)
+
+type RefreshTokenRepository interface {
+	Create(ctx context.Context, t *RefreshToken) error
+	GetByHash(ctx context.Context, tokenHash string) (*RefreshToken, error)
+	// Rotate revokes the token with oldID and stores next in one step. It
+	// returns ErrInvalidRefreshToken if oldID was already revoked, so two
+	// concurrent refreshes cannot both succeed.
+	Rotate(ctx context.Context, oldID int64, next *RefreshToken) error
+	RevokeFamily(ctx context.Context, familyID string) error
+	RevokeAllForUser(ctx context.Context, userID int64) error
+}
+
+type OneTimeTokenRepository interface {
+	Create(ctx context.Context, t *OneTimeToken) error
+	// Consume marks an unused, unexpired token as used and returns it, or
+	// returns ErrInvalidOneTimeToken. A token can only be consumed once.
+	Consume(ctx context.Context, purpose TokenPurpose, tokenHash string, now time.Time) (*OneTimeToken, error)
+	// InvalidateForUser voids the user's outstanding tokens of purpose.
+	InvalidateForUser(ctx context.Context, userID int64, purpose TokenPurpose) error
+}
+
+// LoginAttemptRepository tracks failed logins per account and per IP.
+type LoginAttemptRepository interface {
+	// Get returns the counter for key, or a zero counter if there is none.
+	Get(ctx context.Context, scope LoginScope, key string) (*LoginFailures, error)
+	// RecordFailure adds a failure and returns the updated counter. The count
+	// restarts at 1 when the previous failure is older than window.
+	RecordFailure(ctx context.Context, scope LoginScope, key string, now time.Time, window time.Duration) (*LoginFailures, error)
+	// Lock blocks key until the given time and resets its count.
+	Lock(ctx context.Context, scope LoginScope, key string, until time.Time) error
+	Clear(ctx context.Context, scope LoginScope, key string) error
+}
+
+// MFARepository stores TOTP secrets and hashed recovery codes.
+type MFARepository interface {
+	// GetTOTP returns ErrMFANotEnrolled when the user has no secret.
+	GetTOTP(ctx context.Context, userID int64) (*TOTPSecret, error)
+	// SaveTOTP inserts or replaces the user's secret.
+	SaveTOTP(ctx context.Context, t *TOTPSecret) error
+	// DeleteTOTP removes the secret and the recovery codes.
+	DeleteTOTP(ctx context.Context, userID int64) error
+	// UseStep records step as the last accepted code. It returns false if a
+	// code of this or a later step was already used, so codes cannot be replayed.
+	UseStep(ctx context.Context, userID int64, step int64) (bool, error)
+	// ReplaceRecoveryCodes drops the user's codes and stores the new hashes.
+	ReplaceRecoveryCodes(ctx context.Context, userID int64, hashes []string) error
+	// UseRecoveryCode marks an unused code as used, or returns ErrInvalidMFACode.
+	UseRecoveryCode(ctx context.Context, userID int64, hash string, now time.Time) error
+	CountRecoveryCodes(ctx context.Context, userID int64) (int, error)
+}
+
+// Denylist holds the IDs (jti) of access tokens revoked before they expire,
+// and per-user cut-offs that void every token a user was issued up to a moment.
+type Denylist interface {
+	Revoke(ctx context.Context, jti string, expiresAt time.Time) error
+	IsRevoked(ctx context.Context, jti string) (bool, error)
+	// RevokeUser voids the user's access tokens issued at or before at.
+	RevokeUser(ctx context.Context, userID int64, at time.Time) error
+	// TokensValidAfter returns the user's cut-off, or the zero time if none.
+	TokensValidAfter(ctx context.Context, userID int64) (time.Time, error)
+}
@@ -0,0 +1,80 @@
+package auth
+
+import "time"
+
+// RefreshToken is a server-side session. Only the SHA-256 hash of the opaque
+// token handed to the client is stored. Every refresh rotates the token;
+// all tokens descending from one login share a FamilyID.
+type RefreshToken struct {
+	ID        int64
+	UserID    int64
+	TokenHash string
+	FamilyID  string
+	ExpiresAt time.Time
+	RevokedAt *time.Time
+	CreatedAt time.Time
+}
+
+func (t *RefreshToken) IsRevoked() bool {
+	return t.RevokedAt != nil
+}
+
+func (t *RefreshToken) IsExpired(now time.Time) bool {
+	return !now.Before(t.ExpiresAt)
+}
+
+type TokenPurpose string
+
+const (
+	PurposeVerifyEmail   TokenPurpose = "VERIFY_EMAIL"
+	PurposeResetPassword TokenPurpose = "RESET_PASSWORD"
+	// PurposeMFAChallenge links the password step of a login to its second factor.
+	PurposeMFAChallenge TokenPurpose = "MFA_CHALLENGE"
+)
+
+// OneTimeToken is an emailed, single-use token such as an email verification
+// or password reset link. As with refresh tokens only the hash is stored.
+type OneTimeToken struct {
+	ID        int64
+	UserID    int64
+	Purpose   TokenPurpose
+	TokenHash string
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+// LoginScope says what a failed login counter is keyed by.
+type LoginScope string
+
+const (
+	ScopeAccount LoginScope = "ACCOUNT"
+	ScopeIP      LoginScope = "IP"
+)
+
+// LoginFailures counts recent failed logins for one email or client IP.
+type LoginFailures struct {
+	Scope        LoginScope
+	Key          string
+	Count        int
+	LastFailedAt time.Time
+	LockedUntil  *time.Time
+}
+
+func (f *LoginFailures) IsLocked(now time.Time) bool {
+	return f.LockedUntil != nil && now.Before(*f.LockedUntil)
+}
+
+// TOTPSecret is a user's authenticator app enrollment. It only guards logins
+// once confirmed, i.e. after the user proved the app produces valid codes.
+type TOTPSecret struct {
+	UserID       int64
+	Secret       string
+	ConfirmedAt  *time.Time
+	LastUsedStep int64
+	CreatedAt    time.Time
+}
+
+func (t *TOTPSecret) IsConfirmed() bool {
+	return t.ConfirmedAt != nil
+}
@@ -1,18 +1,64 @@
 package cart
 
+import "example.com/my-golang-sample/app/internal/domain/money"
+
+// Owner identifies a cart: either a signed-in user or an anonymous shopper
+// holding an opaque guest token.
+type Owner struct {
+	UserID     int64
+	GuestToken string
+}
+
+func UserOwner(userID int64) Owner {
+	return Owner{UserID: userID}
+}
+
+func GuestOwner(token string) Owner {
+	return Owner{GuestToken: token}
+}
+
+func (o Owner) IsGuest() bool {
+	return o.UserID == 0
+}
+
 type Item struct {
 	ProductID int64
 	Quantity  int64
+	// UnitPrice is the product price when the item was added, zero if unknown.
+	UnitPrice money.Money
 }
 
 type DetailedItem struct {
 	Item
 	ProductName  string
-	ProductPrice float64
+	ProductPrice money.Money
+	Subtotal     money.Money
+}
+
+type WarningCode string
+
+const (
+	WarningProductInactive   WarningCode = "PRODUCT_INACTIVE"
+	WarningProductDeleted    WarningCode = "PRODUCT_DELETED"
+	WarningPriceChanged      WarningCode = "PRICE_CHANGED"
+	WarningInsufficientStock WarningCode = "INSUFFICIENT_STOCK"
+	WarningPriceUnavailable  WarningCode = "PRICE_UNAVAILABLE"
+)
+
+// Warning flags a cart line that changed since it was added. Inactive and
+// deleted products, and products not sold in the cart currency, are left out
+// of Items and of the totals.
+type Warning struct {
+	ProductID int64
+	Code      WarningCode
+	Message   string
 }
 
 type Cart struct {
-	UserID int64
-	Items  []DetailedItem
+	UserID    int64 // 0 for guest carts
+	Items     []DetailedItem
+	Total     money.Money
+	ItemCount int64
+	Warnings  []Warning
 }
 
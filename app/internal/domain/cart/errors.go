@@ -3,6 +3,7 @@ package cart
 import "errors"
 
 var (
-	ErrCartEmpty = errors.New("cart is empty")
+	ErrCartEmpty        = errors.New("cart is empty")
+	ErrCartItemNotFound = errors.New("cart item not found")
 )
 
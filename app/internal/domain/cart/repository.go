@@ -3,8 +3,14 @@ package cart
 import "context"
 
 type Repository interface {
-	AddOrUpdateItem(ctx context.Context, userID int64, productID int64, quantity int64) error
-	ListItems(ctx context.Context, userID int64) ([]Item, error)
-	Clear(ctx context.Context, userID int64) error
+	AddOrUpdateItem(ctx context.Context, owner Owner, productID int64, quantity int64) error
+	// SetItemQuantity overwrites the quantity of an item already in the cart.
+	SetItemQuantity(ctx context.Context, owner Owner, productID int64, quantity int64) error
+	RemoveItem(ctx context.Context, owner Owner, productID int64) error
+	ListItems(ctx context.Context, owner Owner) ([]Item, error)
+	Clear(ctx context.Context, owner Owner) error
+	// MergeItems sets the quantities of items in the cart of to and empties
+	// the cart of from in one transaction. Nothing is written when from is
+	// already empty, e.g. because a concurrent login merged it first.
+	MergeItems(ctx context.Context, from, to Owner, items []Item) error
 }
-
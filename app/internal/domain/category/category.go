@@ -8,6 +8,9 @@ type Category struct {
 	IsActive    bool
 }
 
+// SortFields are the fields a category list can be sorted by.
+var SortFields = []string{"id", "name", "slug"}
+
 type ListFilter struct {
 	OnlyActive bool
 }
@@ -1,12 +1,16 @@
 package category
 
-import "context"
+import (
+	"context"
+
+	"example.com/my-golang-sample/app/internal/domain/page"
+)
 
 type Repository interface {
 	Create(ctx context.Context, c *Category) (*Category, error)
 	Update(ctx context.Context, c *Category) (*Category, error)
 	Delete(ctx context.Context, id int64) error
 	GetByID(ctx context.Context, id int64) (*Category, error)
-	List(ctx context.Context, filter ListFilter) ([]*Category, error)
+	List(ctx context.Context, filter ListFilter, p page.Request) ([]*Category, page.Info, error)
 }
 
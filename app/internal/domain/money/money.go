@@ -0,0 +1,216 @@
+package money
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// DefaultCurrency is used when no store currency is configured.
+const DefaultCurrency = "SAR"
+
+// MaxExponent is the scale of the DECIMAL money columns; currencies with
+// more minor-unit digits would be rounded on every write.
+const MaxExponent = 2
+
+var (
+	ErrInvalidAmount       = errors.New("invalid money amount")
+	ErrInvalidCurrency     = errors.New("invalid currency code")
+	ErrUnsupportedCurrency = errors.New("currency has more minor digits than prices can store")
+	ErrCurrencyMismatch    = errors.New("currency mismatch")
+	ErrOverflow            = errors.New("money amount out of range")
+)
+
+// exponents lists the ISO 4217 currencies the store knows by their number of
+// minor-unit digits.
+var exponents = map[string]int{
+	"SAR": 2, "AED": 2, "QAR": 2, "EGP": 2, "MAD": 2, "TRY": 2, "PKR": 2, "INR": 2,
+	"USD": 2, "EUR": 2, "GBP": 2, "CHF": 2, "CAD": 2, "AUD": 2, "CNY": 2, "SGD": 2,
+	"JPY": 0, "KRW": 0, "VND": 0, "CLP": 0, "ISK": 0, "UGX": 0,
+	"KWD": 3, "BHD": 3, "OMR": 3, "JOD": 3, "TND": 3, "LYD": 3, "IQD": 3,
+}
+
+// Money is an exact amount expressed in the minor unit of its currency
+// (halalas for SAR, cents for USD, fils for KWD...).
+type Money struct {
+	Amount   int64
+	Currency string
+}
+
+func New(minor int64, currency string) Money {
+	return Money{Amount: minor, Currency: currency}
+}
+
+func Zero(currency string) Money {
+	return Money{Currency: currency}
+}
+
+// ParseCurrency normalises an ISO 4217 code such as "usd" to "USD". Only
+// known currencies whose minor unit fits MaxExponent are accepted.
+func ParseCurrency(s string) (string, error) {
+	code := strings.ToUpper(strings.TrimSpace(s))
+	exp, ok := exponents[code]
+	if !ok {
+		return "", fmt.Errorf("%w: %q", ErrInvalidCurrency, s)
+	}
+	if exp > MaxExponent {
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedCurrency, code)
+	}
+	return code, nil
+}
+
+// Exponent is the number of minor-unit digits of an ISO 4217 currency.
+// Unknown codes are assumed to use two.
+func Exponent(currency string) int {
+	if exp, ok := exponents[strings.ToUpper(currency)]; ok {
+		return exp
+	}
+	return 2
+}
+
+// Parse reads a decimal string such as "12.5" or "-0.35". Extra fraction
+// digits are rounded half away from zero, so the result never depends on
+// binary floating point.
+func Parse(s, currency string) (Money, error) {
+	return parse(s, currency, false)
+}
+
+// ParseExact is Parse for request input: an amount finer than the
+// currency's minor unit, such as "9.999" SAR, is rejected instead of rounded.
+func ParseExact(s, currency string) (Money, error) {
+	return parse(s, currency, true)
+}
+
+func parse(s, currency string, exact bool) (Money, error) {
+	s = strings.TrimSpace(s)
+	neg := false
+	switch {
+	case strings.HasPrefix(s, "-"):
+		neg = true
+		s = s[1:]
+	case strings.HasPrefix(s, "+"):
+		s = s[1:]
+	}
+
+	whole, frac, _ := strings.Cut(s, ".")
+	if whole == "" && frac == "" || !isDigits(whole) || !isDigits(frac) {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidAmount, s)
+	}
+
+	exp := Exponent(currency)
+	roundUp := false
+	if len(frac) > exp {
+		if exact && strings.TrimRight(frac[exp:], "0") != "" {
+			return Money{}, fmt.Errorf("%w: %q has more than %d decimals", ErrInvalidAmount, s, exp)
+		}
+		roundUp = frac[exp] >= '5'
+		frac = frac[:exp]
+	}
+	frac += strings.Repeat("0", exp-len(frac))
+
+	digits := strings.TrimLeft(whole+frac, "0")
+	var amount int64
+	if digits != "" {
+		v, err := strconv.ParseInt(digits, 10, 64)
+		if err != nil {
+			return Money{}, fmt.Errorf("%w: %q", ErrInvalidAmount, s)
+		}
+		amount = v
+	}
+	if roundUp {
+		if amount == math.MaxInt64 {
+			return Money{}, fmt.Errorf("%w: %q", ErrOverflow, s)
+		}
+		amount++
+	}
+	if neg {
+		amount = -amount
+	}
+	return Money{Amount: amount, Currency: currency}, nil
+}
+
+// MustParse is Parse for literals known to be valid (seeds, tests).
+func MustParse(s, currency string) Money {
+	m, err := Parse(s, currency)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Add sums two amounts of the same currency. A zero value without currency
+// adopts the other operand's currency, so totals can start from Money{}.
+func (m Money) Add(o Money) (Money, error) {
+	switch {
+	case m.Currency == "":
+		m.Currency = o.Currency
+	case o.Currency != "" && o.Currency != m.Currency:
+		return Money{}, fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency, o.Currency)
+	}
+	sum := m.Amount + o.Amount
+	if (o.Amount > 0 && sum < m.Amount) || (o.Amount < 0 && sum > m.Amount) {
+		return Money{}, fmt.Errorf("%w: %s + %s", ErrOverflow, m, o)
+	}
+	m.Amount = sum
+	return m, nil
+}
+
+// Mul multiplies the amount by a quantity.
+func (m Money) Mul(n int64) (Money, error) {
+	if m.Amount == 0 || n == 0 {
+		return Money{Currency: m.Currency}, nil
+	}
+	product := m.Amount * n
+	// MinInt64 / -1 tràn thành chính nó nên phải kiểm tra riêng
+	if product/n != m.Amount || (m.Amount == -1 && n == math.MinInt64) || (n == -1 && m.Amount == math.MinInt64) {
+		return Money{}, fmt.Errorf("%w: %s x %d", ErrOverflow, m, n)
+	}
+	m.Amount = product
+	return m, nil
+}
+
+func (m Money) IsZero() bool     { return m.Amount == 0 }
+func (m Money) IsPositive() bool { return m.Amount > 0 }
+
+// String formats the amount as a plain decimal without the currency code,
+// e.g. "1250.00".
+func (m Money) String() string {
+	exp := Exponent(m.Currency)
+	amount := m.Amount
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	digits := strconv.FormatInt(amount, 10)
+	if exp == 0 {
+		return sign + digits
+	}
+	if len(digits) <= exp {
+		digits = strings.Repeat("0", exp-len(digits)+1) + digits
+	}
+	return sign + digits[:len(digits)-exp] + "." + digits[len(digits)-exp:]
+}
+
+// MarshalJSON writes the amount as an exact JSON number; the currency is
+// reported next to it by the caller.
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// Value stores the amount in a DECIMAL column.
+func (m Money) Value() (driver.Value, error) {
+	return m.String(), nil
+}
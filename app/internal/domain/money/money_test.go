@@ -0,0 +1,187 @@
+package money
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		in       string
+		currency string
+		want     int64
+	}{
+		{"whole", "12", "SAR", 1200},
+		{"one fraction digit", "12.5", "SAR", 1250},
+		{"rounds half up", "0.125", "SAR", 13},
+		{"rounds down", "0.124", "SAR", 12},
+		{"rounds negative away from zero", "-0.125", "SAR", -13},
+		{"leading plus and spaces", " +1.00 ", "USD", 100},
+		{"fraction only", ".5", "USD", 50},
+		{"exponent 0", "1500", "JPY", 1500},
+		{"exponent 0 rounds", "1499.5", "JPY", 1500},
+		{"exponent 3", "1.234", "KWD", 1234},
+		{"exponent 3 rounds", "1.2345", "KWD", 1235},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := Parse(tt.in, tt.currency)
+
+			require.NoError(t, err)
+			require.Equal(t, New(tt.want, tt.currency), m)
+		})
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		err  error
+	}{
+		{"empty", "", ErrInvalidAmount},
+		{"dot only", ".", ErrInvalidAmount},
+		{"letters", "12a", ErrInvalidAmount},
+		{"two dots", "1.2.3", ErrInvalidAmount},
+		{"exponent form", "1e3", ErrInvalidAmount},
+		{"too many digits", "999999999999999999999", ErrInvalidAmount},
+		{"rounds past int64", "92233720368547758.075", ErrOverflow},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.in, "SAR")
+
+			require.ErrorIs(t, err, tt.err)
+		})
+	}
+}
+
+func TestParseExact(t *testing.T) {
+	tests := []struct {
+		in       string
+		currency string
+		want     int64
+		err      error
+	}{
+		{"12.5", "SAR", 1250, nil},
+		{"9.990", "SAR", 999, nil},
+		{"1500.00", "JPY", 1500, nil},
+		{"9.999", "SAR", 0, ErrInvalidAmount},
+		{"0.001", "USD", 0, ErrInvalidAmount},
+		{"1499.5", "JPY", 0, ErrInvalidAmount},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in+" "+tt.currency, func(t *testing.T) {
+			m, err := ParseExact(tt.in, tt.currency)
+
+			if tt.err != nil {
+				require.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, New(tt.want, tt.currency), m)
+		})
+	}
+}
+
+func TestParseCurrency(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+		err  error
+	}{
+		{"sar", "SAR", nil},
+		{" usd ", "USD", nil},
+		{"JPY", "JPY", nil},
+		{"XYZ", "", ErrInvalidCurrency},
+		{"US", "", ErrInvalidCurrency},
+		{"dollars", "", ErrInvalidCurrency},
+		{"KWD", "", ErrUnsupportedCurrency},
+		{"bhd", "", ErrUnsupportedCurrency},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			code, err := ParseCurrency(tt.in)
+
+			if tt.err != nil {
+				require.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, code)
+		})
+	}
+}
+
+func TestStringAndMarshalJSON(t *testing.T) {
+	tests := []struct {
+		m    Money
+		want string
+	}{
+		{New(125000, "SAR"), "1250.00"},
+		{New(5, "SAR"), "0.05"},
+		{New(-35, "USD"), "-0.35"},
+		{Zero("SAR"), "0.00"},
+		{New(1500, "JPY"), "1500"},
+		{New(-7, "JPY"), "-7"},
+		{New(1234, "KWD"), "1.234"},
+		{New(5, "KWD"), "0.005"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want+" "+tt.m.Currency, func(t *testing.T) {
+			require.Equal(t, tt.want, tt.m.String())
+
+			b, err := json.Marshal(map[string]Money{"price": tt.m})
+			require.NoError(t, err)
+			require.JSONEq(t, `{"price":`+tt.want+`}`, string(b))
+		})
+	}
+}
+
+func TestAdd(t *testing.T) {
+	sum, err := Money{}.Add(New(150, "SAR"))
+	require.NoError(t, err)
+	require.Equal(t, New(150, "SAR"), sum)
+
+	_, err = New(1, "SAR").Add(New(1, "USD"))
+	require.ErrorIs(t, err, ErrCurrencyMismatch)
+
+	_, err = New(math.MaxInt64, "SAR").Add(New(1, "SAR"))
+	require.ErrorIs(t, err, ErrOverflow)
+
+	_, err = New(math.MinInt64, "SAR").Add(New(-1, "SAR"))
+	require.ErrorIs(t, err, ErrOverflow)
+}
+
+func TestMul(t *testing.T) {
+	tests := []struct {
+		name   string
+		amount int64
+		n      int64
+		want   int64
+		err    error
+	}{
+		{"quantity", 1250, 3, 3750, nil},
+		{"zero quantity", 1250, 0, 0, nil},
+		{"negative", -5, 2, -10, nil},
+		{"overflow", math.MaxInt64/2 + 1, 2, 0, ErrOverflow},
+		{"negative overflow", math.MinInt64, -1, 0, ErrOverflow},
+		{"overflow by min quantity", -1, math.MinInt64, 0, ErrOverflow},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := New(tt.amount, "SAR").Mul(tt.n)
+
+			if tt.err != nil {
+				require.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, New(tt.want, "SAR"), m)
+		})
+	}
+}
@@ -5,8 +5,12 @@ import "errors"
 var (
 	ErrOrderNotFound      = errors.New("order not found")
 	ErrInvalidStatus      = errors.New("invalid order status")
+	ErrInvalidTransition  = errors.New("invalid order status transition")
+	ErrNotCancelable      = errors.New("order can no longer be canceled")
 	ErrInvalidPayment     = errors.New("invalid payment method")
 	ErrEmptyOrderItems    = errors.New("no items to checkout")
 	ErrCheckoutValidation = errors.New("checkout validation failed")
+	ErrInvalidDateRange   = errors.New("order 'created_from' must be before 'created_to'")
+	ErrInvalidTotalRange  = errors.New("order 'min_total' must not exceed 'max_total'")
 )
 
@@ -1,29 +1,44 @@
 package order
 
 import (
+	"slices"
 	"time"
 
 	domcart "example.com/my-golang-sample/app/internal/domain/cart"
+	"example.com/my-golang-sample/app/internal/domain/money"
 )
 
 type Status string
 
 const (
-	StatusPending  Status = "PENDING"
-	StatusPaid     Status = "PAID"
-	StatusShipped  Status = "SHIPPED"
-	StatusCanceled Status = "CANCELED"
+	StatusPending   Status = "PENDING"
+	StatusPaid      Status = "PAID"
+	StatusShipped   Status = "SHIPPED"
+	StatusDelivered Status = "DELIVERED"
+	StatusCanceled  Status = "CANCELED"
 )
 
 func (s Status) IsValid() bool {
 	switch s {
-	case StatusPending, StatusPaid, StatusShipped, StatusCanceled:
+	case StatusPending, StatusPaid, StatusShipped, StatusDelivered, StatusCanceled:
 		return true
 	default:
 		return false
 	}
 }
 
+// transitions is the order lifecycle. DELIVERED and CANCELED are terminal,
+// and an order can no longer be canceled once it has shipped.
+var transitions = map[Status][]Status{
+	StatusPending: {StatusPaid, StatusCanceled},
+	StatusPaid:    {StatusShipped, StatusCanceled},
+	StatusShipped: {StatusDelivered},
+}
+
+func (s Status) CanTransitionTo(to Status) bool {
+	return slices.Contains(transitions[s], to)
+}
+
 type PaymentMethod string
 
 const (
@@ -40,22 +55,65 @@ func (p PaymentMethod) IsValid() bool {
 	}
 }
 
+// ListFilter narrows admin order queries; zero values match everything.
+// MinTotal and MaxTotal only match orders placed in their currency.
+type ListFilter struct {
+	Status        *Status
+	PaymentMethod *PaymentMethod
+	UserID        *int64
+	Email         *string
+	Currency      *string
+	CreatedFrom   *time.Time
+	CreatedTo     *time.Time
+	MinTotal      *money.Money
+	MaxTotal      *money.Money
+}
+
+// SortFields are the fields an order list can be sorted by.
+var SortFields = []string{"id", "created_at", "status"}
+
 type Order struct {
 	ID            int64
 	UserID        int64
 	Status        Status
 	PaymentMethod PaymentMethod
-	TotalAmount   float64
+	TotalAmount   money.Money
 	Items         []OrderItem
 	CreatedAt     time.Time
 }
 
+// StatusChange is a transition to persist together with its history entry.
+// ChangedBy is 0 for changes made by the system (e.g. payment webhooks).
+type StatusChange struct {
+	OrderID   int64
+	From      Status
+	To        Status
+	ChangedBy int64
+	Note      string
+}
+
+// ReleasesStock reports whether the change must give the ordered quantities
+// back to inventory.
+func (c StatusChange) ReleasesStock() bool {
+	return c.To == StatusCanceled && c.From != StatusCanceled
+}
+
+type StatusHistory struct {
+	ID        int64
+	OrderID   int64
+	From      Status // empty for the entry recorded when the order is placed
+	To        Status
+	ChangedBy int64
+	Note      string
+	CreatedAt time.Time
+}
+
 type OrderItem struct {
 	ID        int64
 	OrderID   int64
 	ProductID int64
 	Name      string
-	Price     float64
+	Price     money.Money
 	Quantity  int64
 }
 
@@ -4,12 +4,21 @@ import (
 	"context"
 
 	domcart "example.com/my-golang-sample/app/internal/domain/cart"
+	"example.com/my-golang-sample/app/internal/domain/page"
 )
 
 type Repository interface {
-	CreateFromCart(ctx context.Context, userID int64, items []domcart.Item, payment PaymentMethod) (*Order, error)
-	List(ctx context.Context) ([]*Order, error)
+	// CreateFromCart prices the items in currency (the store currency if empty)
+	// and fails with domproduct.ErrPriceUnavailable for products not sold in it.
+	CreateFromCart(ctx context.Context, userID int64, items []domcart.Item, payment PaymentMethod, currency string) (*Order, error)
+	List(ctx context.Context, filter ListFilter, p page.Request) ([]*Order, page.Info, error)
 	GetByID(ctx context.Context, id int64) (*Order, error)
-	UpdateStatus(ctx context.Context, id int64, status Status) (*Order, error)
+	ListByUser(ctx context.Context, userID int64, p page.Request) ([]*Order, page.Info, error)
+	// GetByIDForUser returns ErrOrderNotFound when the order belongs to another user.
+	GetByIDForUser(ctx context.Context, id, userID int64) (*Order, error)
+	// UpdateStatus applies the change only if the order is still in change.From
+	// and records it in the status history.
+	UpdateStatus(ctx context.Context, change StatusChange) (*Order, error)
+	ListStatusHistory(ctx context.Context, orderID int64) ([]StatusHistory, error)
 }
 
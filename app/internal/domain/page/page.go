@@ -0,0 +1,127 @@
+package page
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+)
+
+const (
+	DefaultSize = 20
+	MaxSize     = 100
+	// DefaultSort keeps the old behaviour: newest rows first.
+	DefaultSort = "id"
+)
+
+var (
+	ErrInvalidPage   = errors.New("page and size must be positive")
+	ErrInvalidSort   = errors.New("unsupported sort field")
+	ErrInvalidCursor = errors.New("invalid cursor")
+)
+
+// Request is the pagination contract shared by every list: page number and
+// size, or an opaque keyset cursor, plus a whitelisted sort field. When Cursor is set it replaces Page: the page
+// starts right after the row the cursor points at.
+type Request struct {
+	Page   int
+	Size   int
+	Sort   string
+	Desc   bool
+	Cursor string
+
+	// After is the decoded Cursor, filled in by Normalize.
+	After *Cursor
+}
+
+// Info describes the page that was returned.
+type Info struct {
+	Total int64
+	// Page is 0 when the page was requested by cursor.
+	Page       int
+	Size       int
+	NextCursor string
+}
+
+// Cursor points at the last row of a page. Sort and Desc are kept so a
+// cursor cannot be replayed against a different ordering.
+type Cursor struct {
+	Sort  string `json:"s"`
+	Desc  bool   `json:"d,omitempty"`
+	Value any    `json:"v"`
+	ID    int64  `json:"id"`
+}
+
+// ParseSort reads "name" as ascending and "-name" as descending.
+func ParseSort(s string) (string, bool) {
+	s = strings.TrimSpace(s)
+	if rest, ok := strings.CutPrefix(s, "-"); ok {
+		return rest, true
+	}
+	return s, false
+}
+
+// Normalize fills in defaults, caps the size at MaxSize and rejects sort
+// fields outside allowed and cursors issued for another ordering.
+func (r Request) Normalize(allowed []string) (Request, error) {
+	if r.Page < 0 || r.Size < 0 {
+		return r, ErrInvalidPage
+	}
+	if r.Page == 0 {
+		r.Page = 1
+	}
+	if r.Size == 0 {
+		r.Size = DefaultSize
+	}
+	if r.Size > MaxSize {
+		r.Size = MaxSize
+	}
+	if r.Sort == "" {
+		r.Sort, r.Desc = DefaultSort, true
+	}
+	if r.Sort != DefaultSort && !slices.Contains(allowed, r.Sort) {
+		return r, ErrInvalidSort
+	}
+
+	r.After = nil
+	if r.Cursor != "" {
+		c, err := DecodeCursor(r.Cursor)
+		if err != nil {
+			return r, err
+		}
+		if c.Sort != r.Sort || c.Desc != r.Desc {
+			return r, ErrInvalidCursor
+		}
+		r.After = &c
+	}
+	return r, nil
+}
+
+// Offset is the number of rows skipped for page-number requests.
+func (r Request) Offset() int {
+	if r.After != nil || r.Page < 1 {
+		return 0
+	}
+	return (r.Page - 1) * r.Size
+}
+
+func EncodeCursor(c Cursor) string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func DecodeCursor(s string) (Cursor, error) {
+	var c Cursor
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, ErrInvalidCursor
+	}
+	dec := json.NewDecoder(strings.NewReader(string(b)))
+	// Giữ số nguyên dạng chuỗi để không mất độ chính xác qua float64
+	dec.UseNumber()
+	if err := dec.Decode(&c); err != nil || c.Sort == "" {
+		return c, ErrInvalidCursor
+	}
+	return c, nil
+}
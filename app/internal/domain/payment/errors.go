@@ -0,0 +1,12 @@
+package payment
+
+import "errors"
+
+var (
+	ErrPaymentNotFound     = errors.New("payment not found")
+	ErrUnsupportedProvider = errors.New("unsupported payment provider")
+	ErrGatewayRequest      = errors.New("payment gateway request failed")
+	ErrInvalidTransition   = errors.New("invalid payment status transition")
+	ErrInvalidSignature    = errors.New("invalid webhook signature")
+	ErrInvalidWebhook      = errors.New("invalid webhook payload")
+)
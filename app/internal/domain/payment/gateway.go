@@ -0,0 +1,24 @@
+package payment
+
+import (
+	"context"
+
+	"example.com/my-golang-sample/app/internal/domain/money"
+)
+
+// Gateway is implemented by every online payment provider adapter.
+type Gateway interface {
+	Provider() Provider
+	CreateSession(ctx context.Context, req SessionRequest) (*Session, error)
+	// Authorise confirms a payment the customer approved, so it can be
+	// captured later.
+	Authorise(ctx context.Context, reference string, amount money.Money) error
+	Capture(ctx context.Context, reference string, amount money.Money) error
+	Refund(ctx context.Context, reference string, amount money.Money) error
+	Void(ctx context.Context, reference string, amount money.Money) error
+	// SignatureHeader names the one request header that carries the
+	// webhook signature.
+	SignatureHeader() string
+	// ParseWebhook verifies the signature of a notification and decodes it.
+	ParseWebhook(payload []byte, signature string) (*WebhookEvent, error)
+}
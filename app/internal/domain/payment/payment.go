@@ -0,0 +1,97 @@
+package payment
+
+import (
+	"strings"
+	"time"
+
+	"example.com/my-golang-sample/app/internal/domain/money"
+)
+
+// Provider identifies an online payment provider, e.g. "tamara".
+type Provider string
+
+const (
+	ProviderTamara Provider = "tamara"
+)
+
+// ParseProvider normalises a provider name coming from a URL or config.
+func ParseProvider(s string) (Provider, error) {
+	p := Provider(strings.ToLower(strings.TrimSpace(s)))
+	switch p {
+	case ProviderTamara:
+		return p, nil
+	default:
+		return "", ErrUnsupportedProvider
+	}
+}
+
+type Status string
+
+const (
+	StatusPending    Status = "PENDING"
+	StatusAuthorized Status = "AUTHORIZED"
+	StatusCaptured   Status = "CAPTURED"
+	StatusRefunded   Status = "REFUNDED"
+	StatusVoided     Status = "VOIDED"
+	StatusFailed     Status = "FAILED"
+)
+
+// Payment is the local record of a provider session opened for an order.
+type Payment struct {
+	ID          int64
+	OrderID     int64
+	Provider    Provider
+	Reference   string
+	RedirectURL string
+	Amount      money.Money
+	Status      Status
+	CreatedAt   time.Time
+}
+
+type Customer struct {
+	ID    int64
+	Name  string
+	Email string
+}
+
+type SessionItem struct {
+	ProductID int64
+	Name      string
+	UnitPrice money.Money
+	Quantity  int64
+}
+
+// SessionRequest carries everything a provider needs to build a hosted checkout.
+type SessionRequest struct {
+	OrderID  int64
+	Amount   money.Money
+	Customer Customer
+	Items    []SessionItem
+}
+
+// Session is what a provider returns after opening a checkout:
+// Reference is the provider-side order id, RedirectURL the page the customer must visit.
+type Session struct {
+	Reference   string
+	RedirectURL string
+}
+
+type EventType string
+
+const (
+	EventApproved EventType = "APPROVED"
+	EventDeclined EventType = "DECLINED"
+	EventCanceled EventType = "CANCELED"
+	EventExpired  EventType = "EXPIRED"
+)
+
+// WebhookEvent is a provider notification after its signature has been verified.
+// ID is unique per provider and is used to make replays idempotent.
+type WebhookEvent struct {
+	ID        string
+	Provider  Provider
+	Type      EventType
+	Reference string
+	OrderID   int64
+	Payload   []byte
+}
@@ -0,0 +1,13 @@
+package payment
+
+import "context"
+
+type Repository interface {
+	Create(ctx context.Context, p *Payment) (*Payment, error)
+	GetByOrderID(ctx context.Context, orderID int64) (*Payment, error)
+	UpdateStatus(ctx context.Context, id int64, status Status) error
+
+	// RecordEvent stores a webhook event once and reports whether it was already processed.
+	RecordEvent(ctx context.Context, e *WebhookEvent) (processed bool, err error)
+	MarkEventProcessed(ctx context.Context, provider Provider, eventID string) error
+}
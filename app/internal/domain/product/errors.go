@@ -5,5 +5,9 @@ import "errors"
 var (
 	ErrProductNotFound = errors.New("product not found")
 	ErrOutOfStock      = errors.New("product out of stock")
+	// ErrPriceUnavailable: the product has no price in the requested currency.
+	ErrPriceUnavailable  = errors.New("product is not sold in this currency")
+	ErrPriceNotFound     = errors.New("price not found")
+	ErrBasePriceRequired = errors.New("base price cannot be removed")
 )
 
@@ -1,18 +1,49 @@
 package product
 
+import "example.com/my-golang-sample/app/internal/domain/money"
+
+// StockAdjustmentReason explains a stock change that did not come from an
+// admin editing the product.
+type StockAdjustmentReason string
+
+const (
+	AdjustmentOrderCanceled StockAdjustmentReason = "ORDER_CANCELED"
+)
+
 type Product struct {
 	ID          int64
 	Name        string
 	Description string
-	Price       float64
+	Price       money.Money // base price, in the store currency
 	Stock       int64
 	CategoryID  int64
 	IsActive    bool
+	// Prices is the price list for the other currencies the product is sold in.
+	Prices []money.Money
 }
 
+// PriceIn returns the price the product sells for in currency. An empty
+// currency means the base price.
+func (p *Product) PriceIn(currency string) (money.Money, error) {
+	if currency == "" || currency == p.Price.Currency {
+		return p.Price, nil
+	}
+	for _, price := range p.Prices {
+		if price.Currency == currency {
+			return price, nil
+		}
+	}
+	return money.Money{}, ErrPriceUnavailable
+}
+
+// SortFields are the fields a product list can be sorted by.
+var SortFields = []string{"id", "name", "price", "stock"}
+
 type ListFilter struct {
 	CategoryID *int64
 	Search     string
 	OnlyActive bool
+	// Currency keeps only products that have a price in this currency.
+	Currency string
 }
 
@@ -1,13 +1,21 @@
 package product
 
-import "context"
+import (
+	"context"
+
+	"example.com/my-golang-sample/app/internal/domain/money"
+	"example.com/my-golang-sample/app/internal/domain/page"
+)
 
 type Repository interface {
 	Create(ctx context.Context, p *Product) (*Product, error)
 	Update(ctx context.Context, p *Product) (*Product, error)
 	Delete(ctx context.Context, id int64) error
 	GetByID(ctx context.Context, id int64) (*Product, error)
-	List(ctx context.Context, filter ListFilter) ([]*Product, error)
+	List(ctx context.Context, filter ListFilter, p page.Request) ([]*Product, page.Info, error)
 	GetByIDs(ctx context.Context, ids []int64) ([]*Product, error)
+	// SetPrice adds or replaces the price list entry for price.Currency.
+	SetPrice(ctx context.Context, productID int64, price money.Money) error
+	DeletePrice(ctx context.Context, productID int64, currency string) error
 }
 
@@ -0,0 +1,53 @@
+package product
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"unicode"
+
+	"example.com/my-golang-sample/app/internal/domain/page"
+)
+
+// SortRelevance orders search results best match first. It is only accepted
+// together with a search query.
+const SortRelevance = "relevance"
+
+// minSearchPrefix is the shortest prefix a fuzzy match is allowed to use.
+const minSearchPrefix = 4
+
+// Searcher runs free-text product searches.
+type Searcher interface {
+	// Search returns one page of the products matching filter.Search and the
+	// rest of filter, most relevant first. Results are paged by page number
+	// only; no cursor is issued.
+	Search(ctx context.Context, filter ListFilter, p page.Request) ([]*Product, page.Info, error)
+}
+
+// SearchTerms splits a query into distinct lower-case words. Punctuation
+// separates words and is dropped, so the terms are safe to embed in a
+// full-text query.
+func SearchTerms(query string) []string {
+	words := strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	terms := make([]string, 0, len(words))
+	for _, w := range words {
+		if !slices.Contains(terms, w) {
+			terms = append(terms, w)
+		}
+	}
+	return terms
+}
+
+// SearchPrefix is the leading part of term that a word has to start with to
+// count as a fuzzy match. Longer words keep only their first half, so a typo
+// further in still matches ("keybaord" finds "keyboard").
+func SearchPrefix(term string) string {
+	r := []rune(term)
+	n := max(minSearchPrefix, (len(r)+1)/2)
+	if n >= len(r) {
+		return term
+	}
+	return string(r[:n])
+}
@@ -10,4 +10,5 @@ var (
 	ErrInvalidCredential       = errors.New("invalid credential")
 	ErrAdminCannotCreateAdmin  = errors.New("admin cannot create admin")
 	ErrAdminCannotPromoteAdmin = errors.New("admin cannot promote admin")
+	ErrEmailNotVerified        = errors.New("email not verified")
 )
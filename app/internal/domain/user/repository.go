@@ -1,12 +1,16 @@
 package user
 
-import "context"
+import (
+	"context"
+
+	"example.com/my-golang-sample/app/internal/domain/page"
+)
 
 type Repository interface {
 	Create(ctx context.Context, u *User) (*User, error)
 	GetByID(ctx context.Context, id int64) (*User, error)
 	GetByEmail(ctx context.Context, email string) (*User, error)
-	List(ctx context.Context, filter ListUsersFilter) ([]*User, error)
+	List(ctx context.Context, filter ListUsersFilter, p page.Request) ([]*User, page.Info, error)
 	Update(ctx context.Context, u *User) (*User, error)
 	Delete(ctx context.Context, id int64) error
 
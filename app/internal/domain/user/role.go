@@ -43,22 +43,3 @@ func ParseRoleCode(s string) (RoleCode, error) {
 	}
 	return c, nil
 }
-
-// =====================
-// Policy phân quyền cho việc gán role
-// =====================
-
-// CanAssignRole: kiểm tra executorRole có được phép gán targetRole hay không
-//
-// Rule theo yêu cầu của bạn:
-// - Chỉ SUPER_ADMIN mới được tạo/assign user có role ADMIN
-// - ADMIN không được tạo/assign user role ADMIN
-func CanAssignRole(executorRole RoleCode, targetRole RoleCode) bool {
-	// Nếu target là ADMIN thì chỉ SUPER_ADMIN mới được phép
-	if targetRole == RoleCodeAdmin {
-		return executorRole == RoleCodeSuperAdmin
-	}
-
-	// Các role khác (ví dụ CUSTOMER) tạm cho phép
-	return true
-}
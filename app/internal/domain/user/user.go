@@ -1,5 +1,7 @@
 package user
 
+import "time"
+
 type User struct {
 	ID           int64
 	Name         string
@@ -7,8 +9,17 @@ type User struct {
 	PasswordHash string
 	UserRoleID   int64
 	RoleCode     RoleCode
+	// EmailVerifiedAt is nil until a self-registered user confirms the email.
+	EmailVerifiedAt *time.Time
+}
+
+func (u *User) IsEmailVerified() bool {
+	return u.EmailVerifiedAt != nil
 }
 
+// SortFields are the fields a user list can be sorted by.
+var SortFields = []string{"id", "name", "email"}
+
 type ListUsersFilter struct {
 	RoleCode *RoleCode
 }
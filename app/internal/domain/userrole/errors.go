@@ -7,4 +7,8 @@ var (
 	ErrRoleCodeExisted = errors.New("role code already exists")
 	ErrRoleInUse       = errors.New("role is in use")
 	ErrRoleImmutable   = errors.New("system role cannot be modified")
+	ErrRankTooHigh     = errors.New("role rank must be below your own")
+
+	ErrUnknownPermission     = errors.New("unknown permission")
+	ErrCannotGrantPermission = errors.New("cannot grant a permission you do not have")
 )
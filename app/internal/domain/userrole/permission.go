@@ -0,0 +1,74 @@
+package userrole
+
+import (
+	"context"
+	"strings"
+
+	domuser "example.com/my-golang-sample/app/internal/domain/user"
+)
+
+// Permission is one admin capability, named "<resource>:<action>".
+type Permission string
+
+const (
+	PermRolesRead          Permission = "roles:read"
+	PermRolesWrite         Permission = "roles:write"
+	PermUsersRead          Permission = "users:read"
+	PermUsersWrite         Permission = "users:write"
+	PermCategoriesRead     Permission = "categories:read"
+	PermCategoriesWrite    Permission = "categories:write"
+	PermProductsRead       Permission = "products:read"
+	PermProductsWrite      Permission = "products:write"
+	PermOrdersRead         Permission = "orders:read"
+	PermOrdersUpdateStatus Permission = "orders:update_status"
+	PermAuditRead          Permission = "audit:read"
+)
+
+var allPermissions = []Permission{
+	PermRolesRead,
+	PermRolesWrite,
+	PermUsersRead,
+	PermUsersWrite,
+	PermCategoriesRead,
+	PermCategoriesWrite,
+	PermProductsRead,
+	PermProductsWrite,
+	PermOrdersRead,
+	PermOrdersUpdateStatus,
+	PermAuditRead,
+}
+
+// AllPermissions lists every permission the API checks.
+func AllPermissions() []Permission {
+	return append([]Permission(nil), allPermissions...)
+}
+
+// ParsePermission chỉ nhận các permission đã được định nghĩa ở trên
+func ParsePermission(s string) (Permission, error) {
+	p := Permission(strings.ToLower(strings.TrimSpace(s)))
+	for _, known := range allPermissions {
+		if p == known {
+			return p, nil
+		}
+	}
+	return "", ErrUnknownPermission
+}
+
+// DefaultPermissions is what a system role starts with. SUPER_ADMIN always
+// holds every permission; ADMIN starts with all of them but can be narrowed.
+func DefaultPermissions(code domuser.RoleCode) []Permission {
+	switch code {
+	case domuser.RoleCodeSuperAdmin, domuser.RoleCodeAdmin:
+		return AllPermissions()
+	default:
+		return nil
+	}
+}
+
+type PermissionRepository interface {
+	ListByRole(ctx context.Context, roleID int64) ([]Permission, error)
+	ListByRoleCode(ctx context.Context, code domuser.RoleCode) ([]Permission, error)
+	// Grant is a no-op when the role already has the permission.
+	Grant(ctx context.Context, roleID int64, p Permission) error
+	Revoke(ctx context.Context, roleID int64, p Permission) error
+}
@@ -0,0 +1,90 @@
+package userrole
+
+import (
+	"context"
+
+	domuser "example.com/my-golang-sample/app/internal/domain/user"
+)
+
+// Ranks of the system roles. A role outranks every role with a lower rank.
+const (
+	RankSuperAdmin = 100
+	RankAdmin      = 50
+	RankCustomer   = 0
+)
+
+// DefaultRank is the rank of a system role before anything is stored;
+// other roles rank lowest.
+func DefaultRank(code domuser.RoleCode) int {
+	switch code {
+	case domuser.RoleCodeSuperAdmin:
+		return RankSuperAdmin
+	case domuser.RoleCodeAdmin:
+		return RankAdmin
+	default:
+		return RankCustomer
+	}
+}
+
+type RankResolver interface {
+	RankOf(ctx context.Context, code domuser.RoleCode) (int, error)
+}
+
+type defaultRanks struct{}
+
+func (defaultRanks) RankOf(ctx context.Context, code domuser.RoleCode) (int, error) {
+	return DefaultRank(code), nil
+}
+
+type repositoryRanks struct {
+	repo Repository
+}
+
+// RanksFrom reads ranks from the stored roles.
+func RanksFrom(repo Repository) RankResolver {
+	return repositoryRanks{repo: repo}
+}
+
+func (r repositoryRanks) RankOf(ctx context.Context, code domuser.RoleCode) (int, error) {
+	role, err := r.repo.GetByCode(ctx, string(code))
+	if err != nil {
+		return 0, err
+	}
+	return role.Rank, nil
+}
+
+// AssignmentPolicy là rule phân quyền dựa trên rank: executor chỉ được gán,
+// thu hồi hoặc chỉnh sửa các role có rank thấp hơn hẳn role của mình.
+type AssignmentPolicy struct {
+	ranks RankResolver
+}
+
+// NewAssignmentPolicy uses DefaultRank when ranks is nil.
+func NewAssignmentPolicy(ranks RankResolver) *AssignmentPolicy {
+	if ranks == nil {
+		ranks = defaultRanks{}
+	}
+	return &AssignmentPolicy{ranks: ranks}
+}
+
+// CanAssign reports whether executor ranks strictly above target.
+func (p *AssignmentPolicy) CanAssign(ctx context.Context, executor, target domuser.RoleCode) (bool, error) {
+	executorRank, err := p.ranks.RankOf(ctx, executor)
+	if err != nil {
+		return false, err
+	}
+	targetRank, err := p.ranks.RankOf(ctx, target)
+	if err != nil {
+		return false, err
+	}
+	return targetRank < executorRank, nil
+}
+
+// CanSetRank reports whether executor may give a role the rank.
+func (p *AssignmentPolicy) CanSetRank(ctx context.Context, executor domuser.RoleCode, rank int) (bool, error) {
+	executorRank, err := p.ranks.RankOf(ctx, executor)
+	if err != nil {
+		return false, err
+	}
+	return rank < executorRank, nil
+}
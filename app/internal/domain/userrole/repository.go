@@ -2,6 +2,8 @@ package userrole
 
 import (
 	"context"
+
+	"example.com/my-golang-sample/app/internal/domain/page"
 )
 
 type Repository interface {
@@ -10,6 +12,6 @@ type Repository interface {
 	Delete(ctx context.Context, id int64) error
 	GetByID(ctx context.Context, id int64) (*UserRole, error)
 	GetByCode(ctx context.Context, code string) (*UserRole, error)
-	List(ctx context.Context, filter ListFilter) ([]*UserRole, error)
+	List(ctx context.Context, filter ListFilter, p page.Request) ([]*UserRole, page.Info, error)
 }
 
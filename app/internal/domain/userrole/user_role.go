@@ -10,8 +10,13 @@ type UserRole struct {
 	Name        string
 	Description string
 	IsSystem    bool
+	// Rank orders roles: users may only assign roles ranked below their own.
+	Rank int
 }
 
+// SortFields are the fields a role list can be sorted by.
+var SortFields = []string{"id", "code", "name", "rank"}
+
 type ListFilter struct {
 	Query *string
 }
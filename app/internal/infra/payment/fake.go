@@ -0,0 +1,134 @@
+package payment
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"example.com/my-golang-sample/app/internal/domain/money"
+	dompayment "example.com/my-golang-sample/app/internal/domain/payment"
+)
+
+// FakeGateway is an in-process Gateway used by tests and local runs without
+// provider credentials. It never touches the network.
+type FakeGateway struct {
+	provider    dompayment.Provider
+	redirectURL string
+
+	mu       sync.Mutex
+	sessions map[string]dompayment.SessionRequest
+	Calls    []FakeCall
+	// Err, when set, is returned by every call instead of succeeding.
+	Err error
+	// WebhookSecret is the HMAC-SHA256 key for SignWebhook/ParseWebhook.
+	WebhookSecret string
+}
+
+// FakeWebhook is the notification body understood by FakeGateway.
+type FakeWebhook struct {
+	ID        string               `json:"id"`
+	Type      dompayment.EventType `json:"type"`
+	Reference string               `json:"reference"`
+	OrderID   int64                `json:"order_id"`
+}
+
+type FakeCall struct {
+	Op        string
+	Reference string
+	Amount    money.Money
+}
+
+func NewFakeGateway(provider dompayment.Provider, redirectURL string) *FakeGateway {
+	return &FakeGateway{
+		provider:    provider,
+		redirectURL: redirectURL,
+		sessions:    make(map[string]dompayment.SessionRequest),
+	}
+}
+
+func (g *FakeGateway) Provider() dompayment.Provider {
+	return g.provider
+}
+
+func (g *FakeGateway) CreateSession(ctx context.Context, req dompayment.SessionRequest) (*dompayment.Session, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.Err != nil {
+		return nil, g.Err
+	}
+
+	ref := fmt.Sprintf("fake-%s-%d", g.provider, req.OrderID)
+	g.sessions[ref] = req
+	g.Calls = append(g.Calls, FakeCall{Op: "create", Reference: ref, Amount: req.Amount})
+
+	return &dompayment.Session{
+		Reference:   ref,
+		RedirectURL: g.redirectURL + "?ref=" + ref,
+	}, nil
+}
+
+func (g *FakeGateway) Authorise(ctx context.Context, reference string, amount money.Money) error {
+	return g.record("authorise", reference, amount)
+}
+
+func (g *FakeGateway) Capture(ctx context.Context, reference string, amount money.Money) error {
+	return g.record("capture", reference, amount)
+}
+
+func (g *FakeGateway) Refund(ctx context.Context, reference string, amount money.Money) error {
+	return g.record("refund", reference, amount)
+}
+
+func (g *FakeGateway) Void(ctx context.Context, reference string, amount money.Money) error {
+	return g.record("void", reference, amount)
+}
+
+func (g *FakeGateway) record(op, reference string, amount money.Money) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.Err != nil {
+		return g.Err
+	}
+	if _, ok := g.sessions[reference]; !ok {
+		return dompayment.ErrPaymentNotFound
+	}
+	g.Calls = append(g.Calls, FakeCall{Op: op, Reference: reference, Amount: amount})
+	return nil
+}
+
+// SignWebhook returns the hex HMAC-SHA256 of payload, as a provider would send it.
+func (g *FakeGateway) SignWebhook(payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(g.WebhookSecret))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (g *FakeGateway) SignatureHeader() string {
+	return "X-Webhook-Signature"
+}
+
+func (g *FakeGateway) ParseWebhook(payload []byte, signature string) (*dompayment.WebhookEvent, error) {
+	if g.WebhookSecret == "" || !hmac.Equal([]byte(g.SignWebhook(payload)), []byte(signature)) {
+		return nil, dompayment.ErrInvalidSignature
+	}
+
+	var body FakeWebhook
+	if err := json.Unmarshal(payload, &body); err != nil || body.ID == "" || body.OrderID == 0 {
+		return nil, dompayment.ErrInvalidWebhook
+	}
+
+	return &dompayment.WebhookEvent{
+		ID:        body.ID,
+		Provider:  g.provider,
+		Type:      body.Type,
+		Reference: body.Reference,
+		OrderID:   body.OrderID,
+		Payload:   payload,
+	}, nil
+}
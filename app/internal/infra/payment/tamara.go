@@ -0,0 +1,295 @@
+package payment
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"example.com/my-golang-sample/app/internal/domain/money"
+	dompayment "example.com/my-golang-sample/app/internal/domain/payment"
+)
+
+type TamaraConfig struct {
+	BaseURL         string
+	APIToken        string
+	CountryCode     string
+	SuccessURL      string
+	FailureURL      string
+	CancelURL       string
+	NotificationURL string
+	// NotificationToken is the key Tamara uses to sign the tamaraToken JWT on webhooks.
+	NotificationToken string
+	Timeout           time.Duration
+}
+
+// TamaraGateway talks to the Tamara merchant API.
+type TamaraGateway struct {
+	cfg    TamaraConfig
+	client *http.Client
+}
+
+func NewTamaraGateway(cfg TamaraConfig) *TamaraGateway {
+	if cfg.Timeout == 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	if cfg.CountryCode == "" {
+		cfg.CountryCode = "SA"
+	}
+	cfg.BaseURL = strings.TrimRight(cfg.BaseURL, "/")
+	return &TamaraGateway{
+		cfg:    cfg,
+		client: &http.Client{Timeout: cfg.Timeout},
+	}
+}
+
+func (g *TamaraGateway) Provider() dompayment.Provider {
+	return dompayment.ProviderTamara
+}
+
+type tamaraAmount struct {
+	Amount   money.Money `json:"amount"`
+	Currency string      `json:"currency"`
+}
+
+func newTamaraAmount(m money.Money) tamaraAmount {
+	return tamaraAmount{Amount: m, Currency: m.Currency}
+}
+
+type tamaraItem struct {
+	ReferenceID string       `json:"reference_id"`
+	Type        string       `json:"type"`
+	Name        string       `json:"name"`
+	SKU         string       `json:"sku"`
+	Quantity    int64        `json:"quantity"`
+	UnitPrice   tamaraAmount `json:"unit_price"`
+	TotalAmount tamaraAmount `json:"total_amount"`
+}
+
+type tamaraConsumer struct {
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Email     string `json:"email"`
+}
+
+type tamaraMerchantURL struct {
+	Success      string `json:"success"`
+	Failure      string `json:"failure"`
+	Cancel       string `json:"cancel"`
+	Notification string `json:"notification"`
+}
+
+type tamaraCheckoutRequest struct {
+	OrderReferenceID string            `json:"order_reference_id"`
+	OrderNumber      string            `json:"order_number"`
+	TotalAmount      tamaraAmount      `json:"total_amount"`
+	ShippingAmount   tamaraAmount      `json:"shipping_amount"`
+	TaxAmount        tamaraAmount      `json:"tax_amount"`
+	Description      string            `json:"description"`
+	CountryCode      string            `json:"country_code"`
+	PaymentType      string            `json:"payment_type"`
+	Items            []tamaraItem      `json:"items"`
+	Consumer         tamaraConsumer    `json:"consumer"`
+	MerchantURL      tamaraMerchantURL `json:"merchant_url"`
+}
+
+type tamaraCheckoutResponse struct {
+	OrderID     string `json:"order_id"`
+	CheckoutID  string `json:"checkout_id"`
+	CheckoutURL string `json:"checkout_url"`
+}
+
+func (g *TamaraGateway) CreateSession(ctx context.Context, req dompayment.SessionRequest) (*dompayment.Session, error) {
+	ref := strconv.FormatInt(req.OrderID, 10)
+	zero := newTamaraAmount(money.Zero(req.Amount.Currency))
+
+	items := make([]tamaraItem, 0, len(req.Items))
+	for _, item := range req.Items {
+		productRef := strconv.FormatInt(item.ProductID, 10)
+		total, err := item.UnitPrice.Mul(item.Quantity)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, tamaraItem{
+			ReferenceID: productRef,
+			Type:        "Physical",
+			Name:        item.Name,
+			SKU:         productRef,
+			Quantity:    item.Quantity,
+			UnitPrice:   newTamaraAmount(item.UnitPrice),
+			TotalAmount: newTamaraAmount(total),
+		})
+	}
+
+	firstName, lastName := splitName(req.Customer.Name)
+	body := tamaraCheckoutRequest{
+		OrderReferenceID: ref,
+		OrderNumber:      ref,
+		TotalAmount:      newTamaraAmount(req.Amount),
+		ShippingAmount:   zero,
+		TaxAmount:        zero,
+		Description:      "Order #" + ref,
+		CountryCode:      g.cfg.CountryCode,
+		PaymentType:      "PAY_BY_INSTALMENTS",
+		Items:            items,
+		Consumer: tamaraConsumer{
+			FirstName: firstName,
+			LastName:  lastName,
+			Email:     req.Customer.Email,
+		},
+		MerchantURL: tamaraMerchantURL{
+			Success:      g.cfg.SuccessURL,
+			Failure:      g.cfg.FailureURL,
+			Cancel:       g.cfg.CancelURL,
+			Notification: g.cfg.NotificationURL,
+		},
+	}
+
+	var resp tamaraCheckoutResponse
+	if err := g.do(ctx, http.MethodPost, "/checkout", body, &resp); err != nil {
+		return nil, err
+	}
+	if resp.OrderID == "" || resp.CheckoutURL == "" {
+		return nil, fmt.Errorf("tamara: incomplete checkout response: %w", dompayment.ErrGatewayRequest)
+	}
+
+	return &dompayment.Session{
+		Reference:   resp.OrderID,
+		RedirectURL: resp.CheckoutURL,
+	}, nil
+}
+
+// Authorise tells Tamara the approved order was received; without it Tamara
+// does not release the funds and eventually expires the order.
+func (g *TamaraGateway) Authorise(ctx context.Context, reference string, amount money.Money) error {
+	return g.do(ctx, http.MethodPost, "/orders/"+reference+"/authorise", map[string]any{}, nil)
+}
+
+func (g *TamaraGateway) Capture(ctx context.Context, reference string, amount money.Money) error {
+	body := map[string]any{
+		"order_id":        reference,
+		"total_amount":    newTamaraAmount(amount),
+		"shipping_amount": newTamaraAmount(money.Zero(amount.Currency)),
+		"tax_amount":      newTamaraAmount(money.Zero(amount.Currency)),
+	}
+	return g.do(ctx, http.MethodPost, "/payments/capture", body, nil)
+}
+
+func (g *TamaraGateway) Refund(ctx context.Context, reference string, amount money.Money) error {
+	body := map[string]any{
+		"total_amount": newTamaraAmount(amount),
+		"comment":      "Refund for order " + reference,
+	}
+	return g.do(ctx, http.MethodPost, "/payments/simplified-refund/"+reference, body, nil)
+}
+
+func (g *TamaraGateway) Void(ctx context.Context, reference string, amount money.Money) error {
+	body := map[string]any{
+		"total_amount": newTamaraAmount(amount),
+	}
+	return g.do(ctx, http.MethodPost, "/orders/"+reference+"/cancel", body, nil)
+}
+
+func (g *TamaraGateway) do(ctx context.Context, method, path string, body any, out any) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, g.cfg.BaseURL+path, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+g.cfg.APIToken)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("tamara %s %s: %v: %w", method, path, err, dompayment.ErrGatewayRequest)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("tamara %s %s: status %d: %w", method, path, resp.StatusCode, dompayment.ErrGatewayRequest)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+type tamaraWebhookPayload struct {
+	OrderID          string `json:"order_id"`
+	OrderReferenceID string `json:"order_reference_id"`
+	EventType        string `json:"event_type"`
+}
+
+var tamaraEventTypes = map[string]dompayment.EventType{
+	"order_approved": dompayment.EventApproved,
+	"order_declined": dompayment.EventDeclined,
+	"order_canceled": dompayment.EventCanceled,
+	"order_expired":  dompayment.EventExpired,
+}
+
+// SignatureHeader is Authorization: Tamara sends the tamaraToken both there,
+// as a Bearer token, and in the query string, which ends up in access logs.
+func (g *TamaraGateway) SignatureHeader() string {
+	return "Authorization"
+}
+
+// ParseWebhook verifies the tamaraToken (an HS256 JWT signed with the
+// notification token) and decodes the order status notification.
+func (g *TamaraGateway) ParseWebhook(payload []byte, signature string) (*dompayment.WebhookEvent, error) {
+	token, ok := strings.CutPrefix(signature, "Bearer ")
+	if !ok || token == "" || g.cfg.NotificationToken == "" {
+		return nil, dompayment.ErrInvalidSignature
+	}
+	_, err := jwt.Parse(token, func(token *jwt.Token) (interface{}, error) {
+		return []byte(g.cfg.NotificationToken), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	if err != nil {
+		return nil, dompayment.ErrInvalidSignature
+	}
+
+	var body tamaraWebhookPayload
+	if err := json.Unmarshal(payload, &body); err != nil {
+		return nil, dompayment.ErrInvalidWebhook
+	}
+	orderID, err := strconv.ParseInt(body.OrderReferenceID, 10, 64)
+	if err != nil || body.OrderID == "" || body.EventType == "" {
+		return nil, dompayment.ErrInvalidWebhook
+	}
+
+	eventType, ok := tamaraEventTypes[body.EventType]
+	if !ok {
+		eventType = dompayment.EventType(strings.ToUpper(body.EventType))
+	}
+
+	return &dompayment.WebhookEvent{
+		// Tamara không gửi event id riêng → order_id + event_type là duy nhất
+		ID:        body.OrderID + ":" + body.EventType,
+		Provider:  dompayment.ProviderTamara,
+		Type:      eventType,
+		Reference: body.OrderID,
+		OrderID:   orderID,
+		Payload:   payload,
+	}, nil
+}
+
+func splitName(name string) (string, string) {
+	parts := strings.Fields(name)
+	switch len(parts) {
+	case 0:
+		return "", ""
+	case 1:
+		return parts[0], parts[0]
+	default:
+		return parts[0], strings.Join(parts[1:], " ")
+	}
+}
@@ -0,0 +1,142 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	domaudit "example.com/my-golang-sample/app/internal/domain/audit"
+	"example.com/my-golang-sample/app/internal/domain/page"
+)
+
+// AuditLogRepository appends to audit_logs. It has no update or delete.
+type AuditLogRepository struct {
+	db *sql.DB
+}
+
+func NewAuditLogRepository(db *sql.DB) *AuditLogRepository {
+	return &AuditLogRepository{db: db}
+}
+
+func (r *AuditLogRepository) Append(ctx context.Context, e *domaudit.Entry) error {
+	details, err := marshalJSONColumn(e.Details, len(e.Details))
+	if err != nil {
+		return err
+	}
+	changes, err := marshalJSONColumn(e.Changes, len(e.Changes))
+	if err != nil {
+		return err
+	}
+
+	res, err := r.db.ExecContext(ctx, `
+        INSERT INTO audit_logs (actor_id, action, target_type, target_id, ip, request_id, details, changes)
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+    `, e.ActorID, e.Action, e.TargetType, e.TargetID, e.IP, e.RequestID, details, changes)
+	if err != nil {
+		return err
+	}
+	e.ID, _ = res.LastInsertId()
+	return nil
+}
+
+var auditKeyset = keyset[*domaudit.Entry]{
+	idColumn: "id",
+	id:       func(e *domaudit.Entry) int64 { return e.ID },
+}
+
+func (r *AuditLogRepository) List(ctx context.Context, filter domaudit.ListFilter, req page.Request) ([]*domaudit.Entry, page.Info, error) {
+	var clauses []string
+	var args []any
+
+	if filter.ActorID != nil {
+		clauses = append(clauses, "actor_id = ?")
+		args = append(args, *filter.ActorID)
+	}
+	if filter.Action != nil {
+		clauses = append(clauses, "action = ?")
+		args = append(args, string(*filter.Action))
+	}
+	if filter.TargetType != nil {
+		clauses = append(clauses, "target_type = ?")
+		args = append(args, *filter.TargetType)
+	}
+	if filter.TargetID != nil {
+		clauses = append(clauses, "target_id = ?")
+		args = append(args, *filter.TargetID)
+	}
+	if filter.RequestID != nil {
+		clauses = append(clauses, "request_id = ?")
+		args = append(args, *filter.RequestID)
+	}
+	if filter.From != nil {
+		clauses = append(clauses, "created_at >= ?")
+		args = append(args, *filter.From)
+	}
+	if filter.To != nil {
+		clauses = append(clauses, "created_at < ?")
+		args = append(args, *filter.To)
+	}
+
+	total, err := countRows(ctx, r.db, "audit_logs", clauses, args)
+	if err != nil {
+		return nil, page.Info{}, err
+	}
+
+	clauses, args = auditKeyset.where(req, clauses, args)
+	order, args := auditKeyset.orderAndLimit(req, args)
+	query := `
+        SELECT id, actor_id, action, target_type, target_id, ip, request_id, details, changes, created_at
+        FROM audit_logs
+    ` + whereClause(clauses) + order
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, page.Info{}, err
+	}
+	defer rows.Close()
+
+	var entries []*domaudit.Entry
+	for rows.Next() {
+		var (
+			e                domaudit.Entry
+			actorID          sql.NullInt64
+			action           string
+			details, changes []byte
+			createdAt        sql.NullTime
+		)
+		if err := rows.Scan(&e.ID, &actorID, &action, &e.TargetType, &e.TargetID, &e.IP, &e.RequestID, &details, &changes, &createdAt); err != nil {
+			return nil, page.Info{}, err
+		}
+		e.Action = domaudit.Action(action)
+		if actorID.Valid {
+			e.ActorID = &actorID.Int64
+		}
+		if len(details) > 0 {
+			if err := json.Unmarshal(details, &e.Details); err != nil {
+				return nil, page.Info{}, err
+			}
+		}
+		if len(changes) > 0 {
+			if err := json.Unmarshal(changes, &e.Changes); err != nil {
+				return nil, page.Info{}, err
+			}
+		}
+		if createdAt.Valid {
+			e.CreatedAt = createdAt.Time
+		}
+		entries = append(entries, &e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, page.Info{}, err
+	}
+	entries, info := auditKeyset.page(req, total, entries)
+	return entries, info, nil
+}
+
+// marshalJSONColumn stores empty maps as NULL.
+func marshalJSONColumn(v any, n int) ([]byte, error) {
+	if n == 0 {
+		return nil, nil
+	}
+	return json.Marshal(v)
+}
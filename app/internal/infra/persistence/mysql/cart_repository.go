@@ -2,34 +2,85 @@ package mysql
 
 import (
 	"context"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
 
 	domcart "example.com/my-golang-sample/app/internal/domain/cart"
 )
 
 type CartRepository struct {
-	db *sql.DB
+	db       *sql.DB
+	currency string // currency of the unit_price snapshots
 }
 
-func NewCartRepository(db *sql.DB) *CartRepository {
-	return &CartRepository{db: db}
+func NewCartRepository(db *sql.DB, currency string) *CartRepository {
+	return &CartRepository{db: db, currency: currency}
 }
 
-func (r *CartRepository) AddOrUpdateItem(ctx context.Context, userID int64, productID int64, quantity int64) error {
+func (r *CartRepository) AddOrUpdateItem(ctx context.Context, owner domcart.Owner, productID int64, quantity int64) error {
+	userID, guestKey := ownerColumns(owner)
+	// Lưu lại giá hiện tại để GetCart phát hiện sản phẩm bị đổi giá
 	_, err := r.db.ExecContext(ctx, `
-        INSERT INTO cart_items (user_id, product_id, quantity)
-        VALUES (?, ?, ?)
-        ON DUPLICATE KEY UPDATE quantity = quantity + VALUES(quantity)
-    `, userID, productID, quantity)
+        INSERT INTO cart_items (user_id, guest_token, product_id, quantity, unit_price)
+        SELECT ?, ?, id, ?, price FROM products WHERE id = ?
+        ON DUPLICATE KEY UPDATE quantity = quantity + VALUES(quantity), unit_price = VALUES(unit_price)
+    `, userID, guestKey, quantity, productID)
 	return err
 }
 
-func (r *CartRepository) ListItems(ctx context.Context, userID int64) ([]domcart.Item, error) {
+func (r *CartRepository) SetItemQuantity(ctx context.Context, owner domcart.Owner, productID int64, quantity int64) error {
+	where, key := ownerFilter(owner)
+	res, err := r.db.ExecContext(ctx, `
+        UPDATE cart_items SET quantity = ?
+        WHERE `+where+` AND product_id = ?
+    `, quantity, key, productID)
+	if err != nil {
+		return err
+	}
+	// RowsAffected = 0 cả khi quantity không đổi, nên kiểm tra lại sự tồn tại
+	rows, _ := res.RowsAffected()
+	if rows == 0 {
+		return r.ensureItem(ctx, owner, productID)
+	}
+	return nil
+}
+
+func (r *CartRepository) RemoveItem(ctx context.Context, owner domcart.Owner, productID int64) error {
+	where, key := ownerFilter(owner)
+	res, err := r.db.ExecContext(ctx, `
+        DELETE FROM cart_items WHERE `+where+` AND product_id = ?
+    `, key, productID)
+	if err != nil {
+		return err
+	}
+	rows, _ := res.RowsAffected()
+	if rows == 0 {
+		return domcart.ErrCartItemNotFound
+	}
+	return nil
+}
+
+func (r *CartRepository) ensureItem(ctx context.Context, owner domcart.Owner, productID int64) error {
+	where, key := ownerFilter(owner)
+	var exists bool
+	if err := r.db.QueryRowContext(ctx, `
+        SELECT EXISTS(SELECT 1 FROM cart_items WHERE `+where+` AND product_id = ?)
+    `, key, productID).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return domcart.ErrCartItemNotFound
+	}
+	return nil
+}
+
+func (r *CartRepository) ListItems(ctx context.Context, owner domcart.Owner) ([]domcart.Item, error) {
+	where, key := ownerFilter(owner)
 	rows, err := r.db.QueryContext(ctx, `
-        SELECT product_id, quantity
+        SELECT product_id, quantity, unit_price
         FROM cart_items
-        WHERE user_id = ?
-    `, userID)
+        WHERE `+where, key)
 	if err != nil {
 		return nil, err
 	}
@@ -38,7 +89,7 @@ func (r *CartRepository) ListItems(ctx context.Context, userID int64) ([]domcart
 	var items []domcart.Item
 	for rows.Next() {
 		var item domcart.Item
-		if err := rows.Scan(&item.ProductID, &item.Quantity); err != nil {
+		if err := rows.Scan(&item.ProductID, &item.Quantity, scanMoney(&item.UnitPrice, r.currency)); err != nil {
 			return nil, err
 		}
 		items = append(items, item)
@@ -46,8 +97,64 @@ func (r *CartRepository) ListItems(ctx context.Context, userID int64) ([]domcart
 	return items, nil
 }
 
-func (r *CartRepository) Clear(ctx context.Context, userID int64) error {
-	_, err := r.db.ExecContext(ctx, `DELETE FROM cart_items WHERE user_id = ?`, userID)
+func (r *CartRepository) Clear(ctx context.Context, owner domcart.Owner) error {
+	where, key := ownerFilter(owner)
+	_, err := r.db.ExecContext(ctx, `DELETE FROM cart_items WHERE `+where, key)
 	return err
 }
 
+func (r *CartRepository) MergeItems(ctx context.Context, from, to domcart.Owner, items []domcart.Item) (retErr error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if retErr != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	// Xóa giỏ khách trước: nếu không còn dòng nào thì một lần đăng nhập khác đã gộp rồi
+	where, key := ownerFilter(from)
+	res, err := tx.ExecContext(ctx, `DELETE FROM cart_items WHERE `+where, key)
+	if err != nil {
+		return err
+	}
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		return tx.Rollback()
+	}
+
+	userID, guestKey := ownerColumns(to)
+	for _, item := range items {
+		// Dòng đã có giữ nguyên unit_price để GetCart vẫn báo được đổi giá
+		if _, err := tx.ExecContext(ctx, `
+            INSERT INTO cart_items (user_id, guest_token, product_id, quantity, unit_price)
+            SELECT ?, ?, id, ?, price FROM products WHERE id = ?
+            ON DUPLICATE KEY UPDATE quantity = VALUES(quantity)
+        `, userID, guestKey, item.Quantity, item.ProductID); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// ownerFilter trả về điều kiện WHERE (tên cột cố định) và tham số cho owner của giỏ.
+func ownerFilter(owner domcart.Owner) (string, any) {
+	if owner.IsGuest() {
+		return "guest_token = ?", hashGuestToken(owner.GuestToken)
+	}
+	return "user_id = ?", owner.UserID
+}
+
+func ownerColumns(owner domcart.Owner) (sql.NullInt64, sql.NullString) {
+	if owner.IsGuest() {
+		return sql.NullInt64{}, sql.NullString{String: hashGuestToken(owner.GuestToken), Valid: true}
+	}
+	return sql.NullInt64{Int64: owner.UserID, Valid: true}, sql.NullString{}
+}
+
+// Guest tokens are bearer secrets, so only their hash is stored.
+func hashGuestToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,67 @@
+package mysql
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	domcart "example.com/my-golang-sample/app/internal/domain/cart"
+)
+
+func newFakeCartDB(t *testing.T, guestRows int64, insertErr error) (*CartRepository, *fakeDB) {
+	db, fake := newFakeDB(t, func(query string, args []driver.Value) (*fakeRows, error) {
+		return nil, fmt.Errorf("unexpected query: %s", query)
+	})
+	fake.exec = func(query string, args []driver.Value) (int64, error) {
+		switch {
+		case strings.Contains(query, "DELETE FROM cart_items WHERE guest_token"):
+			return guestRows, nil
+		case strings.Contains(query, "INSERT INTO cart_items"):
+			return 1, insertErr
+		}
+		return 0, fmt.Errorf("unexpected exec: %s", query)
+	}
+	return NewCartRepository(db, "SAR"), fake
+}
+
+func TestCartRepository_MergeItems(t *testing.T) {
+	items := []domcart.Item{{ProductID: 1, Quantity: 5}, {ProductID: 2, Quantity: 3}}
+	tests := []struct {
+		name      string
+		guestRows int64
+		insertErr error
+		inserts   int
+		commits   int
+		wantErr   bool
+	}{
+		{"merges and empties the guest cart", 3, nil, 2, 1, false},
+		{"guest cart already merged", 0, nil, 0, 0, false},
+		{"failed insert rolls back", 3, errors.New("deadlock"), 1, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, fake := newFakeCartDB(t, tt.guestRows, tt.insertErr)
+
+			err := repo.MergeItems(context.Background(), domcart.GuestOwner("guest-token"), domcart.UserOwner(100), items)
+
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			inserts := 0
+			for _, q := range fake.queries {
+				if strings.Contains(q, "INSERT INTO cart_items") {
+					inserts++
+				}
+			}
+			require.Equal(t, tt.inserts, inserts)
+			require.Equal(t, tt.commits, fake.commits)
+		})
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	domcategory "example.com/my-golang-sample/app/internal/domain/category"
+	"example.com/my-golang-sample/app/internal/domain/page"
 )
 
 type CategoryRepository struct {
@@ -79,17 +80,34 @@ func (r *CategoryRepository) GetByID(ctx context.Context, id int64) (*domcategor
 	return &c, nil
 }
 
-func (r *CategoryRepository) List(ctx context.Context, filter domcategory.ListFilter) ([]*domcategory.Category, error) {
-	query := `SELECT id, name, slug, description, is_active FROM categories`
-	args := []any{}
+var categoryKeyset = keyset[*domcategory.Category]{
+	idColumn: "id",
+	id:       func(c *domcategory.Category) int64 { return c.ID },
+	fields: map[string]sortColumn[*domcategory.Category]{
+		"name": {column: "name", value: func(c *domcategory.Category) any { return c.Name }},
+		"slug": {column: "slug", value: func(c *domcategory.Category) any { return c.Slug }},
+	},
+}
+
+func (r *CategoryRepository) List(ctx context.Context, filter domcategory.ListFilter, req page.Request) ([]*domcategory.Category, page.Info, error) {
+	var clauses []string
+	var args []any
 	if filter.OnlyActive {
-		query += ` WHERE is_active = 1`
+		clauses = append(clauses, "is_active = 1")
+	}
+
+	total, err := countRows(ctx, r.db, "categories", clauses, args)
+	if err != nil {
+		return nil, page.Info{}, err
 	}
-	query += ` ORDER BY id DESC`
+
+	clauses, args = categoryKeyset.where(req, clauses, args)
+	order, args := categoryKeyset.orderAndLimit(req, args)
+	query := `SELECT id, name, slug, description, is_active FROM categories` + whereClause(clauses) + order
 
 	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, err
+		return nil, page.Info{}, err
 	}
 	defer rows.Close()
 
@@ -97,9 +115,13 @@ func (r *CategoryRepository) List(ctx context.Context, filter domcategory.ListFi
 	for rows.Next() {
 		var c domcategory.Category
 		if err := rows.Scan(&c.ID, &c.Name, &c.Slug, &c.Description, &c.IsActive); err != nil {
-			return nil, err
+			return nil, page.Info{}, err
 		}
 		categories = append(categories, &c)
 	}
-	return categories, nil
+	if err := rows.Err(); err != nil {
+		return nil, page.Info{}, err
+	}
+	categories, info := categoryKeyset.page(req, total, categories)
+	return categories, info, nil
 }
@@ -0,0 +1,134 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+)
+
+// fakeDB is a database/sql driver that answers queries from a handler
+// instead of a server and records every query it receives, so tests can
+// assert how many round trips a repository makes. Statements run through
+// exec, which returns the number of affected rows; transactions only count
+// commits, they do not isolate or undo anything.
+type fakeDB struct {
+	mu      sync.Mutex
+	queries []string
+	commits int
+	handle  func(query string, args []driver.Value) (*fakeRows, error)
+	exec    func(query string, args []driver.Value) (int64, error)
+}
+
+func newFakeDB(tb testing.TB, handle func(query string, args []driver.Value) (*fakeRows, error)) (*sql.DB, *fakeDB) {
+	tb.Helper()
+	fake := &fakeDB{handle: handle}
+	db := sql.OpenDB(fakeConnector{db: fake})
+	tb.Cleanup(func() { db.Close() })
+	return db, fake
+}
+
+func (f *fakeDB) queryCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.queries)
+}
+
+func (f *fakeDB) reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.queries = nil
+}
+
+type fakeConnector struct{ db *fakeDB }
+
+func (c fakeConnector) Connect(context.Context) (driver.Conn, error) {
+	return &fakeConn{db: c.db}, nil
+}
+
+func (c fakeConnector) Driver() driver.Driver { return fakeDriver{} }
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(string) (driver.Conn, error) {
+	return nil, errors.New("fakedb: open through sql.OpenDB")
+}
+
+type fakeConn struct{ db *fakeDB }
+
+func (c *fakeConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("fakedb: prepared statements are not supported")
+}
+
+func (c *fakeConn) Close() error { return nil }
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return fakeTx{db: c.db}, nil
+}
+
+func (c *fakeConn) QueryContext(_ context.Context, query string, named []driver.NamedValue) (driver.Rows, error) {
+	args := c.record(query, named)
+	rows, err := c.db.handle(query, args)
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func (c *fakeConn) ExecContext(_ context.Context, query string, named []driver.NamedValue) (driver.Result, error) {
+	args := c.record(query, named)
+	if c.db.exec == nil {
+		return nil, errors.New("fakedb: no exec handler")
+	}
+	affected, err := c.db.exec(query, args)
+	if err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(affected), nil
+}
+
+func (c *fakeConn) record(query string, named []driver.NamedValue) []driver.Value {
+	args := make([]driver.Value, len(named))
+	for i, nv := range named {
+		args[i] = nv.Value
+	}
+
+	c.db.mu.Lock()
+	c.db.queries = append(c.db.queries, query)
+	c.db.mu.Unlock()
+	return args
+}
+
+type fakeTx struct{ db *fakeDB }
+
+func (t fakeTx) Commit() error {
+	t.db.mu.Lock()
+	defer t.db.mu.Unlock()
+	t.db.commits++
+	return nil
+}
+
+func (t fakeTx) Rollback() error { return nil }
+
+// fakeRows is a fixed result set.
+type fakeRows struct {
+	columns []string
+	values  [][]driver.Value
+	next    int
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+
+func (r *fakeRows) Close() error { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.next >= len(r.values) {
+		return io.EOF
+	}
+	copy(dest, r.values[r.next])
+	r.next++
+	return nil
+}
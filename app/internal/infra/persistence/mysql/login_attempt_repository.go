@@ -0,0 +1,68 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	domauth "example.com/my-golang-sample/app/internal/domain/auth"
+)
+
+// LoginAttemptRepository keeps one failed-login counter row per email or IP.
+type LoginAttemptRepository struct {
+	db *sql.DB
+}
+
+func NewLoginAttemptRepository(db *sql.DB) *LoginAttemptRepository {
+	return &LoginAttemptRepository{db: db}
+}
+
+func (r *LoginAttemptRepository) Get(ctx context.Context, scope domauth.LoginScope, key string) (*domauth.LoginFailures, error) {
+	f := &domauth.LoginFailures{Scope: scope, Key: key}
+	var lockedUntil sql.NullTime
+	err := r.db.QueryRowContext(ctx, `
+        SELECT failures, last_failed_at, locked_until
+        FROM login_attempts
+        WHERE scope = ? AND attempt_key = ?
+    `, scope, key).Scan(&f.Count, &f.LastFailedAt, &lockedUntil)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return f, nil
+		}
+		return nil, err
+	}
+	if lockedUntil.Valid {
+		f.LockedUntil = &lockedUntil.Time
+	}
+	return f, nil
+}
+
+func (r *LoginAttemptRepository) RecordFailure(ctx context.Context, scope domauth.LoginScope, key string, now time.Time, window time.Duration) (*domauth.LoginFailures, error) {
+	// failures được gán trước last_failed_at nên IF() còn đọc giá trị cũ
+	_, err := r.db.ExecContext(ctx, `
+        INSERT INTO login_attempts (scope, attempt_key, failures, last_failed_at)
+        VALUES (?, ?, 1, ?)
+        ON DUPLICATE KEY UPDATE
+            failures = IF(last_failed_at < ?, 1, failures + 1),
+            last_failed_at = VALUES(last_failed_at)
+    `, scope, key, now, now.Add(-window))
+	if err != nil {
+		return nil, err
+	}
+	return r.Get(ctx, scope, key)
+}
+
+func (r *LoginAttemptRepository) Lock(ctx context.Context, scope domauth.LoginScope, key string, until time.Time) error {
+	_, err := r.db.ExecContext(ctx, `
+        UPDATE login_attempts
+        SET failures = 0, locked_until = ?
+        WHERE scope = ? AND attempt_key = ?
+    `, until, scope, key)
+	return err
+}
+
+func (r *LoginAttemptRepository) Clear(ctx context.Context, scope domauth.LoginScope, key string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM login_attempts WHERE scope = ? AND attempt_key = ?`, scope, key)
+	return err
+}
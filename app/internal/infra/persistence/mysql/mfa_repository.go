@@ -0,0 +1,132 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	domauth "example.com/my-golang-sample/app/internal/domain/auth"
+)
+
+type MFARepository struct {
+	db *sql.DB
+}
+
+func NewMFARepository(db *sql.DB) *MFARepository {
+	return &MFARepository{db: db}
+}
+
+func (r *MFARepository) GetTOTP(ctx context.Context, userID int64) (*domauth.TOTPSecret, error) {
+	var t domauth.TOTPSecret
+	var confirmedAt sql.NullTime
+	err := r.db.QueryRowContext(ctx, `
+        SELECT user_id, secret, confirmed_at, last_used_step, created_at
+        FROM user_totp
+        WHERE user_id = ?
+    `, userID).Scan(&t.UserID, &t.Secret, &confirmedAt, &t.LastUsedStep, &t.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domauth.ErrMFANotEnrolled
+		}
+		return nil, err
+	}
+	if confirmedAt.Valid {
+		t.ConfirmedAt = &confirmedAt.Time
+	}
+	return &t, nil
+}
+
+func (r *MFARepository) SaveTOTP(ctx context.Context, t *domauth.TOTPSecret) error {
+	_, err := r.db.ExecContext(ctx, `
+        INSERT INTO user_totp (user_id, secret, confirmed_at, last_used_step)
+        VALUES (?, ?, ?, ?)
+        ON DUPLICATE KEY UPDATE
+            secret = VALUES(secret),
+            confirmed_at = VALUES(confirmed_at),
+            last_used_step = VALUES(last_used_step)
+    `, t.UserID, t.Secret, t.ConfirmedAt, t.LastUsedStep)
+	return err
+}
+
+func (r *MFARepository) DeleteTOTP(ctx context.Context, userID int64) (retErr error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if retErr != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM user_recovery_codes WHERE user_id = ?`, userID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM user_totp WHERE user_id = ?`, userID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *MFARepository) UseStep(ctx context.Context, userID int64, step int64) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `
+        UPDATE user_totp SET last_used_step = ?
+        WHERE user_id = ? AND last_used_step < ?
+    `, step, userID, step)
+	if err != nil {
+		return false, err
+	}
+	rows, err := res.RowsAffected()
+	return rows > 0, err
+}
+
+func (r *MFARepository) ReplaceRecoveryCodes(ctx context.Context, userID int64, hashes []string) (retErr error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if retErr != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM user_recovery_codes WHERE user_id = ?`, userID); err != nil {
+		return err
+	}
+	for _, h := range hashes {
+		if _, err := tx.ExecContext(ctx, `
+            INSERT INTO user_recovery_codes (user_id, code_hash) VALUES (?, ?)
+        `, userID, h); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *MFARepository) UseRecoveryCode(ctx context.Context, userID int64, hash string, now time.Time) error {
+	res, err := r.db.ExecContext(ctx, `
+        UPDATE user_recovery_codes SET used_at = ?
+        WHERE user_id = ? AND code_hash = ? AND used_at IS NULL
+    `, now, userID, hash)
+	if err != nil {
+		return err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return domauth.ErrInvalidMFACode
+	}
+	return nil
+}
+
+func (r *MFARepository) CountRecoveryCodes(ctx context.Context, userID int64) (int, error) {
+	var n int
+	err := r.db.QueryRowContext(ctx, `
+        SELECT COUNT(*) FROM user_recovery_codes WHERE user_id = ? AND used_at IS NULL
+    `, userID).Scan(&n)
+	return n, err
+}
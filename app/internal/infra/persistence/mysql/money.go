@@ -0,0 +1,50 @@
+package mysql
+
+import (
+	"fmt"
+	"strconv"
+
+	"example.com/my-golang-sample/app/internal/domain/money"
+)
+
+// moneyColumn scans a DECIMAL column into dst without going through float64.
+// NULL becomes a zero amount.
+type moneyColumn struct {
+	dst      *money.Money
+	currency *string
+}
+
+func scanMoney(dst *money.Money, currency string) *moneyColumn {
+	return &moneyColumn{dst: dst, currency: &currency}
+}
+
+// scanMoneyIn reads the currency from *currency when the column is scanned, so
+// it can point at a currency column selected earlier in the same row.
+func scanMoneyIn(dst *money.Money, currency *string) *moneyColumn {
+	return &moneyColumn{dst: dst, currency: currency}
+}
+
+func (c *moneyColumn) Scan(src any) error {
+	var s string
+	switch v := src.(type) {
+	case nil:
+		*c.dst = money.Zero(*c.currency)
+		return nil
+	case []byte:
+		s = string(v)
+	case string:
+		s = v
+	case int64:
+		s = strconv.FormatInt(v, 10)
+	case float64:
+		s = strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Errorf("cannot scan %T into money", src)
+	}
+	m, err := money.Parse(s, *c.currency)
+	if err != nil {
+		return err
+	}
+	*c.dst = m
+	return nil
+}
@@ -0,0 +1,77 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	domauth "example.com/my-golang-sample/app/internal/domain/auth"
+)
+
+type OneTimeTokenRepository struct {
+	db *sql.DB
+}
+
+func NewOneTimeTokenRepository(db *sql.DB) *OneTimeTokenRepository {
+	return &OneTimeTokenRepository{db: db}
+}
+
+func (r *OneTimeTokenRepository) Create(ctx context.Context, t *domauth.OneTimeToken) error {
+	res, err := r.db.ExecContext(ctx, `
+        INSERT INTO user_tokens (user_id, purpose, token_hash, expires_at)
+        VALUES (?, ?, ?, ?)
+    `, t.UserID, t.Purpose, t.TokenHash, t.ExpiresAt)
+	if err != nil {
+		return err
+	}
+	t.ID, _ = res.LastInsertId()
+	return nil
+}
+
+func (r *OneTimeTokenRepository) Consume(ctx context.Context, purpose domauth.TokenPurpose, tokenHash string, now time.Time) (_ *domauth.OneTimeToken, retErr error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if retErr != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	var t domauth.OneTimeToken
+	var usedAt sql.NullTime
+	err = tx.QueryRowContext(ctx, `
+        SELECT id, user_id, purpose, token_hash, expires_at, used_at, created_at
+        FROM user_tokens
+        WHERE token_hash = ? AND purpose = ?
+        FOR UPDATE
+    `, tokenHash, purpose).Scan(&t.ID, &t.UserID, &t.Purpose, &t.TokenHash, &t.ExpiresAt, &usedAt, &t.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domauth.ErrInvalidOneTimeToken
+		}
+		return nil, err
+	}
+	if usedAt.Valid || !now.Before(t.ExpiresAt) {
+		return nil, domauth.ErrInvalidOneTimeToken
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE user_tokens SET used_at = ? WHERE id = ?`, now, t.ID); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	t.UsedAt = &now
+	return &t, nil
+}
+
+func (r *OneTimeTokenRepository) InvalidateForUser(ctx context.Context, userID int64, purpose domauth.TokenPurpose) error {
+	_, err := r.db.ExecContext(ctx, `
+        UPDATE user_tokens SET used_at = NOW()
+        WHERE user_id = ? AND purpose = ? AND used_at IS NULL
+    `, userID, purpose)
+	return err
+}
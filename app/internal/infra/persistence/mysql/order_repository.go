@@ -4,20 +4,34 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"strings"
 
 	domcart "example.com/my-golang-sample/app/internal/domain/cart"
+	"example.com/my-golang-sample/app/internal/domain/money"
 	domorder "example.com/my-golang-sample/app/internal/domain/order"
+	"example.com/my-golang-sample/app/internal/domain/page"
+	domproduct "example.com/my-golang-sample/app/internal/domain/product"
 )
 
 type OrderRepository struct {
-	db *sql.DB
+	db       *sql.DB
+	currency string // store currency, used for base product prices
 }
 
-func NewOrderRepository(db *sql.DB) *OrderRepository {
-	return &OrderRepository{db: db}
+func NewOrderRepository(db *sql.DB, currency string) *OrderRepository {
+	return &OrderRepository{db: db, currency: currency}
 }
 
-func (r *OrderRepository) CreateFromCart(ctx context.Context, userID int64, items []domcart.Item, payment domorder.PaymentMethod) (_ *domorder.Order, retErr error) {
+func (r *OrderRepository) CreateFromCart(ctx context.Context, userID int64, items []domcart.Item, payment domorder.PaymentMethod, currency string) (_ *domorder.Order, retErr error) {
+	if currency == "" {
+		currency = r.currency
+	}
+	// Giá bán theo currency: base price hoặc price list
+	priceColumn := "pp.price"
+	if currency == r.currency {
+		priceColumn = "p.price"
+	}
+
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, err
@@ -28,21 +42,22 @@ func (r *OrderRepository) CreateFromCart(ctx context.Context, userID int64, item
 		}
 	}()
 
-	var total float64
+	total := money.Zero(currency)
 	orderItems := make([]domorder.OrderItem, 0, len(items))
 
 	for _, item := range items {
 		var name string
-		var price float64
+		var amount sql.NullString
 		var stock int64
 
 		row := tx.QueryRowContext(ctx, `
-            SELECT name, price, stock
-            FROM products
-            WHERE id = ?
+            SELECT p.name, `+priceColumn+`, p.stock
+            FROM products p
+            LEFT JOIN product_prices pp ON pp.product_id = p.id AND pp.currency = ?
+            WHERE p.id = ?
             FOR UPDATE
-        `, item.ProductID)
-		if err = row.Scan(&name, &price, &stock); err != nil {
+        `, currency, item.ProductID)
+		if err = row.Scan(&name, &amount, &stock); err != nil {
 			if errors.Is(err, sql.ErrNoRows) {
 				retErr = domorder.ErrCheckoutValidation
 				return nil, retErr
@@ -55,8 +70,25 @@ func (r *OrderRepository) CreateFromCart(ctx context.Context, userID int64, item
 			retErr = domorder.ErrCheckoutValidation
 			return nil, retErr
 		}
+		if !amount.Valid {
+			retErr = domproduct.ErrPriceUnavailable
+			return nil, retErr
+		}
+		price, err := money.Parse(amount.String, currency)
+		if err != nil {
+			retErr = err
+			return nil, retErr
+		}
 
-		total += price * float64(item.Quantity)
+		subtotal, err := price.Mul(item.Quantity)
+		if err != nil {
+			retErr = err
+			return nil, retErr
+		}
+		if total, err = total.Add(subtotal); err != nil {
+			retErr = err
+			return nil, retErr
+		}
 		orderItems = append(orderItems, domorder.OrderItem{
 			ProductID: item.ProductID,
 			Name:      name,
@@ -66,20 +98,29 @@ func (r *OrderRepository) CreateFromCart(ctx context.Context, userID int64, item
 	}
 
 	res, err := tx.ExecContext(ctx, `
-        INSERT INTO orders (user_id, status, payment_method, total_amount)
-        VALUES (?, ?, ?, ?)
-    `, userID, domorder.StatusPending, payment, total)
+        INSERT INTO orders (user_id, status, payment_method, currency, total_amount)
+        VALUES (?, ?, ?, ?, ?)
+    `, userID, domorder.StatusPending, payment, currency, total)
 	if err != nil {
 		retErr = err
 		return nil, retErr
 	}
 	orderID, _ := res.LastInsertId()
 
+	if err = insertStatusHistory(ctx, tx, domorder.StatusChange{
+		OrderID:   orderID,
+		To:        domorder.StatusPending,
+		ChangedBy: userID,
+	}); err != nil {
+		retErr = err
+		return nil, retErr
+	}
+
 	for _, item := range orderItems {
 		_, err = tx.ExecContext(ctx, `
-            INSERT INTO order_items (order_id, product_id, product_name, unit_price, quantity)
-            VALUES (?, ?, ?, ?, ?)
-        `, orderID, item.ProductID, item.Name, item.Price, item.Quantity)
+            INSERT INTO order_items (order_id, product_id, product_name, currency, unit_price, quantity)
+            VALUES (?, ?, ?, ?, ?, ?)
+        `, orderID, item.ProductID, item.Name, currency, item.Price, item.Quantity)
 		if err != nil {
 			retErr = err
 			return nil, retErr
@@ -102,85 +143,293 @@ func (r *OrderRepository) CreateFromCart(ctx context.Context, userID int64, item
 	return r.GetByID(ctx, orderID)
 }
 
-func (r *OrderRepository) List(ctx context.Context) ([]*domorder.Order, error) {
+var orderKeyset = keyset[*domorder.Order]{
+	idColumn: "id",
+	id:       func(o *domorder.Order) int64 { return o.ID },
+	fields: map[string]sortColumn[*domorder.Order]{
+		// Giữ nguyên múi giờ khi đọc ra để so sánh đúng với giá trị đã lưu
+		"created_at": {column: "created_at", value: func(o *domorder.Order) any { return o.CreatedAt.Format("2006-01-02 15:04:05.999999") }},
+		"status":     {column: "status", value: func(o *domorder.Order) any { return string(o.Status) }},
+	},
+}
+
+func (r *OrderRepository) List(ctx context.Context, filter domorder.ListFilter, req page.Request) ([]*domorder.Order, page.Info, error) {
+	var clauses []string
+	var args []any
+
+	if filter.Status != nil {
+		clauses = append(clauses, "status = ?")
+		args = append(args, *filter.Status)
+	}
+	if filter.PaymentMethod != nil {
+		clauses = append(clauses, "payment_method = ?")
+		args = append(args, *filter.PaymentMethod)
+	}
+	if filter.UserID != nil {
+		clauses = append(clauses, "user_id = ?")
+		args = append(args, *filter.UserID)
+	}
+	if filter.Email != nil {
+		clauses = append(clauses, "user_id IN (SELECT id FROM users WHERE email = ?)")
+		args = append(args, *filter.Email)
+	}
+	if filter.Currency != nil {
+		clauses = append(clauses, "currency = ?")
+		args = append(args, *filter.Currency)
+	}
+	if filter.CreatedFrom != nil {
+		clauses = append(clauses, "created_at >= ?")
+		args = append(args, *filter.CreatedFrom)
+	}
+	if filter.CreatedTo != nil {
+		clauses = append(clauses, "created_at < ?")
+		args = append(args, *filter.CreatedTo)
+	}
+	// Khoảng tổng tiền chỉ có nghĩa trong cùng một currency
+	if filter.MinTotal != nil {
+		clauses = append(clauses, "currency = ? AND total_amount >= ?")
+		args = append(args, filter.MinTotal.Currency, *filter.MinTotal)
+	}
+	if filter.MaxTotal != nil {
+		clauses = append(clauses, "currency = ? AND total_amount <= ?")
+		args = append(args, filter.MaxTotal.Currency, *filter.MaxTotal)
+	}
+
+	return r.listPage(ctx, clauses, args, req)
+}
+
+func (r *OrderRepository) ListByUser(ctx context.Context, userID int64, req page.Request) ([]*domorder.Order, page.Info, error) {
+	return r.listPage(ctx, []string{"user_id = ?"}, []any{userID}, req)
+}
+
+func (r *OrderRepository) listPage(ctx context.Context, clauses []string, args []any, req page.Request) ([]*domorder.Order, page.Info, error) {
+	total, err := countRows(ctx, r.db, "orders", clauses, args)
+	if err != nil {
+		return nil, page.Info{}, err
+	}
+
+	clauses, args = orderKeyset.where(req, clauses, args)
+	order, args := orderKeyset.orderAndLimit(req, args)
 	rows, err := r.db.QueryContext(ctx, `
-        SELECT id, user_id, status, payment_method, total_amount, created_at
+        SELECT id, user_id, status, payment_method, currency, total_amount, created_at
         FROM orders
-        ORDER BY id DESC
-    `)
+    `+whereClause(clauses)+order, args...)
 	if err != nil {
-		return nil, err
+		return nil, page.Info{}, err
+	}
+	orders, err := r.scanOrders(rows)
+	if err != nil {
+		return nil, page.Info{}, err
+	}
+	orders, info := orderKeyset.page(req, total, orders)
+	if err := r.loadItems(ctx, orders); err != nil {
+		return nil, page.Info{}, err
 	}
+	return orders, info, nil
+}
+
+func (r *OrderRepository) GetByID(ctx context.Context, id int64) (*domorder.Order, error) {
+	row := r.db.QueryRowContext(ctx, `
+        SELECT id, user_id, status, payment_method, currency, total_amount, created_at
+        FROM orders WHERE id = ?
+    `, id)
+	return r.scanOrder(ctx, row)
+}
+
+func (r *OrderRepository) GetByIDForUser(ctx context.Context, id, userID int64) (*domorder.Order, error) {
+	row := r.db.QueryRowContext(ctx, `
+        SELECT id, user_id, status, payment_method, currency, total_amount, created_at
+        FROM orders WHERE id = ? AND user_id = ?
+    `, id, userID)
+	return r.scanOrder(ctx, row)
+}
+
+func (r *OrderRepository) scanOrders(rows *sql.Rows) ([]*domorder.Order, error) {
 	defer rows.Close()
 
 	var orders []*domorder.Order
 	for rows.Next() {
 		var o domorder.Order
-		if err := rows.Scan(&o.ID, &o.UserID, &o.Status, &o.PaymentMethod, &o.TotalAmount, &o.CreatedAt); err != nil {
-			return nil, err
-		}
-		items, err := r.listOrderItems(ctx, o.ID)
-		if err != nil {
+		var currency string
+		if err := rows.Scan(&o.ID, &o.UserID, &o.Status, &o.PaymentMethod, &currency, scanMoneyIn(&o.TotalAmount, &currency), &o.CreatedAt); err != nil {
 			return nil, err
 		}
-		o.Items = items
 		orders = append(orders, &o)
 	}
-	return orders, nil
+	return orders, rows.Err()
 }
 
-func (r *OrderRepository) GetByID(ctx context.Context, id int64) (*domorder.Order, error) {
-	row := r.db.QueryRowContext(ctx, `
-        SELECT id, user_id, status, payment_method, total_amount, created_at
-        FROM orders WHERE id = ?
-    `, id)
-
+func (r *OrderRepository) scanOrder(ctx context.Context, row *sql.Row) (*domorder.Order, error) {
 	var o domorder.Order
-	if err := row.Scan(&o.ID, &o.UserID, &o.Status, &o.PaymentMethod, &o.TotalAmount, &o.CreatedAt); err != nil {
+	var currency string
+	if err := row.Scan(&o.ID, &o.UserID, &o.Status, &o.PaymentMethod, &currency, scanMoneyIn(&o.TotalAmount, &currency), &o.CreatedAt); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, domorder.ErrOrderNotFound
 		}
 		return nil, err
 	}
-	items, err := r.listOrderItems(ctx, o.ID)
-	if err != nil {
+	if err := r.loadItems(ctx, []*domorder.Order{&o}); err != nil {
 		return nil, err
 	}
-	o.Items = items
 	return &o, nil
 }
 
-func (r *OrderRepository) UpdateStatus(ctx context.Context, id int64, status domorder.Status) (*domorder.Order, error) {
-	res, err := r.db.ExecContext(ctx, `
-        UPDATE orders SET status = ? WHERE id = ?
-    `, status, id)
+func (r *OrderRepository) UpdateStatus(ctx context.Context, change domorder.StatusChange) (_ *domorder.Order, retErr error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if retErr != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	// Điều kiện status = from chặn hai request đổi trạng thái cùng lúc
+	res, err := tx.ExecContext(ctx, `
+        UPDATE orders SET status = ? WHERE id = ? AND status = ?
+    `, change.To, change.OrderID, change.From)
 	if err != nil {
 		return nil, err
 	}
 	rows, _ := res.RowsAffected()
 	if rows == 0 {
-		return nil, domorder.ErrOrderNotFound
+		var exists bool
+		if err := tx.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM orders WHERE id = ?)`, change.OrderID).Scan(&exists); err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, domorder.ErrOrderNotFound
+		}
+		return nil, domorder.ErrInvalidTransition
+	}
+
+	if err := insertStatusHistory(ctx, tx, change); err != nil {
+		return nil, err
+	}
+	if change.ReleasesStock() {
+		if err := restockOrderItems(ctx, tx, change.OrderID); err != nil {
+			return nil, err
+		}
 	}
-	return r.GetByID(ctx, id)
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return r.GetByID(ctx, change.OrderID)
 }
 
-func (r *OrderRepository) listOrderItems(ctx context.Context, orderID int64) ([]domorder.OrderItem, error) {
+func (r *OrderRepository) ListStatusHistory(ctx context.Context, orderID int64) ([]domorder.StatusHistory, error) {
 	rows, err := r.db.QueryContext(ctx, `
-        SELECT id, order_id, product_id, product_name, unit_price, quantity
-        FROM order_items WHERE order_id = ?
+        SELECT id, order_id, from_status, to_status, changed_by, note, created_at
+        FROM order_status_history
+        WHERE order_id = ?
+        ORDER BY id
     `, orderID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
+	history := make([]domorder.StatusHistory, 0)
+	for rows.Next() {
+		var h domorder.StatusHistory
+		var from sql.NullString
+		var changedBy sql.NullInt64
+		if err := rows.Scan(&h.ID, &h.OrderID, &from, &h.To, &changedBy, &h.Note, &h.CreatedAt); err != nil {
+			return nil, err
+		}
+		h.From = domorder.Status(from.String)
+		h.ChangedBy = changedBy.Int64
+		history = append(history, h)
+	}
+	return history, rows.Err()
+}
+
+func insertStatusHistory(ctx context.Context, tx *sql.Tx, change domorder.StatusChange) error {
+	_, err := tx.ExecContext(ctx, `
+        INSERT INTO order_status_history (order_id, from_status, to_status, changed_by, note)
+        VALUES (?, ?, ?, ?, ?)
+    `, change.OrderID,
+		sql.NullString{String: string(change.From), Valid: change.From != ""},
+		change.To,
+		sql.NullInt64{Int64: change.ChangedBy, Valid: change.ChangedBy != 0},
+		change.Note)
+	return err
+}
+
+// restockOrderItems trả lại số lượng của từng order item về kho và ghi lại điều chỉnh
+func restockOrderItems(ctx context.Context, tx *sql.Tx, orderID int64) error {
+	rows, err := tx.QueryContext(ctx, `
+        SELECT product_id, quantity
+        FROM order_items WHERE order_id = ?
+    `, orderID)
+	if err != nil {
+		return err
+	}
 	var items []domorder.OrderItem
 	for rows.Next() {
 		var item domorder.OrderItem
-		if err := rows.Scan(&item.ID, &item.OrderID, &item.ProductID, &item.Name, &item.Price, &item.Quantity); err != nil {
-			return nil, err
+		if err := rows.Scan(&item.ProductID, &item.Quantity); err != nil {
+			rows.Close()
+			return err
 		}
 		items = append(items, item)
 	}
-	return items, nil
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		if _, err := tx.ExecContext(ctx, `
+            UPDATE products SET stock = stock + ?
+            WHERE id = ?
+        `, item.Quantity, item.ProductID); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `
+            INSERT INTO stock_adjustments (product_id, order_id, quantity_delta, reason)
+            VALUES (?, ?, ?, ?)
+        `, item.ProductID, orderID, item.Quantity, domproduct.AdjustmentOrderCanceled); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadItems fills the items of orders with a single query, however many
+// orders there are.
+func (r *OrderRepository) loadItems(ctx context.Context, orders []*domorder.Order) error {
+	if len(orders) == 0 {
+		return nil
+	}
+	byID := make(map[int64]*domorder.Order, len(orders))
+	args := make([]any, 0, len(orders))
+	for _, o := range orders {
+		byID[o.ID] = o
+		args = append(args, o.ID)
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+        SELECT id, order_id, product_id, product_name, currency, unit_price, quantity
+        FROM order_items
+        WHERE order_id IN (?`+strings.Repeat(",?", len(args)-1)+`)
+        ORDER BY order_id, id
+    `, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item domorder.OrderItem
+		var currency string
+		if err := rows.Scan(&item.ID, &item.OrderID, &item.ProductID, &item.Name, &currency, scanMoneyIn(&item.Price, &currency), &item.Quantity); err != nil {
+			return err
+		}
+		if o, ok := byID[item.OrderID]; ok {
+			o.Items = append(o.Items, item)
+		}
+	}
+	return rows.Err()
 }
@@ -0,0 +1,235 @@
+package mysql
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	domorder "example.com/my-golang-sample/app/internal/domain/order"
+	"example.com/my-golang-sample/app/internal/domain/page"
+	domproduct "example.com/my-golang-sample/app/internal/domain/product"
+	orderuc "example.com/my-golang-sample/app/internal/usecase/order"
+)
+
+const itemsPerOrder = 2
+
+// newFakeOrderDB serves n orders of itemsPerOrder items each, honouring the
+// LIMIT/OFFSET of the page query and the IN list of the items query.
+func newFakeOrderDB(tb testing.TB, n int) (*OrderRepository, *fakeDB) {
+	createdAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	db, fake := newFakeDB(tb, func(query string, args []driver.Value) (*fakeRows, error) {
+		switch {
+		case strings.Contains(query, "COUNT(*)"):
+			return &fakeRows{columns: []string{"count"}, values: [][]driver.Value{{int64(n)}}}, nil
+
+		case strings.Contains(query, "FROM order_items"):
+			rows := &fakeRows{columns: []string{"id", "order_id", "product_id", "product_name", "currency", "unit_price", "quantity"}}
+			for _, arg := range args {
+				orderID := arg.(int64)
+				for i := int64(1); i <= itemsPerOrder; i++ {
+					rows.values = append(rows.values, []driver.Value{
+						orderID*10 + i, orderID, i, fmt.Sprintf("Product %d", i), "SAR", "10.00", int64(1),
+					})
+				}
+			}
+			return rows, nil
+
+		case strings.Contains(query, "FROM orders"):
+			first, last := args[0].(int64), args[0].(int64) // WHERE id = ?
+			if strings.Contains(query, "LIMIT") {
+				limit, offset := args[len(args)-2].(int64), args[len(args)-1].(int64)
+				first, last = offset+1, min(int64(n), offset+limit)
+			}
+			rows := &fakeRows{columns: []string{"id", "user_id", "status", "payment_method", "currency", "total_amount", "created_at"}}
+			for id := first; id <= last; id++ {
+				rows.values = append(rows.values, []driver.Value{
+					id, int64(100), "PENDING", "COD", "SAR", "20.00", createdAt,
+				})
+			}
+			return rows, nil
+		}
+		return nil, fmt.Errorf("unexpected query: %s", query)
+	})
+	return NewOrderRepository(db, "SAR"), fake
+}
+
+func TestOrderRepository_ListQueryCountIsConstant(t *testing.T) {
+	for _, n := range []int{1, 50, 500} {
+		t.Run(fmt.Sprintf("%d orders", n), func(t *testing.T) {
+			repo, fake := newFakeOrderDB(t, n)
+			req := page.Request{Page: 1, Size: n, Sort: page.DefaultSort}
+
+			orders, info, err := repo.List(context.Background(), domorder.ListFilter{}, req)
+			require.NoError(t, err)
+			require.Len(t, orders, n)
+			require.Equal(t, int64(n), info.Total)
+			for _, o := range orders {
+				require.Len(t, o.Items, itemsPerOrder)
+				for _, item := range o.Items {
+					require.Equal(t, o.ID, item.OrderID)
+				}
+			}
+			require.Equal(t, 3, fake.queryCount(), "count, page and items queries")
+
+			fake.reset()
+			orders, _, err = repo.ListByUser(context.Background(), 100, req)
+			require.NoError(t, err)
+			require.Len(t, orders, n)
+			require.Equal(t, 3, fake.queryCount(), "count, page and items queries")
+		})
+	}
+}
+
+func TestOrderRepository_ListLoadsItemsForTrimmedPageOnly(t *testing.T) {
+	repo, fake := newFakeOrderDB(t, 10)
+
+	orders, info, err := repo.List(context.Background(), domorder.ListFilter{}, page.Request{Page: 1, Size: 4, Sort: page.DefaultSort})
+	require.NoError(t, err)
+	require.Len(t, orders, 4)
+	require.NotEmpty(t, info.NextCursor)
+	require.Equal(t, 3, fake.queryCount())
+
+	itemsQuery := fake.queries[2]
+	require.Contains(t, itemsQuery, "FROM order_items")
+	require.Equal(t, 4, strings.Count(itemsQuery, "?"), "the look-ahead row is not loaded")
+}
+
+func TestOrderRepository_GetByIDUsesTwoQueries(t *testing.T) {
+	repo, fake := newFakeOrderDB(t, 1)
+
+	order, err := repo.GetByID(context.Background(), 1)
+	require.NoError(t, err)
+	require.Len(t, order.Items, itemsPerOrder)
+	require.Equal(t, 2, fake.queryCount())
+}
+
+func BenchmarkOrderRepository_List(b *testing.B) {
+	for _, n := range []int{10, 100, 500} {
+		b.Run(fmt.Sprintf("%d orders", n), func(b *testing.B) {
+			repo, fake := newFakeOrderDB(b, n)
+			req := page.Request{Page: 1, Size: n, Sort: page.DefaultSort}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, _, err := repo.List(context.Background(), domorder.ListFilter{}, req); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(float64(fake.queryCount())/float64(b.N), "queries/op")
+		})
+	}
+}
+
+// stockState is the slice of the schema touched by a cancellation: one order
+// with two items, product stock and the stock_adjustments rows.
+type stockState struct {
+	mu          sync.Mutex
+	status      domorder.Status
+	stock       map[int64]int64
+	adjustments [][]driver.Value // product_id, order_id, quantity_delta, reason
+}
+
+// newFakeStockDB serves order 1 (items: product 1 x2, product 2 x3) and
+// applies the statements of UpdateStatus to state.
+func newFakeStockDB(t *testing.T, state *stockState) (*OrderRepository, *fakeDB) {
+	createdAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	items := [][]driver.Value{{int64(1), int64(2)}, {int64(2), int64(3)}}
+	db, fake := newFakeDB(t, func(query string, args []driver.Value) (*fakeRows, error) {
+		state.mu.Lock()
+		defer state.mu.Unlock()
+		switch {
+		case strings.Contains(query, "SELECT EXISTS"):
+			return &fakeRows{columns: []string{"exists"}, values: [][]driver.Value{{args[0].(int64) == 1}}}, nil
+
+		case strings.Contains(query, "SELECT product_id, quantity"):
+			return &fakeRows{columns: []string{"product_id", "quantity"}, values: items}, nil
+
+		case strings.Contains(query, "FROM order_items"):
+			rows := &fakeRows{columns: []string{"id", "order_id", "product_id", "product_name", "currency", "unit_price", "quantity"}}
+			for i, item := range items {
+				rows.values = append(rows.values, []driver.Value{int64(i + 1), int64(1), item[0], "Product", "SAR", "10.00", item[1]})
+			}
+			return rows, nil
+
+		case strings.Contains(query, "FROM orders"):
+			return &fakeRows{
+				columns: []string{"id", "user_id", "status", "payment_method", "currency", "total_amount", "created_at"},
+				values:  [][]driver.Value{{int64(1), int64(100), string(state.status), "TAMARA", "SAR", "50.00", createdAt}},
+			}, nil
+		}
+		return nil, fmt.Errorf("unexpected query: %s", query)
+	})
+	fake.exec = func(query string, args []driver.Value) (int64, error) {
+		state.mu.Lock()
+		defer state.mu.Unlock()
+		switch {
+		case strings.Contains(query, "UPDATE orders SET status"):
+			if args[1].(int64) != 1 || state.status != domorder.Status(args[2].(string)) {
+				return 0, nil
+			}
+			state.status = domorder.Status(args[0].(string))
+			return 1, nil
+
+		case strings.Contains(query, "INSERT INTO order_status_history"):
+			return 1, nil
+
+		case strings.Contains(query, "UPDATE products SET stock"):
+			state.stock[args[1].(int64)] += args[0].(int64)
+			return 1, nil
+
+		case strings.Contains(query, "INSERT INTO stock_adjustments"):
+			state.adjustments = append(state.adjustments, args)
+			return 1, nil
+		}
+		return 0, fmt.Errorf("unexpected statement: %s", query)
+	}
+	return NewOrderRepository(db, "SAR"), fake
+}
+
+func TestOrderRepository_CancelRestocksItems(t *testing.T) {
+	tests := []struct {
+		name string
+		in   orderuc.UpdateStatusInput
+	}{
+		{"admin cancel", orderuc.UpdateStatusInput{Status: domorder.StatusCanceled, ChangedBy: 1, Note: "customer asked by phone"}},
+		{"payment failure", orderuc.UpdateStatusInput{Status: domorder.StatusCanceled, Note: "TAMARA webhook order_declined"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := &stockState{status: domorder.StatusPending, stock: map[int64]int64{1: 5, 2: 0}}
+			repo, fake := newFakeStockDB(t, state)
+
+			order, err := orderuc.NewService(repo).UpdateStatus(context.Background(), 1, tt.in)
+
+			require.NoError(t, err)
+			require.Equal(t, domorder.StatusCanceled, order.Status)
+			require.Equal(t, map[int64]int64{1: 7, 2: 3}, state.stock)
+			require.Equal(t, [][]driver.Value{
+				{int64(1), int64(1), int64(2), string(domproduct.AdjustmentOrderCanceled)},
+				{int64(2), int64(1), int64(3), string(domproduct.AdjustmentOrderCanceled)},
+			}, state.adjustments)
+			require.Equal(t, 1, fake.commits)
+		})
+	}
+}
+
+func TestOrderRepository_CancelDoesNotRestockTwice(t *testing.T) {
+	state := &stockState{status: domorder.StatusPending, stock: map[int64]int64{1: 5, 2: 0}}
+	repo, fake := newFakeStockDB(t, state)
+	// Cả admin và webhook đều đọc thấy PENDING trước khi một bên kịp hủy
+	change := domorder.StatusChange{OrderID: 1, From: domorder.StatusPending, To: domorder.StatusCanceled}
+
+	_, err := repo.UpdateStatus(context.Background(), change)
+	require.NoError(t, err)
+	_, err = repo.UpdateStatus(context.Background(), change)
+
+	require.ErrorIs(t, err, domorder.ErrInvalidTransition)
+	require.Equal(t, map[int64]int64{1: 7, 2: 3}, state.stock)
+	require.Len(t, state.adjustments, 2)
+	require.Equal(t, 1, fake.commits)
+}
@@ -0,0 +1,102 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"example.com/my-golang-sample/app/internal/domain/page"
+)
+
+// keyset describes how one table is paged: the column behind each public
+// sort field, and how to read the same value back from a scanned row so the
+// next cursor can point at the last row of a page. The id column breaks ties.
+type keyset[T any] struct {
+	idColumn string
+	id       func(T) int64
+	fields   map[string]sortColumn[T]
+}
+
+type sortColumn[T any] struct {
+	column string
+	value  func(T) any
+}
+
+func (k keyset[T]) column(req page.Request) string {
+	if f, ok := k.fields[req.Sort]; ok {
+		return f.column
+	}
+	return k.idColumn
+}
+
+// where appends the condition that skips every row up to the cursor.
+func (k keyset[T]) where(req page.Request, clauses []string, args []any) ([]string, []any) {
+	if req.After == nil {
+		return clauses, args
+	}
+	op := ">"
+	if req.Desc {
+		op = "<"
+	}
+	col := k.column(req)
+	if col == k.idColumn {
+		return append(clauses, fmt.Sprintf("%s %s ?", col, op)), append(args, req.After.ID)
+	}
+	clause := fmt.Sprintf("(%s %s ? OR (%s = ? AND %s %s ?))", col, op, col, k.idColumn, op)
+	return append(clauses, clause), append(args, req.After.Value, req.After.Value, req.After.ID)
+}
+
+// orderAndLimit fetches one row more than the page size, so page can tell
+// whether another page follows.
+func (k keyset[T]) orderAndLimit(req page.Request, args []any) (string, []any) {
+	dir := "ASC"
+	if req.Desc {
+		dir = "DESC"
+	}
+	col := k.column(req)
+	query := fmt.Sprintf(" ORDER BY %s %s", col, dir)
+	if col != k.idColumn {
+		query += fmt.Sprintf(", %s %s", k.idColumn, dir)
+	}
+	return query + " LIMIT ? OFFSET ?", append(args, req.Size+1, req.Offset())
+}
+
+// page drops the look-ahead row and builds the page info.
+func (k keyset[T]) page(req page.Request, total int64, items []T) ([]T, page.Info) {
+	info := page.Info{Total: total, Size: req.Size}
+	if req.After == nil {
+		info.Page = req.Page
+	}
+	if len(items) > req.Size {
+		items = items[:req.Size]
+		last := items[len(items)-1]
+		c := page.Cursor{Sort: req.Sort, Desc: req.Desc, ID: k.id(last)}
+		if f, ok := k.fields[req.Sort]; ok && f.column != k.idColumn {
+			c.Value = f.value(last)
+		}
+		info.NextCursor = page.EncodeCursor(c)
+	}
+	return items, info
+}
+
+// countRows counts the rows matching clauses, ignoring the page.
+func countRows(ctx context.Context, db *sql.DB, from string, clauses []string, args []any) (int64, error) {
+	query := "SELECT COUNT(*) FROM " + from
+	if len(clauses) > 0 {
+		query += " WHERE " + strings.Join(clauses, " AND ")
+	}
+	var total int64
+	if err := db.QueryRowContext(ctx, query, args...).Scan(&total); err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+// whereClause joins clauses into a WHERE clause, or "" when there are none.
+func whereClause(clauses []string) string {
+	if len(clauses) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(clauses, " AND ")
+}
@@ -0,0 +1,105 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"example.com/my-golang-sample/app/internal/domain/money"
+	dompayment "example.com/my-golang-sample/app/internal/domain/payment"
+)
+
+type PaymentRepository struct {
+	db *sql.DB
+}
+
+func NewPaymentRepository(db *sql.DB) *PaymentRepository {
+	return &PaymentRepository{db: db}
+}
+
+func (r *PaymentRepository) Create(ctx context.Context, p *dompayment.Payment) (*dompayment.Payment, error) {
+	res, err := r.db.ExecContext(ctx, `
+        INSERT INTO payments (order_id, provider, reference, redirect_url, amount, currency, status)
+        VALUES (?, ?, ?, ?, ?, ?, ?)
+    `, p.OrderID, p.Provider, p.Reference, p.RedirectURL, p.Amount, p.Amount.Currency, p.Status)
+	if err != nil {
+		return nil, err
+	}
+	p.ID, _ = res.LastInsertId()
+	return r.getByID(ctx, p.ID)
+}
+
+func (r *PaymentRepository) GetByOrderID(ctx context.Context, orderID int64) (*dompayment.Payment, error) {
+	row := r.db.QueryRowContext(ctx, `
+        SELECT id, order_id, provider, reference, redirect_url, amount, currency, status, created_at
+        FROM payments
+        WHERE order_id = ?
+        ORDER BY id DESC
+        LIMIT 1
+    `, orderID)
+	return scanPayment(row)
+}
+
+func (r *PaymentRepository) UpdateStatus(ctx context.Context, id int64, status dompayment.Status) error {
+	res, err := r.db.ExecContext(ctx, `UPDATE payments SET status = ? WHERE id = ?`, status, id)
+	if err != nil {
+		return err
+	}
+	rows, _ := res.RowsAffected()
+	if rows == 0 {
+		return dompayment.ErrPaymentNotFound
+	}
+	return nil
+}
+
+func (r *PaymentRepository) getByID(ctx context.Context, id int64) (*dompayment.Payment, error) {
+	row := r.db.QueryRowContext(ctx, `
+        SELECT id, order_id, provider, reference, redirect_url, amount, currency, status, created_at
+        FROM payments
+        WHERE id = ?
+    `, id)
+	return scanPayment(row)
+}
+
+func scanPayment(row *sql.Row) (*dompayment.Payment, error) {
+	var p dompayment.Payment
+	var amount, currency string
+	if err := row.Scan(&p.ID, &p.OrderID, &p.Provider, &p.Reference, &p.RedirectURL, &amount, &currency, &p.Status, &p.CreatedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, dompayment.ErrPaymentNotFound
+		}
+		return nil, err
+	}
+	var err error
+	if p.Amount, err = money.Parse(amount, currency); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (r *PaymentRepository) RecordEvent(ctx context.Context, e *dompayment.WebhookEvent) (bool, error) {
+	if _, err := r.db.ExecContext(ctx, `
+        INSERT IGNORE INTO payment_events (provider, event_id, event_type, order_id, payload)
+        VALUES (?, ?, ?, ?, ?)
+    `, e.Provider, e.ID, e.Type, e.OrderID, string(e.Payload)); err != nil {
+		return false, err
+	}
+
+	var processed bool
+	if err := r.db.QueryRowContext(ctx, `
+        SELECT processed_at IS NOT NULL
+        FROM payment_events
+        WHERE provider = ? AND event_id = ?
+    `, e.Provider, e.ID).Scan(&processed); err != nil {
+		return false, err
+	}
+	return processed, nil
+}
+
+func (r *PaymentRepository) MarkEventProcessed(ctx context.Context, provider dompayment.Provider, eventID string) error {
+	_, err := r.db.ExecContext(ctx, `
+        UPDATE payment_events SET processed_at = CURRENT_TIMESTAMP
+        WHERE provider = ? AND event_id = ? AND processed_at IS NULL
+    `, provider, eventID)
+	return err
+}
@@ -4,18 +4,20 @@ import (
 	"context"
 	"database/sql"
 	"errors"
-	"fmt"
 	"strings"
 
+	"example.com/my-golang-sample/app/internal/domain/money"
+	"example.com/my-golang-sample/app/internal/domain/page"
 	domproduct "example.com/my-golang-sample/app/internal/domain/product"
 )
 
 type ProductRepository struct {
-	db *sql.DB
+	db       *sql.DB
+	currency string // currency of products.price
 }
 
-func NewProductRepository(db *sql.DB) *ProductRepository {
-	return &ProductRepository{db: db}
+func NewProductRepository(db *sql.DB, currency string) *ProductRepository {
+	return &ProductRepository{db: db, currency: currency}
 }
 
 func (r *ProductRepository) Create(ctx context.Context, p *domproduct.Product) (*domproduct.Product, error) {
@@ -64,55 +66,159 @@ func (r *ProductRepository) GetByID(ctx context.Context, id int64) (*domproduct.
     `, id)
 
 	var p domproduct.Product
-	if err := row.Scan(&p.ID, &p.Name, &p.Description, &p.Price, &p.Stock, &p.CategoryID, &p.IsActive); err != nil {
+	if err := row.Scan(&p.ID, &p.Name, &p.Description, scanMoney(&p.Price, r.currency), &p.Stock, &p.CategoryID, &p.IsActive); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, domproduct.ErrProductNotFound
 		}
 		return nil, err
 	}
+	if err := r.loadPrices(ctx, []*domproduct.Product{&p}); err != nil {
+		return nil, err
+	}
 	return &p, nil
 }
 
-func (r *ProductRepository) List(ctx context.Context, filter domproduct.ListFilter) ([]*domproduct.Product, error) {
+var productKeyset = keyset[*domproduct.Product]{
+	idColumn: "id",
+	id:       func(p *domproduct.Product) int64 { return p.ID },
+	fields: map[string]sortColumn[*domproduct.Product]{
+		"name":  {column: "name", value: func(p *domproduct.Product) any { return p.Name }},
+		"price": {column: "price", value: func(p *domproduct.Product) any { return p.Price.String() }},
+		"stock": {column: "stock", value: func(p *domproduct.Product) any { return p.Stock }},
+	},
+}
+
+func (r *ProductRepository) List(ctx context.Context, filter domproduct.ListFilter, req page.Request) ([]*domproduct.Product, page.Info, error) {
+	clauses, args := r.filterClauses(filter)
+	if terms := domproduct.SearchTerms(filter.Search); len(terms) > 0 {
+		clauses = append(clauses, "MATCH(name, description) AGAINST (? IN BOOLEAN MODE)")
+		args = append(args, fuzzyQuery(terms))
+	}
+
+	total, err := countRows(ctx, r.db, "products", clauses, args)
+	if err != nil {
+		return nil, page.Info{}, err
+	}
+
+	clauses, args = productKeyset.where(req, clauses, args)
+	order, args := productKeyset.orderAndLimit(req, args)
 	query := `
         SELECT id, name, description, price, stock, category_id, is_active
         FROM products
-    `
-	var clauses []string
-	var args []any
+    ` + whereClause(clauses) + order
 
-	if filter.CategoryID != nil {
-		clauses = append(clauses, "category_id = ?")
-		args = append(args, *filter.CategoryID)
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, page.Info{}, err
 	}
-	if filter.Search != "" {
-		clauses = append(clauses, "name LIKE ?")
-		args = append(args, fmt.Sprintf("%%%s%%", filter.Search))
+	defer rows.Close()
+
+	var products []*domproduct.Product
+	for rows.Next() {
+		var p domproduct.Product
+		if err := rows.Scan(&p.ID, &p.Name, &p.Description, scanMoney(&p.Price, r.currency), &p.Stock, &p.CategoryID, &p.IsActive); err != nil {
+			return nil, page.Info{}, err
+		}
+		products = append(products, &p)
 	}
-	if filter.OnlyActive {
-		clauses = append(clauses, "is_active = 1")
+	if err := rows.Err(); err != nil {
+		return nil, page.Info{}, err
+	}
+
+	products, info := productKeyset.page(req, total, products)
+	if err := r.loadPrices(ctx, products); err != nil {
+		return nil, page.Info{}, err
+	}
+	return products, info, nil
+}
+
+// Search ranks products with the FULLTEXT indexes on products. Every word
+// matches fuzzily by prefix; whole-word matches and matches in the name
+// score higher.
+func (r *ProductRepository) Search(ctx context.Context, filter domproduct.ListFilter, req page.Request) ([]*domproduct.Product, page.Info, error) {
+	terms := domproduct.SearchTerms(filter.Search)
+	if len(terms) == 0 {
+		return []*domproduct.Product{}, page.Info{Page: req.Page, Size: req.Size}, nil
 	}
+	fuzzy := fuzzyQuery(terms)
+
+	clauses, args := r.filterClauses(filter)
+	clauses = append(clauses, "MATCH(name, description) AGAINST (? IN BOOLEAN MODE)")
+	args = append(args, fuzzy)
 
-	if len(clauses) > 0 {
-		query += " WHERE " + strings.Join(clauses, " AND ")
+	total, err := countRows(ctx, r.db, "products", clauses, args)
+	if err != nil {
+		return nil, page.Info{}, err
 	}
-	query += " ORDER BY id DESC"
+
+	// Tên sản phẩm có trọng số gấp đôi mô tả; khớp nguyên từ được cộng thêm điểm
+	query := `
+        SELECT id, name, description, price, stock, category_id, is_active,
+            MATCH(name) AGAINST (? IN BOOLEAN MODE) * 2
+            + MATCH(name, description) AGAINST (? IN NATURAL LANGUAGE MODE)
+            + MATCH(name, description) AGAINST (? IN BOOLEAN MODE) AS relevance
+        FROM products
+    ` + whereClause(clauses) + `
+        ORDER BY relevance DESC, id DESC
+        LIMIT ? OFFSET ?
+    `
+	args = append([]any{fuzzy, strings.Join(terms, " "), fuzzy}, args...)
+	args = append(args, req.Size, req.Offset())
 
 	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, err
+		return nil, page.Info{}, err
 	}
 	defer rows.Close()
 
-	var products []*domproduct.Product
+	products := make([]*domproduct.Product, 0, req.Size)
 	for rows.Next() {
 		var p domproduct.Product
-		if err := rows.Scan(&p.ID, &p.Name, &p.Description, &p.Price, &p.Stock, &p.CategoryID, &p.IsActive); err != nil {
-			return nil, err
+		var relevance float64
+		if err := rows.Scan(&p.ID, &p.Name, &p.Description, scanMoney(&p.Price, r.currency), &p.Stock, &p.CategoryID, &p.IsActive, &relevance); err != nil {
+			return nil, page.Info{}, err
 		}
 		products = append(products, &p)
 	}
-	return products, nil
+	if err := rows.Err(); err != nil {
+		return nil, page.Info{}, err
+	}
+
+	if err := r.loadPrices(ctx, products); err != nil {
+		return nil, page.Info{}, err
+	}
+	return products, page.Info{Total: total, Page: req.Page, Size: req.Size}, nil
+}
+
+// filterClauses builds the WHERE conditions shared by List and Search,
+// leaving out the search text itself.
+func (r *ProductRepository) filterClauses(filter domproduct.ListFilter) ([]string, []any) {
+	var clauses []string
+	var args []any
+
+	if filter.CategoryID != nil {
+		clauses = append(clauses, "category_id = ?")
+		args = append(args, *filter.CategoryID)
+	}
+	if filter.OnlyActive {
+		clauses = append(clauses, "is_active = 1")
+	}
+	if filter.Currency != "" && filter.Currency != r.currency {
+		clauses = append(clauses, "EXISTS (SELECT 1 FROM product_prices pp WHERE pp.product_id = products.id AND pp.currency = ?)")
+		args = append(args, filter.Currency)
+	}
+	return clauses, args
+}
+
+// fuzzyQuery turns search terms into a BOOLEAN MODE query where each word
+// matches any word starting with its SearchPrefix. Terms only hold letters
+// and digits, so none of them can be read as an operator.
+func fuzzyQuery(terms []string) string {
+	parts := make([]string, len(terms))
+	for i, term := range terms {
+		parts[i] = domproduct.SearchPrefix(term) + "*"
+	}
+	return strings.Join(parts, " ")
 }
 
 func (r *ProductRepository) GetByIDs(ctx context.Context, ids []int64) ([]*domproduct.Product, error) {
@@ -140,11 +246,84 @@ func (r *ProductRepository) GetByIDs(ctx context.Context, ids []int64) ([]*dompr
 	var products []*domproduct.Product
 	for rows.Next() {
 		var p domproduct.Product
-		if err := rows.Scan(&p.ID, &p.Name, &p.Description, &p.Price, &p.Stock, &p.CategoryID, &p.IsActive); err != nil {
+		if err := rows.Scan(&p.ID, &p.Name, &p.Description, scanMoney(&p.Price, r.currency), &p.Stock, &p.CategoryID, &p.IsActive); err != nil {
 			return nil, err
 		}
 		products = append(products, &p)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if err := r.loadPrices(ctx, products); err != nil {
+		return nil, err
+	}
 	return products, nil
 }
 
+func (r *ProductRepository) SetPrice(ctx context.Context, productID int64, price money.Money) error {
+	var exists bool
+	if err := r.db.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM products WHERE id = ?)`, productID).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return domproduct.ErrProductNotFound
+	}
+	_, err := r.db.ExecContext(ctx, `
+        INSERT INTO product_prices (product_id, currency, price)
+        VALUES (?, ?, ?)
+        ON DUPLICATE KEY UPDATE price = VALUES(price)
+    `, productID, price.Currency, price)
+	return err
+}
+
+func (r *ProductRepository) DeletePrice(ctx context.Context, productID int64, currency string) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM product_prices WHERE product_id = ? AND currency = ?`, productID, currency)
+	if err != nil {
+		return err
+	}
+	rows, _ := res.RowsAffected()
+	if rows == 0 {
+		return domproduct.ErrPriceNotFound
+	}
+	return nil
+}
+
+// loadPrices fills the price lists of products with a single query.
+func (r *ProductRepository) loadPrices(ctx context.Context, products []*domproduct.Product) error {
+	if len(products) == 0 {
+		return nil
+	}
+	byID := make(map[int64]*domproduct.Product, len(products))
+	args := make([]any, 0, len(products))
+	for _, p := range products {
+		byID[p.ID] = p
+		args = append(args, p.ID)
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+        SELECT product_id, currency, price
+        FROM product_prices
+        WHERE product_id IN (?`+strings.Repeat(",?", len(args)-1)+`)
+        ORDER BY currency
+    `, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var productID int64
+		var currency, amount string
+		if err := rows.Scan(&productID, &currency, &amount); err != nil {
+			return err
+		}
+		price, err := money.Parse(amount, currency)
+		if err != nil {
+			return err
+		}
+		if p, ok := byID[productID]; ok {
+			p.Prices = append(p.Prices, price)
+		}
+	}
+	return rows.Err()
+}
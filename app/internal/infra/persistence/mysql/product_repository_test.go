@@ -0,0 +1,58 @@
+package mysql
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"example.com/my-golang-sample/app/internal/domain/page"
+	domproduct "example.com/my-golang-sample/app/internal/domain/product"
+)
+
+func TestProductRepository_SearchUsesFulltextRanking(t *testing.T) {
+	var searchArgs []driver.Value
+	db, fake := newFakeDB(t, func(query string, args []driver.Value) (*fakeRows, error) {
+		switch {
+		case strings.Contains(query, "COUNT(*)"):
+			require.Contains(t, query, "MATCH(name, description) AGAINST (? IN BOOLEAN MODE)")
+			return &fakeRows{columns: []string{"count"}, values: [][]driver.Value{{int64(1)}}}, nil
+		case strings.Contains(query, "AS relevance"):
+			require.Contains(t, query, "ORDER BY relevance DESC, id DESC")
+			searchArgs = args
+			return &fakeRows{
+				columns: []string{"id", "name", "description", "price", "stock", "category_id", "is_active", "relevance"},
+				values:  [][]driver.Value{{int64(2), "Wireless Keyboard", "Bluetooth", "220.00", int64(5), int64(1), true, 3.5}},
+			}, nil
+		case strings.Contains(query, "FROM product_prices"):
+			return &fakeRows{columns: []string{"product_id", "currency", "price"}}, nil
+		}
+		return nil, fmt.Errorf("unexpected query: %s", query)
+	})
+	repo := NewProductRepository(db, "SAR")
+
+	products, info, err := repo.Search(context.Background(), domproduct.ListFilter{Search: "Wireless KEYBAORD!", OnlyActive: true}, page.Request{Page: 2, Size: 10})
+	require.NoError(t, err)
+	require.Len(t, products, 1)
+	require.Equal(t, "Wireless Keyboard", products[0].Name)
+	require.Equal(t, page.Info{Total: 1, Page: 2, Size: 10}, info)
+	require.Equal(t, 3, fake.queryCount(), "count, search and prices queries")
+
+	// relevance terms, then the WHERE match, then LIMIT/OFFSET
+	require.Equal(t, []driver.Value{"wire* keyb*", "wireless keybaord", "wire* keyb*", "wire* keyb*", int64(10), int64(10)}, searchArgs)
+}
+
+func TestProductRepository_SearchWithoutTermsSkipsTheDatabase(t *testing.T) {
+	db, fake := newFakeDB(t, func(query string, args []driver.Value) (*fakeRows, error) {
+		return nil, fmt.Errorf("unexpected query: %s", query)
+	})
+	repo := NewProductRepository(db, "SAR")
+
+	products, _, err := repo.Search(context.Background(), domproduct.ListFilter{Search: " -- "}, page.Request{Page: 1, Size: 10})
+	require.NoError(t, err)
+	require.Empty(t, products)
+	require.Zero(t, fake.queryCount())
+}
@@ -0,0 +1,100 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	domauth "example.com/my-golang-sample/app/internal/domain/auth"
+)
+
+type RefreshTokenRepository struct {
+	db *sql.DB
+}
+
+func NewRefreshTokenRepository(db *sql.DB) *RefreshTokenRepository {
+	return &RefreshTokenRepository{db: db}
+}
+
+func (r *RefreshTokenRepository) Create(ctx context.Context, t *domauth.RefreshToken) error {
+	return insertRefreshToken(ctx, r.db, t)
+}
+
+func (r *RefreshTokenRepository) GetByHash(ctx context.Context, tokenHash string) (*domauth.RefreshToken, error) {
+	var t domauth.RefreshToken
+	var revokedAt sql.NullTime
+	err := r.db.QueryRowContext(ctx, `
+        SELECT id, user_id, token_hash, family_id, expires_at, revoked_at, created_at
+        FROM refresh_tokens
+        WHERE token_hash = ?
+    `, tokenHash).Scan(&t.ID, &t.UserID, &t.TokenHash, &t.FamilyID, &t.ExpiresAt, &revokedAt, &t.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domauth.ErrRefreshTokenNotFound
+		}
+		return nil, err
+	}
+	if revokedAt.Valid {
+		t.RevokedAt = &revokedAt.Time
+	}
+	return &t, nil
+}
+
+func (r *RefreshTokenRepository) Rotate(ctx context.Context, oldID int64, next *domauth.RefreshToken) (retErr error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if retErr != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	res, err := tx.ExecContext(ctx, `
+        UPDATE refresh_tokens SET revoked_at = NOW()
+        WHERE id = ? AND revoked_at IS NULL
+    `, oldID)
+	if err != nil {
+		return err
+	}
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		return domauth.ErrInvalidRefreshToken
+	}
+	if err := insertRefreshToken(ctx, tx, next); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *RefreshTokenRepository) RevokeFamily(ctx context.Context, familyID string) error {
+	_, err := r.db.ExecContext(ctx, `
+        UPDATE refresh_tokens SET revoked_at = NOW()
+        WHERE family_id = ? AND revoked_at IS NULL
+    `, familyID)
+	return err
+}
+
+func (r *RefreshTokenRepository) RevokeAllForUser(ctx context.Context, userID int64) error {
+	_, err := r.db.ExecContext(ctx, `
+        UPDATE refresh_tokens SET revoked_at = NOW()
+        WHERE user_id = ? AND revoked_at IS NULL
+    `, userID)
+	return err
+}
+
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+func insertRefreshToken(ctx context.Context, db execer, t *domauth.RefreshToken) error {
+	res, err := db.ExecContext(ctx, `
+        INSERT INTO refresh_tokens (user_id, token_hash, family_id, expires_at)
+        VALUES (?, ?, ?, ?)
+    `, t.UserID, t.TokenHash, t.FamilyID, t.ExpiresAt)
+	if err != nil {
+		return err
+	}
+	t.ID, _ = res.LastInsertId()
+	return nil
+}
@@ -0,0 +1,68 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+
+	domuser "example.com/my-golang-sample/app/internal/domain/user"
+	domrole "example.com/my-golang-sample/app/internal/domain/userrole"
+)
+
+type RolePermissionRepository struct {
+	db *sql.DB
+}
+
+func NewRolePermissionRepository(db *sql.DB) *RolePermissionRepository {
+	return &RolePermissionRepository{db: db}
+}
+
+func (r *RolePermissionRepository) ListByRole(ctx context.Context, roleID int64) ([]domrole.Permission, error) {
+	return r.list(ctx, `
+        SELECT permission FROM role_permissions
+        WHERE role_id = ?
+        ORDER BY permission
+    `, roleID)
+}
+
+func (r *RolePermissionRepository) ListByRoleCode(ctx context.Context, code domuser.RoleCode) ([]domrole.Permission, error) {
+	return r.list(ctx, `
+        SELECT rp.permission
+        FROM role_permissions rp
+        JOIN user_roles ur ON ur.id = rp.role_id
+        WHERE ur.code = ?
+        ORDER BY rp.permission
+    `, string(code))
+}
+
+func (r *RolePermissionRepository) list(ctx context.Context, query string, args ...any) ([]domrole.Permission, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var perms []domrole.Permission
+	for rows.Next() {
+		var p string
+		if err := rows.Scan(&p); err != nil {
+			return nil, err
+		}
+		perms = append(perms, domrole.Permission(p))
+	}
+	return perms, rows.Err()
+}
+
+func (r *RolePermissionRepository) Grant(ctx context.Context, roleID int64, p domrole.Permission) error {
+	_, err := r.db.ExecContext(ctx, `
+        INSERT IGNORE INTO role_permissions (role_id, permission)
+        VALUES (?, ?)
+    `, roleID, string(p))
+	return err
+}
+
+func (r *RolePermissionRepository) Revoke(ctx context.Context, roleID int64, p domrole.Permission) error {
+	_, err := r.db.ExecContext(ctx, `
+        DELETE FROM role_permissions WHERE role_id = ? AND permission = ?
+    `, roleID, string(p))
+	return err
+}
@@ -0,0 +1,55 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// TokenDenylistRepository stores revoked access token IDs until the tokens
+// would have expired anyway, and one token cut-off per user.
+type TokenDenylistRepository struct {
+	db *sql.DB
+}
+
+func NewTokenDenylistRepository(db *sql.DB) *TokenDenylistRepository {
+	return &TokenDenylistRepository{db: db}
+}
+
+func (r *TokenDenylistRepository) Revoke(ctx context.Context, jti string, expiresAt time.Time) error {
+	if _, err := r.db.ExecContext(ctx, `
+        INSERT INTO revoked_tokens (jti, expires_at)
+        VALUES (?, ?)
+        ON DUPLICATE KEY UPDATE expires_at = VALUES(expires_at)
+    `, jti, expiresAt); err != nil {
+		return err
+	}
+	// Dọn các jti đã hết hạn, token đó không còn dùng được nữa
+	_, err := r.db.ExecContext(ctx, `DELETE FROM revoked_tokens WHERE expires_at < ?`, time.Now())
+	return err
+}
+
+func (r *TokenDenylistRepository) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	var revoked bool
+	err := r.db.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM revoked_tokens WHERE jti = ?)`, jti).Scan(&revoked)
+	return revoked, err
+}
+
+func (r *TokenDenylistRepository) RevokeUser(ctx context.Context, userID int64, at time.Time) error {
+	_, err := r.db.ExecContext(ctx, `
+        INSERT INTO user_token_cutoffs (user_id, tokens_valid_after)
+        VALUES (?, ?)
+        ON DUPLICATE KEY UPDATE tokens_valid_after = GREATEST(tokens_valid_after, VALUES(tokens_valid_after))
+    `, userID, at)
+	return err
+}
+
+func (r *TokenDenylistRepository) TokensValidAfter(ctx context.Context, userID int64) (time.Time, error) {
+	var at time.Time
+	err := r.db.QueryRowContext(ctx, `SELECT tokens_valid_after FROM user_token_cutoffs WHERE user_id = ?`, userID).Scan(&at)
+	if errors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, nil
+	}
+	return at, err
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"strings"
 
+	"example.com/my-golang-sample/app/internal/domain/page"
 	dom "example.com/my-golang-sample/app/internal/domain/user"
 	domrole "example.com/my-golang-sample/app/internal/domain/userrole"
 )
@@ -36,9 +37,9 @@ func (r *UserRepository) GetRoleIDByCode(ctx context.Context, code dom.RoleCode)
 
 func (r *UserRepository) Create(ctx context.Context, u *dom.User) (*dom.User, error) {
 	res, err := r.db.ExecContext(ctx,
-		`INSERT INTO users (name, email, password_hash, user_role_id)
-         VALUES (?, ?, ?, ?)`,
-		u.Name, u.Email, u.PasswordHash, u.UserRoleID,
+		`INSERT INTO users (name, email, password_hash, user_role_id, email_verified_at)
+         VALUES (?, ?, ?, ?, ?)`,
+		u.Name, u.Email, u.PasswordHash, u.UserRoleID, u.EmailVerifiedAt,
 	)
 	if err != nil {
 		if strings.Contains(strings.ToLower(err.Error()), "duplicate") {
@@ -53,30 +54,28 @@ func (r *UserRepository) Create(ctx context.Context, u *dom.User) (*dom.User, er
 
 func (r *UserRepository) GetByID(ctx context.Context, id int64) (*dom.User, error) {
 	row := r.db.QueryRowContext(ctx, `
-        SELECT u.id, u.name, u.email, u.password_hash, u.user_role_id, r.code
+        SELECT u.id, u.name, u.email, u.password_hash, u.user_role_id, u.email_verified_at, r.code
         FROM users u
         JOIN user_roles r ON u.user_role_id = r.id
         WHERE u.id = ?
     `, id)
 
-	var u dom.User
-	var roleCode string
-	if err := row.Scan(&u.ID, &u.Name, &u.Email, &u.PasswordHash, &u.UserRoleID, &roleCode); err != nil {
+	u, err := scanUser(row)
+	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, dom.ErrUserNotFound
 		}
 		return nil, err
 	}
-	u.RoleCode = dom.RoleCode(roleCode)
-	return &u, nil
+	return u, nil
 }
 
 func (r *UserRepository) Update(ctx context.Context, u *dom.User) (*dom.User, error) {
 	res, err := r.db.ExecContext(ctx, `
         UPDATE users
-        SET name = ?, email = ?, password_hash = ?, user_role_id = ?
+        SET name = ?, email = ?, password_hash = ?, user_role_id = ?, email_verified_at = ?
         WHERE id = ?
-    `, u.Name, u.Email, u.PasswordHash, u.UserRoleID, u.ID)
+    `, u.Name, u.Email, u.PasswordHash, u.UserRoleID, u.EmailVerifiedAt, u.ID)
 	if err != nil {
 		if strings.Contains(strings.ToLower(err.Error()), "duplicate") {
 			return nil, dom.ErrEmailAlreadyUsed
@@ -106,51 +105,82 @@ func (r *UserRepository) Delete(ctx context.Context, id int64) error {
 
 func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*dom.User, error) {
 	row := r.db.QueryRowContext(ctx, `
-        SELECT u.id, u.name, u.email, u.password_hash, u.user_role_id, r.code
+        SELECT u.id, u.name, u.email, u.password_hash, u.user_role_id, u.email_verified_at, r.code
         FROM users u
         JOIN user_roles r ON u.user_role_id = r.id
         WHERE u.email = ?
     `, email)
 
-	var u dom.User
-	var roleCode string
-	if err := row.Scan(&u.ID, &u.Name, &u.Email, &u.PasswordHash, &u.UserRoleID, &roleCode); err != nil {
+	u, err := scanUser(row)
+	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, dom.ErrUserNotFound
 		}
 		return nil, err
 	}
-	u.RoleCode = dom.RoleCode(roleCode)
-	return &u, nil
+	return u, nil
 }
 
-func (r *UserRepository) List(ctx context.Context, filter dom.ListUsersFilter) ([]*dom.User, error) {
-	query := `
-        SELECT u.id, u.name, u.email, u.password_hash, u.user_role_id, r.code
-        FROM users u
-        JOIN user_roles r ON u.user_role_id = r.id
-    `
-	args := []any{}
+var userKeyset = keyset[*dom.User]{
+	idColumn: "u.id",
+	id:       func(u *dom.User) int64 { return u.ID },
+	fields: map[string]sortColumn[*dom.User]{
+		"name":  {column: "u.name", value: func(u *dom.User) any { return u.Name }},
+		"email": {column: "u.email", value: func(u *dom.User) any { return u.Email }},
+	},
+}
+
+func (r *UserRepository) List(ctx context.Context, filter dom.ListUsersFilter, req page.Request) ([]*dom.User, page.Info, error) {
+	const from = `users u JOIN user_roles r ON u.user_role_id = r.id`
+	var clauses []string
+	var args []any
 	if filter.RoleCode != nil {
-		query += ` WHERE r.code = ?`
+		clauses = append(clauses, "r.code = ?")
 		args = append(args, string(*filter.RoleCode))
 	}
 
+	total, err := countRows(ctx, r.db, from, clauses, args)
+	if err != nil {
+		return nil, page.Info{}, err
+	}
+
+	clauses, args = userKeyset.where(req, clauses, args)
+	order, args := userKeyset.orderAndLimit(req, args)
+	query := `
+        SELECT u.id, u.name, u.email, u.password_hash, u.user_role_id, u.email_verified_at, r.code
+        FROM ` + from + whereClause(clauses) + order
+
 	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, err
+		return nil, page.Info{}, err
 	}
 	defer rows.Close()
 
 	var users []*dom.User
 	for rows.Next() {
-		var u dom.User
-		var roleCode string
-		if err := rows.Scan(&u.ID, &u.Name, &u.Email, &u.PasswordHash, &u.UserRoleID, &roleCode); err != nil {
-			return nil, err
+		u, err := scanUser(rows)
+		if err != nil {
+			return nil, page.Info{}, err
 		}
-		u.RoleCode = dom.RoleCode(roleCode)
-		users = append(users, &u)
+		users = append(users, u)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, page.Info{}, err
+	}
+	users, info := userKeyset.page(req, total, users)
+	return users, info, nil
+}
+
+func scanUser(row interface{ Scan(dest ...any) error }) (*dom.User, error) {
+	var u dom.User
+	var roleCode string
+	var verifiedAt sql.NullTime
+	if err := row.Scan(&u.ID, &u.Name, &u.Email, &u.PasswordHash, &u.UserRoleID, &verifiedAt, &roleCode); err != nil {
+		return nil, err
+	}
+	u.RoleCode = dom.RoleCode(roleCode)
+	if verifiedAt.Valid {
+		u.EmailVerifiedAt = &verifiedAt.Time
 	}
-	return users, nil
+	return &u, nil
 }
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"strings"
 
+	"example.com/my-golang-sample/app/internal/domain/page"
 	domuser "example.com/my-golang-sample/app/internal/domain/user"
 	domrole "example.com/my-golang-sample/app/internal/domain/userrole"
 )
@@ -21,9 +22,9 @@ func NewUserRoleRepository(db *sql.DB) *UserRoleRepository {
 
 func (r *UserRoleRepository) Create(ctx context.Context, role *domrole.UserRole) (*domrole.UserRole, error) {
 	res, err := r.db.ExecContext(ctx, `
-        INSERT INTO user_roles (code, name, description)
-        VALUES (?, ?, ?)
-    `, string(role.Code), role.Name, role.Description)
+        INSERT INTO user_roles (code, name, description, role_rank)
+        VALUES (?, ?, ?, ?)
+    `, string(role.Code), role.Name, role.Description, role.Rank)
 	if err != nil {
 		if strings.Contains(strings.ToLower(err.Error()), "duplicate") {
 			return nil, domrole.ErrRoleCodeExisted
@@ -37,9 +38,9 @@ func (r *UserRoleRepository) Create(ctx context.Context, role *domrole.UserRole)
 func (r *UserRoleRepository) Update(ctx context.Context, role *domrole.UserRole) (*domrole.UserRole, error) {
 	res, err := r.db.ExecContext(ctx, `
         UPDATE user_roles
-        SET name = ?, description = ?
+        SET name = ?, description = ?, role_rank = ?
         WHERE id = ?
-    `, role.Name, role.Description, role.ID)
+    `, role.Name, role.Description, role.Rank, role.ID)
 	if err != nil {
 		return nil, err
 	}
@@ -92,14 +93,14 @@ func (r *UserRoleRepository) Delete(ctx context.Context, id int64) error {
 
 func (r *UserRoleRepository) GetByID(ctx context.Context, id int64) (*domrole.UserRole, error) {
 	row := r.db.QueryRowContext(ctx, `
-        SELECT id, code, name, description, is_system
+        SELECT id, code, name, description, is_system, role_rank
         FROM user_roles
         WHERE id = ?
     `, id)
 
 	var role domrole.UserRole
 	var code string
-	if err := row.Scan(&role.ID, &code, &role.Name, &role.Description, &role.IsSystem); err != nil {
+	if err := row.Scan(&role.ID, &code, &role.Name, &role.Description, &role.IsSystem, &role.Rank); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, domrole.ErrRoleNotFound
 		}
@@ -111,14 +112,14 @@ func (r *UserRoleRepository) GetByID(ctx context.Context, id int64) (*domrole.Us
 
 func (r *UserRoleRepository) GetByCode(ctx context.Context, code string) (*domrole.UserRole, error) {
 	row := r.db.QueryRowContext(ctx, `
-        SELECT id, code, name, description, is_system
+        SELECT id, code, name, description, is_system, role_rank
         FROM user_roles
         WHERE code = ?
     `, code)
 
 	var role domrole.UserRole
 	var dbCode string
-	if err := row.Scan(&role.ID, &dbCode, &role.Name, &role.Description, &role.IsSystem); err != nil {
+	if err := row.Scan(&role.ID, &dbCode, &role.Name, &role.Description, &role.IsSystem, &role.Rank); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, domrole.ErrRoleNotFound
 		}
@@ -128,22 +129,40 @@ func (r *UserRoleRepository) GetByCode(ctx context.Context, code string) (*domro
 	return &role, nil
 }
 
-func (r *UserRoleRepository) List(ctx context.Context, filter domrole.ListFilter) ([]*domrole.UserRole, error) {
-	query := `
-        SELECT id, code, name, description, is_system
-        FROM user_roles
-    `
+var roleKeyset = keyset[*domrole.UserRole]{
+	idColumn: "id",
+	id:       func(role *domrole.UserRole) int64 { return role.ID },
+	fields: map[string]sortColumn[*domrole.UserRole]{
+		"code": {column: "code", value: func(role *domrole.UserRole) any { return string(role.Code) }},
+		"name": {column: "name", value: func(role *domrole.UserRole) any { return role.Name }},
+		"rank": {column: "role_rank", value: func(role *domrole.UserRole) any { return role.Rank }},
+	},
+}
+
+func (r *UserRoleRepository) List(ctx context.Context, filter domrole.ListFilter, req page.Request) ([]*domrole.UserRole, page.Info, error) {
+	var clauses []string
 	var args []any
 	if filter.Query != nil {
-		query += " WHERE name LIKE ? OR code LIKE ?"
+		clauses = append(clauses, "(name LIKE ? OR code LIKE ?)")
 		arg := fmt.Sprintf("%%%s%%", *filter.Query)
 		args = append(args, arg, arg)
 	}
-	query += " ORDER BY id DESC"
+
+	total, err := countRows(ctx, r.db, "user_roles", clauses, args)
+	if err != nil {
+		return nil, page.Info{}, err
+	}
+
+	clauses, args = roleKeyset.where(req, clauses, args)
+	order, args := roleKeyset.orderAndLimit(req, args)
+	query := `
+        SELECT id, code, name, description, is_system, role_rank
+        FROM user_roles
+    ` + whereClause(clauses) + order
 
 	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, err
+		return nil, page.Info{}, err
 	}
 	defer rows.Close()
 
@@ -151,11 +170,15 @@ func (r *UserRoleRepository) List(ctx context.Context, filter domrole.ListFilter
 	for rows.Next() {
 		var role domrole.UserRole
 		var code string
-		if err := rows.Scan(&role.ID, &code, &role.Name, &role.Description, &role.IsSystem); err != nil {
-			return nil, err
+		if err := rows.Scan(&role.ID, &code, &role.Name, &role.Description, &role.IsSystem, &role.Rank); err != nil {
+			return nil, page.Info{}, err
 		}
 		role.Code = domuser.RoleCode(code)
 		roles = append(roles, &role)
 	}
-	return roles, nil
+	if err := rows.Err(); err != nil {
+		return nil, page.Info{}, err
+	}
+	roles, info := roleKeyset.page(req, total, roles)
+	return roles, info, nil
 }
@@ -0,0 +1,119 @@
+package search
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+	"sync"
+
+	"example.com/my-golang-sample/app/internal/domain/page"
+	domproduct "example.com/my-golang-sample/app/internal/domain/product"
+)
+
+// MemoryIndex is an in-process product Searcher used by tests and local runs
+// without MySQL. It ranks like the FULLTEXT implementation: a name match is
+// worth twice a description match, and a whole word twice a prefix match.
+type MemoryIndex struct {
+	mu       sync.RWMutex
+	products map[int64]*domproduct.Product
+}
+
+func NewMemoryIndex(products ...*domproduct.Product) *MemoryIndex {
+	m := &MemoryIndex{products: make(map[int64]*domproduct.Product, len(products))}
+	for _, p := range products {
+		m.Put(p)
+	}
+	return m
+}
+
+// Put adds p to the index or replaces the entry with the same ID.
+func (m *MemoryIndex) Put(p *domproduct.Product) {
+	cloned := *p
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.products[p.ID] = &cloned
+}
+
+func (m *MemoryIndex) Delete(id int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.products, id)
+}
+
+func (m *MemoryIndex) Search(ctx context.Context, filter domproduct.ListFilter, req page.Request) ([]*domproduct.Product, page.Info, error) {
+	terms := domproduct.SearchTerms(filter.Search)
+
+	type hit struct {
+		product *domproduct.Product
+		score   int
+	}
+	var hits []hit
+
+	m.mu.RLock()
+	for _, p := range m.products {
+		if !matchesFilter(p, filter) {
+			continue
+		}
+		if score := relevance(p, terms); score > 0 {
+			cloned := *p
+			hits = append(hits, hit{product: &cloned, score: score})
+		}
+	}
+	m.mu.RUnlock()
+
+	slices.SortFunc(hits, func(a, b hit) int {
+		if c := cmp.Compare(b.score, a.score); c != 0 {
+			return c
+		}
+		return cmp.Compare(b.product.ID, a.product.ID)
+	})
+
+	info := page.Info{Total: int64(len(hits)), Page: req.Page, Size: req.Size}
+	products := make([]*domproduct.Product, 0, req.Size)
+	for i := req.Offset(); i < len(hits) && len(products) < req.Size; i++ {
+		products = append(products, hits[i].product)
+	}
+	return products, info, nil
+}
+
+func matchesFilter(p *domproduct.Product, filter domproduct.ListFilter) bool {
+	if filter.CategoryID != nil && p.CategoryID != *filter.CategoryID {
+		return false
+	}
+	if filter.OnlyActive && !p.IsActive {
+		return false
+	}
+	if filter.Currency != "" {
+		if _, err := p.PriceIn(filter.Currency); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// relevance scores p against the search terms; 0 means no match.
+func relevance(p *domproduct.Product, terms []string) int {
+	name := domproduct.SearchTerms(p.Name)
+	description := domproduct.SearchTerms(p.Description)
+	score := 0
+	for _, term := range terms {
+		score += 2*fieldScore(name, term) + fieldScore(description, term)
+	}
+	return score
+}
+
+// fieldScore is 2 when words contains term, 1 when a word starts with the
+// fuzzy prefix of term, and 0 otherwise.
+func fieldScore(words []string, term string) int {
+	if slices.Contains(words, term) {
+		return 2
+	}
+	prefix := domproduct.SearchPrefix(term)
+	for _, w := range words {
+		if strings.HasPrefix(w, prefix) {
+			return 1
+		}
+	}
+	return 0
+}
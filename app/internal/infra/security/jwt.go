@@ -1,6 +1,10 @@
 package security
 
 import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -9,8 +13,14 @@ import (
 	authuc "example.com/my-golang-sample/app/internal/usecase/auth"
 )
 
+var errInvalidToken = errors.New("invalid token")
+
+// JWTService signs access tokens either with a shared HS256 secret or, when
+// built with NewJWTServiceWithKeys, with RS256/EdDSA keys that other services
+// can verify through the published JWKS.
 type JWTService struct {
 	secret     []byte
+	keys       *KeySet
 	expiration time.Duration
 }
 
@@ -21,6 +31,13 @@ func NewJWTService(secret string, expiration time.Duration) *JWTService {
 	}
 }
 
+func NewJWTServiceWithKeys(keys *KeySet, expiration time.Duration) *JWTService {
+	return &JWTService{
+		keys:       keys,
+		expiration: expiration,
+	}
+}
+
 type jwtClaims struct {
 	UserID int64  `json:"uid"`
 	Role   string `json:"role"`
@@ -30,6 +47,10 @@ type jwtClaims struct {
 }
 
 func (s *JWTService) GenerateToken(u *domuser.User) (string, error) {
+	jti, err := newTokenID()
+	if err != nil {
+		return "", err
+	}
 	now := time.Now()
 	claims := jwtClaims{
 		UserID: u.ID,
@@ -37,26 +58,39 @@ func (s *JWTService) GenerateToken(u *domuser.User) (string, error) {
 		Email:  u.Email,
 		Name:   u.Name,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
 			IssuedAt:  jwt.NewNumericDate(now),
 			ExpiresAt: jwt.NewNumericDate(now.Add(s.expiration)),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(s.secret)
+	if s.keys == nil {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		return token.SignedString(s.secret)
+	}
+
+	key, err := s.keys.signingKey()
+	if err != nil {
+		return "", err
+	}
+	token := jwt.NewWithClaims(key.method(), claims)
+	token.Header["kid"] = key.ID
+	return token.SignedString(key.Private)
 }
 
 func (s *JWTService) ParseToken(token string) (*authuc.Claims, error) {
-	parsed, err := jwt.ParseWithClaims(token, &jwtClaims{}, func(token *jwt.Token) (interface{}, error) {
-		return s.secret, nil
-	})
+	// Chỉ chấp nhận đúng thuật toán của key, tránh alg confusion (none, HS256 với public key...)
+	parsed, err := jwt.ParseWithClaims(token, &jwtClaims{}, s.verificationKey,
+		jwt.WithValidMethods(s.algorithms()),
+		jwt.WithExpirationRequired(),
+	)
 	if err != nil {
 		return nil, err
 	}
 
 	claims, ok := parsed.Claims.(*jwtClaims)
 	if !ok || !parsed.Valid {
-		return nil, err
+		return nil, errInvalidToken
 	}
 
 	role, err := domuser.ParseRoleCode(claims.Role)
@@ -64,11 +98,59 @@ func (s *JWTService) ParseToken(token string) (*authuc.Claims, error) {
 		return nil, err
 	}
 
-	return &authuc.Claims{
+	out := &authuc.Claims{
 		UserID:   claims.UserID,
 		RoleCode: role,
 		Email:    claims.Email,
 		Name:     claims.Name,
-	}, nil
+		ID:       claims.ID,
+	}
+	if claims.IssuedAt != nil {
+		out.IssuedAt = claims.IssuedAt.Time
+	}
+	if claims.ExpiresAt != nil {
+		out.ExpiresAt = claims.ExpiresAt.Time
+	}
+	return out, nil
+}
+
+// PublicKeys returns the JWKS keys; it is empty for HS256, whose secret must
+// never be published.
+func (s *JWTService) PublicKeys() []authuc.JSONWebKey {
+	if s.keys == nil {
+		return nil
+	}
+	return s.keys.PublicKeys()
+}
+
+func (s *JWTService) algorithms() []string {
+	if s.keys == nil {
+		return []string{jwt.SigningMethodHS256.Alg()}
+	}
+	return s.keys.algorithms()
+}
+
+func (s *JWTService) verificationKey(token *jwt.Token) (any, error) {
+	if s.keys == nil {
+		return s.secret, nil
+	}
+	kid, _ := token.Header["kid"].(string)
+	key := s.keys.verificationKey(kid)
+	if key == nil {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	if token.Method.Alg() != key.method().Alg() {
+		return nil, fmt.Errorf("key %q does not sign %s", kid, token.Method.Alg())
+	}
+	return key.Private.Public(), nil
+}
+
+// newTokenID returns a random jti so a single token can be revoked.
+func newTokenID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
 }
 
@@ -0,0 +1,235 @@
+package security
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	authuc "example.com/my-golang-sample/app/internal/usecase/auth"
+)
+
+var ErrNoSigningKey = errors.New("no active signing key")
+
+// SigningKey is one private key of a KeySet. SignFrom and RetireAt form the
+// rotation schedule: a key is published and accepted until RetireAt, and
+// the newest key whose SignFrom has passed signs new tokens. Publishing the
+// next key before its SignFrom lets verifiers pick it up ahead of time.
+type SigningKey struct {
+	ID       string
+	Private  crypto.Signer
+	SignFrom time.Time // zero means immediately
+	RetireAt time.Time // zero means never
+}
+
+func (k *SigningKey) method() jwt.SigningMethod {
+	if _, ok := k.Private.(ed25519.PrivateKey); ok {
+		return jwt.SigningMethodEdDSA
+	}
+	return jwt.SigningMethodRS256
+}
+
+func (k *SigningKey) retired(now time.Time) bool {
+	return !k.RetireAt.IsZero() && !now.Before(k.RetireAt)
+}
+
+// KeySet holds the RS256/EdDSA keys used by JWTService, selected by kid.
+type KeySet struct {
+	keys []SigningKey
+	now  func() time.Time
+}
+
+func NewKeySet(keys ...SigningKey) (*KeySet, error) {
+	if len(keys) == 0 {
+		return nil, ErrNoSigningKey
+	}
+	seen := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		if k.ID == "" {
+			return nil, errors.New("signing key without kid")
+		}
+		if seen[k.ID] {
+			return nil, fmt.Errorf("duplicate kid %q", k.ID)
+		}
+		seen[k.ID] = true
+		switch pk := k.Private.(type) {
+		case *rsa.PrivateKey:
+			if pk.N.BitLen() < 2048 {
+				return nil, fmt.Errorf("key %q: RSA keys must be at least 2048 bits", k.ID)
+			}
+		case ed25519.PrivateKey:
+		default:
+			return nil, fmt.Errorf("key %q: unsupported key type %T", k.ID, k.Private)
+		}
+	}
+
+	sorted := append([]SigningKey(nil), keys...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].SignFrom.Before(sorted[j].SignFrom)
+	})
+	return &KeySet{keys: sorted, now: time.Now}, nil
+}
+
+// GenerateEd25519Key creates a throwaway key, e.g. when no keys are
+// configured in development.
+func GenerateEd25519Key(id string) (SigningKey, error) {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return SigningKey{}, err
+	}
+	return SigningKey{ID: id, Private: priv}, nil
+}
+
+type keyManifest struct {
+	Keys []struct {
+		Kid      string `json:"kid"`
+		File     string `json:"file"`
+		SignFrom string `json:"sign_from"`
+		RetireAt string `json:"retire_at"`
+	} `json:"keys"`
+}
+
+// LoadKeySet reads a JSON manifest listing PEM private keys (PKCS#8, or
+// PKCS#1 for RSA). Relative file paths are resolved against the manifest's
+// directory and times use RFC 3339:
+//
+//	{"keys": [
+//	  {"kid": "2026-09", "file": "2026-09.pem", "retire_at": "2026-10-02T00:00:00Z"},
+//	  {"kid": "2026-10", "file": "2026-10.pem", "sign_from": "2026-10-01T00:00:00Z"}
+//	]}
+func LoadKeySet(manifestPath string) (*KeySet, error) {
+	raw, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return nil, err
+	}
+	var m keyManifest
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", manifestPath, err)
+	}
+
+	keys := make([]SigningKey, 0, len(m.Keys))
+	for _, entry := range m.Keys {
+		file := entry.File
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(filepath.Dir(manifestPath), file)
+		}
+		priv, err := readPrivateKey(file)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", entry.Kid, err)
+		}
+		k := SigningKey{ID: entry.Kid, Private: priv}
+		if k.SignFrom, err = parseOptionalTime(entry.SignFrom); err != nil {
+			return nil, fmt.Errorf("key %q: sign_from: %w", entry.Kid, err)
+		}
+		if k.RetireAt, err = parseOptionalTime(entry.RetireAt); err != nil {
+			return nil, fmt.Errorf("key %q: retire_at: %w", entry.Kid, err)
+		}
+		keys = append(keys, k)
+	}
+	return NewKeySet(keys...)
+}
+
+func readPrivateKey(path string) (crypto.Signer, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(raw)
+	if block == nil {
+		return nil, errors.New("no PEM data")
+	}
+	if block.Type == "RSA PRIVATE KEY" {
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported key type %T", key)
+	}
+	return signer, nil
+}
+
+func parseOptionalTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
+
+// signingKey returns the newest key whose schedule allows signing now.
+func (ks *KeySet) signingKey() (*SigningKey, error) {
+	now := ks.now()
+	for i := len(ks.keys) - 1; i >= 0; i-- {
+		k := &ks.keys[i]
+		if !k.SignFrom.After(now) && !k.retired(now) {
+			return k, nil
+		}
+	}
+	return nil, ErrNoSigningKey
+}
+
+// verificationKey returns the published key with this kid, if any.
+func (ks *KeySet) verificationKey(kid string) *SigningKey {
+	now := ks.now()
+	for i := range ks.keys {
+		if ks.keys[i].ID == kid && !ks.keys[i].retired(now) {
+			return &ks.keys[i]
+		}
+	}
+	return nil
+}
+
+func (ks *KeySet) algorithms() []string {
+	seen := make(map[string]bool)
+	var algs []string
+	for i := range ks.keys {
+		alg := ks.keys[i].method().Alg()
+		if !seen[alg] {
+			seen[alg] = true
+			algs = append(algs, alg)
+		}
+	}
+	return algs
+}
+
+// PublicKeys lists every key that is not retired, including keys scheduled
+// to start signing later.
+func (ks *KeySet) PublicKeys() []authuc.JSONWebKey {
+	now := ks.now()
+	out := make([]authuc.JSONWebKey, 0, len(ks.keys))
+	for i := range ks.keys {
+		k := &ks.keys[i]
+		if k.retired(now) {
+			continue
+		}
+		jwk := authuc.JSONWebKey{Kid: k.ID, Use: "sig", Alg: k.method().Alg()}
+		switch pub := k.Private.Public().(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		}
+		out = append(out, jwk)
+	}
+	return out
+}
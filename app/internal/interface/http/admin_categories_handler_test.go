@@ -11,6 +11,7 @@ import (
 	"time"
 
 	domcategory "example.com/my-golang-sample/app/internal/domain/category"
+	"example.com/my-golang-sample/app/internal/domain/page"
 	domuser "example.com/my-golang-sample/app/internal/domain/user"
 	"example.com/my-golang-sample/app/internal/infra/security"
 	categoryuc "example.com/my-golang-sample/app/internal/usecase/category"
@@ -84,7 +85,7 @@ func (m *memoryCategoryRepo) GetByID(ctx context.Context, id int64) (*domcategor
 	return nil, domcategory.ErrCategoryNotFound
 }
 
-func (m *memoryCategoryRepo) List(ctx context.Context, filter domcategory.ListFilter) ([]*domcategory.Category, error) {
+func (m *memoryCategoryRepo) List(ctx context.Context, filter domcategory.ListFilter, req page.Request) ([]*domcategory.Category, page.Info, error) {
 	result := make([]*domcategory.Category, 0, len(m.items))
 	for _, c := range m.items {
 		if filter.OnlyActive && !c.IsActive {
@@ -92,7 +93,7 @@ func (m *memoryCategoryRepo) List(ctx context.Context, filter domcategory.ListFi
 		}
 		result = append(result, m.clone(c))
 	}
-	return result, nil
+	return result, page.Info{Total: int64(len(result)), Page: req.Page, Size: req.Size}, nil
 }
 
 func setupCategoryAPI(repo domcategory.Repository) (*API, string) {
@@ -1,16 +1,23 @@
 package http
 
 import (
+	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 	"strings"
 
+	"github.com/go-chi/chi/v5"
+
+	domaudit "example.com/my-golang-sample/app/internal/domain/audit"
 	domcategory "example.com/my-golang-sample/app/internal/domain/category"
+	"example.com/my-golang-sample/app/internal/domain/money"
 	domorder "example.com/my-golang-sample/app/internal/domain/order"
 	domproduct "example.com/my-golang-sample/app/internal/domain/product"
 	domuser "example.com/my-golang-sample/app/internal/domain/user"
 	domrole "example.com/my-golang-sample/app/internal/domain/userrole"
 	categoryuc "example.com/my-golang-sample/app/internal/usecase/category"
+	orderuc "example.com/my-golang-sample/app/internal/usecase/order"
 	useruc "example.com/my-golang-sample/app/internal/usecase/user"
 	userroleuc "example.com/my-golang-sample/app/internal/usecase/userrole"
 )
@@ -19,11 +26,13 @@ type createRoleRequest struct {
 	Code        string `json:"code" validate:"required"`
 	Name        string `json:"name" validate:"required"`
 	Description string `json:"description"`
+	Rank        int    `json:"rank" validate:"min=0"`
 }
 
 type updateRoleRequest struct {
 	Name        *string `json:"name"`
 	Description *string `json:"description"`
+	Rank        *int    `json:"rank" validate:"omitempty,min=0"`
 }
 
 func (a *API) handleListUserRoles(w http.ResponseWriter, r *http.Request) {
@@ -32,7 +41,12 @@ func (a *API) handleListUserRoles(w http.ResponseWriter, r *http.Request) {
 		filter.Query = &q
 	}
 
-	roles, err := a.roleSvc.List(r.Context(), filter)
+	pageReq, err := parsePageRequest(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+	roles, info, err := a.roleSvc.List(r.Context(), filter, pageReq)
 	if err != nil {
 		handleDomainError(w, err)
 		return
@@ -42,10 +56,16 @@ func (a *API) handleListUserRoles(w http.ResponseWriter, r *http.Request) {
 	for _, role := range roles {
 		resp = append(resp, mapRole(role))
 	}
-	writeJSON(w, http.StatusOK, map[string]any{"data": resp})
+	writePage(w, resp, info)
 }
 
 func (a *API) handleCreateUserRole(w http.ResponseWriter, r *http.Request) {
+	executor := getAuthUser(r.Context())
+	if executor == nil {
+		respondError(w, http.StatusUnauthorized, errUnauthenticated)
+		return
+	}
+
 	var req createRoleRequest
 	if err := a.decodeAndValidate(r, &req); err != nil {
 		respondError(w, http.StatusBadRequest, err)
@@ -53,18 +73,27 @@ func (a *API) handleCreateUserRole(w http.ResponseWriter, r *http.Request) {
 	}
 
 	role, err := a.roleSvc.Create(r.Context(), userroleuc.CreateInput{
-		Code:        req.Code,
-		Name:        req.Name,
-		Description: req.Description,
+		ExecutorRole: executor.RoleCode,
+		Code:         req.Code,
+		Name:         req.Name,
+		Description:  req.Description,
+		Rank:         req.Rank,
 	})
 	if err != nil {
 		handleDomainError(w, err)
 		return
 	}
+	a.recordAudit(r, domaudit.ActionRoleCreated, "role", role.ID, nil, mapRole(role))
 	writeJSON(w, http.StatusCreated, mapRole(role))
 }
 
 func (a *API) handleUpdateUserRole(w http.ResponseWriter, r *http.Request) {
+	executor := getAuthUser(r.Context())
+	if executor == nil {
+		respondError(w, http.StatusUnauthorized, errUnauthenticated)
+		return
+	}
+
 	id, err := parseIDParam(r, "id")
 	if err != nil {
 		respondError(w, http.StatusBadRequest, err)
@@ -76,28 +105,39 @@ func (a *API) handleUpdateUserRole(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	before := auditSnapshot(a, r.Context(), a.roleSvc.GetByID, id, mapRole)
 	role, err := a.roleSvc.Update(r.Context(), userroleuc.UpdateInput{
-		ID:          id,
-		Name:        req.Name,
-		Description: req.Description,
+		ExecutorRole: executor.RoleCode,
+		ID:           id,
+		Name:         req.Name,
+		Description:  req.Description,
+		Rank:         req.Rank,
 	})
 	if err != nil {
 		handleDomainError(w, err)
 		return
 	}
+	a.recordAudit(r, domaudit.ActionRoleUpdated, "role", id, before, mapRole(role))
 	writeJSON(w, http.StatusOK, mapRole(role))
 }
 
 func (a *API) handleDeleteUserRole(w http.ResponseWriter, r *http.Request) {
+	executor := getAuthUser(r.Context())
+	if executor == nil {
+		respondError(w, http.StatusUnauthorized, errUnauthenticated)
+		return
+	}
 	id, err := parseIDParam(r, "id")
 	if err != nil {
 		respondError(w, http.StatusBadRequest, err)
 		return
 	}
-	if err := a.roleSvc.Delete(r.Context(), id); err != nil {
+	before := auditSnapshot(a, r.Context(), a.roleSvc.GetByID, id, mapRole)
+	if err := a.roleSvc.Delete(r.Context(), executor.RoleCode, id); err != nil {
 		handleDomainError(w, err)
 		return
 	}
+	a.recordAudit(r, domaudit.ActionRoleDeleted, "role", id, before, nil)
 	w.WriteHeader(http.StatusNoContent)
 }
 
@@ -126,7 +166,12 @@ func (a *API) handleListUsers(w http.ResponseWriter, r *http.Request) {
 		filter.RoleCode = &role
 	}
 
-	users, err := a.userSvc.ListUsers(r.Context(), filter)
+	pageReq, err := parsePageRequest(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+	users, info, err := a.userSvc.ListUsers(r.Context(), filter, pageReq)
 	if err != nil {
 		handleDomainError(w, err)
 		return
@@ -135,7 +180,7 @@ func (a *API) handleListUsers(w http.ResponseWriter, r *http.Request) {
 	for _, u := range users {
 		resp = append(resp, mapUser(u))
 	}
-	writeJSON(w, http.StatusOK, map[string]any{"data": resp})
+	writePage(w, resp, info)
 }
 
 func (a *API) handleCreateUser(w http.ResponseWriter, r *http.Request) {
@@ -168,6 +213,7 @@ func (a *API) handleCreateUser(w http.ResponseWriter, r *http.Request) {
 		handleDomainError(w, err)
 		return
 	}
+	a.recordAudit(r, domaudit.ActionUserCreated, "user", user.ID, nil, mapUser(user))
 	writeJSON(w, http.StatusCreated, mapUser(user))
 }
 
@@ -213,6 +259,7 @@ func (a *API) handleUpdateUser(w http.ResponseWriter, r *http.Request) {
 		roleCode = &role
 	}
 
+	before := auditSnapshot(a, r.Context(), a.userSvc.GetUser, id, mapUser)
 	user, err := a.userSvc.UpdateUser(r.Context(), useruc.UpdateUserInput{
 		ExecutorRole: executor.RoleCode,
 		ID:           id,
@@ -225,16 +272,48 @@ func (a *API) handleUpdateUser(w http.ResponseWriter, r *http.Request) {
 		handleDomainError(w, err)
 		return
 	}
+	after := mapUser(user)
+	if req.Password != nil {
+		// Không lưu mật khẩu, chỉ ghi nhận là đã đổi
+		after["password_changed"] = true
+	}
+	a.recordAudit(r, domaudit.ActionUserUpdated, "user", id, before, after)
 	writeJSON(w, http.StatusOK, mapUser(user))
 }
 
 func (a *API) handleDeleteUser(w http.ResponseWriter, r *http.Request) {
+	executor := getAuthUser(r.Context())
+	if executor == nil {
+		respondError(w, http.StatusUnauthorized, errUnauthenticated)
+		return
+	}
 	id, err := parseIDParam(r, "id")
 	if err != nil {
 		respondError(w, http.StatusBadRequest, err)
 		return
 	}
-	if err := a.userSvc.DeleteUser(r.Context(), id); err != nil {
+	before := auditSnapshot(a, r.Context(), a.userSvc.GetUser, id, mapUser)
+	if err := a.userSvc.DeleteUser(r.Context(), executor.RoleCode, id); err != nil {
+		handleDomainError(w, err)
+		return
+	}
+	a.recordAudit(r, domaudit.ActionUserDeleted, "user", id, before, nil)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleUnlockUser lifts a login lockout before it expires.
+func (a *API) handleUnlockUser(w http.ResponseWriter, r *http.Request) {
+	executor := getAuthUser(r.Context())
+	if executor == nil {
+		respondError(w, http.StatusUnauthorized, errUnauthenticated)
+		return
+	}
+	id, err := parseIDParam(r, "id")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := a.authSvc.UnlockAccount(r.Context(), executor.UserID, id); err != nil {
 		handleDomainError(w, err)
 		return
 	}
@@ -259,7 +338,12 @@ func (a *API) handleListCategories(w http.ResponseWriter, r *http.Request) {
 		filter.OnlyActive = val
 	}
 
-	categories, err := a.categorySvc.List(r.Context(), filter)
+	pageReq, err := parsePageRequest(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+	categories, info, err := a.categorySvc.List(r.Context(), filter, pageReq)
 	if err != nil {
 		handleDomainError(w, err)
 		return
@@ -268,7 +352,7 @@ func (a *API) handleListCategories(w http.ResponseWriter, r *http.Request) {
 	for _, c := range categories {
 		resp = append(resp, mapCategory(c))
 	}
-	writeJSON(w, http.StatusOK, map[string]any{"data": resp})
+	writePage(w, resp, info)
 }
 
 func (a *API) handleCreateCategory(w http.ResponseWriter, r *http.Request) {
@@ -288,6 +372,7 @@ func (a *API) handleCreateCategory(w http.ResponseWriter, r *http.Request) {
 		handleDomainError(w, err)
 		return
 	}
+	a.recordAudit(r, domaudit.ActionCategoryCreated, "category", category.ID, nil, mapCategory(category))
 	writeJSON(w, http.StatusCreated, mapCategory(category))
 }
 
@@ -303,6 +388,7 @@ func (a *API) handleUpdateCategory(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	before := auditSnapshot(a, r.Context(), a.categorySvc.GetByID, id, mapCategory)
 	category, err := a.categorySvc.Update(r.Context(), categoryuc.UpdateInput{
 		ID:          id,
 		Name:        &req.Name,
@@ -314,6 +400,7 @@ func (a *API) handleUpdateCategory(w http.ResponseWriter, r *http.Request) {
 		handleDomainError(w, err)
 		return
 	}
+	a.recordAudit(r, domaudit.ActionCategoryUpdated, "category", id, before, mapCategory(category))
 	writeJSON(w, http.StatusOK, mapCategory(category))
 }
 
@@ -323,10 +410,12 @@ func (a *API) handleDeleteCategory(w http.ResponseWriter, r *http.Request) {
 		respondError(w, http.StatusBadRequest, err)
 		return
 	}
+	before := auditSnapshot(a, r.Context(), a.categorySvc.GetByID, id, mapCategory)
 	if err := a.categorySvc.Delete(r.Context(), id); err != nil {
 		handleDomainError(w, err)
 		return
 	}
+	a.recordAudit(r, domaudit.ActionCategoryDeleted, "category", id, before, nil)
 	w.WriteHeader(http.StatusNoContent)
 }
 
@@ -345,12 +434,27 @@ func (a *API) handleGetCategory(w http.ResponseWriter, r *http.Request) {
 }
 
 type productRequest struct {
-	Name        string  `json:"name" validate:"required"`
-	Description string  `json:"description"`
-	Price       float64 `json:"price" validate:"required,gt=0"`
-	Stock       int64   `json:"stock" validate:"required,gte=0"`
-	CategoryID  int64   `json:"category_id" validate:"required,gt=0"`
-	IsActive    bool    `json:"is_active"`
+	Name        string      `json:"name" validate:"required"`
+	Description string      `json:"description"`
+	Price       json.Number `json:"price" validate:"required"`
+	Stock       int64       `json:"stock" validate:"required,gte=0"`
+	CategoryID  int64       `json:"category_id" validate:"required,gt=0"`
+	IsActive    bool        `json:"is_active"`
+}
+
+type productPriceRequest struct {
+	Price json.Number `json:"price" validate:"required"`
+}
+
+var errInvalidPrice = errors.New("price must be a positive amount")
+
+// parsePrice reads a requested price exactly, in the given currency.
+func parsePrice(n json.Number, currency string) (money.Money, error) {
+	price, err := money.ParseExact(n.String(), currency)
+	if err != nil || !price.IsPositive() {
+		return money.Money{}, errInvalidPrice
+	}
+	return price, nil
 }
 
 func (a *API) handleCreateProduct(w http.ResponseWriter, r *http.Request) {
@@ -359,10 +463,15 @@ func (a *API) handleCreateProduct(w http.ResponseWriter, r *http.Request) {
 		respondError(w, http.StatusBadRequest, err)
 		return
 	}
+	price, err := parsePrice(req.Price, a.currency)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
 	product, err := a.productSvc.Create(r.Context(), &domproduct.Product{
 		Name:        req.Name,
 		Description: req.Description,
-		Price:       req.Price,
+		Price:       price,
 		Stock:       req.Stock,
 		CategoryID:  req.CategoryID,
 		IsActive:    req.IsActive,
@@ -371,6 +480,7 @@ func (a *API) handleCreateProduct(w http.ResponseWriter, r *http.Request) {
 		handleDomainError(w, err)
 		return
 	}
+	a.recordAudit(r, domaudit.ActionProductCreated, "product", product.ID, nil, mapProduct(product))
 	writeJSON(w, http.StatusCreated, mapProduct(product))
 }
 
@@ -385,12 +495,18 @@ func (a *API) handleUpdateProduct(w http.ResponseWriter, r *http.Request) {
 		respondError(w, http.StatusBadRequest, err)
 		return
 	}
+	price, err := parsePrice(req.Price, a.currency)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
 
+	before := auditSnapshot(a, r.Context(), a.productSvc.GetByID, id, mapProduct)
 	product, err := a.productSvc.Update(r.Context(), &domproduct.Product{
 		ID:          id,
 		Name:        req.Name,
 		Description: req.Description,
-		Price:       req.Price,
+		Price:       price,
 		Stock:       req.Stock,
 		CategoryID:  req.CategoryID,
 		IsActive:    req.IsActive,
@@ -399,6 +515,7 @@ func (a *API) handleUpdateProduct(w http.ResponseWriter, r *http.Request) {
 		handleDomainError(w, err)
 		return
 	}
+	a.recordAudit(r, domaudit.ActionProductUpdated, "product", id, before, mapProduct(product))
 	writeJSON(w, http.StatusOK, mapProduct(product))
 }
 
@@ -408,19 +525,85 @@ func (a *API) handleDeleteProduct(w http.ResponseWriter, r *http.Request) {
 		respondError(w, http.StatusBadRequest, err)
 		return
 	}
+	before := auditSnapshot(a, r.Context(), a.productSvc.GetByID, id, mapProduct)
 	if err := a.productSvc.Delete(r.Context(), id); err != nil {
 		handleDomainError(w, err)
 		return
 	}
+	a.recordAudit(r, domaudit.ActionProductDeleted, "product", id, before, nil)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (a *API) handleSetProductPrice(w http.ResponseWriter, r *http.Request) {
+	id, err := parseIDParam(r, "id")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+	currency, err := money.ParseCurrency(chi.URLParam(r, "currency"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+	var req productPriceRequest
+	if err := a.decodeAndValidate(r, &req); err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+	price, err := parsePrice(req.Price, currency)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	before := auditSnapshot(a, r.Context(), a.productSvc.GetByID, id, mapProduct)
+	product, err := a.productSvc.SetPrice(r.Context(), id, price)
+	if err != nil {
+		handleDomainError(w, err)
+		return
+	}
+	a.recordAudit(r, domaudit.ActionProductPriceSet, "product", id, before, mapProduct(product))
+	writeJSON(w, http.StatusOK, mapProduct(product))
+}
+
+func (a *API) handleDeleteProductPrice(w http.ResponseWriter, r *http.Request) {
+	id, err := parseIDParam(r, "id")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+	currency, err := money.ParseCurrency(chi.URLParam(r, "currency"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+	before := auditSnapshot(a, r.Context(), a.productSvc.GetByID, id, mapProduct)
+	if err := a.productSvc.DeletePrice(r.Context(), id, currency); err != nil {
+		handleDomainError(w, err)
+		return
+	}
+	after := auditSnapshot(a, r.Context(), a.productSvc.GetByID, id, mapProduct)
+	a.recordAudit(r, domaudit.ActionProductPriceDeleted, "product", id, before, after)
 	w.WriteHeader(http.StatusNoContent)
 }
 
 type updateOrderStatusRequest struct {
 	Status string `json:"status" validate:"required"`
+	Note   string `json:"note" validate:"omitempty,max=255"`
 }
 
 func (a *API) handleListOrders(w http.ResponseWriter, r *http.Request) {
-	orders, err := a.orderSvc.List(r.Context())
+	filter, err := a.parseOrderFilter(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+	pageReq, err := parsePageRequest(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+	orders, info, err := a.orderSvc.List(r.Context(), filter, pageReq)
 	if err != nil {
 		handleDomainError(w, err)
 		return
@@ -429,7 +612,74 @@ func (a *API) handleListOrders(w http.ResponseWriter, r *http.Request) {
 	for _, o := range orders {
 		resp = append(resp, mapOrder(o))
 	}
-	writeJSON(w, http.StatusOK, map[string]any{"data": resp})
+	writePage(w, resp, info)
+}
+
+// parseOrderFilter reads the admin order search parameters. Totals are
+// amounts in currency, which defaults to the store currency.
+func (a *API) parseOrderFilter(r *http.Request) (domorder.ListFilter, error) {
+	q := r.URL.Query()
+	filter := domorder.ListFilter{}
+
+	if v := strings.TrimSpace(q.Get("status")); v != "" {
+		status := domorder.Status(strings.ToUpper(v))
+		if !status.IsValid() {
+			return filter, domorder.ErrInvalidStatus
+		}
+		filter.Status = &status
+	}
+	if v := strings.TrimSpace(q.Get("payment_method")); v != "" {
+		method := domorder.PaymentMethod(strings.ToUpper(v))
+		if !method.IsValid() {
+			return filter, domorder.ErrInvalidPayment
+		}
+		filter.PaymentMethod = &method
+	}
+	if v := strings.TrimSpace(q.Get("user_id")); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || id <= 0 {
+			return filter, errors.New("invalid user_id")
+		}
+		filter.UserID = &id
+	}
+	if v := strings.TrimSpace(strings.ToLower(q.Get("email"))); v != "" {
+		filter.Email = &v
+	}
+
+	selected := strings.TrimSpace(q.Get("currency"))
+	currency, err := a.requestCurrency(selected)
+	if err != nil {
+		return filter, err
+	}
+	if selected != "" {
+		filter.Currency = &currency
+	}
+	if filter.CreatedFrom, err = parseTimeParam(q.Get("created_from")); err != nil {
+		return filter, err
+	}
+	if filter.CreatedTo, err = parseTimeParam(q.Get("created_to")); err != nil {
+		return filter, err
+	}
+	if filter.MinTotal, err = parseMoneyParam(q.Get("min_total"), currency); err != nil {
+		return filter, err
+	}
+	if filter.MaxTotal, err = parseMoneyParam(q.Get("max_total"), currency); err != nil {
+		return filter, err
+	}
+	return filter, nil
+}
+
+// parseMoneyParam reads an optional decimal amount in currency.
+func parseMoneyParam(v, currency string) (*money.Money, error) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return nil, nil
+	}
+	m, err := money.ParseExact(v, currency)
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
 }
 
 func (a *API) handleGetOrder(w http.ResponseWriter, r *http.Request) {
@@ -443,7 +693,15 @@ func (a *API) handleGetOrder(w http.ResponseWriter, r *http.Request) {
 		handleDomainError(w, err)
 		return
 	}
-	writeJSON(w, http.StatusOK, mapOrder(order))
+	history, err := a.orderSvc.ListStatusHistory(r.Context(), id)
+	if err != nil {
+		handleDomainError(w, err)
+		return
+	}
+
+	resp := mapOrder(order)
+	resp["status_history"] = mapOrderStatusHistory(history)
+	writeJSON(w, http.StatusOK, resp)
 }
 
 func (a *API) handleUpdateOrderStatus(w http.ResponseWriter, r *http.Request) {
@@ -458,11 +716,26 @@ func (a *API) handleUpdateOrderStatus(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	status := domorder.Status(req.Status)
-	order, err := a.orderSvc.UpdateStatus(r.Context(), id, status)
+	executor := getAuthUser(r.Context())
+	if executor == nil {
+		respondError(w, http.StatusUnauthorized, errUnauthenticated)
+		return
+	}
+
+	before := auditSnapshot(a, r.Context(), a.orderSvc.GetByID, id, mapOrder)
+	order, err := a.orderSvc.UpdateStatus(r.Context(), id, orderuc.UpdateStatusInput{
+		Status:    domorder.Status(req.Status),
+		ChangedBy: executor.UserID,
+		Note:      req.Note,
+	})
 	if err != nil {
 		handleDomainError(w, err)
 		return
 	}
+	after := mapOrder(order)
+	if req.Note != "" {
+		after["note"] = req.Note
+	}
+	a.recordAudit(r, domaudit.ActionOrderStatusUpdated, "order", id, before, after)
 	writeJSON(w, http.StatusOK, mapOrder(order))
 }
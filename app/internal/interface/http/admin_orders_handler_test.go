@@ -12,15 +12,19 @@ import (
 	"github.com/stretchr/testify/require"
 
 	domcart "example.com/my-golang-sample/app/internal/domain/cart"
+	"example.com/my-golang-sample/app/internal/domain/money"
 	domorder "example.com/my-golang-sample/app/internal/domain/order"
+	"example.com/my-golang-sample/app/internal/domain/page"
 	domuser "example.com/my-golang-sample/app/internal/domain/user"
 	"example.com/my-golang-sample/app/internal/infra/security"
 	orderuc "example.com/my-golang-sample/app/internal/usecase/order"
 )
 
 type fakeOrderRepo struct {
-	orders map[int64]*domorder.Order
-	nextID int64
+	orders     map[int64]*domorder.Order
+	nextID     int64
+	history    []domorder.StatusHistory
+	lastFilter domorder.ListFilter
 }
 
 func newFakeOrderRepo() *fakeOrderRepo {
@@ -31,10 +35,10 @@ func newFakeOrderRepo() *fakeOrderRepo {
 				UserID:        100,
 				Status:        domorder.StatusPending,
 				PaymentMethod: domorder.PaymentCOD,
-				TotalAmount:   50.0,
+				TotalAmount:   money.MustParse("50.0", "SAR"),
 				Items: []domorder.OrderItem{
-					{ID: 1, OrderID: 1, ProductID: 1, Name: "Product 1", Price: 10.0, Quantity: 2},
-					{ID: 2, OrderID: 1, ProductID: 2, Name: "Product 2", Price: 30.0, Quantity: 1},
+					{ID: 1, OrderID: 1, ProductID: 1, Name: "Product 1", Price: money.MustParse("10.0", "SAR"), Quantity: 2},
+					{ID: 2, OrderID: 1, ProductID: 2, Name: "Product 2", Price: money.MustParse("30.0", "SAR"), Quantity: 1},
 				},
 			},
 			2: {
@@ -42,9 +46,9 @@ func newFakeOrderRepo() *fakeOrderRepo {
 				UserID:        101,
 				Status:        domorder.StatusPaid,
 				PaymentMethod: domorder.PaymentTamara,
-				TotalAmount:   100.0,
+				TotalAmount:   money.MustParse("100.0", "SAR"),
 				Items: []domorder.OrderItem{
-					{ID: 3, OrderID: 2, ProductID: 3, Name: "Product 3", Price: 100.0, Quantity: 1},
+					{ID: 3, OrderID: 2, ProductID: 3, Name: "Product 3", Price: money.MustParse("100.0", "SAR"), Quantity: 1},
 				},
 			},
 		},
@@ -52,17 +56,23 @@ func newFakeOrderRepo() *fakeOrderRepo {
 	}
 }
 
-func (f *fakeOrderRepo) CreateFromCart(ctx context.Context, userID int64, items []domcart.Item, payment domorder.PaymentMethod) (*domorder.Order, error) {
+func (f *fakeOrderRepo) CreateFromCart(ctx context.Context, userID int64, items []domcart.Item, payment domorder.PaymentMethod, currency string) (*domorder.Order, error) {
 	return nil, nil
 }
 
-func (f *fakeOrderRepo) List(ctx context.Context) ([]*domorder.Order, error) {
+func (f *fakeOrderRepo) List(ctx context.Context, filter domorder.ListFilter, req page.Request) ([]*domorder.Order, page.Info, error) {
+	f.lastFilter = filter
 	var result []*domorder.Order
 	for _, order := range f.orders {
+		if filter.Status != nil && order.Status != *filter.Status ||
+			filter.PaymentMethod != nil && order.PaymentMethod != *filter.PaymentMethod ||
+			filter.UserID != nil && order.UserID != *filter.UserID {
+			continue
+		}
 		cloned := *order
 		result = append(result, &cloned)
 	}
-	return result, nil
+	return result, page.Info{Total: int64(len(result)), Page: req.Page, Size: req.Size}, nil
 }
 
 func (f *fakeOrderRepo) GetByID(ctx context.Context, id int64) (*domorder.Order, error) {
@@ -73,21 +83,61 @@ func (f *fakeOrderRepo) GetByID(ctx context.Context, id int64) (*domorder.Order,
 	return nil, domorder.ErrOrderNotFound
 }
 
-func (f *fakeOrderRepo) UpdateStatus(ctx context.Context, id int64, status domorder.Status) (*domorder.Order, error) {
-	order, ok := f.orders[id]
+func (f *fakeOrderRepo) ListByUser(ctx context.Context, userID int64, req page.Request) ([]*domorder.Order, page.Info, error) {
+	var result []*domorder.Order
+	for _, order := range f.orders {
+		if order.UserID == userID {
+			cloned := *order
+			result = append(result, &cloned)
+		}
+	}
+	return result, page.Info{Total: int64(len(result)), Page: req.Page, Size: req.Size}, nil
+}
+
+func (f *fakeOrderRepo) GetByIDForUser(ctx context.Context, id, userID int64) (*domorder.Order, error) {
+	if order, ok := f.orders[id]; ok && order.UserID == userID {
+		cloned := *order
+		return &cloned, nil
+	}
+	return nil, domorder.ErrOrderNotFound
+}
+
+func (f *fakeOrderRepo) UpdateStatus(ctx context.Context, change domorder.StatusChange) (*domorder.Order, error) {
+	order, ok := f.orders[change.OrderID]
 	if !ok {
 		return nil, domorder.ErrOrderNotFound
 	}
-	if !status.IsValid() {
-		return nil, domorder.ErrInvalidStatus
+	if order.Status != change.From {
+		return nil, domorder.ErrInvalidTransition
 	}
-	order.Status = status
+	order.Status = change.To
+	f.history = append(f.history, domorder.StatusHistory{
+		ID:        int64(len(f.history) + 1),
+		OrderID:   change.OrderID,
+		From:      change.From,
+		To:        change.To,
+		ChangedBy: change.ChangedBy,
+		Note:      change.Note,
+	})
 	cloned := *order
 	return &cloned, nil
 }
 
+func (f *fakeOrderRepo) ListStatusHistory(ctx context.Context, orderID int64) ([]domorder.StatusHistory, error) {
+	var result []domorder.StatusHistory
+	for _, h := range f.history {
+		if h.OrderID == orderID {
+			result = append(result, h)
+		}
+	}
+	return result, nil
+}
+
 func setupOrderAPI(roleCode domuser.RoleCode) (*API, string) {
-	orderRepo := newFakeOrderRepo()
+	return setupOrderAPIWithRepo(roleCode, newFakeOrderRepo())
+}
+
+func setupOrderAPIWithRepo(roleCode domuser.RoleCode, orderRepo *fakeOrderRepo) (*API, string) {
 	orderSvc := orderuc.NewService(orderRepo)
 	tokenSvc := security.NewJWTService("test-secret", time.Hour)
 
@@ -258,7 +308,7 @@ func TestAdminOrders_UpdateOrderStatusAsSuperAdminReturns200(t *testing.T) {
 	}
 	payload, _ := json.Marshal(body)
 
-	req := httptest.NewRequest(http.MethodPatch, "/api/v1/admin/orders/1", bytes.NewReader(payload))
+	req := httptest.NewRequest(http.MethodPatch, "/api/v1/admin/orders/2", bytes.NewReader(payload))
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+token)
 	rec := httptest.NewRecorder()
@@ -347,13 +397,13 @@ func TestAdminOrders_UpdateOrderStatusNotFoundReturns404(t *testing.T) {
 	require.Equal(t, http.StatusNotFound, rec.Code, rec.Body.String())
 }
 
-func TestAdminOrders_UpdateOrderStatusAllValidStatuses(t *testing.T) {
+func TestAdminOrders_UpdateOrderStatusFollowsLifecycle(t *testing.T) {
 	api, token := setupOrderAPI(domuser.RoleCodeAdmin)
 	router := api.Router()
 
-	validStatuses := []string{"PENDING", "PAID", "SHIPPED", "CANCELED"}
+	lifecycle := []string{"PAID", "SHIPPED", "DELIVERED"}
 
-	for _, status := range validStatuses {
+	for _, status := range lifecycle {
 		body := map[string]any{
 			"status": status,
 		}
@@ -366,10 +416,139 @@ func TestAdminOrders_UpdateOrderStatusAllValidStatuses(t *testing.T) {
 
 		router.ServeHTTP(rec, req)
 
-		require.Equal(t, http.StatusOK, rec.Code, "status %s should be valid", status)
+		require.Equal(t, http.StatusOK, rec.Code, "transition to %s should be allowed", status)
 
 		var order map[string]any
 		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &order))
 		require.Equal(t, status, order["status"])
 	}
 }
+
+func TestAdminOrders_UpdateOrderStatusIllegalTransitionReturns422(t *testing.T) {
+	api, token := setupOrderAPI(domuser.RoleCodeAdmin)
+	router := api.Router()
+
+	// Order 2 is PAID; it cannot go back to PENDING
+	payload, _ := json.Marshal(map[string]any{"status": "PENDING"})
+
+	req := httptest.NewRequest(http.MethodPatch, "/api/v1/admin/orders/2", bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusUnprocessableEntity, rec.Code, rec.Body.String())
+	require.Contains(t, rec.Body.String(), domorder.ErrInvalidTransition.Error())
+}
+
+func TestAdminOrders_GetOrderIncludesStatusHistory(t *testing.T) {
+	api, token := setupOrderAPI(domuser.RoleCodeAdmin)
+	router := api.Router()
+
+	payload, _ := json.Marshal(map[string]any{"status": "PAID", "note": "bank transfer received"})
+	req := httptest.NewRequest(http.MethodPatch, "/api/v1/admin/orders/1", bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/admin/orders/1", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var order map[string]any
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &order))
+	history, ok := order["status_history"].([]any)
+	require.True(t, ok, "status_history should be present")
+	require.Len(t, history, 1)
+
+	entry := history[0].(map[string]any)
+	require.Equal(t, "PENDING", entry["from"])
+	require.Equal(t, "PAID", entry["to"])
+	require.Equal(t, float64(1), entry["changed_by"])
+	require.Equal(t, "bank transfer received", entry["note"])
+}
+
+func TestAdminOrders_ListPagination(t *testing.T) {
+	api, token := setupOrderAPI(domuser.RoleCodeAdmin)
+	router := api.Router()
+	get := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/orders"+query, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := get("?size=1000&sort=-created_at")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var response map[string]any
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	require.Equal(t, float64(100), response["size"], "size is capped")
+	require.Equal(t, float64(1), response["page"])
+	require.Equal(t, float64(2), response["total"])
+	require.Contains(t, response, "next_cursor")
+
+	descCursor := page.EncodeCursor(page.Cursor{Sort: "id", Desc: true, ID: 2})
+	for _, query := range []string{"?sort=total_amount", "?size=0", "?page=abc", "?cursor=garbage", "?sort=id&cursor=" + descCursor} {
+		rec := get(query)
+		require.Equal(t, http.StatusBadRequest, rec.Code, query)
+	}
+
+	rec = get("?cursor=" + descCursor)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+}
+
+func TestAdminOrders_ListFilters(t *testing.T) {
+	orderRepo := newFakeOrderRepo()
+	api, token := setupOrderAPIWithRepo(domuser.RoleCodeAdmin, orderRepo)
+	router := api.Router()
+	get := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/orders"+query, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := get("?status=paid&payment_method=TAMARA&user_id=101")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var response map[string]any
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	require.Equal(t, float64(1), response["total"])
+	data := response["data"].([]any)
+	require.Equal(t, float64(2), data[0].(map[string]any)["id"])
+
+	rec = get("?email=Buyer@Example.com&created_from=2026-01-01T00:00:00Z&created_to=2026-02-01T00:00:00Z&min_total=10&max_total=99.5")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	filter := orderRepo.lastFilter
+	require.Equal(t, "buyer@example.com", *filter.Email)
+	require.Equal(t, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), *filter.CreatedFrom)
+	require.Equal(t, time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC), *filter.CreatedTo)
+	require.Equal(t, money.MustParse("10", money.DefaultCurrency), *filter.MinTotal)
+	require.Equal(t, money.MustParse("99.5", money.DefaultCurrency), *filter.MaxTotal)
+	require.Nil(t, filter.Currency, "totals default to the store currency without filtering on it")
+
+	rec = get("?currency=usd&min_total=5")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.Equal(t, "USD", *orderRepo.lastFilter.Currency)
+	require.Equal(t, money.MustParse("5", "USD"), *orderRepo.lastFilter.MinTotal)
+
+	for _, query := range []string{
+		"?status=LOST",
+		"?payment_method=CASH",
+		"?user_id=abc",
+		"?created_from=yesterday",
+		"?created_from=2026-02-01T00:00:00Z&created_to=2026-01-01T00:00:00Z",
+		"?min_total=abc",
+		"?min_total=50&max_total=10",
+		"?currency=XX",
+	} {
+		rec := get(query)
+		require.Equal(t, http.StatusBadRequest, rec.Code, query)
+	}
+}
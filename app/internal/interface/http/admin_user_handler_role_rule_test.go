@@ -12,6 +12,8 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	domauth "example.com/my-golang-sample/app/internal/domain/auth"
+	"example.com/my-golang-sample/app/internal/domain/page"
 	domuser "example.com/my-golang-sample/app/internal/domain/user"
 	"example.com/my-golang-sample/app/internal/infra/security"
 	authuc "example.com/my-golang-sample/app/internal/usecase/auth"
@@ -78,7 +80,7 @@ func (m *minimalUserRepo) GetByEmail(ctx context.Context, email string) (*domuse
 	return nil, domuser.ErrUserNotFound
 }
 
-func (m *minimalUserRepo) List(ctx context.Context, filter domuser.ListUsersFilter) ([]*domuser.User, error) {
+func (m *minimalUserRepo) List(ctx context.Context, filter domuser.ListUsersFilter, req page.Request) ([]*domuser.User, page.Info, error) {
 	users := make([]*domuser.User, 0, len(m.users))
 	for _, u := range m.users {
 		users = append(users, &domuser.User{
@@ -90,7 +92,7 @@ func (m *minimalUserRepo) List(ctx context.Context, filter domuser.ListUsersFilt
 			RoleCode:     u.RoleCode,
 		})
 	}
-	return users, nil
+	return users, page.Info{Total: int64(len(users)), Page: req.Page, Size: req.Size}, nil
 }
 
 func (m *minimalUserRepo) Update(ctx context.Context, u *domuser.User) (*domuser.User, error) {
@@ -148,7 +150,7 @@ func setupAPIWithRole(role domuser.RoleCode) (*API, *minimalUserRepo, string) {
 	
 	// Create minimal auth service (not really used in these tests)
 	fakeAuthRepo := &fakeAuthUserRepo{}
-	authSvc := authuc.NewService(fakeAuthRepo, passwordSvc, tokenSvc)
+	authSvc := authuc.NewService(fakeAuthRepo, passwordSvc, tokenSvc, nil)
 
 	api := NewAPI(Dependencies{
 		AuthService: authSvc,
@@ -219,7 +221,7 @@ func TestAdminUpdateUser_AsAdmin_CannotPromoteToAdmin_Returns422(t *testing.T) {
 	
 	// Create minimal auth service
 	fakeAuthRepo := &fakeAuthUserRepo{}
-	authSvc := authuc.NewService(fakeAuthRepo, passwordSvc, tokenSvc)
+	authSvc := authuc.NewService(fakeAuthRepo, passwordSvc, tokenSvc, nil)
 
 	// Create SUPER_ADMIN API to pre-create user
 	superAdminAPI := NewAPI(Dependencies{
@@ -381,3 +383,80 @@ func TestAdminUpdateUser_AsSuperAdmin_CanPromoteToAdmin_Returns200(t *testing.T)
 	require.Equal(t, float64(existingUserID), user["id"], "user ID should match")
 }
 
+
+// TestAdminUser_AsAdmin_CannotTakeOverOrDeleteSuperAdmin verifies that the rank
+// rule also covers plain profile edits and deletes, not only role changes.
+func TestAdminUser_AsAdmin_CannotTakeOverOrDeleteSuperAdmin(t *testing.T) {
+	api, repo, token := setupAPIWithRole(domuser.RoleCodeAdmin)
+	router := api.Router()
+	repo.users[42] = &domuser.User{ID: 42, Name: "Root", Email: "root@example.com", PasswordHash: "hash", UserRoleID: 1, RoleCode: domuser.RoleCodeSuperAdmin}
+
+	for _, body := range []string{`{"email":"attacker@example.com"}`, `{"password":"taken-over"}`} {
+		req := httptest.NewRequest(http.MethodPut, "/api/v1/admin/users/42", bytes.NewReader([]byte(body)))
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusUnprocessableEntity, rec.Code, body)
+	}
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/admin/users/42", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+
+	require.Equal(t, "root@example.com", repo.users[42].Email)
+	require.Equal(t, "hash", repo.users[42].PasswordHash)
+}
+
+func TestAdminUser_RoleChangeAndDeleteRevokeExistingTokens(t *testing.T) {
+	repo := newMinimalUserRepo()
+	repo.users[42] = &domuser.User{ID: 42, Name: "Demoted", Email: "demoted@example.com", UserRoleID: 2, RoleCode: domuser.RoleCodeAdmin}
+	repo.users[43] = &domuser.User{ID: 43, Name: "Deleted", Email: "deleted@example.com", UserRoleID: 2, RoleCode: domuser.RoleCodeAdmin}
+	tokenSvc := security.NewJWTService("test-secret", time.Hour)
+	refresh := &fakeRefreshTokenRepo{}
+	authSvc := authuc.NewService(&fakeAuthUserRepo{}, fakePasswordService{}, tokenSvc, nil,
+		authuc.WithSessions(refresh, &fakeDenylist{revoked: make(map[string]time.Time)}, time.Hour))
+	api := NewAPI(Dependencies{
+		AuthService:  authSvc,
+		UserService:  useruc.NewService(repo, fakePasswordService{}, useruc.WithTokenRevoker(authSvc)),
+		TokenService: tokenSvc,
+	})
+	router := api.Router()
+
+	send := func(method, path, token string, body any) int {
+		var payload []byte
+		if body != nil {
+			payload, _ = json.Marshal(body)
+		}
+		req := httptest.NewRequest(method, path, bytes.NewReader(payload))
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	issue := func(u *domuser.User) string {
+		token, err := tokenSvc.GenerateToken(u)
+		require.NoError(t, err)
+		return token
+	}
+	superToken := issue(&domuser.User{ID: 1, Email: "root@example.com", RoleCode: domuser.RoleCodeSuperAdmin})
+	demotedToken := issue(repo.users[42])
+	deletedToken := issue(repo.users[43])
+	refresh.tokens = append(refresh.tokens, &domauth.RefreshToken{UserID: 42, ExpiresAt: time.Now().Add(time.Hour)})
+
+	require.Equal(t, http.StatusOK, send(http.MethodGet, "/api/v1/admin/users", demotedToken, nil))
+	require.Equal(t, http.StatusOK, send(http.MethodPut, "/api/v1/admin/users/42", superToken, map[string]any{"name": "Renamed"}))
+	require.Equal(t, http.StatusOK, send(http.MethodGet, "/api/v1/admin/users", demotedToken, nil), "other edits keep the session")
+
+	require.Equal(t, http.StatusOK, send(http.MethodPut, "/api/v1/admin/users/42", superToken, map[string]any{"role_code": "CUSTOMER"}))
+	require.Equal(t, http.StatusUnauthorized, send(http.MethodGet, "/api/v1/admin/users", demotedToken, nil), "the old role is gone from the access token")
+	require.True(t, refresh.tokens[0].IsRevoked())
+
+	require.Equal(t, http.StatusNoContent, send(http.MethodDelete, "/api/v1/admin/users/43", superToken, nil))
+	require.Equal(t, http.StatusUnauthorized, send(http.MethodGet, "/api/v1/admin/users", deletedToken, nil))
+
+	require.Equal(t, http.StatusOK, send(http.MethodGet, "/api/v1/admin/users", superToken, nil))
+}
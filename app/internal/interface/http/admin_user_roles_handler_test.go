@@ -13,6 +13,7 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"example.com/my-golang-sample/app/internal/domain/page"
 	domuser "example.com/my-golang-sample/app/internal/domain/user"
 	domrole "example.com/my-golang-sample/app/internal/domain/userrole"
 	"example.com/my-golang-sample/app/internal/infra/security"
@@ -28,9 +29,9 @@ type fakeRoleRepo struct {
 func newFakeRoleRepo() *fakeRoleRepo {
 	return &fakeRoleRepo{
 		roles: map[int64]*domrole.UserRole{
-			1: {ID: 1, Code: domuser.RoleCodeSuperAdmin, Name: "Super Admin", IsSystem: true},
-			2: {ID: 2, Code: domuser.RoleCodeAdmin, Name: "Admin", IsSystem: true},
-			3: {ID: 3, Code: domuser.RoleCodeCustomer, Name: "Customer", IsSystem: true},
+			1: {ID: 1, Code: domuser.RoleCodeSuperAdmin, Name: "Super Admin", IsSystem: true, Rank: domrole.RankSuperAdmin},
+			2: {ID: 2, Code: domuser.RoleCodeAdmin, Name: "Admin", IsSystem: true, Rank: domrole.RankAdmin},
+			3: {ID: 3, Code: domuser.RoleCodeCustomer, Name: "Customer", IsSystem: true, Rank: domrole.RankCustomer},
 		},
 		nextID: 4,
 		inUse:  map[int64]bool{},
@@ -91,7 +92,7 @@ func (f *fakeRoleRepo) GetByCode(ctx context.Context, code string) (*domrole.Use
 	return nil, domrole.ErrRoleNotFound
 }
 
-func (f *fakeRoleRepo) List(ctx context.Context, filter domrole.ListFilter) ([]*domrole.UserRole, error) {
+func (f *fakeRoleRepo) List(ctx context.Context, filter domrole.ListFilter, req page.Request) ([]*domrole.UserRole, page.Info, error) {
 	results := make([]*domrole.UserRole, 0, len(f.roles))
 	var query string
 	if filter.Query != nil {
@@ -108,7 +109,7 @@ func (f *fakeRoleRepo) List(ctx context.Context, filter domrole.ListFilter) ([]*
 		cloned := *role
 		results = append(results, &cloned)
 	}
-	return results, nil
+	return results, page.Info{Total: int64(len(results)), Page: req.Page, Size: req.Size}, nil
 }
 
 func newTestAPIForRoles(repo domrole.Repository) (*API, string) {
@@ -237,3 +238,39 @@ func TestAdminUserRole_CreateDuplicateReturns409(t *testing.T) {
 	router.ServeHTTP(rec, req)
 	require.Equal(t, http.StatusConflict, rec.Code, rec.Body.String())
 }
+
+func TestAdminUserRole_RankMustBeBelowExecutor(t *testing.T) {
+	repo := newFakeRoleRepo()
+	api, superToken := newTestAPIForRoles(repo)
+	router := api.Router()
+	adminToken, _ := security.NewJWTService("secret", time.Hour).GenerateToken(&domuser.User{
+		ID:       2,
+		Email:    "admin@example.com",
+		RoleCode: domuser.RoleCodeAdmin,
+	})
+
+	send := func(method, path, token string, body map[string]any) *httptest.ResponseRecorder {
+		payload, _ := json.Marshal(body)
+		req := httptest.NewRequest(method, path, bytes.NewReader(payload))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := send(http.MethodPost, "/api/v1/admin/user-roles", adminToken, map[string]any{"code": "MANAGER", "name": "Manager", "rank": domrole.RankAdmin})
+	require.Equal(t, http.StatusForbidden, rec.Code, rec.Body.String())
+
+	rec = send(http.MethodPost, "/api/v1/admin/user-roles", adminToken, map[string]any{"code": "SUPPORT", "name": "Support", "rank": 20})
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	var created map[string]any
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &created))
+	require.Equal(t, float64(20), created["rank"])
+
+	rec = send(http.MethodPut, "/api/v1/admin/user-roles/2", superToken, map[string]any{"rank": 10})
+	require.Equal(t, http.StatusUnprocessableEntity, rec.Code, "system role ranks are fixed")
+
+	rec = send(http.MethodPost, "/api/v1/admin/user-roles", superToken, map[string]any{"code": "BAD", "name": "Bad", "rank": -1})
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+}
@@ -10,6 +10,7 @@ import (
 	"testing"
 	"time"
 
+	"example.com/my-golang-sample/app/internal/domain/page"
 	domuser "example.com/my-golang-sample/app/internal/domain/user"
 	domrole "example.com/my-golang-sample/app/internal/domain/userrole"
 	"example.com/my-golang-sample/app/internal/infra/security"
@@ -35,8 +36,8 @@ func (f *fakeUserRepo) GetByEmail(ctx context.Context, email string) (*domuser.U
 	return nil, domuser.ErrUserNotFound
 }
 
-func (f *fakeUserRepo) List(ctx context.Context, filter domuser.ListUsersFilter) ([]*domuser.User, error) {
-	return nil, nil
+func (f *fakeUserRepo) List(ctx context.Context, filter domuser.ListUsersFilter, req page.Request) ([]*domuser.User, page.Info, error) {
+	return nil, page.Info{}, nil
 }
 
 func (f *fakeUserRepo) Update(ctx context.Context, u *domuser.User) (*domuser.User, error) {
@@ -68,7 +69,7 @@ func TestAdminCreateAdminRoleReturns422(t *testing.T) {
 	userSvc := useruc.NewService(repo, passwordSvc)
 
 	tokenSvc := security.NewJWTService("test-secret", time.Hour)
-	authSvc := authuc.NewService(repo, passwordSvc, tokenSvc)
+	authSvc := authuc.NewService(repo, passwordSvc, tokenSvc, nil)
 
 	api := NewAPI(Dependencies{
 		AuthService:     authSvc,
@@ -170,7 +171,7 @@ func (m *memoryUserRepo) GetByEmail(ctx context.Context, email string) (*domuser
 	return nil, domuser.ErrUserNotFound
 }
 
-func (m *memoryUserRepo) List(ctx context.Context, filter domuser.ListUsersFilter) ([]*domuser.User, error) {
+func (m *memoryUserRepo) List(ctx context.Context, filter domuser.ListUsersFilter, req page.Request) ([]*domuser.User, page.Info, error) {
 	var result []*domuser.User
 	for _, user := range m.users {
 		if filter.RoleCode != nil && user.RoleCode != *filter.RoleCode {
@@ -178,7 +179,7 @@ func (m *memoryUserRepo) List(ctx context.Context, filter domuser.ListUsersFilte
 		}
 		result = append(result, m.clone(user))
 	}
-	return result, nil
+	return result, page.Info{Total: int64(len(result)), Page: req.Page, Size: req.Size}, nil
 }
 
 func (m *memoryUserRepo) Update(ctx context.Context, u *domuser.User) (*domuser.User, error) {
@@ -218,7 +219,7 @@ func setupUserAPI(repo domuser.Repository) (*API, string) {
 	userSvc := useruc.NewService(repo, passwordSvc)
 
 	tokenSvc := security.NewJWTService("test-secret", time.Hour)
-	authSvc := authuc.NewService(repo, passwordSvc, tokenSvc)
+	authSvc := authuc.NewService(repo, passwordSvc, tokenSvc, nil)
 
 	api := NewAPI(Dependencies{
 		AuthService:  authSvc,
@@ -10,16 +10,23 @@ import (
 	chimw "github.com/go-chi/chi/v5/middleware"
 	"github.com/go-playground/validator/v10"
 
+	domaudit "example.com/my-golang-sample/app/internal/domain/audit"
+	domauth "example.com/my-golang-sample/app/internal/domain/auth"
 	domcart "example.com/my-golang-sample/app/internal/domain/cart"
 	domcategory "example.com/my-golang-sample/app/internal/domain/category"
+	"example.com/my-golang-sample/app/internal/domain/money"
 	domorder "example.com/my-golang-sample/app/internal/domain/order"
+	"example.com/my-golang-sample/app/internal/domain/page"
+	dompayment "example.com/my-golang-sample/app/internal/domain/payment"
 	domproduct "example.com/my-golang-sample/app/internal/domain/product"
 	domuser "example.com/my-golang-sample/app/internal/domain/user"
 	domrole "example.com/my-golang-sample/app/internal/domain/userrole"
+	audituc "example.com/my-golang-sample/app/internal/usecase/audit"
 	authuc "example.com/my-golang-sample/app/internal/usecase/auth"
 	cartuc "example.com/my-golang-sample/app/internal/usecase/cart"
 	categoryuc "example.com/my-golang-sample/app/internal/usecase/category"
 	orderuc "example.com/my-golang-sample/app/internal/usecase/order"
+	paymentuc "example.com/my-golang-sample/app/internal/usecase/payment"
 	productuc "example.com/my-golang-sample/app/internal/usecase/product"
 	useruc "example.com/my-golang-sample/app/internal/usecase/user"
 	userroleuc "example.com/my-golang-sample/app/internal/usecase/userrole"
@@ -33,8 +40,11 @@ type API struct {
 	productSvc  *productuc.Service
 	cartSvc     *cartuc.Service
 	orderSvc    *orderuc.Service
+	paymentSvc  *paymentuc.Service
+	auditSvc    *audituc.Service
 	validator   *validator.Validate
 	tokenSvc    authuc.TokenService
+	currency    string
 }
 
 type Dependencies struct {
@@ -45,11 +55,20 @@ type Dependencies struct {
 	ProductService  *productuc.Service
 	CartService     *cartuc.Service
 	OrderService    *orderuc.Service
-	TokenService    authuc.TokenService
+	PaymentService  *paymentuc.Service
+	// AuditService records admin mutations; nil disables the audit log.
+	AuditService *audituc.Service
+	TokenService authuc.TokenService
+	// Currency of catalog prices; defaults to money.DefaultCurrency.
+	Currency string
 }
 
 func NewAPI(deps Dependencies) *API {
 	validate := validator.New()
+	currency := deps.Currency
+	if currency == "" {
+		currency = money.DefaultCurrency
+	}
 	return &API{
 		authSvc:     deps.AuthService,
 		userSvc:     deps.UserService,
@@ -58,15 +77,20 @@ func NewAPI(deps Dependencies) *API {
 		productSvc:  deps.ProductService,
 		cartSvc:     deps.CartService,
 		orderSvc:    deps.OrderService,
+		paymentSvc:  deps.PaymentService,
+		auditSvc:    deps.AuditService,
 		tokenSvc:    deps.TokenService,
 		validator:   validate,
+		currency:    currency,
 	}
 }
 
 func (a *API) Router() chi.Router {
 	r := chi.NewRouter()
 	r.Use(chimw.RequestID)
+	r.Use(auditRequestID)
 	r.Use(chimw.RealIP)
+	r.Use(redactQuery("tamaraToken"))
 	r.Use(chimw.Logger)
 	r.Use(chimw.Recoverer)
 	r.Use(chimw.AllowContentType("application/json", "text/plain"))
@@ -74,60 +98,102 @@ func (a *API) Router() chi.Router {
 	r.Get("/health", func(w http.ResponseWriter, r *http.Request) {
 		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
 	})
+	r.Get("/.well-known/jwks.json", a.handleJWKS)
 
 	r.Route("/api/v1", func(r chi.Router) {
 		r.Post("/auth/login", a.handleLogin)
+		r.Post("/auth/refresh", a.handleRefreshToken)
+		r.Post("/auth/register", a.handleRegister)
+		r.Get("/auth/verify-email", a.handleVerifyEmail)
+		r.Post("/auth/verify-email/resend", a.handleResendVerification)
+		r.Post("/auth/password/forgot", a.handleForgotPassword)
+		r.Post("/auth/password/reset", a.handleResetPassword)
+		r.Post("/auth/2fa/verify", a.handleVerifyMFA)
+		r.Post("/auth/2fa/enroll", a.handleEnrollMFAWithChallenge)
 		r.Get("/products", a.handleListProducts)
 		r.Get("/products/{id}", a.handleGetProduct)
+		r.Post("/webhooks/payments/{provider}", a.handlePaymentWebhook)
+
+		// Guest cart, keyed by the X-Cart-Token header or cart_token cookie
+		r.Get("/cart", a.handleGetCart)
+		r.Delete("/cart", a.handleClearCart)
+		r.Post("/cart/items", a.handleAddCartItem)
+		r.Patch("/cart/items/{id}", a.handleUpdateCartItem)
+		r.Delete("/cart/items/{id}", a.handleRemoveCartItem)
 
 		r.Group(func(pr chi.Router) {
 			pr.Use(a.authMiddleware)
+			pr.Post("/auth/logout", a.handleLogout)
+			pr.Get("/me", a.handleGetMe)
+			pr.Patch("/me", a.handleUpdateMe)
+			pr.Post("/me/password", a.handleChangePassword)
 			pr.Get("/me/cart", a.handleGetCart)
+			pr.Delete("/me/cart", a.handleClearCart)
 			pr.Post("/me/cart/items", a.handleAddCartItem)
+			pr.Patch("/me/cart/items/{id}", a.handleUpdateCartItem)
+			pr.Delete("/me/cart/items/{id}", a.handleRemoveCartItem)
 			pr.Post("/me/checkout", a.handleCheckout)
+			pr.Get("/me/orders", a.handleListMyOrders)
+			pr.Get("/me/orders/{id}", a.handleGetMyOrder)
+			pr.Post("/me/orders/{id}/cancel", a.handleCancelMyOrder)
+			pr.Get("/me/2fa", a.handleGetMFAStatus)
+			pr.Post("/me/2fa/enroll", a.handleStartMFAEnrollment)
+			pr.Post("/me/2fa/confirm", a.handleConfirmMFA)
+			pr.Post("/me/2fa/disable", a.handleDisableMFA)
+			pr.Post("/me/2fa/recovery-codes", a.handleRegenerateRecoveryCodes)
 		})
 
 		r.Group(func(ar chi.Router) {
 			ar.Use(a.authMiddleware)
-			ar.Use(a.requireRoles(domuser.RoleCodeAdmin, domuser.RoleCodeSuperAdmin))
 
+			// Mỗi route admin yêu cầu một permission riêng, gán cho role trong DB
 			ar.Route("/admin", func(admin chi.Router) {
+				admin.With(a.requirePermission(domrole.PermRolesRead)).Get("/permissions", a.handleListPermissions)
+
 				admin.Route("/user-roles", func(rr chi.Router) {
-					rr.Get("/", a.handleListUserRoles)
-					rr.Post("/", a.handleCreateUserRole)
-					rr.Get("/{id}", a.handleGetUserRole)
-					rr.Put("/{id}", a.handleUpdateUserRole)
-					rr.Delete("/{id}", a.handleDeleteUserRole)
+					rr.With(a.requirePermission(domrole.PermRolesRead)).Get("/", a.handleListUserRoles)
+					rr.With(a.requirePermission(domrole.PermRolesWrite)).Post("/", a.handleCreateUserRole)
+					rr.With(a.requirePermission(domrole.PermRolesRead)).Get("/{id}", a.handleGetUserRole)
+					rr.With(a.requirePermission(domrole.PermRolesWrite)).Put("/{id}", a.handleUpdateUserRole)
+					rr.With(a.requirePermission(domrole.PermRolesWrite)).Delete("/{id}", a.handleDeleteUserRole)
+					rr.With(a.requirePermission(domrole.PermRolesRead)).Get("/{id}/permissions", a.handleListRolePermissions)
+					rr.With(a.requirePermission(domrole.PermRolesWrite)).Put("/{id}/permissions/{permission}", a.handleGrantRolePermission)
+					rr.With(a.requirePermission(domrole.PermRolesWrite)).Delete("/{id}/permissions/{permission}", a.handleRevokeRolePermission)
 				})
 
 				admin.Route("/users", func(rr chi.Router) {
-					rr.Get("/", a.handleListUsers)
-					rr.Post("/", a.handleCreateUser)
-					rr.Get("/{id}", a.handleGetUser)
-					rr.Put("/{id}", a.handleUpdateUser)
-					rr.Delete("/{id}", a.handleDeleteUser)
+					rr.With(a.requirePermission(domrole.PermUsersRead)).Get("/", a.handleListUsers)
+					rr.With(a.requirePermission(domrole.PermUsersWrite)).Post("/", a.handleCreateUser)
+					rr.With(a.requirePermission(domrole.PermUsersRead)).Get("/{id}", a.handleGetUser)
+					rr.With(a.requirePermission(domrole.PermUsersWrite)).Put("/{id}", a.handleUpdateUser)
+					rr.With(a.requirePermission(domrole.PermUsersWrite)).Delete("/{id}", a.handleDeleteUser)
+					rr.With(a.requirePermission(domrole.PermUsersWrite)).Post("/{id}/unlock", a.handleUnlockUser)
 				})
 
 				admin.Route("/categories", func(rr chi.Router) {
-					rr.Get("/", a.handleListCategories)
-					rr.Post("/", a.handleCreateCategory)
-					rr.Get("/{id}", a.handleGetCategory)
-					rr.Put("/{id}", a.handleUpdateCategory)
-					rr.Delete("/{id}", a.handleDeleteCategory)
+					rr.With(a.requirePermission(domrole.PermCategoriesRead)).Get("/", a.handleListCategories)
+					rr.With(a.requirePermission(domrole.PermCategoriesWrite)).Post("/", a.handleCreateCategory)
+					rr.With(a.requirePermission(domrole.PermCategoriesRead)).Get("/{id}", a.handleGetCategory)
+					rr.With(a.requirePermission(domrole.PermCategoriesWrite)).Put("/{id}", a.handleUpdateCategory)
+					rr.With(a.requirePermission(domrole.PermCategoriesWrite)).Delete("/{id}", a.handleDeleteCategory)
 				})
 
 				admin.Route("/products", func(rr chi.Router) {
-					rr.Get("/", a.handleListProductsAdmin)
-					rr.Post("/", a.handleCreateProduct)
-					rr.Put("/{id}", a.handleUpdateProduct)
-					rr.Delete("/{id}", a.handleDeleteProduct)
+					rr.With(a.requirePermission(domrole.PermProductsRead)).Get("/", a.handleListProductsAdmin)
+					rr.With(a.requirePermission(domrole.PermProductsWrite)).Post("/", a.handleCreateProduct)
+					rr.With(a.requirePermission(domrole.PermProductsWrite)).Put("/{id}", a.handleUpdateProduct)
+					rr.With(a.requirePermission(domrole.PermProductsWrite)).Delete("/{id}", a.handleDeleteProduct)
+					rr.With(a.requirePermission(domrole.PermProductsWrite)).Put("/{id}/prices/{currency}", a.handleSetProductPrice)
+					rr.With(a.requirePermission(domrole.PermProductsWrite)).Delete("/{id}/prices/{currency}", a.handleDeleteProductPrice)
 				})
 
 				admin.Route("/orders", func(rr chi.Router) {
-					rr.Get("/", a.handleListOrders)
-					rr.Get("/{id}", a.handleGetOrder)
-					rr.Patch("/{id}", a.handleUpdateOrderStatus)
+					rr.With(a.requirePermission(domrole.PermOrdersRead)).Get("/", a.handleListOrders)
+					rr.With(a.requirePermission(domrole.PermOrdersRead)).Get("/{id}", a.handleGetOrder)
+					rr.With(a.requirePermission(domrole.PermOrdersUpdateStatus)).Patch("/{id}", a.handleUpdateOrderStatus)
 				})
+
+				admin.With(a.requirePermission(domrole.PermAuditRead)).Get("/audit-logs", a.handleListAuditLogs)
 			})
 		})
 	})
@@ -163,12 +229,58 @@ func parseIDParam(r *http.Request, key string) (int64, error) {
 	return strconv.ParseInt(idStr, 10, 64)
 }
 
+// parsePageRequest reads the shared list parameters: page and size, or
+// cursor, and sort ("name" ascending, "-name" descending).
+func parsePageRequest(r *http.Request) (page.Request, error) {
+	q := r.URL.Query()
+	req := page.Request{Cursor: q.Get("cursor")}
+	req.Sort, req.Desc = page.ParseSort(q.Get("sort"))
+	var err error
+	if req.Page, err = positiveIntParam(q.Get("page")); err != nil {
+		return req, err
+	}
+	if req.Size, err = positiveIntParam(q.Get("size")); err != nil {
+		return req, err
+	}
+	return req, nil
+}
+
+// positiveIntParam parses an optional page or size; empty means 0 (default).
+func positiveIntParam(v string) (int, error) {
+	if v == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 1 {
+		return 0, page.ErrInvalidPage
+	}
+	return n, nil
+}
+
+// writePage wraps one page of a list in the {"data": ...} envelope.
+func writePage(w http.ResponseWriter, data any, info page.Info) {
+	resp := map[string]any{
+		"data":        data,
+		"total":       info.Total,
+		"size":        info.Size,
+		"next_cursor": nil,
+	}
+	if info.Page > 0 {
+		resp["page"] = info.Page
+	}
+	if info.NextCursor != "" {
+		resp["next_cursor"] = info.NextCursor
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
 func mapUser(u *domuser.User) map[string]any {
 	return map[string]any{
-		"id":        u.ID,
-		"name":      u.Name,
-		"email":     u.Email,
-		"role_code": u.RoleCode,
+		"id":             u.ID,
+		"name":           u.Name,
+		"email":          u.Email,
+		"role_code":      u.RoleCode,
+		"email_verified": u.IsEmailVerified(),
 	}
 }
 
@@ -179,6 +291,7 @@ func mapRole(role *domrole.UserRole) map[string]any {
 		"name":        role.Name,
 		"description": role.Description,
 		"is_system":   role.IsSystem,
+		"rank":        role.Rank,
 	}
 }
 
@@ -192,18 +305,47 @@ func mapCategory(c *domcategory.Category) map[string]any {
 	}
 }
 
+// requestCurrency validates a currency selector; empty means the store currency.
+func (a *API) requestCurrency(s string) (string, error) {
+	if s == "" {
+		return a.currency, nil
+	}
+	return money.ParseCurrency(s)
+}
+
 func mapProduct(p *domproduct.Product) map[string]any {
+	prices := make([]map[string]any, 0, len(p.Prices)+1)
+	for _, price := range append([]money.Money{p.Price}, p.Prices...) {
+		prices = append(prices, map[string]any{
+			"currency": price.Currency,
+			"price":    price,
+		})
+	}
 	return map[string]any{
 		"id":          p.ID,
 		"name":        p.Name,
 		"description": p.Description,
 		"price":       p.Price,
+		"currency":    p.Price.Currency,
+		"prices":      prices,
 		"stock":       p.Stock,
 		"category_id": p.CategoryID,
 		"is_active":   p.IsActive,
 	}
 }
 
+// mapProductIn reports price and currency in the selected currency.
+func mapProductIn(p *domproduct.Product, currency string) (map[string]any, error) {
+	price, err := p.PriceIn(currency)
+	if err != nil {
+		return nil, err
+	}
+	resp := mapProduct(p)
+	resp["price"] = price
+	resp["currency"] = price.Currency
+	return resp, nil
+}
+
 func mapCart(cart *domcart.Cart) map[string]any {
 	items := make([]map[string]any, 0, len(cart.Items))
 	for _, item := range cart.Items {
@@ -212,11 +354,24 @@ func mapCart(cart *domcart.Cart) map[string]any {
 			"quantity":   item.Quantity,
 			"name":       item.ProductName,
 			"price":      item.ProductPrice,
+			"subtotal":   item.Subtotal,
+		})
+	}
+	warnings := make([]map[string]any, 0, len(cart.Warnings))
+	for _, w := range cart.Warnings {
+		warnings = append(warnings, map[string]any{
+			"product_id": w.ProductID,
+			"code":       w.Code,
+			"message":    w.Message,
 		})
 	}
 	return map[string]any{
-		"user_id": cart.UserID,
-		"items":   items,
+		"user_id":    cart.UserID,
+		"items":      items,
+		"total":      cart.Total,
+		"currency":   cart.Total.Currency,
+		"item_count": cart.ItemCount,
+		"warnings":   warnings,
 	}
 }
 
@@ -237,15 +392,49 @@ func mapOrder(o *domorder.Order) map[string]any {
 		"status":         o.Status,
 		"payment_method": o.PaymentMethod,
 		"total_amount":   o.TotalAmount,
+		"currency":       o.TotalAmount.Currency,
 		"created_at":     o.CreatedAt,
 		"items":          items,
 	}
 }
 
+func mapOrderStatusHistory(history []domorder.StatusHistory) []map[string]any {
+	resp := make([]map[string]any, 0, len(history))
+	for _, h := range history {
+		entry := map[string]any{
+			"from":       h.From,
+			"to":         h.To,
+			"changed_by": h.ChangedBy,
+			"note":       h.Note,
+			"created_at": h.CreatedAt,
+		}
+		if h.From == "" {
+			entry["from"] = nil
+		}
+		if h.ChangedBy == 0 {
+			entry["changed_by"] = nil
+		}
+		resp = append(resp, entry)
+	}
+	return resp
+}
+
+func mapPayment(p *dompayment.Payment) map[string]any {
+	return map[string]any{
+		"provider":     p.Provider,
+		"reference":    p.Reference,
+		"redirect_url": p.RedirectURL,
+		"amount":       p.Amount,
+		"currency":     p.Amount.Currency,
+		"status":       p.Status,
+	}
+}
+
 func handleDomainError(w http.ResponseWriter, err error) {
 	switch {
 	case errors.Is(err, domuser.ErrCannotAssignRole),
 		errors.Is(err, domuser.ErrInvalidRoleCode),
+		errors.Is(err, domrole.ErrUnknownPermission),
 		errors.Is(err, domuser.ErrInvalidCredential),
 		errors.Is(err, domuser.ErrAdminCannotCreateAdmin),
 		errors.Is(err, domuser.ErrAdminCannotPromoteAdmin):
@@ -261,19 +450,62 @@ func handleDomainError(w http.ResponseWriter, err error) {
 		errors.Is(err, domrole.ErrRoleNotFound),
 		errors.Is(err, domcategory.ErrCategoryNotFound),
 		errors.Is(err, domproduct.ErrProductNotFound),
-		errors.Is(err, domorder.ErrOrderNotFound):
+		errors.Is(err, domorder.ErrOrderNotFound),
+		errors.Is(err, domcart.ErrCartItemNotFound),
+		errors.Is(err, domproduct.ErrPriceNotFound),
+		errors.Is(err, dompayment.ErrPaymentNotFound):
 		respondError(w, http.StatusNotFound, err)
-	case errors.Is(err, domuser.ErrUnauthorized):
+	case errors.Is(err, domuser.ErrUnauthorized),
+		errors.Is(err, domauth.ErrInvalidRefreshToken),
+		errors.Is(err, domauth.ErrInvalidMFACode):
 		respondError(w, http.StatusUnauthorized, err)
+	case errors.Is(err, domuser.ErrEmailNotVerified),
+		errors.Is(err, authuc.ErrRegistrationDisabled),
+		errors.Is(err, authuc.ErrPasswordResetDisabled),
+		errors.Is(err, userroleuc.ErrPermissionsDisabled),
+		errors.Is(err, domrole.ErrCannotGrantPermission),
+		errors.Is(err, domrole.ErrRankTooHigh),
+		errors.Is(err, useruc.ErrPasswordChangeDisabled),
+		errors.Is(err, useruc.ErrEmailChangeDisabled),
+		errors.Is(err, authuc.ErrMFARequired):
+		respondError(w, http.StatusForbidden, err)
+	case errors.Is(err, domauth.ErrMFAAlreadyEnabled),
+		errors.Is(err, domauth.ErrMFANotEnrolled):
+		respondError(w, http.StatusConflict, err)
+	case errors.Is(err, authuc.ErrMFAUnavailable):
+		respondError(w, http.StatusNotFound, err)
+	case errors.Is(err, domauth.ErrInvalidOneTimeToken),
+		errors.Is(err, domaudit.ErrInvalidTimeRange),
+		errors.Is(err, domorder.ErrInvalidDateRange),
+		errors.Is(err, domorder.ErrInvalidTotalRange),
+		errors.Is(err, page.ErrInvalidPage),
+		errors.Is(err, page.ErrInvalidSort),
+		errors.Is(err, page.ErrInvalidCursor),
+		errors.Is(err, money.ErrUnsupportedCurrency):
+		respondError(w, http.StatusBadRequest, err)
+	case errors.Is(err, authuc.ErrTooManyLoginAttempts):
+		respondError(w, http.StatusTooManyRequests, err)
 	case errors.Is(err, domrole.ErrRoleImmutable),
 		errors.Is(err, domrole.ErrRoleInUse),
 		errors.Is(err, domorder.ErrEmptyOrderItems),
 		errors.Is(err, domorder.ErrInvalidPayment),
 		errors.Is(err, domorder.ErrCheckoutValidation),
 		errors.Is(err, domorder.ErrInvalidStatus),
-		errors.Is(err, domproduct.ErrOutOfStock):
+		errors.Is(err, domorder.ErrInvalidTransition),
+		errors.Is(err, domorder.ErrNotCancelable),
+		errors.Is(err, domproduct.ErrOutOfStock),
+		errors.Is(err, domproduct.ErrPriceUnavailable),
+		errors.Is(err, domproduct.ErrBasePriceRequired),
+		errors.Is(err, dompayment.ErrUnsupportedProvider),
+		errors.Is(err, dompayment.ErrInvalidTransition):
 		// Lỗi nghiệp vụ khi checkout/cart → 422
 		respondError(w, http.StatusUnprocessableEntity, err)
+	case errors.Is(err, dompayment.ErrInvalidSignature):
+		respondError(w, http.StatusUnauthorized, err)
+	case errors.Is(err, dompayment.ErrInvalidWebhook):
+		respondError(w, http.StatusBadRequest, err)
+	case errors.Is(err, dompayment.ErrGatewayRequest):
+		respondError(w, http.StatusBadGateway, err)
 	default:
 		respondError(w, http.StatusInternalServerError, err)
 	}
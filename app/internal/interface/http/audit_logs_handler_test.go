@@ -0,0 +1,116 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	domaudit "example.com/my-golang-sample/app/internal/domain/audit"
+	domuser "example.com/my-golang-sample/app/internal/domain/user"
+	"example.com/my-golang-sample/app/internal/infra/security"
+	audituc "example.com/my-golang-sample/app/internal/usecase/audit"
+	categoryuc "example.com/my-golang-sample/app/internal/usecase/category"
+)
+
+func setupAuditAPI(t *testing.T) (http.Handler, *fakeAuditLog, func(domuser.RoleCode) string) {
+	t.Helper()
+	auditLog := &fakeAuditLog{}
+	tokenSvc := security.NewJWTService("audit-secret", time.Hour)
+	api := NewAPI(Dependencies{
+		CategoryService: categoryuc.NewService(newMemoryCategoryRepo()),
+		AuditService:    audituc.NewService(auditLog),
+		TokenService:    tokenSvc,
+	})
+	tokenFor := func(role domuser.RoleCode) string {
+		token, err := tokenSvc.GenerateToken(&domuser.User{ID: 7, Name: "Root", Email: "root@example.com", RoleCode: role})
+		require.NoError(t, err)
+		return token
+	}
+	return api.Router(), auditLog, tokenFor
+}
+
+func sendAdminJSON(router http.Handler, method, path, token string, body any) *httptest.ResponseRecorder {
+	var payload []byte
+	if body != nil {
+		payload, _ = json.Marshal(body)
+	}
+	req := httptest.NewRequest(method, path, bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestAuditLog_RecordsAdminMutations(t *testing.T) {
+	router, auditLog, tokenFor := setupAuditAPI(t)
+	token := tokenFor(domuser.RoleCodeSuperAdmin)
+
+	rec := sendAdminJSON(router, http.MethodPost, "/api/v1/admin/categories", token, map[string]any{"name": "Books"})
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	id := strconv.Itoa(int(decodeBody(t, rec)["id"].(float64)))
+
+	rec = sendAdminJSON(router, http.MethodPut, "/api/v1/admin/categories/"+id, token, map[string]any{"name": "Comics"})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	rec = sendAdminJSON(router, http.MethodDelete, "/api/v1/admin/categories/"+id, token, nil)
+	require.Equal(t, http.StatusNoContent, rec.Code, rec.Body.String())
+
+	// A failed mutation leaves no entry
+	rec = sendAdminJSON(router, http.MethodDelete, "/api/v1/admin/categories/"+id, token, nil)
+	require.Equal(t, http.StatusNotFound, rec.Code)
+
+	require.Len(t, auditLog.entries, 3)
+	update := auditLog.entries[1]
+	require.Equal(t, domaudit.ActionCategoryUpdated, update.Action)
+	require.Equal(t, int64(7), *update.ActorID)
+	require.Equal(t, "category", update.TargetType)
+	require.Equal(t, id, update.TargetID)
+	require.NotEmpty(t, update.RequestID)
+	require.Equal(t, domaudit.Change{Before: "Books", After: "Comics"}, update.Changes["name"])
+	require.NotContains(t, update.Changes, "id", "unchanged fields are not stored")
+	require.Equal(t, "Comics", auditLog.entries[2].Changes["name"].Before)
+	require.Nil(t, auditLog.entries[2].Changes["name"].After)
+}
+
+func TestAuditLog_ListWithFilters(t *testing.T) {
+	router, _, tokenFor := setupAuditAPI(t)
+	token := tokenFor(domuser.RoleCodeSuperAdmin)
+	for _, name := range []string{"Books", "Games"} {
+		rec := sendAdminJSON(router, http.MethodPost, "/api/v1/admin/categories", token, map[string]any{"name": name})
+		require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	}
+	rec := sendAdminJSON(router, http.MethodPut, "/api/v1/admin/categories/1", token, map[string]any{"name": "Novels"})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	rec = sendAdminJSON(router, http.MethodGet, "/api/v1/admin/audit-logs?action=category.created", token, nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	data := decodeBody(t, rec)["data"].([]any)
+	require.Len(t, data, 2)
+	newest := data[0].(map[string]any)
+	require.Equal(t, "2", newest["target_id"], "newest first")
+	require.Equal(t, "Games", newest["changes"].(map[string]any)["name"].(map[string]any)["after"])
+
+	rec = sendAdminJSON(router, http.MethodGet, "/api/v1/admin/audit-logs?target_type=category&target_id=1", token, nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.Len(t, decodeBody(t, rec)["data"], 2)
+
+	rec = sendAdminJSON(router, http.MethodGet, "/api/v1/admin/audit-logs?from=2026-02-01T00:00:00Z&to=2026-01-01T00:00:00Z", token, nil)
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = sendAdminJSON(router, http.MethodGet, "/api/v1/admin/audit-logs?from=yesterday", token, nil)
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestAuditLog_RequiresAuditPermission(t *testing.T) {
+	router, _, tokenFor := setupAuditAPI(t)
+
+	rec := sendAdminJSON(router, http.MethodGet, "/api/v1/admin/audit-logs", tokenFor(domuser.RoleCodeCustomer), nil)
+
+	require.Equal(t, http.StatusForbidden, rec.Code)
+}
@@ -11,6 +11,7 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"example.com/my-golang-sample/app/internal/domain/page"
 	domuser "example.com/my-golang-sample/app/internal/domain/user"
 	"example.com/my-golang-sample/app/internal/infra/security"
 	authuc "example.com/my-golang-sample/app/internal/usecase/auth"
@@ -40,8 +41,8 @@ func (m *mockAuthUserRepo) GetByEmail(ctx context.Context, email string) (*domus
 	return nil, domuser.ErrUserNotFound
 }
 
-func (m *mockAuthUserRepo) List(ctx context.Context, filter domuser.ListUsersFilter) ([]*domuser.User, error) {
-	return nil, nil
+func (m *mockAuthUserRepo) List(ctx context.Context, filter domuser.ListUsersFilter, req page.Request) ([]*domuser.User, page.Info, error) {
+	return nil, page.Info{}, nil
 }
 
 func (m *mockAuthUserRepo) Update(ctx context.Context, u *domuser.User) (*domuser.User, error) {
@@ -73,7 +74,7 @@ func setupLoginRouter(user *domuser.User, passwordValid bool) http.Handler {
 	repo := &mockAuthUserRepo{user: user}
 	checker := &mockPasswordChecker{shouldSucceed: passwordValid}
 	tokenSvc := security.NewJWTService("test-secret", time.Hour)
-	authSvc := authuc.NewService(repo, checker, tokenSvc)
+	authSvc := authuc.NewService(repo, checker, tokenSvc, nil)
 
 	api := NewAPI(Dependencies{
 		AuthService:  authSvc,
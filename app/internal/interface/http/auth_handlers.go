@@ -1,7 +1,13 @@
 package http
 
 import (
+	"errors"
+	"io"
+	"log"
+	"math"
+	"net"
 	"net/http"
+	"strconv"
 
 	authuc "example.com/my-golang-sample/app/internal/usecase/auth"
 )
@@ -18,7 +24,110 @@ func (a *API) handleLogin(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	guestToken := guestCartToken(r)
 	result, err := a.authSvc.Login(r.Context(), authuc.LoginInput{
+		Email:          req.Email,
+		Password:       req.Password,
+		GuestCartToken: guestToken,
+		IP:             clientIP(r),
+	})
+	if err != nil {
+		setRetryAfter(w, err)
+		handleDomainError(w, err)
+		return
+	}
+	// With 2FA the cart is merged after the second step
+	if guestToken != "" && !result.MFARequired && !result.CartMergeFailed {
+		clearGuestCartToken(w)
+	}
+
+	writeJSON(w, http.StatusOK, mapLoginResult(result))
+}
+
+// setRetryAfter tells a throttled client when to try logging in again.
+func setRetryAfter(w http.ResponseWriter, err error) {
+	var throttled *authuc.LoginThrottledError
+	if errors.As(err, &throttled) {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(throttled.RetryAfter.Seconds()))))
+	}
+}
+
+// clientIP is the request address as set by chimw.RealIP, without the port.
+func clientIP(r *http.Request) string {
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
+
+type refreshRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+func (a *API) handleRefreshToken(w http.ResponseWriter, r *http.Request) {
+	var req refreshRequest
+	if err := a.decodeAndValidate(r, &req); err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	result, err := a.authSvc.Refresh(r.Context(), req.RefreshToken)
+	if err != nil {
+		handleDomainError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, mapLoginResult(result))
+}
+
+type logoutRequest struct {
+	RefreshToken string `json:"refresh_token"`
+	// All ends every session of the user, not only this one.
+	All bool `json:"all"`
+}
+
+func (a *API) handleLogout(w http.ResponseWriter, r *http.Request) {
+	user := getAuthUser(r.Context())
+	if user == nil {
+		respondError(w, http.StatusUnauthorized, errUnauthenticated)
+		return
+	}
+
+	// Body là tuỳ chọn
+	var req logoutRequest
+	if err := a.decodeAndValidate(r, &req); err != nil && !errors.Is(err, io.EOF) {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	err := a.authSvc.Logout(r.Context(), authuc.LogoutInput{
+		UserID:         user.UserID,
+		TokenID:        user.TokenID,
+		TokenExpiresAt: user.TokenExpiresAt,
+		RefreshToken:   req.RefreshToken,
+		AllSessions:    req.All,
+	})
+	if err != nil {
+		handleDomainError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type registerRequest struct {
+	Name     string `json:"name" validate:"required,max=255"`
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required,min=8,max=72"`
+}
+
+func (a *API) handleRegister(w http.ResponseWriter, r *http.Request) {
+	var req registerRequest
+	if err := a.decodeAndValidate(r, &req); err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	result, err := a.authSvc.Register(r.Context(), authuc.RegisterInput{
+		Name:     req.Name,
 		Email:    req.Email,
 		Password: req.Password,
 	})
@@ -26,10 +135,126 @@ func (a *API) handleLogin(w http.ResponseWriter, r *http.Request) {
 		handleDomainError(w, err)
 		return
 	}
+	writeJSON(w, http.StatusCreated, map[string]any{
+		"user":              mapUser(result.User),
+		"verification_sent": result.VerificationSent,
+	})
+}
+
+// handleVerifyEmail is the target of the link in the verification email.
+func (a *API) handleVerifyEmail(w http.ResponseWriter, r *http.Request) {
+	user, err := a.authSvc.VerifyEmail(r.Context(), r.URL.Query().Get("token"))
+	if err != nil {
+		handleDomainError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"user": mapUser(user)})
+}
+
+type resendVerificationRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+func (a *API) handleResendVerification(w http.ResponseWriter, r *http.Request) {
+	var req resendVerificationRequest
+	if err := a.decodeAndValidate(r, &req); err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+	err := a.authSvc.ResendVerification(r.Context(), req.Email)
+	if errors.Is(err, authuc.ErrRegistrationDisabled) {
+		handleDomainError(w, err)
+		return
+	}
+	if err != nil {
+		// Lỗi gửi mail chỉ xảy ra với email đã đăng ký, trả lỗi về sẽ lộ email đó
+		log.Printf("auth: resend verification: %v", err)
+	}
+	// Same answer whether or not the email exists
+	w.WriteHeader(http.StatusAccepted)
+}
 
-	writeJSON(w, http.StatusOK, map[string]any{
+type forgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+func (a *API) handleForgotPassword(w http.ResponseWriter, r *http.Request) {
+	var req forgotPasswordRequest
+	if err := a.decodeAndValidate(r, &req); err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+	err := a.authSvc.ForgotPassword(r.Context(), req.Email)
+	if errors.Is(err, authuc.ErrPasswordResetDisabled) {
+		handleDomainError(w, err)
+		return
+	}
+	if err != nil {
+		// Lỗi gửi mail chỉ xảy ra với email đã đăng ký, trả 500 sẽ lộ email nào tồn tại
+		log.Printf("auth: forgot password: %v", err)
+	}
+	// Same answer whether or not the email exists
+	w.WriteHeader(http.StatusAccepted)
+}
+
+type resetPasswordRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required,min=8,max=72"`
+}
+
+func (a *API) handleResetPassword(w http.ResponseWriter, r *http.Request) {
+	var req resetPasswordRequest
+	if err := a.decodeAndValidate(r, &req); err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+	err := a.authSvc.ResetPassword(r.Context(), authuc.ResetPasswordInput{
+		Token:       req.Token,
+		NewPassword: req.Password,
+	})
+	if err != nil {
+		handleDomainError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleJWKS publishes the public keys that verify our access tokens. It is
+// 404 when tokens are signed with a shared secret.
+func (a *API) handleJWKS(w http.ResponseWriter, r *http.Request) {
+	publisher, ok := a.tokenSvc.(authuc.KeyPublisher)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	keys := publisher.PublicKeys()
+	if len(keys) == 0 {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	writeJSON(w, http.StatusOK, map[string]any{"keys": keys})
+}
+
+func mapLoginResult(result *authuc.LoginResult) map[string]any {
+	if result.MFARequired {
+		// Chưa qua bước 2FA: không trả token, cũng không trả thông tin user
+		return map[string]any{
+			"mfa_required":            true,
+			"mfa_enrollment_required": result.MFAEnrollmentRequired,
+			"challenge_token":         result.ChallengeToken,
+		}
+	}
+	resp := map[string]any{
 		"token": result.Token,
 		"user":  mapUser(result.User),
-	})
+	}
+	if result.RefreshToken != "" {
+		resp["refresh_token"] = result.RefreshToken
+	}
+	if len(result.RecoveryCodes) > 0 {
+		resp["recovery_codes"] = result.RecoveryCodes
+	}
+	return resp
 }
 
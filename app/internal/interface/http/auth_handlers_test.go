@@ -11,6 +11,7 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"example.com/my-golang-sample/app/internal/domain/page"
 	domuser "example.com/my-golang-sample/app/internal/domain/user"
 	"example.com/my-golang-sample/app/internal/infra/security"
 	authuc "example.com/my-golang-sample/app/internal/usecase/auth"
@@ -25,7 +26,10 @@ func (f *fakeAuthUserRepo) Create(ctx context.Context, u *domuser.User) (*domuse
 }
 
 func (f *fakeAuthUserRepo) GetByID(ctx context.Context, id int64) (*domuser.User, error) {
-	return nil, nil
+	if f.user != nil && f.user.ID == id {
+		return f.user, nil
+	}
+	return nil, domuser.ErrUserNotFound
 }
 
 func (f *fakeAuthUserRepo) GetByEmail(ctx context.Context, email string) (*domuser.User, error) {
@@ -35,8 +39,8 @@ func (f *fakeAuthUserRepo) GetByEmail(ctx context.Context, email string) (*domus
 	return nil, domuser.ErrUserNotFound
 }
 
-func (f *fakeAuthUserRepo) List(ctx context.Context, filter domuser.ListUsersFilter) ([]*domuser.User, error) {
-	return nil, nil
+func (f *fakeAuthUserRepo) List(ctx context.Context, filter domuser.ListUsersFilter, req page.Request) ([]*domuser.User, page.Info, error) {
+	return nil, page.Info{}, nil
 }
 
 func (f *fakeAuthUserRepo) Update(ctx context.Context, u *domuser.User) (*domuser.User, error) {
@@ -66,7 +70,7 @@ func setupAuthAPI(user *domuser.User, passwordValid bool) *API {
 	repo := &fakeAuthUserRepo{user: user}
 	checker := &fakePasswordChecker{shouldSucceed: passwordValid}
 	tokenSvc := security.NewJWTService("test-secret", time.Hour)
-	authSvc := authuc.NewService(repo, checker, tokenSvc)
+	authSvc := authuc.NewService(repo, checker, tokenSvc, nil)
 
 	return NewAPI(Dependencies{
 		AuthService:  authSvc,
@@ -0,0 +1,208 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	domauth "example.com/my-golang-sample/app/internal/domain/auth"
+	domuser "example.com/my-golang-sample/app/internal/domain/user"
+	"example.com/my-golang-sample/app/internal/infra/security"
+	authuc "example.com/my-golang-sample/app/internal/usecase/auth"
+)
+
+type fakeRefreshTokenRepo struct {
+	tokens []*domauth.RefreshToken
+}
+
+func (f *fakeRefreshTokenRepo) Create(ctx context.Context, t *domauth.RefreshToken) error {
+	t.ID = int64(len(f.tokens) + 1)
+	f.tokens = append(f.tokens, t)
+	return nil
+}
+
+func (f *fakeRefreshTokenRepo) GetByHash(ctx context.Context, tokenHash string) (*domauth.RefreshToken, error) {
+	for _, t := range f.tokens {
+		if t.TokenHash == tokenHash {
+			cloned := *t
+			return &cloned, nil
+		}
+	}
+	return nil, domauth.ErrRefreshTokenNotFound
+}
+
+func (f *fakeRefreshTokenRepo) Rotate(ctx context.Context, oldID int64, next *domauth.RefreshToken) error {
+	old := f.tokens[oldID-1]
+	if old.IsRevoked() {
+		return domauth.ErrInvalidRefreshToken
+	}
+	now := time.Now()
+	old.RevokedAt = &now
+	return f.Create(ctx, next)
+}
+
+func (f *fakeRefreshTokenRepo) RevokeFamily(ctx context.Context, familyID string) error {
+	now := time.Now()
+	for _, t := range f.tokens {
+		if t.FamilyID == familyID && !t.IsRevoked() {
+			t.RevokedAt = &now
+		}
+	}
+	return nil
+}
+
+func (f *fakeRefreshTokenRepo) RevokeAllForUser(ctx context.Context, userID int64) error {
+	now := time.Now()
+	for _, t := range f.tokens {
+		if t.UserID == userID && !t.IsRevoked() {
+			t.RevokedAt = &now
+		}
+	}
+	return nil
+}
+
+type fakeDenylist struct {
+	revoked map[string]time.Time
+	cutoffs map[int64]time.Time
+}
+
+func (f *fakeDenylist) Revoke(ctx context.Context, jti string, expiresAt time.Time) error {
+	f.revoked[jti] = expiresAt
+	return nil
+}
+
+func (f *fakeDenylist) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	_, ok := f.revoked[jti]
+	return ok, nil
+}
+
+func (f *fakeDenylist) RevokeUser(ctx context.Context, userID int64, at time.Time) error {
+	if f.cutoffs == nil {
+		f.cutoffs = make(map[int64]time.Time)
+	}
+	f.cutoffs[userID] = at
+	return nil
+}
+
+func (f *fakeDenylist) TokensValidAfter(ctx context.Context, userID int64) (time.Time, error) {
+	return f.cutoffs[userID], nil
+}
+
+func setupSessionAPI(t *testing.T) (http.Handler, *fakeDenylist) {
+	t.Helper()
+	user := &domuser.User{
+		ID:           100,
+		Name:         "Test User",
+		Email:        "user@example.com",
+		PasswordHash: "hashed",
+		RoleCode:     domuser.RoleCodeCustomer,
+	}
+	denylist := &fakeDenylist{revoked: make(map[string]time.Time)}
+	tokenSvc := security.NewJWTService("test-secret", time.Hour)
+	authSvc := authuc.NewService(&fakeAuthUserRepo{user: user}, &fakePasswordChecker{shouldSucceed: true}, tokenSvc, nil,
+		authuc.WithSessions(&fakeRefreshTokenRepo{}, denylist, time.Hour))
+
+	api := NewAPI(Dependencies{
+		AuthService:  authSvc,
+		TokenService: tokenSvc,
+	})
+	return api.Router(), denylist
+}
+
+func postSessionJSON(router http.Handler, path, token string, body any) *httptest.ResponseRecorder {
+	var payload []byte
+	if body != nil {
+		payload, _ = json.Marshal(body)
+	}
+	req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func loginForSession(t *testing.T, router http.Handler) (string, string) {
+	t.Helper()
+	rec := postSessionJSON(router, "/api/v1/auth/login", "", map[string]string{
+		"email":    "user@example.com",
+		"password": "password123",
+	})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var resp map[string]any
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	token, _ := resp["token"].(string)
+	refresh, _ := resp["refresh_token"].(string)
+	require.NotEmpty(t, token)
+	require.NotEmpty(t, refresh)
+	return token, refresh
+}
+
+func TestRefresh_ReturnsNewTokenPair(t *testing.T) {
+	router, _ := setupSessionAPI(t)
+	_, refresh := loginForSession(t, router)
+
+	rec := postSessionJSON(router, "/api/v1/auth/refresh", "", map[string]string{"refresh_token": refresh})
+
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var resp map[string]any
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	require.NotEmpty(t, resp["token"])
+	require.NotEqual(t, refresh, resp["refresh_token"])
+
+	// The old refresh token was rotated out
+	rec = postSessionJSON(router, "/api/v1/auth/refresh", "", map[string]string{"refresh_token": refresh})
+	require.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestRefresh_MissingTokenReturns400(t *testing.T) {
+	router, _ := setupSessionAPI(t)
+
+	rec := postSessionJSON(router, "/api/v1/auth/refresh", "", map[string]string{})
+
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestLogout_RevokesAccessAndRefreshToken(t *testing.T) {
+	router, denylist := setupSessionAPI(t)
+	token, refresh := loginForSession(t, router)
+
+	rec := postSessionJSON(router, "/api/v1/auth/logout", token, map[string]string{"refresh_token": refresh})
+	require.Equal(t, http.StatusNoContent, rec.Code, rec.Body.String())
+	require.Len(t, denylist.revoked, 1)
+
+	// The access token is rejected right away, not at expiry
+	rec = postSessionJSON(router, "/api/v1/auth/logout", token, nil)
+	require.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	rec = postSessionJSON(router, "/api/v1/auth/refresh", "", map[string]string{"refresh_token": refresh})
+	require.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestLogout_WithoutBodyOnlyRevokesAccessToken(t *testing.T) {
+	router, _ := setupSessionAPI(t)
+	token, refresh := loginForSession(t, router)
+
+	rec := postSessionJSON(router, "/api/v1/auth/logout", token, nil)
+	require.Equal(t, http.StatusNoContent, rec.Code, rec.Body.String())
+
+	rec = postSessionJSON(router, "/api/v1/auth/refresh", "", map[string]string{"refresh_token": refresh})
+	require.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestLogout_RequiresAuth(t *testing.T) {
+	router, _ := setupSessionAPI(t)
+
+	rec := postSessionJSON(router, "/api/v1/auth/logout", "", nil)
+
+	require.Equal(t, http.StatusUnauthorized, rec.Code)
+}
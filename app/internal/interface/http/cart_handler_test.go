@@ -12,6 +12,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	domcart "example.com/my-golang-sample/app/internal/domain/cart"
+	"example.com/my-golang-sample/app/internal/domain/money"
 	domorder "example.com/my-golang-sample/app/internal/domain/order"
 	domproduct "example.com/my-golang-sample/app/internal/domain/product"
 	domuser "example.com/my-golang-sample/app/internal/domain/user"
@@ -22,25 +23,41 @@ import (
 // --- Mock Repositories for Cart Tests ---
 
 type mockCartRepository struct {
-	items map[int64]map[int64]int64 // userID -> productID -> quantity
+	items map[domcart.Owner]map[int64]int64 // owner -> productID -> quantity
 }
 
 func newMockCartRepository() *mockCartRepository {
 	return &mockCartRepository{
-		items: make(map[int64]map[int64]int64),
+		items: make(map[domcart.Owner]map[int64]int64),
 	}
 }
 
-func (m *mockCartRepository) AddOrUpdateItem(ctx context.Context, userID, productID, quantity int64) error {
-	if m.items[userID] == nil {
-		m.items[userID] = make(map[int64]int64)
+func (m *mockCartRepository) AddOrUpdateItem(ctx context.Context, owner domcart.Owner, productID, quantity int64) error {
+	if m.items[owner] == nil {
+		m.items[owner] = make(map[int64]int64)
 	}
-	m.items[userID][productID] += quantity
+	m.items[owner][productID] += quantity
 	return nil
 }
 
-func (m *mockCartRepository) ListItems(ctx context.Context, userID int64) ([]domcart.Item, error) {
-	userItems := m.items[userID]
+func (m *mockCartRepository) SetItemQuantity(ctx context.Context, owner domcart.Owner, productID, quantity int64) error {
+	if _, ok := m.items[owner][productID]; !ok {
+		return domcart.ErrCartItemNotFound
+	}
+	m.items[owner][productID] = quantity
+	return nil
+}
+
+func (m *mockCartRepository) RemoveItem(ctx context.Context, owner domcart.Owner, productID int64) error {
+	if _, ok := m.items[owner][productID]; !ok {
+		return domcart.ErrCartItemNotFound
+	}
+	delete(m.items[owner], productID)
+	return nil
+}
+
+func (m *mockCartRepository) ListItems(ctx context.Context, owner domcart.Owner) ([]domcart.Item, error) {
+	userItems := m.items[owner]
 	if userItems == nil {
 		return []domcart.Item{}, nil
 	}
@@ -54,8 +71,22 @@ func (m *mockCartRepository) ListItems(ctx context.Context, userID int64) ([]dom
 	return items, nil
 }
 
-func (m *mockCartRepository) Clear(ctx context.Context, userID int64) error {
-	delete(m.items, userID)
+func (m *mockCartRepository) Clear(ctx context.Context, owner domcart.Owner) error {
+	delete(m.items, owner)
+	return nil
+}
+
+func (m *mockCartRepository) MergeItems(ctx context.Context, from, to domcart.Owner, items []domcart.Item) error {
+	if len(m.items[from]) == 0 {
+		return nil
+	}
+	delete(m.items, from)
+	if m.items[to] == nil {
+		m.items[to] = make(map[int64]int64)
+	}
+	for _, item := range items {
+		m.items[to][item.ProductID] = item.Quantity
+	}
 	return nil
 }
 
@@ -67,9 +98,9 @@ type mockProductRepositoryForCart struct {
 func newMockProductRepositoryForCart() *mockProductRepositoryForCart {
 	return &mockProductRepositoryForCart{
 		products: map[int64]*domproduct.Product{
-			1: {ID: 1, Name: "Product 1", Price: 10.0, Stock: 100, CategoryID: 1, IsActive: true},
-			2: {ID: 2, Name: "Product 2", Price: 20.0, Stock: 5, CategoryID: 1, IsActive: true},
-			3: {ID: 3, Name: "Inactive Product", Price: 30.0, Stock: 50, CategoryID: 1, IsActive: false},
+			1: {ID: 1, Name: "Product 1", Price: money.MustParse("10.0", "SAR"), Stock: 100, CategoryID: 1, IsActive: true},
+			2: {ID: 2, Name: "Product 2", Price: money.MustParse("20.0", "SAR"), Stock: 5, CategoryID: 1, IsActive: true},
+			3: {ID: 3, Name: "Inactive Product", Price: money.MustParse("30.0", "SAR"), Stock: 50, CategoryID: 1, IsActive: false},
 		},
 	}
 }
@@ -107,7 +138,7 @@ func newMockOrderRepositoryForCart() *mockOrderRepositoryForCart {
 	}
 }
 
-func (m *mockOrderRepositoryForCart) CreateFromCart(ctx context.Context, userID int64, items []domcart.Item, payment domorder.PaymentMethod) (*domorder.Order, error) {
+func (m *mockOrderRepositoryForCart) CreateFromCart(ctx context.Context, userID int64, items []domcart.Item, payment domorder.PaymentMethod, currency string) (*domorder.Order, error) {
 	if m.createErr != nil {
 		return nil, m.createErr
 	}
@@ -115,7 +146,7 @@ func (m *mockOrderRepositoryForCart) CreateFromCart(ctx context.Context, userID
 		return nil, domorder.ErrEmptyOrderItems
 	}
 
-	var totalAmount float64
+	var totalAmount money.Money
 	orderItems := make([]domorder.OrderItem, 0, len(items))
 	productRepo := newMockProductRepositoryForCart()
 
@@ -127,7 +158,8 @@ func (m *mockOrderRepositoryForCart) CreateFromCart(ctx context.Context, userID
 		if product.Stock < item.Quantity {
 			return nil, domorder.ErrCheckoutValidation
 		}
-		totalAmount += product.Price * float64(item.Quantity)
+		subtotal, _ := product.Price.Mul(item.Quantity)
+		totalAmount, _ = totalAmount.Add(subtotal)
 		orderItems = append(orderItems, domorder.OrderItem{
 			ID:        int64(len(orderItems) + 1),
 			OrderID:   1,
@@ -152,6 +184,17 @@ func (m *mockOrderRepositoryForCart) CreateFromCart(ctx context.Context, userID
 	return order, nil
 }
 
+func (m *mockOrderRepositoryForCart) UpdateStatus(ctx context.Context, change domorder.StatusChange) (*domorder.Order, error) {
+	for _, order := range m.createdOrders {
+		if order.ID == change.OrderID {
+			order.Status = change.To
+			cloned := *order
+			return &cloned, nil
+		}
+	}
+	return nil, domorder.ErrOrderNotFound
+}
+
 // --- Helper Functions ---
 
 func setupCartAPIWithCustomer() (*API, string) {
@@ -159,7 +202,7 @@ func setupCartAPIWithCustomer() (*API, string) {
 	productRepo := newMockProductRepositoryForCart()
 	orderRepo := newMockOrderRepositoryForCart()
 
-	cartSvc := cartuc.NewService(cartRepo, productRepo, orderRepo)
+	cartSvc := cartuc.NewService(cartRepo, productRepo, orderRepo, nil)
 	tokenSvc := security.NewJWTService("test-secret", time.Hour)
 
 	api := NewAPI(Dependencies{
@@ -3,7 +3,9 @@ package http
 import (
 	"net/http"
 
+	domcart "example.com/my-golang-sample/app/internal/domain/cart"
 	domorder "example.com/my-golang-sample/app/internal/domain/order"
+	cartuc "example.com/my-golang-sample/app/internal/usecase/cart"
 )
 
 type addCartItemRequest struct {
@@ -11,39 +13,88 @@ type addCartItemRequest struct {
 	Quantity  int64 `json:"quantity" validate:"required,gt=0"`
 }
 
+// Quantity is a pointer so that an explicit 0 (remove the item) passes "required".
+type updateCartItemRequest struct {
+	Quantity *int64 `json:"quantity" validate:"required,gte=0"`
+}
+
 type checkoutRequest struct {
 	PaymentMethod string `json:"payment_method" validate:"required,oneof=TAMARA COD"`
+	// Currency defaults to the store currency.
+	Currency string `json:"currency"`
 }
 
 func (a *API) handleAddCartItem(w http.ResponseWriter, r *http.Request) {
-	user := getAuthUser(r.Context())
-	if user == nil {
-		respondError(w, http.StatusUnauthorized, errUnauthenticated)
-		return
-	}
-
 	var req addCartItemRequest
 	if err := a.decodeAndValidate(r, &req); err != nil {
 		respondError(w, http.StatusBadRequest, err)
 		return
 	}
 
-	if err := a.cartSvc.AddToCart(r.Context(), user.UserID, req.ProductID, req.Quantity); err != nil {
+	resp := map[string]string{"status": "added"}
+	owner := cartOwner(r)
+	if owner.IsGuest() && owner.GuestToken == "" {
+		// Lần đầu guest thêm hàng → cấp cart token mới
+		token, err := cartuc.NewGuestToken()
+		if err != nil {
+			respondError(w, http.StatusInternalServerError, err)
+			return
+		}
+		owner = domcart.GuestOwner(token)
+		resp["cart_token"] = token
+	}
+
+	if err := a.cartSvc.AddToCart(r.Context(), owner, req.ProductID, req.Quantity); err != nil {
 		handleDomainError(w, err)
 		return
 	}
 
-	writeJSON(w, http.StatusCreated, map[string]string{"status": "added"})
+	if owner.IsGuest() {
+		setGuestCartToken(w, r, owner.GuestToken)
+	}
+	writeJSON(w, http.StatusCreated, resp)
 }
 
 func (a *API) handleGetCart(w http.ResponseWriter, r *http.Request) {
-	user := getAuthUser(r.Context())
-	if user == nil {
-		respondError(w, http.StatusUnauthorized, errUnauthenticated)
+	currency, err := a.requestCurrency(r.URL.Query().Get("currency"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+	cart, err := a.cartSvc.GetCart(r.Context(), cartOwner(r), currency)
+	if err != nil {
+		handleDomainError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, mapCart(cart))
+}
+
+func (a *API) handleUpdateCartItem(w http.ResponseWriter, r *http.Request) {
+	productID, err := parseIDParam(r, "id")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	currency, err := a.requestCurrency(r.URL.Query().Get("currency"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	var req updateCartItemRequest
+	if err := a.decodeAndValidate(r, &req); err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	owner := cartOwner(r)
+	if err := a.cartSvc.UpdateItemQuantity(r.Context(), owner, productID, *req.Quantity); err != nil {
+		handleDomainError(w, err)
 		return
 	}
 
-	cart, err := a.cartSvc.GetCart(r.Context(), user.UserID)
+	cart, err := a.cartSvc.GetCart(r.Context(), owner, currency)
 	if err != nil {
 		handleDomainError(w, err)
 		return
@@ -51,6 +102,28 @@ func (a *API) handleGetCart(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, mapCart(cart))
 }
 
+func (a *API) handleRemoveCartItem(w http.ResponseWriter, r *http.Request) {
+	productID, err := parseIDParam(r, "id")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := a.cartSvc.RemoveItem(r.Context(), cartOwner(r), productID); err != nil {
+		handleDomainError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (a *API) handleClearCart(w http.ResponseWriter, r *http.Request) {
+	if err := a.cartSvc.ClearCart(r.Context(), cartOwner(r)); err != nil {
+		handleDomainError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
 func (a *API) handleCheckout(w http.ResponseWriter, r *http.Request) {
 	user := getAuthUser(r.Context())
 	if user == nil {
@@ -64,13 +137,23 @@ func (a *API) handleCheckout(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	currency, err := a.requestCurrency(req.Currency)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+
 	method := domorder.PaymentMethod(req.PaymentMethod)
-	order, err := a.cartSvc.Checkout(r.Context(), user.UserID, method)
+	result, err := a.cartSvc.Checkout(r.Context(), user.UserID, method, currency)
 	if err != nil {
 		handleDomainError(w, err)
 		return
 	}
 
-	writeJSON(w, http.StatusCreated, mapOrder(order))
+	resp := mapOrder(result.Order)
+	if result.Payment != nil {
+		resp["payment"] = mapPayment(result.Payment)
+	}
+	writeJSON(w, http.StatusCreated, resp)
 }
 
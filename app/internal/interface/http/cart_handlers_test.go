@@ -12,6 +12,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	domcart "example.com/my-golang-sample/app/internal/domain/cart"
+	"example.com/my-golang-sample/app/internal/domain/money"
 	domorder "example.com/my-golang-sample/app/internal/domain/order"
 	domproduct "example.com/my-golang-sample/app/internal/domain/product"
 	domuser "example.com/my-golang-sample/app/internal/domain/user"
@@ -20,25 +21,41 @@ import (
 )
 
 type fakeCartRepo struct {
-	items map[int64]map[int64]int64 // userID -> productID -> quantity
+	items map[domcart.Owner]map[int64]int64 // owner -> productID -> quantity
 }
 
 func newFakeCartRepo() *fakeCartRepo {
 	return &fakeCartRepo{
-		items: make(map[int64]map[int64]int64),
+		items: make(map[domcart.Owner]map[int64]int64),
 	}
 }
 
-func (f *fakeCartRepo) AddOrUpdateItem(ctx context.Context, userID, productID, quantity int64) error {
-	if f.items[userID] == nil {
-		f.items[userID] = make(map[int64]int64)
+func (f *fakeCartRepo) AddOrUpdateItem(ctx context.Context, owner domcart.Owner, productID, quantity int64) error {
+	if f.items[owner] == nil {
+		f.items[owner] = make(map[int64]int64)
 	}
-	f.items[userID][productID] += quantity
+	f.items[owner][productID] += quantity
 	return nil
 }
 
-func (f *fakeCartRepo) ListItems(ctx context.Context, userID int64) ([]domcart.Item, error) {
-	userItems := f.items[userID]
+func (f *fakeCartRepo) SetItemQuantity(ctx context.Context, owner domcart.Owner, productID, quantity int64) error {
+	if _, ok := f.items[owner][productID]; !ok {
+		return domcart.ErrCartItemNotFound
+	}
+	f.items[owner][productID] = quantity
+	return nil
+}
+
+func (f *fakeCartRepo) RemoveItem(ctx context.Context, owner domcart.Owner, productID int64) error {
+	if _, ok := f.items[owner][productID]; !ok {
+		return domcart.ErrCartItemNotFound
+	}
+	delete(f.items[owner], productID)
+	return nil
+}
+
+func (f *fakeCartRepo) ListItems(ctx context.Context, owner domcart.Owner) ([]domcart.Item, error) {
+	userItems := f.items[owner]
 	if userItems == nil {
 		return []domcart.Item{}, nil
 	}
@@ -52,8 +69,22 @@ func (f *fakeCartRepo) ListItems(ctx context.Context, userID int64) ([]domcart.I
 	return items, nil
 }
 
-func (f *fakeCartRepo) Clear(ctx context.Context, userID int64) error {
-	delete(f.items, userID)
+func (f *fakeCartRepo) Clear(ctx context.Context, owner domcart.Owner) error {
+	delete(f.items, owner)
+	return nil
+}
+
+func (f *fakeCartRepo) MergeItems(ctx context.Context, from, to domcart.Owner, items []domcart.Item) error {
+	if len(f.items[from]) == 0 {
+		return nil
+	}
+	delete(f.items, from)
+	if f.items[to] == nil {
+		f.items[to] = make(map[int64]int64)
+	}
+	for _, item := range items {
+		f.items[to][item.ProductID] = item.Quantity
+	}
 	return nil
 }
 
@@ -64,9 +95,9 @@ type fakeProductRepoForCart struct {
 func newFakeProductRepoForCart() *fakeProductRepoForCart {
 	return &fakeProductRepoForCart{
 		products: map[int64]*domproduct.Product{
-			1: {ID: 1, Name: "Product 1", Price: 10.0, Stock: 100, IsActive: true},
-			2: {ID: 2, Name: "Product 2", Price: 20.0, Stock: 5, IsActive: true},
-			3: {ID: 3, Name: "Inactive Product", Price: 30.0, Stock: 50, IsActive: false},
+			1: {ID: 1, Name: "Product 1", Price: money.MustParse("10.0", "SAR"), Stock: 100, IsActive: true},
+			2: {ID: 2, Name: "Product 2", Price: money.MustParse("20.0", "SAR"), Stock: 5, IsActive: true},
+			3: {ID: 3, Name: "Inactive Product", Price: money.MustParse("30.0", "SAR"), Stock: 50, IsActive: false},
 		},
 	}
 }
@@ -92,12 +123,12 @@ type fakeOrderRepoForCart struct {
 	createdOrders []*domorder.Order
 }
 
-func (f *fakeOrderRepoForCart) CreateFromCart(ctx context.Context, userID int64, items []domcart.Item, payment domorder.PaymentMethod) (*domorder.Order, error) {
+func (f *fakeOrderRepoForCart) CreateFromCart(ctx context.Context, userID int64, items []domcart.Item, payment domorder.PaymentMethod, currency string) (*domorder.Order, error) {
 	if len(items) == 0 {
 		return nil, domorder.ErrEmptyOrderItems
 	}
 
-	var totalAmount float64
+	var totalAmount money.Money
 	orderItems := make([]domorder.OrderItem, 0, len(items))
 	productRepo := newFakeProductRepoForCart()
 
@@ -109,7 +140,8 @@ func (f *fakeOrderRepoForCart) CreateFromCart(ctx context.Context, userID int64,
 		if product.Stock < item.Quantity {
 			return nil, domorder.ErrCheckoutValidation
 		}
-		totalAmount += product.Price * float64(item.Quantity)
+		subtotal, _ := product.Price.Mul(item.Quantity)
+		totalAmount, _ = totalAmount.Add(subtotal)
 		orderItems = append(orderItems, domorder.OrderItem{
 			ID:        int64(len(orderItems) + 1),
 			OrderID:   1,
@@ -137,12 +169,23 @@ func (f *fakeOrderRepoForCart) CreateFromCart(ctx context.Context, userID int64,
 	return order, nil
 }
 
+func (f *fakeOrderRepoForCart) UpdateStatus(ctx context.Context, change domorder.StatusChange) (*domorder.Order, error) {
+	for _, order := range f.createdOrders {
+		if order.ID == change.OrderID {
+			order.Status = change.To
+			cloned := *order
+			return &cloned, nil
+		}
+	}
+	return nil, domorder.ErrOrderNotFound
+}
+
 func setupCartAPI() (*API, string, *fakeCartRepo, *fakeOrderRepoForCart) {
 	cartRepo := newFakeCartRepo()
 	productRepo := newFakeProductRepoForCart()
 	orderRepo := &fakeOrderRepoForCart{}
 
-	cartSvc := cartuc.NewService(cartRepo, productRepo, orderRepo)
+	cartSvc := cartuc.NewService(cartRepo, productRepo, orderRepo, nil)
 	tokenSvc := security.NewJWTService("test-secret", time.Hour)
 
 	api := NewAPI(Dependencies{
@@ -395,7 +438,7 @@ func TestCheckout_WithCODReturnsOrderAndClearsCart(t *testing.T) {
 	require.Equal(t, "PENDING", orderResponse["status"])
 
 	// Verify cart is cleared
-	items, err := cartRepo.ListItems(context.Background(), 100)
+	items, err := cartRepo.ListItems(context.Background(), domcart.UserOwner(100))
 	require.NoError(t, err)
 	require.Len(t, items, 0, "cart should be cleared after checkout")
 
@@ -439,7 +482,7 @@ func TestCheckout_WithTAMARAReturnsOrderAndClearsCart(t *testing.T) {
 	require.Equal(t, "PENDING", orderResponse["status"])
 
 	// Verify cart is cleared
-	items, err := cartRepo.ListItems(context.Background(), 100)
+	items, err := cartRepo.ListItems(context.Background(), domcart.UserOwner(100))
 	require.NoError(t, err)
 	require.Len(t, items, 0, "cart should be cleared after checkout")
 
@@ -521,7 +564,7 @@ func TestCheckout_CreatesOrderWithCorrectItemsAndTotal(t *testing.T) {
 
 	// Add multiple items
 	body1 := map[string]any{
-		"product_id": 1, // Price: 10.0
+		"product_id": 1, // Price: money.MustParse("10.0", "SAR")
 		"quantity":   2,
 	}
 	payload1, _ := json.Marshal(body1)
@@ -533,7 +576,7 @@ func TestCheckout_CreatesOrderWithCorrectItemsAndTotal(t *testing.T) {
 	require.Equal(t, http.StatusCreated, rec.Code)
 
 	body2 := map[string]any{
-		"product_id": 2, // Price: 20.0
+		"product_id": 2, // Price: money.MustParse("20.0", "SAR")
 		"quantity":   1,
 	}
 	payload2, _ := json.Marshal(body2)
@@ -569,7 +612,7 @@ func TestCheckout_CreatesOrderWithCorrectItemsAndTotal(t *testing.T) {
 	require.Len(t, items, 2, "should have 2 order items")
 
 	// Verify cart is cleared
-	cartItems, err := cartRepo.ListItems(context.Background(), 100)
+	cartItems, err := cartRepo.ListItems(context.Background(), domcart.UserOwner(100))
 	require.NoError(t, err)
 	require.Len(t, cartItems, 0, "cart should be cleared")
 
@@ -0,0 +1,182 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	domcart "example.com/my-golang-sample/app/internal/domain/cart"
+)
+
+func newCartItemRequest(method, path, token string, body any) *http.Request {
+	var req *http.Request
+	if body != nil {
+		payload, _ := json.Marshal(body)
+		req = httptest.NewRequest(method, path, bytes.NewReader(payload))
+		req.Header.Set("Content-Type", "application/json")
+	} else {
+		req = httptest.NewRequest(method, path, nil)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return req
+}
+
+func TestCart_UpdateItemSetsAbsoluteQuantity(t *testing.T) {
+	api, token, cartRepo, _ := setupCartAPI()
+	cartRepo.AddOrUpdateItem(context.Background(), domcart.UserOwner(100), 1, 5)
+
+	rec := httptest.NewRecorder()
+	api.Router().ServeHTTP(rec, newCartItemRequest(http.MethodPatch, "/api/v1/me/cart/items/1", token, map[string]any{
+		"quantity": 2,
+	}))
+
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.Equal(t, int64(2), cartRepo.items[domcart.UserOwner(100)][1], "quantity should be replaced, not added")
+
+	var cart map[string]any
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &cart))
+	items := cart["items"].([]any)
+	require.Len(t, items, 1)
+	require.Equal(t, float64(2), items[0].(map[string]any)["quantity"])
+}
+
+func TestCart_UpdateItemToZeroRemovesIt(t *testing.T) {
+	api, token, cartRepo, _ := setupCartAPI()
+	cartRepo.AddOrUpdateItem(context.Background(), domcart.UserOwner(100), 1, 5)
+
+	rec := httptest.NewRecorder()
+	api.Router().ServeHTTP(rec, newCartItemRequest(http.MethodPatch, "/api/v1/me/cart/items/1", token, map[string]any{
+		"quantity": 0,
+	}))
+
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.NotContains(t, cartRepo.items[domcart.UserOwner(100)], int64(1))
+}
+
+func TestCart_UpdateItemExceedsStockReturns422(t *testing.T) {
+	api, token, cartRepo, _ := setupCartAPI()
+	cartRepo.AddOrUpdateItem(context.Background(), domcart.UserOwner(100), 2, 1)
+
+	// Product 2 has only 5 in stock
+	rec := httptest.NewRecorder()
+	api.Router().ServeHTTP(rec, newCartItemRequest(http.MethodPatch, "/api/v1/me/cart/items/2", token, map[string]any{
+		"quantity": 6,
+	}))
+
+	require.Equal(t, http.StatusUnprocessableEntity, rec.Code, rec.Body.String())
+	require.Equal(t, int64(1), cartRepo.items[domcart.UserOwner(100)][2])
+}
+
+func TestCart_UpdateItemNotInCartReturns404(t *testing.T) {
+	api, token, _, _ := setupCartAPI()
+
+	rec := httptest.NewRecorder()
+	api.Router().ServeHTTP(rec, newCartItemRequest(http.MethodPatch, "/api/v1/me/cart/items/1", token, map[string]any{
+		"quantity": 1,
+	}))
+
+	require.Equal(t, http.StatusNotFound, rec.Code, rec.Body.String())
+}
+
+func TestCart_UpdateItemValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		body map[string]any
+	}{
+		{name: "missing quantity", body: map[string]any{}},
+		{name: "negative quantity", body: map[string]any{"quantity": -1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api, token, cartRepo, _ := setupCartAPI()
+			cartRepo.AddOrUpdateItem(context.Background(), domcart.UserOwner(100), 1, 5)
+
+			rec := httptest.NewRecorder()
+			api.Router().ServeHTTP(rec, newCartItemRequest(http.MethodPatch, "/api/v1/me/cart/items/1", token, tt.body))
+
+			require.Equal(t, http.StatusBadRequest, rec.Code, rec.Body.String())
+			require.Equal(t, int64(5), cartRepo.items[domcart.UserOwner(100)][1])
+		})
+	}
+}
+
+func TestCart_RemoveItemReturns204(t *testing.T) {
+	api, token, cartRepo, _ := setupCartAPI()
+	cartRepo.AddOrUpdateItem(context.Background(), domcart.UserOwner(100), 1, 5)
+	cartRepo.AddOrUpdateItem(context.Background(), domcart.UserOwner(100), 2, 1)
+
+	rec := httptest.NewRecorder()
+	api.Router().ServeHTTP(rec, newCartItemRequest(http.MethodDelete, "/api/v1/me/cart/items/1", token, nil))
+
+	require.Equal(t, http.StatusNoContent, rec.Code, rec.Body.String())
+	require.NotContains(t, cartRepo.items[domcart.UserOwner(100)], int64(1))
+	require.Contains(t, cartRepo.items[domcart.UserOwner(100)], int64(2))
+}
+
+func TestCart_RemoveMissingItemReturns404(t *testing.T) {
+	api, token, _, _ := setupCartAPI()
+
+	rec := httptest.NewRecorder()
+	api.Router().ServeHTTP(rec, newCartItemRequest(http.MethodDelete, "/api/v1/me/cart/items/1", token, nil))
+
+	require.Equal(t, http.StatusNotFound, rec.Code, rec.Body.String())
+}
+
+func TestCart_ClearCartReturns204(t *testing.T) {
+	api, token, cartRepo, _ := setupCartAPI()
+	cartRepo.AddOrUpdateItem(context.Background(), domcart.UserOwner(100), 1, 5)
+	cartRepo.AddOrUpdateItem(context.Background(), domcart.UserOwner(100), 2, 1)
+
+	rec := httptest.NewRecorder()
+	api.Router().ServeHTTP(rec, newCartItemRequest(http.MethodDelete, "/api/v1/me/cart", token, nil))
+
+	require.Equal(t, http.StatusNoContent, rec.Code, rec.Body.String())
+
+	items, err := cartRepo.ListItems(context.Background(), domcart.UserOwner(100))
+	require.NoError(t, err)
+	require.Empty(t, items)
+}
+
+func TestCart_ManageItemsWithoutAuthReturns401(t *testing.T) {
+	api, _, _, _ := setupCartAPI()
+
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodPatch, "/api/v1/me/cart/items/1", nil),
+		httptest.NewRequest(http.MethodDelete, "/api/v1/me/cart/items/1", nil),
+		httptest.NewRequest(http.MethodDelete, "/api/v1/me/cart", nil),
+	} {
+		rec := httptest.NewRecorder()
+		api.Router().ServeHTTP(rec, req)
+		require.Equal(t, http.StatusUnauthorized, rec.Code, req.Method+" "+req.URL.Path)
+	}
+}
+
+func TestCart_GetCartIncludesTotalsAndWarnings(t *testing.T) {
+	api, token, cartRepo, _ := setupCartAPI()
+	cartRepo.AddOrUpdateItem(context.Background(), domcart.UserOwner(100), 1, 2)
+	cartRepo.AddOrUpdateItem(context.Background(), domcart.UserOwner(100), 2, 6) // only 5 in stock
+	cartRepo.AddOrUpdateItem(context.Background(), domcart.UserOwner(100), 3, 1) // inactive
+
+	rec := httptest.NewRecorder()
+	api.Router().ServeHTTP(rec, newCartItemRequest(http.MethodGet, "/api/v1/me/cart", token, nil))
+
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var cart map[string]any
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &cart))
+	require.Equal(t, 2*10.0+6*20.0, cart["total"])
+	require.Equal(t, float64(8), cart["item_count"])
+	require.Len(t, cart["items"], 2)
+
+	codes := map[string]bool{}
+	for _, w := range cart["warnings"].([]any) {
+		codes[w.(map[string]any)["code"].(string)] = true
+	}
+	require.Equal(t, map[string]bool{"INSUFFICIENT_STOCK": true, "PRODUCT_INACTIVE": true}, codes)
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	domcart "example.com/my-golang-sample/app/internal/domain/cart"
+	"example.com/my-golang-sample/app/internal/domain/money"
 	domorder "example.com/my-golang-sample/app/internal/domain/order"
 	domproduct "example.com/my-golang-sample/app/internal/domain/product"
 	domuser "example.com/my-golang-sample/app/internal/domain/user"
@@ -22,30 +23,46 @@ import (
 // --- Mock Repositories for Checkout Tests ---
 
 type mockCheckoutCartRepository struct {
-	items    map[int64]map[int64]int64 // userID -> productID -> quantity
+	items    map[domcart.Owner]map[int64]int64 // owner -> productID -> quantity
 	listErr  error
 	clearErr error
 }
 
 func newMockCheckoutCartRepository() *mockCheckoutCartRepository {
 	return &mockCheckoutCartRepository{
-		items: make(map[int64]map[int64]int64),
+		items: make(map[domcart.Owner]map[int64]int64),
 	}
 }
 
-func (m *mockCheckoutCartRepository) AddOrUpdateItem(ctx context.Context, userID, productID, quantity int64) error {
-	if m.items[userID] == nil {
-		m.items[userID] = make(map[int64]int64)
+func (m *mockCheckoutCartRepository) AddOrUpdateItem(ctx context.Context, owner domcart.Owner, productID, quantity int64) error {
+	if m.items[owner] == nil {
+		m.items[owner] = make(map[int64]int64)
 	}
-	m.items[userID][productID] += quantity
+	m.items[owner][productID] += quantity
 	return nil
 }
 
-func (m *mockCheckoutCartRepository) ListItems(ctx context.Context, userID int64) ([]domcart.Item, error) {
+func (m *mockCheckoutCartRepository) SetItemQuantity(ctx context.Context, owner domcart.Owner, productID, quantity int64) error {
+	if _, ok := m.items[owner][productID]; !ok {
+		return domcart.ErrCartItemNotFound
+	}
+	m.items[owner][productID] = quantity
+	return nil
+}
+
+func (m *mockCheckoutCartRepository) RemoveItem(ctx context.Context, owner domcart.Owner, productID int64) error {
+	if _, ok := m.items[owner][productID]; !ok {
+		return domcart.ErrCartItemNotFound
+	}
+	delete(m.items[owner], productID)
+	return nil
+}
+
+func (m *mockCheckoutCartRepository) ListItems(ctx context.Context, owner domcart.Owner) ([]domcart.Item, error) {
 	if m.listErr != nil {
 		return nil, m.listErr
 	}
-	userItems := m.items[userID]
+	userItems := m.items[owner]
 	if userItems == nil {
 		return []domcart.Item{}, nil
 	}
@@ -59,11 +76,25 @@ func (m *mockCheckoutCartRepository) ListItems(ctx context.Context, userID int64
 	return items, nil
 }
 
-func (m *mockCheckoutCartRepository) Clear(ctx context.Context, userID int64) error {
+func (m *mockCheckoutCartRepository) Clear(ctx context.Context, owner domcart.Owner) error {
 	if m.clearErr != nil {
 		return m.clearErr
 	}
-	delete(m.items, userID)
+	delete(m.items, owner)
+	return nil
+}
+
+func (m *mockCheckoutCartRepository) MergeItems(ctx context.Context, from, to domcart.Owner, items []domcart.Item) error {
+	if len(m.items[from]) == 0 {
+		return nil
+	}
+	delete(m.items, from)
+	if m.items[to] == nil {
+		m.items[to] = make(map[int64]int64)
+	}
+	for _, item := range items {
+		m.items[to][item.ProductID] = item.Quantity
+	}
 	return nil
 }
 
@@ -74,9 +105,9 @@ type mockCheckoutProductRepository struct {
 func newMockCheckoutProductRepository() *mockCheckoutProductRepository {
 	return &mockCheckoutProductRepository{
 		products: map[int64]*domproduct.Product{
-			1: {ID: 1, Name: "Product 1", Price: 10.0, Stock: 100, CategoryID: 1, IsActive: true},
-			2: {ID: 2, Name: "Product 2", Price: 20.0, Stock: 50, CategoryID: 1, IsActive: true},
-			3: {ID: 3, Name: "Product 3", Price: 30.0, Stock: 25, CategoryID: 1, IsActive: true},
+			1: {ID: 1, Name: "Product 1", Price: money.MustParse("10.0", "SAR"), Prices: []money.Money{money.MustParse("2.75", "USD")}, Stock: 100, CategoryID: 1, IsActive: true},
+			2: {ID: 2, Name: "Product 2", Price: money.MustParse("20.0", "SAR"), Prices: []money.Money{money.MustParse("5.50", "USD")}, Stock: 50, CategoryID: 1, IsActive: true},
+			3: {ID: 3, Name: "Product 3", Price: money.MustParse("30.0", "SAR"), Stock: 25, CategoryID: 1, IsActive: true},
 		},
 	}
 }
@@ -111,7 +142,7 @@ func newMockCheckoutOrderRepository() *mockCheckoutOrderRepository {
 	}
 }
 
-func (m *mockCheckoutOrderRepository) CreateFromCart(ctx context.Context, userID int64, items []domcart.Item, payment domorder.PaymentMethod) (*domorder.Order, error) {
+func (m *mockCheckoutOrderRepository) CreateFromCart(ctx context.Context, userID int64, items []domcart.Item, payment domorder.PaymentMethod, currency string) (*domorder.Order, error) {
 	if m.createErr != nil {
 		return nil, m.createErr
 	}
@@ -119,7 +150,7 @@ func (m *mockCheckoutOrderRepository) CreateFromCart(ctx context.Context, userID
 		return nil, domorder.ErrEmptyOrderItems
 	}
 
-	var totalAmount float64
+	var totalAmount money.Money
 	orderItems := make([]domorder.OrderItem, 0, len(items))
 	productRepo := newMockCheckoutProductRepository()
 
@@ -131,13 +162,18 @@ func (m *mockCheckoutOrderRepository) CreateFromCart(ctx context.Context, userID
 		if product.Stock < item.Quantity {
 			return nil, domorder.ErrCheckoutValidation
 		}
-		totalAmount += product.Price * float64(item.Quantity)
+		price, err := product.PriceIn(currency)
+		if err != nil {
+			return nil, err
+		}
+		subtotal, _ := price.Mul(item.Quantity)
+		totalAmount, _ = totalAmount.Add(subtotal)
 		orderItems = append(orderItems, domorder.OrderItem{
 			ID:        int64(len(orderItems) + 1),
 			OrderID:   1,
 			ProductID: item.ProductID,
 			Name:      product.Name,
-			Price:     product.Price,
+			Price:     price,
 			Quantity:  item.Quantity,
 		})
 	}
@@ -156,6 +192,17 @@ func (m *mockCheckoutOrderRepository) CreateFromCart(ctx context.Context, userID
 	return order, nil
 }
 
+func (m *mockCheckoutOrderRepository) UpdateStatus(ctx context.Context, change domorder.StatusChange) (*domorder.Order, error) {
+	for _, order := range m.createdOrders {
+		if order.ID == change.OrderID {
+			order.Status = change.To
+			cloned := *order
+			return &cloned, nil
+		}
+	}
+	return nil, domorder.ErrOrderNotFound
+}
+
 // --- Helper Functions ---
 
 func setupCheckoutAPI() (*API, string, *mockCheckoutCartRepository, *mockCheckoutOrderRepository) {
@@ -163,7 +210,7 @@ func setupCheckoutAPI() (*API, string, *mockCheckoutCartRepository, *mockCheckou
 	productRepo := newMockCheckoutProductRepository()
 	orderRepo := newMockCheckoutOrderRepository()
 
-	cartSvc := cartuc.NewService(cartRepo, productRepo, orderRepo)
+	cartSvc := cartuc.NewService(cartRepo, productRepo, orderRepo, nil)
 	tokenSvc := security.NewJWTService("test-secret", time.Hour)
 
 	api := NewAPI(Dependencies{
@@ -261,7 +308,7 @@ func TestCheckout_InvalidPaymentMethod_ReturnsError(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			api, token, cartRepo, _ := setupCheckoutAPI()
 			// Add items to cart first
-			cartRepo.AddOrUpdateItem(context.Background(), 100, 1, 2)
+			cartRepo.AddOrUpdateItem(context.Background(), domcart.UserOwner(100), 1, 2)
 			router := api.Router()
 
 			body := map[string]any{
@@ -286,8 +333,8 @@ func TestCheckout_COD_Success_Returns201Or200(t *testing.T) {
 	router := api.Router()
 
 	// Add items to cart
-	cartRepo.AddOrUpdateItem(context.Background(), 100, 1, 2) // Product 1, quantity 2
-	cartRepo.AddOrUpdateItem(context.Background(), 100, 2, 1) // Product 2, quantity 1
+	cartRepo.AddOrUpdateItem(context.Background(), domcart.UserOwner(100), 1, 2) // Product 1, quantity 2
+	cartRepo.AddOrUpdateItem(context.Background(), domcart.UserOwner(100), 2, 1) // Product 2, quantity 1
 
 	body := map[string]any{
 		"payment_method": "COD",
@@ -317,7 +364,7 @@ func TestCheckout_COD_Success_Returns201Or200(t *testing.T) {
 	require.Len(t, items, 2, "should have 2 order items")
 
 	// Verify cart was cleared
-	cartItems, err := cartRepo.ListItems(context.Background(), 100)
+	cartItems, err := cartRepo.ListItems(context.Background(), domcart.UserOwner(100))
 	require.NoError(t, err)
 	require.Len(t, cartItems, 0, "cart should be cleared after successful checkout")
 
@@ -326,12 +373,60 @@ func TestCheckout_COD_Success_Returns201Or200(t *testing.T) {
 	require.Equal(t, domorder.PaymentCOD, orderRepo.createdOrders[0].PaymentMethod)
 }
 
+func TestCheckout_InRequestedCurrency(t *testing.T) {
+	api, token, cartRepo, orderRepo := setupCheckoutAPI()
+	router := api.Router()
+
+	cartRepo.AddOrUpdateItem(context.Background(), domcart.UserOwner(100), 1, 2)
+	cartRepo.AddOrUpdateItem(context.Background(), domcart.UserOwner(100), 2, 1)
+
+	body := map[string]any{
+		"payment_method": "COD",
+		"currency":       "usd",
+	}
+
+	req := newAuthenticatedCheckoutRequest(http.MethodPost, "/api/v1/me/checkout", token, body)
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+	var response map[string]any
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	require.Equal(t, "USD", response["currency"])
+	// (2.75 * 2) + 5.50
+	require.Equal(t, 11.0, response["total_amount"])
+	require.Equal(t, money.MustParse("11", "USD"), orderRepo.createdOrders[0].TotalAmount)
+}
+
+func TestCheckout_ProductNotSoldInCurrency_Returns422(t *testing.T) {
+	api, token, cartRepo, orderRepo := setupCheckoutAPI()
+	router := api.Router()
+
+	cartRepo.AddOrUpdateItem(context.Background(), domcart.UserOwner(100), 1, 1)
+	cartRepo.AddOrUpdateItem(context.Background(), domcart.UserOwner(100), 3, 1)
+
+	body := map[string]any{
+		"payment_method": "COD",
+		"currency":       "USD",
+	}
+
+	req := newAuthenticatedCheckoutRequest(http.MethodPost, "/api/v1/me/checkout", token, body)
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusUnprocessableEntity, rec.Code, rec.Body.String())
+	require.Empty(t, orderRepo.createdOrders)
+}
+
 func TestCheckout_Tamara_Success_Returns201Or200(t *testing.T) {
 	api, token, cartRepo, orderRepo := setupCheckoutAPI()
 	router := api.Router()
 
 	// Add items to cart
-	cartRepo.AddOrUpdateItem(context.Background(), 100, 1, 3) // Product 1, quantity 3
+	cartRepo.AddOrUpdateItem(context.Background(), domcart.UserOwner(100), 1, 3) // Product 1, quantity 3
 
 	body := map[string]any{
 		"payment_method": "TAMARA",
@@ -361,7 +456,7 @@ func TestCheckout_Tamara_Success_Returns201Or200(t *testing.T) {
 	require.Len(t, items, 1, "should have 1 order item")
 
 	// Verify cart was cleared
-	cartItems, err := cartRepo.ListItems(context.Background(), 100)
+	cartItems, err := cartRepo.ListItems(context.Background(), domcart.UserOwner(100))
 	require.NoError(t, err)
 	require.Len(t, cartItems, 0, "cart should be cleared after successful checkout")
 
@@ -397,7 +492,7 @@ func TestCheckout_WithoutAuth_Returns401Or403(t *testing.T) {
 func TestCheckout_MissingPaymentMethod_Returns400(t *testing.T) {
 	api, token, cartRepo, _ := setupCheckoutAPI()
 	// Add items to cart first
-	cartRepo.AddOrUpdateItem(context.Background(), 100, 1, 1)
+	cartRepo.AddOrUpdateItem(context.Background(), domcart.UserOwner(100), 1, 1)
 	router := api.Router()
 
 	body := map[string]any{
@@ -437,9 +532,9 @@ func TestCheckout_CreatesOrderWithMultipleItems(t *testing.T) {
 	router := api.Router()
 
 	// Add multiple items to cart
-	cartRepo.AddOrUpdateItem(context.Background(), 100, 1, 2) // Product 1: 10.0 * 2 = 20.0
-	cartRepo.AddOrUpdateItem(context.Background(), 100, 2, 3) // Product 2: 20.0 * 3 = 60.0
-	cartRepo.AddOrUpdateItem(context.Background(), 100, 3, 1) // Product 3: 30.0 * 1 = 30.0
+	cartRepo.AddOrUpdateItem(context.Background(), domcart.UserOwner(100), 1, 2) // Product 1: 10.0 * 2 = 20.0
+	cartRepo.AddOrUpdateItem(context.Background(), domcart.UserOwner(100), 2, 3) // Product 2: 20.0 * 3 = 60.0
+	cartRepo.AddOrUpdateItem(context.Background(), domcart.UserOwner(100), 3, 1) // Product 3: 30.0 * 1 = 30.0
 	// Total: 20.0 + 60.0 + 30.0 = 110.0
 
 	body := map[string]any{
@@ -463,13 +558,13 @@ func TestCheckout_CreatesOrderWithMultipleItems(t *testing.T) {
 	require.Len(t, items, 3, "should have 3 order items")
 
 	// Verify cart was cleared
-	cartItems, err := cartRepo.ListItems(context.Background(), 100)
+	cartItems, err := cartRepo.ListItems(context.Background(), domcart.UserOwner(100))
 	require.NoError(t, err)
 	require.Len(t, cartItems, 0, "cart should be cleared")
 
 	// Verify order in repository
 	require.Len(t, orderRepo.createdOrders, 1)
-	require.Equal(t, 110.0, orderRepo.createdOrders[0].TotalAmount)
+	require.Equal(t, money.MustParse("110.0", "SAR"), orderRepo.createdOrders[0].TotalAmount)
 	require.Len(t, orderRepo.createdOrders[0].Items, 3)
 }
 
@@ -478,7 +573,7 @@ func TestCheckout_UserIsolation(t *testing.T) {
 	router := api.Router()
 
 	// Add items for user 1
-	cartRepo1.AddOrUpdateItem(context.Background(), 100, 1, 2)
+	cartRepo1.AddOrUpdateItem(context.Background(), domcart.UserOwner(100), 1, 2)
 
 	// Create token for user 2
 	tokenSvc := security.NewJWTService("test-secret", time.Hour)
@@ -493,10 +588,10 @@ func TestCheckout_UserIsolation(t *testing.T) {
 	cartRepo2 := newMockCheckoutCartRepository()
 	productRepo2 := newMockCheckoutProductRepository()
 	orderRepo2 := newMockCheckoutOrderRepository()
-	cartSvc2 := cartuc.NewService(cartRepo2, productRepo2, orderRepo2)
+	cartSvc2 := cartuc.NewService(cartRepo2, productRepo2, orderRepo2, nil)
 
 	// Add items for user 2
-	cartRepo2.AddOrUpdateItem(context.Background(), 200, 2, 1)
+	cartRepo2.AddOrUpdateItem(context.Background(), domcart.UserOwner(200), 2, 1)
 
 	// Checkout for user 1
 	body1 := map[string]any{
@@ -513,12 +608,12 @@ func TestCheckout_UserIsolation(t *testing.T) {
 	require.Equal(t, float64(100), response1["user_id"], "order should belong to user 1")
 
 	// Verify user 1's cart is cleared
-	items1, err := cartRepo1.ListItems(context.Background(), 100)
+	items1, err := cartRepo1.ListItems(context.Background(), domcart.UserOwner(100))
 	require.NoError(t, err)
 	require.Len(t, items1, 0, "user 1's cart should be cleared")
 
 	// Verify user 2's cart is NOT affected (still has items)
-	items2, err := cartRepo2.ListItems(context.Background(), 200)
+	items2, err := cartRepo2.ListItems(context.Background(), domcart.UserOwner(200))
 	require.NoError(t, err)
 	require.Len(t, items2, 1, "user 2's cart should still have items")
 
@@ -567,7 +662,7 @@ func TestCheckout_ValidPaymentMethods(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			api, token, cartRepo, orderRepo := setupCheckoutAPI()
 			// Add items to cart
-			cartRepo.AddOrUpdateItem(context.Background(), 100, 1, 1)
+			cartRepo.AddOrUpdateItem(context.Background(), domcart.UserOwner(100), 1, 1)
 			router := api.Router()
 
 			body := map[string]any{
@@ -587,7 +682,7 @@ func TestCheckout_ValidPaymentMethods(t *testing.T) {
 				require.Equal(t, tt.paymentMethod, response["payment_method"])
 
 				// Verify cart was cleared
-				cartItems, err := cartRepo.ListItems(context.Background(), 100)
+				cartItems, err := cartRepo.ListItems(context.Background(), domcart.UserOwner(100))
 				require.NoError(t, err)
 				require.Len(t, cartItems, 0, "cart should be cleared after successful checkout")
 
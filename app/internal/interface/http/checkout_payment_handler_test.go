@@ -0,0 +1,166 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	domcart "example.com/my-golang-sample/app/internal/domain/cart"
+	domorder "example.com/my-golang-sample/app/internal/domain/order"
+	dompayment "example.com/my-golang-sample/app/internal/domain/payment"
+	domuser "example.com/my-golang-sample/app/internal/domain/user"
+	paymentgw "example.com/my-golang-sample/app/internal/infra/payment"
+	"example.com/my-golang-sample/app/internal/infra/security"
+	cartuc "example.com/my-golang-sample/app/internal/usecase/cart"
+	paymentuc "example.com/my-golang-sample/app/internal/usecase/payment"
+)
+
+type memoryPaymentRepo struct {
+	payments []*dompayment.Payment
+	events   map[string]bool // provider:event_id -> processed
+}
+
+func (m *memoryPaymentRepo) Create(ctx context.Context, p *dompayment.Payment) (*dompayment.Payment, error) {
+	p.ID = int64(len(m.payments) + 1)
+	cloned := *p
+	m.payments = append(m.payments, &cloned)
+	return p, nil
+}
+
+func (m *memoryPaymentRepo) GetByOrderID(ctx context.Context, orderID int64) (*dompayment.Payment, error) {
+	for _, p := range m.payments {
+		if p.OrderID == orderID {
+			cloned := *p
+			return &cloned, nil
+		}
+	}
+	return nil, dompayment.ErrPaymentNotFound
+}
+
+func (m *memoryPaymentRepo) UpdateStatus(ctx context.Context, id int64, status dompayment.Status) error {
+	for _, p := range m.payments {
+		if p.ID == id {
+			p.Status = status
+			return nil
+		}
+	}
+	return dompayment.ErrPaymentNotFound
+}
+
+func (m *memoryPaymentRepo) RecordEvent(ctx context.Context, e *dompayment.WebhookEvent) (bool, error) {
+	if m.events == nil {
+		m.events = make(map[string]bool)
+	}
+	key := string(e.Provider) + ":" + e.ID
+	processed, ok := m.events[key]
+	if !ok {
+		m.events[key] = false
+	}
+	return processed, nil
+}
+
+func (m *memoryPaymentRepo) MarkEventProcessed(ctx context.Context, provider dompayment.Provider, eventID string) error {
+	m.events[string(provider)+":"+eventID] = true
+	return nil
+}
+
+type staticUserReader struct{}
+
+func (staticUserReader) GetByID(ctx context.Context, id int64) (*domuser.User, error) {
+	return &domuser.User{ID: id, Name: "Test Customer", Email: "customer@example.com"}, nil
+}
+
+func setupCheckoutPaymentAPI() (*API, string, *mockCheckoutCartRepository, *mockCheckoutOrderRepository, *paymentgw.FakeGateway) {
+	cartRepo := newMockCheckoutCartRepository()
+	productRepo := newMockCheckoutProductRepository()
+	orderRepo := newMockCheckoutOrderRepository()
+	gateway := paymentgw.NewFakeGateway(dompayment.ProviderTamara, "https://pay.test/checkout")
+	paymentSvc := paymentuc.NewService(&memoryPaymentRepo{}, staticUserReader{}, nil, gateway)
+
+	cartSvc := cartuc.NewService(cartRepo, productRepo, orderRepo, paymentSvc)
+	tokenSvc := security.NewJWTService("test-secret", time.Hour)
+
+	api := NewAPI(Dependencies{
+		CartService:  cartSvc,
+		TokenService: tokenSvc,
+	})
+
+	token, _ := tokenSvc.GenerateToken(&domuser.User{
+		ID:       100,
+		Name:     "Test Customer",
+		Email:    "customer@example.com",
+		RoleCode: domuser.RoleCodeCustomer,
+	})
+
+	return api, token, cartRepo, orderRepo, gateway
+}
+
+func TestCheckout_Tamara_ReturnsPaymentRedirect(t *testing.T) {
+	api, token, cartRepo, _, gateway := setupCheckoutPaymentAPI()
+	cartRepo.AddOrUpdateItem(context.Background(), domcart.UserOwner(100), 1, 2)
+
+	req := newAuthenticatedCheckoutRequest(http.MethodPost, "/api/v1/me/checkout", token, map[string]any{
+		"payment_method": "TAMARA",
+	})
+	rec := httptest.NewRecorder()
+	api.Router().ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+	var response map[string]any
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	require.Equal(t, "TAMARA", response["payment_method"])
+
+	payment, ok := response["payment"].(map[string]any)
+	require.True(t, ok, "payment should be present for TAMARA checkout")
+	require.Equal(t, "tamara", payment["provider"])
+	require.Equal(t, "PENDING", payment["status"])
+	require.Equal(t, 20.0, payment["amount"])
+	require.Contains(t, payment["redirect_url"], "https://pay.test/checkout")
+
+	require.Len(t, gateway.Calls, 1)
+}
+
+func TestCheckout_COD_DoesNotOpenPaymentSession(t *testing.T) {
+	api, token, cartRepo, _, gateway := setupCheckoutPaymentAPI()
+	cartRepo.AddOrUpdateItem(context.Background(), domcart.UserOwner(100), 1, 1)
+
+	req := newAuthenticatedCheckoutRequest(http.MethodPost, "/api/v1/me/checkout", token, map[string]any{
+		"payment_method": "COD",
+	})
+	rec := httptest.NewRecorder()
+	api.Router().ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+	var response map[string]any
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	require.NotContains(t, response, "payment")
+	require.Empty(t, gateway.Calls)
+}
+
+func TestCheckout_Tamara_GatewayFailureReturns502AndKeepsCart(t *testing.T) {
+	api, token, cartRepo, orderRepo, gateway := setupCheckoutPaymentAPI()
+	gateway.Err = dompayment.ErrGatewayRequest
+	cartRepo.AddOrUpdateItem(context.Background(), domcart.UserOwner(100), 1, 2)
+
+	req := newAuthenticatedCheckoutRequest(http.MethodPost, "/api/v1/me/checkout", token, map[string]any{
+		"payment_method": "TAMARA",
+	})
+	rec := httptest.NewRecorder()
+	api.Router().ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusBadGateway, rec.Code, rec.Body.String())
+
+	cartItems, err := cartRepo.ListItems(context.Background(), domcart.UserOwner(100))
+	require.NoError(t, err)
+	require.Len(t, cartItems, 1, "cart should be kept so the customer can retry")
+
+	require.Len(t, orderRepo.createdOrders, 1)
+	require.Equal(t, domorder.StatusCanceled, orderRepo.createdOrders[0].Status)
+}
@@ -0,0 +1,57 @@
+package http
+
+import (
+	"net/http"
+	"time"
+
+	domcart "example.com/my-golang-sample/app/internal/domain/cart"
+)
+
+const (
+	guestCartHeader = "X-Cart-Token"
+	guestCartCookie = "cart_token"
+	guestCartMaxAge = 30 * 24 * time.Hour
+)
+
+// cartOwner resolves whose cart a request addresses: the authenticated user on
+// /me routes, otherwise the guest token (empty if the shopper has none yet).
+func cartOwner(r *http.Request) domcart.Owner {
+	if user := getAuthUser(r.Context()); user != nil {
+		return domcart.UserOwner(user.UserID)
+	}
+	return domcart.GuestOwner(guestCartToken(r))
+}
+
+// guestCartToken đọc cart token từ header, nếu không có thì từ cookie.
+func guestCartToken(r *http.Request) string {
+	if token := r.Header.Get(guestCartHeader); token != "" {
+		return token
+	}
+	if c, err := r.Cookie(guestCartCookie); err == nil {
+		return c.Value
+	}
+	return ""
+}
+
+func setGuestCartToken(w http.ResponseWriter, r *http.Request, token string) {
+	w.Header().Set(guestCartHeader, token)
+	http.SetCookie(w, &http.Cookie{
+		Name:     guestCartCookie,
+		Value:    token,
+		Path:     "/",
+		MaxAge:   int(guestCartMaxAge.Seconds()),
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+func clearGuestCartToken(w http.ResponseWriter) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     guestCartCookie,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+	})
+}
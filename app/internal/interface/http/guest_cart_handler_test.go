@@ -0,0 +1,147 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	domcart "example.com/my-golang-sample/app/internal/domain/cart"
+	domuser "example.com/my-golang-sample/app/internal/domain/user"
+	"example.com/my-golang-sample/app/internal/infra/security"
+	authuc "example.com/my-golang-sample/app/internal/usecase/auth"
+	cartuc "example.com/my-golang-sample/app/internal/usecase/cart"
+)
+
+func setupGuestCartAPI() (*API, *fakeCartRepo) {
+	cartRepo := newFakeCartRepo()
+	cartSvc := cartuc.NewService(cartRepo, newFakeProductRepoForCart(), &fakeOrderRepoForCart{}, nil)
+	tokenSvc := security.NewJWTService("test-secret", time.Hour)
+
+	user := &domuser.User{ID: 100, Name: "Test User", Email: "test@example.com", RoleCode: domuser.RoleCodeCustomer, PasswordHash: "hash"}
+	authSvc := authuc.NewService(&fakeAuthUserRepo{user: user}, &fakePasswordChecker{shouldSucceed: true}, tokenSvc, cartSvc)
+
+	api := NewAPI(Dependencies{
+		AuthService:  authSvc,
+		CartService:  cartSvc,
+		TokenService: tokenSvc,
+	})
+	return api, cartRepo
+}
+
+func newGuestCartRequest(method, path, cartToken string, body any) *http.Request {
+	var req *http.Request
+	if body != nil {
+		payload, _ := json.Marshal(body)
+		req = httptest.NewRequest(method, path, bytes.NewReader(payload))
+		req.Header.Set("Content-Type", "application/json")
+	} else {
+		req = httptest.NewRequest(method, path, nil)
+	}
+	if cartToken != "" {
+		req.Header.Set("X-Cart-Token", cartToken)
+	}
+	return req
+}
+
+func TestGuestCart_FirstAddIssuesToken(t *testing.T) {
+	api, cartRepo := setupGuestCartAPI()
+
+	rec := httptest.NewRecorder()
+	api.Router().ServeHTTP(rec, newGuestCartRequest(http.MethodPost, "/api/v1/cart/items", "", map[string]any{
+		"product_id": 1,
+		"quantity":   2,
+	}))
+
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+	var resp map[string]any
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	token, _ := resp["cart_token"].(string)
+	require.NotEmpty(t, token)
+	require.Equal(t, token, rec.Header().Get("X-Cart-Token"))
+
+	cookies := rec.Result().Cookies()
+	require.Len(t, cookies, 1)
+	require.Equal(t, "cart_token", cookies[0].Name)
+	require.Equal(t, token, cookies[0].Value)
+	require.True(t, cookies[0].HttpOnly)
+
+	require.Equal(t, int64(2), cartRepo.items[domcart.GuestOwner(token)][1])
+}
+
+func TestGuestCart_TokenFromHeaderOrCookie(t *testing.T) {
+	api, cartRepo := setupGuestCartAPI()
+	cartRepo.items[domcart.GuestOwner("guest-token")] = map[int64]int64{1: 3}
+
+	rec := httptest.NewRecorder()
+	api.Router().ServeHTTP(rec, newGuestCartRequest(http.MethodGet, "/api/v1/cart", "guest-token", nil))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var cart map[string]any
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &cart))
+	require.Len(t, cart["items"], 1)
+
+	req := newGuestCartRequest(http.MethodPost, "/api/v1/cart/items", "", map[string]any{"product_id": 1, "quantity": 1})
+	req.AddCookie(&http.Cookie{Name: "cart_token", Value: "guest-token"})
+	rec = httptest.NewRecorder()
+	api.Router().ServeHTTP(rec, req)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+	var resp map[string]any
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	require.NotContains(t, resp, "cart_token", "an existing token must be reused")
+	require.Equal(t, int64(4), cartRepo.items[domcart.GuestOwner("guest-token")][1])
+}
+
+func TestGuestCart_WithoutTokenIsEmpty(t *testing.T) {
+	api, _ := setupGuestCartAPI()
+
+	rec := httptest.NewRecorder()
+	api.Router().ServeHTTP(rec, newGuestCartRequest(http.MethodGet, "/api/v1/cart", "", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var cart map[string]any
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &cart))
+	require.Empty(t, cart["items"])
+}
+
+func TestGuestCart_UpdateAndRemoveItems(t *testing.T) {
+	api, cartRepo := setupGuestCartAPI()
+	cartRepo.items[domcart.GuestOwner("guest-token")] = map[int64]int64{1: 3, 2: 1}
+
+	rec := httptest.NewRecorder()
+	api.Router().ServeHTTP(rec, newGuestCartRequest(http.MethodPatch, "/api/v1/cart/items/1", "guest-token", map[string]any{"quantity": 5}))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.Equal(t, int64(5), cartRepo.items[domcart.GuestOwner("guest-token")][1])
+
+	rec = httptest.NewRecorder()
+	api.Router().ServeHTTP(rec, newGuestCartRequest(http.MethodDelete, "/api/v1/cart/items/2", "guest-token", nil))
+	require.Equal(t, http.StatusNoContent, rec.Code, rec.Body.String())
+	require.NotContains(t, cartRepo.items[domcart.GuestOwner("guest-token")], int64(2))
+}
+
+func TestGuestCart_LoginMergesIntoUserCart(t *testing.T) {
+	api, cartRepo := setupGuestCartAPI()
+	cartRepo.items[domcart.GuestOwner("guest-token")] = map[int64]int64{1: 3, 2: 4}
+	cartRepo.items[domcart.UserOwner(100)] = map[int64]int64{2: 3}
+
+	rec := httptest.NewRecorder()
+	api.Router().ServeHTTP(rec, newGuestCartRequest(http.MethodPost, "/api/v1/auth/login", "guest-token", map[string]any{
+		"email":    "test@example.com",
+		"password": "password123",
+	}))
+
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	// Product 2 has 5 in stock: 3+4 is capped by the default rules
+	require.Equal(t, map[int64]int64{1: 3, 2: 5}, cartRepo.items[domcart.UserOwner(100)])
+	require.Empty(t, cartRepo.items[domcart.GuestOwner("guest-token")])
+
+	cookies := rec.Result().Cookies()
+	require.Len(t, cookies, 1)
+	require.Equal(t, "cart_token", cookies[0].Name)
+	require.Negative(t, cookies[0].MaxAge, "guest cart cookie should be expired after the merge")
+}
@@ -0,0 +1,183 @@
+package http
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+
+	domuser "example.com/my-golang-sample/app/internal/domain/user"
+	"example.com/my-golang-sample/app/internal/infra/security"
+	authuc "example.com/my-golang-sample/app/internal/usecase/auth"
+)
+
+var jwksTestUser = &domuser.User{ID: 100, Name: "Test User", Email: "user@example.com", RoleCode: domuser.RoleCodeCustomer}
+
+func newRSAKey(t *testing.T, id string) security.SigningKey {
+	t.Helper()
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	return security.SigningKey{ID: id, Private: priv}
+}
+
+func newEd25519Key(t *testing.T, id string) security.SigningKey {
+	t.Helper()
+	key, err := security.GenerateEd25519Key(id)
+	require.NoError(t, err)
+	return key
+}
+
+func newKeyedTokenService(t *testing.T, keys ...security.SigningKey) *security.JWTService {
+	t.Helper()
+	set, err := security.NewKeySet(keys...)
+	require.NoError(t, err)
+	return security.NewJWTServiceWithKeys(set, time.Hour)
+}
+
+func setupJWKSAPI(tokenSvc authuc.TokenService) http.Handler {
+	authSvc := authuc.NewService(&fakeAuthUserRepo{user: jwksTestUser}, &fakePasswordChecker{shouldSucceed: true}, tokenSvc, nil)
+	return NewAPI(Dependencies{
+		AuthService:  authSvc,
+		TokenService: tokenSvc,
+	}).Router()
+}
+
+// callWithToken hits a route behind authMiddleware.
+func callWithToken(router http.Handler, token string) int {
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/logout", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func tokenKID(t *testing.T, token string) string {
+	t.Helper()
+	parsed, _, err := jwt.NewParser().ParseUnverified(token, jwt.MapClaims{})
+	require.NoError(t, err)
+	kid, _ := parsed.Header["kid"].(string)
+	return kid
+}
+
+func TestJWKS_PublishesPublicKeys(t *testing.T) {
+	tokenSvc := newKeyedTokenService(t, newRSAKey(t, "rsa-1"), newEd25519Key(t, "ed-1"))
+	router := setupJWKSAPI(tokenSvc)
+
+	req := httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Contains(t, rec.Header().Get("Cache-Control"), "max-age")
+
+	var resp struct {
+		Keys []map[string]any `json:"keys"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	require.Len(t, resp.Keys, 2)
+
+	byKID := make(map[string]map[string]any)
+	for _, k := range resp.Keys {
+		byKID[k["kid"].(string)] = k
+		require.NotContains(t, k, "d", "private material must not be published")
+	}
+	require.Equal(t, "RSA", byKID["rsa-1"]["kty"])
+	require.Equal(t, "RS256", byKID["rsa-1"]["alg"])
+	require.Equal(t, "AQAB", byKID["rsa-1"]["e"])
+	require.Equal(t, "OKP", byKID["ed-1"]["kty"])
+	require.Equal(t, "EdDSA", byKID["ed-1"]["alg"])
+	require.Equal(t, "Ed25519", byKID["ed-1"]["crv"])
+}
+
+func TestJWKS_NotPublishedForSharedSecret(t *testing.T) {
+	router := setupJWKSAPI(security.NewJWTService("test-secret", time.Hour))
+
+	req := httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestJWT_AsymmetricTokensAreAccepted(t *testing.T) {
+	for _, key := range []security.SigningKey{newRSAKey(t, "rsa-1"), newEd25519Key(t, "ed-1")} {
+		tokenSvc := newKeyedTokenService(t, key)
+		router := setupJWKSAPI(tokenSvc)
+
+		token, err := tokenSvc.GenerateToken(jwksTestUser)
+		require.NoError(t, err)
+		require.Equal(t, key.ID, tokenKID(t, token))
+
+		require.Equal(t, http.StatusNoContent, callWithToken(router, token), key.ID)
+	}
+}
+
+func TestJWT_SigningMethodIsPinned(t *testing.T) {
+	key := newRSAKey(t, "rsa-1")
+	router := setupJWKSAPI(newKeyedTokenService(t, key))
+
+	claims := jwt.MapClaims{
+		"uid":  jwksTestUser.ID,
+		"role": string(domuser.RoleCodeSuperAdmin),
+		"exp":  time.Now().Add(time.Hour).Unix(),
+	}
+
+	// HS256 keyed with the public key, the classic algorithm confusion attack
+	hs := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	hs.Header["kid"] = "rsa-1"
+	forged, err := hs.SignedString([]byte("any secret the attacker knows"))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusUnauthorized, callWithToken(router, forged))
+
+	none := jwt.NewWithClaims(jwt.SigningMethodNone, claims)
+	none.Header["kid"] = "rsa-1"
+	unsigned, err := none.SignedString(jwt.UnsafeAllowNoneSignatureType)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusUnauthorized, callWithToken(router, unsigned))
+
+	// Signed by a key we never published
+	stranger, err := newKeyedTokenService(t, newRSAKey(t, "rsa-1")).GenerateToken(jwksTestUser)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusUnauthorized, callWithToken(router, stranger))
+}
+
+func TestJWT_KeyRotation(t *testing.T) {
+	oldKey := newEd25519Key(t, "old")
+	oldKey.RetireAt = time.Now().Add(time.Hour)
+	newKey := newEd25519Key(t, "new")
+	newKey.SignFrom = time.Now().Add(-time.Minute)
+	retired := newEd25519Key(t, "retired")
+	retired.RetireAt = time.Now().Add(-time.Minute)
+	upcoming := newEd25519Key(t, "upcoming")
+	upcoming.SignFrom = time.Now().Add(time.Hour)
+
+	tokenSvc := newKeyedTokenService(t, oldKey, newKey, retired, upcoming)
+	router := setupJWKSAPI(tokenSvc)
+
+	token, err := tokenSvc.GenerateToken(jwksTestUser)
+	require.NoError(t, err)
+	require.Equal(t, "new", tokenKID(t, token), "the newest active key signs")
+
+	// Tokens issued before the rotation stay valid until the old key retires
+	oldKey.RetireAt = time.Time{}
+	oldToken, err := newKeyedTokenService(t, oldKey).GenerateToken(jwksTestUser)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNoContent, callWithToken(router, oldToken))
+
+	retired.RetireAt = time.Time{}
+	retiredToken, err := newKeyedTokenService(t, retired).GenerateToken(jwksTestUser)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusUnauthorized, callWithToken(router, retiredToken))
+
+	kids := make([]string, 0)
+	for _, k := range tokenSvc.PublicKeys() {
+		kids = append(kids, k.Kid)
+	}
+	require.ElementsMatch(t, []string{"old", "new", "upcoming"}, kids)
+}
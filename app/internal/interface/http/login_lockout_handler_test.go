@@ -0,0 +1,192 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	domaudit "example.com/my-golang-sample/app/internal/domain/audit"
+	domauth "example.com/my-golang-sample/app/internal/domain/auth"
+	"example.com/my-golang-sample/app/internal/domain/page"
+	domuser "example.com/my-golang-sample/app/internal/domain/user"
+	"example.com/my-golang-sample/app/internal/infra/security"
+	authuc "example.com/my-golang-sample/app/internal/usecase/auth"
+)
+
+type fakeLoginAttemptRepo struct {
+	counters map[string]*domauth.LoginFailures
+}
+
+func (f *fakeLoginAttemptRepo) Get(ctx context.Context, scope domauth.LoginScope, key string) (*domauth.LoginFailures, error) {
+	if c, ok := f.counters[string(scope)+":"+key]; ok {
+		cloned := *c
+		return &cloned, nil
+	}
+	return &domauth.LoginFailures{Scope: scope, Key: key}, nil
+}
+
+func (f *fakeLoginAttemptRepo) RecordFailure(ctx context.Context, scope domauth.LoginScope, key string, now time.Time, window time.Duration) (*domauth.LoginFailures, error) {
+	c, ok := f.counters[string(scope)+":"+key]
+	if !ok {
+		c = &domauth.LoginFailures{Scope: scope, Key: key}
+		f.counters[string(scope)+":"+key] = c
+	}
+	c.Count++
+	c.LastFailedAt = now
+	cloned := *c
+	return &cloned, nil
+}
+
+func (f *fakeLoginAttemptRepo) Lock(ctx context.Context, scope domauth.LoginScope, key string, until time.Time) error {
+	c := f.counters[string(scope)+":"+key]
+	c.Count = 0
+	c.LockedUntil = &until
+	return nil
+}
+
+func (f *fakeLoginAttemptRepo) Clear(ctx context.Context, scope domauth.LoginScope, key string) error {
+	delete(f.counters, string(scope)+":"+key)
+	return nil
+}
+
+type fakeAuditLog struct {
+	entries []*domaudit.Entry
+}
+
+func (f *fakeAuditLog) Append(ctx context.Context, e *domaudit.Entry) error {
+	e.ID = int64(len(f.entries) + 1)
+	f.entries = append(f.entries, e)
+	return nil
+}
+
+func (f *fakeAuditLog) List(ctx context.Context, filter domaudit.ListFilter, req page.Request) ([]*domaudit.Entry, page.Info, error) {
+	var out []*domaudit.Entry
+	for i := len(f.entries) - 1; i >= 0; i-- {
+		e := f.entries[i]
+		if filter.Action != nil && e.Action != *filter.Action {
+			continue
+		}
+		if filter.TargetType != nil && e.TargetType != *filter.TargetType {
+			continue
+		}
+		if filter.TargetID != nil && e.TargetID != *filter.TargetID {
+			continue
+		}
+		if filter.ActorID != nil && (e.ActorID == nil || *e.ActorID != *filter.ActorID) {
+			continue
+		}
+		out = append(out, e)
+	}
+	return out, page.Info{Total: int64(len(out)), Page: req.Page, Size: req.Size}, nil
+}
+
+func setupLockoutAPI(t *testing.T, policy authuc.LockoutPolicy) (http.Handler, *fakeAuditLog) {
+	t.Helper()
+	passwordSvc := security.NewBcryptService(4)
+	hash, err := passwordSvc.Hash("password123")
+	require.NoError(t, err)
+
+	repo := &fakeAccountUserRepo{users: map[string]*domuser.User{
+		"admin@example.com": {ID: 1, Name: "Admin", Email: "admin@example.com", PasswordHash: hash, RoleCode: domuser.RoleCodeAdmin},
+		"jane@example.com":  {ID: 2, Name: "Jane", Email: "jane@example.com", PasswordHash: hash, RoleCode: domuser.RoleCodeCustomer},
+	}}
+	audit := &fakeAuditLog{}
+	tokenSvc := security.NewJWTService("test-secret", time.Hour)
+	authSvc := authuc.NewService(repo, passwordSvc, tokenSvc, nil,
+		authuc.WithLockout(&fakeLoginAttemptRepo{counters: make(map[string]*domauth.LoginFailures)}, policy),
+		authuc.WithAudit(audit))
+
+	api := NewAPI(Dependencies{
+		AuthService:  authSvc,
+		TokenService: tokenSvc,
+	})
+	return api.Router(), audit
+}
+
+func postLoginFrom(router http.Handler, ip, email, password string) *httptest.ResponseRecorder {
+	payload, _ := json.Marshal(map[string]string{"email": email, "password": password})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/login", bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Real-IP", ip)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestLogin_LockoutReturns429WithRetryAfter(t *testing.T) {
+	router, audit := setupLockoutAPI(t, authuc.LockoutPolicy{MaxAccountFailures: 3, LockoutDuration: 15 * time.Minute})
+
+	for i := 0; i < 2; i++ {
+		rec := postLoginFrom(router, "203.0.113.7", "jane@example.com", "wrong-password")
+		require.Equal(t, http.StatusUnauthorized, rec.Code, rec.Body.String())
+	}
+	rec := postLoginFrom(router, "203.0.113.7", "jane@example.com", "wrong-password")
+	require.Equal(t, http.StatusTooManyRequests, rec.Code, rec.Body.String())
+	require.Equal(t, "900", rec.Header().Get("Retry-After"))
+
+	rec = postLoginFrom(router, "203.0.113.7", "jane@example.com", "password123")
+	require.Equal(t, http.StatusTooManyRequests, rec.Code)
+
+	require.Len(t, audit.entries, 1)
+	require.Equal(t, domaudit.ActionAccountLocked, audit.entries[0].Action)
+	require.Equal(t, "203.0.113.7", audit.entries[0].IP, "the address comes from chimw.RealIP")
+}
+
+func TestLogin_IPLockoutAppliesToEveryEmail(t *testing.T) {
+	router, _ := setupLockoutAPI(t, authuc.LockoutPolicy{MaxIPFailures: 2})
+
+	rec := postLoginFrom(router, "203.0.113.8", "a@example.com", "wrong-password")
+	require.Equal(t, http.StatusUnauthorized, rec.Code)
+	rec = postLoginFrom(router, "203.0.113.8", "b@example.com", "wrong-password")
+	require.Equal(t, http.StatusTooManyRequests, rec.Code)
+
+	rec = postLoginFrom(router, "203.0.113.8", "jane@example.com", "password123")
+	require.Equal(t, http.StatusTooManyRequests, rec.Code)
+	rec = postLoginFrom(router, "198.51.100.1", "jane@example.com", "password123")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+}
+
+func TestAdminUnlockUser(t *testing.T) {
+	router, audit := setupLockoutAPI(t, authuc.LockoutPolicy{MaxAccountFailures: 1})
+
+	rec := postLoginFrom(router, "203.0.113.9", "jane@example.com", "wrong-password")
+	require.Equal(t, http.StatusTooManyRequests, rec.Code)
+
+	rec = postLoginFrom(router, "198.51.100.2", "admin@example.com", "password123")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var login map[string]any
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &login))
+	adminToken := login["token"].(string)
+
+	rec = postSessionJSON(router, "/api/v1/admin/users/2/unlock", adminToken, nil)
+	require.Equal(t, http.StatusNoContent, rec.Code, rec.Body.String())
+
+	rec = postLoginFrom(router, "203.0.113.10", "jane@example.com", "password123")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	last := audit.entries[len(audit.entries)-1]
+	require.Equal(t, domaudit.ActionAccountUnlocked, last.Action)
+	require.Equal(t, int64(1), *last.ActorID)
+	require.Equal(t, "2", last.TargetID)
+
+	rec = postSessionJSON(router, "/api/v1/admin/users/99/unlock", adminToken, nil)
+	require.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestAdminUnlockUser_RequiresAdmin(t *testing.T) {
+	router, _ := setupLockoutAPI(t, authuc.LockoutPolicy{})
+
+	rec := postLoginFrom(router, "198.51.100.3", "jane@example.com", "password123")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var login map[string]any
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &login))
+
+	rec = postSessionJSON(router, "/api/v1/admin/users/2/unlock", login["token"].(string), nil)
+	require.Equal(t, http.StatusForbidden, rec.Code)
+}
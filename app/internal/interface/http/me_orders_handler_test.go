@@ -0,0 +1,148 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	domorder "example.com/my-golang-sample/app/internal/domain/order"
+	domuser "example.com/my-golang-sample/app/internal/domain/user"
+	"example.com/my-golang-sample/app/internal/infra/security"
+	orderuc "example.com/my-golang-sample/app/internal/usecase/order"
+)
+
+func setupMyOrdersAPI(userID int64) (*API, string, *fakeOrderRepo) {
+	orderRepo := newFakeOrderRepo()
+	tokenSvc := security.NewJWTService("test-secret", time.Hour)
+
+	api := NewAPI(Dependencies{
+		OrderService: orderuc.NewService(orderRepo),
+		TokenService: tokenSvc,
+	})
+
+	token, _ := tokenSvc.GenerateToken(&domuser.User{
+		ID:       userID,
+		Name:     "Test Customer",
+		Email:    "customer@example.com",
+		RoleCode: domuser.RoleCodeCustomer,
+	})
+
+	return api, token, orderRepo
+}
+
+func TestMyOrders_ListReturnsOnlyOwnOrders(t *testing.T) {
+	api, token, _ := setupMyOrdersAPI(100)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/me/orders", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	api.Router().ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var response struct {
+		Data  []map[string]any `json:"data"`
+		Total int64            `json:"total"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	require.Len(t, response.Data, 1)
+	require.Equal(t, int64(1), response.Total)
+	require.Equal(t, float64(1), response.Data[0]["id"])
+	require.Equal(t, float64(100), response.Data[0]["user_id"])
+}
+
+func TestMyOrders_ListWithoutOrdersReturnsEmptyArray(t *testing.T) {
+	api, token, _ := setupMyOrdersAPI(999)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/me/orders", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	api.Router().ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.JSONEq(t, `{"data":[],"total":0,"page":1,"size":20,"next_cursor":null}`, rec.Body.String())
+}
+
+func TestMyOrders_GetOwnOrderReturns200(t *testing.T) {
+	api, token, _ := setupMyOrdersAPI(100)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/me/orders/1", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	api.Router().ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var order map[string]any
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &order))
+	require.Equal(t, float64(1), order["id"])
+	require.Len(t, order["items"], 2)
+}
+
+func TestMyOrders_GetOtherUsersOrderReturns404(t *testing.T) {
+	api, token, _ := setupMyOrdersAPI(100)
+
+	// Order 2 belongs to user 101
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/me/orders/2", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	api.Router().ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusNotFound, rec.Code, rec.Body.String())
+}
+
+func TestMyOrders_WithoutAuthReturns401(t *testing.T) {
+	api, _, _ := setupMyOrdersAPI(100)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/me/orders", nil)
+	rec := httptest.NewRecorder()
+	api.Router().ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusUnauthorized, rec.Code, rec.Body.String())
+}
+
+func TestMyOrders_CancelPendingOrderReturns200(t *testing.T) {
+	api, token, orderRepo := setupMyOrdersAPI(100)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/me/orders/1/cancel", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	api.Router().ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var order map[string]any
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &order))
+	require.Equal(t, "CANCELED", order["status"])
+	require.Len(t, orderRepo.history, 1)
+	require.Equal(t, int64(100), orderRepo.history[0].ChangedBy)
+}
+
+func TestMyOrders_CancelProgressedOrderReturns422(t *testing.T) {
+	api, token, orderRepo := setupMyOrdersAPI(100)
+	orderRepo.orders[1].Status = domorder.StatusPaid
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/me/orders/1/cancel", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	api.Router().ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusUnprocessableEntity, rec.Code, rec.Body.String())
+	require.Equal(t, domorder.StatusPaid, orderRepo.orders[1].Status)
+}
+
+func TestMyOrders_CancelOtherUsersOrderReturns404(t *testing.T) {
+	api, token, orderRepo := setupMyOrdersAPI(101)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/me/orders/1/cancel", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	api.Router().ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusNotFound, rec.Code, rec.Body.String())
+	require.Equal(t, domorder.StatusPending, orderRepo.orders[1].Status)
+}
@@ -0,0 +1,212 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	domauth "example.com/my-golang-sample/app/internal/domain/auth"
+	domuser "example.com/my-golang-sample/app/internal/domain/user"
+	"example.com/my-golang-sample/app/internal/infra/security"
+	authuc "example.com/my-golang-sample/app/internal/usecase/auth"
+)
+
+type fakeMFARepo struct {
+	secrets map[int64]*domauth.TOTPSecret
+	codes   map[int64]map[string]bool
+}
+
+func (f *fakeMFARepo) GetTOTP(ctx context.Context, userID int64) (*domauth.TOTPSecret, error) {
+	if s, ok := f.secrets[userID]; ok {
+		cloned := *s
+		return &cloned, nil
+	}
+	return nil, domauth.ErrMFANotEnrolled
+}
+
+func (f *fakeMFARepo) SaveTOTP(ctx context.Context, s *domauth.TOTPSecret) error {
+	cloned := *s
+	f.secrets[s.UserID] = &cloned
+	return nil
+}
+
+func (f *fakeMFARepo) DeleteTOTP(ctx context.Context, userID int64) error {
+	delete(f.secrets, userID)
+	delete(f.codes, userID)
+	return nil
+}
+
+func (f *fakeMFARepo) UseStep(ctx context.Context, userID int64, step int64) (bool, error) {
+	s := f.secrets[userID]
+	if s.LastUsedStep >= step {
+		return false, nil
+	}
+	s.LastUsedStep = step
+	return true, nil
+}
+
+func (f *fakeMFARepo) ReplaceRecoveryCodes(ctx context.Context, userID int64, hashes []string) error {
+	f.codes[userID] = make(map[string]bool)
+	for _, h := range hashes {
+		f.codes[userID][h] = false
+	}
+	return nil
+}
+
+func (f *fakeMFARepo) UseRecoveryCode(ctx context.Context, userID int64, hash string, now time.Time) error {
+	used, ok := f.codes[userID][hash]
+	if !ok || used {
+		return domauth.ErrInvalidMFACode
+	}
+	f.codes[userID][hash] = true
+	return nil
+}
+
+func (f *fakeMFARepo) CountRecoveryCodes(ctx context.Context, userID int64) (int, error) {
+	n := 0
+	for _, used := range f.codes[userID] {
+		if !used {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func setupMFAAPI(t *testing.T) (http.Handler, *security.TOTP) {
+	t.Helper()
+	passwordSvc := security.NewBcryptService(4)
+	hash, err := passwordSvc.Hash("password123")
+	require.NoError(t, err)
+
+	repo := &fakeAccountUserRepo{users: map[string]*domuser.User{
+		"admin@example.com": {ID: 1, Name: "Admin", Email: "admin@example.com", PasswordHash: hash, RoleCode: domuser.RoleCodeAdmin},
+		"jane@example.com":  {ID: 2, Name: "Jane", Email: "jane@example.com", PasswordHash: hash, RoleCode: domuser.RoleCodeCustomer},
+	}}
+	totp := security.NewTOTP("test")
+	tokenSvc := security.NewJWTService("test-secret", time.Hour)
+	authSvc := authuc.NewService(repo, passwordSvc, tokenSvc, nil,
+		authuc.WithMFA(authuc.MFAConfig{
+			TOTP:          totp,
+			Repo:          &fakeMFARepo{secrets: make(map[int64]*domauth.TOTPSecret), codes: make(map[int64]map[string]bool)},
+			Challenges:    &fakeOneTimeTokenRepo{},
+			RequiredRoles: []domuser.RoleCode{domuser.RoleCodeAdmin, domuser.RoleCodeSuperAdmin},
+		}))
+
+	api := NewAPI(Dependencies{
+		AuthService:  authSvc,
+		TokenService: tokenSvc,
+	})
+	return api.Router(), totp
+}
+
+func decodeBody(t *testing.T, rec *httptest.ResponseRecorder) map[string]any {
+	t.Helper()
+	var body map[string]any
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	return body
+}
+
+func currentCode(t *testing.T, totp *security.TOTP, secret string) string {
+	t.Helper()
+	code, err := totp.Code(secret, time.Now())
+	require.NoError(t, err)
+	return code
+}
+
+func TestMFA_AdminEnrollsDuringLoginThenUsesRecoveryCode(t *testing.T) {
+	router, totp := setupMFAAPI(t)
+	login := map[string]string{"email": "admin@example.com", "password": "password123"}
+
+	rec := postSessionJSON(router, "/api/v1/auth/login", "", login)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	body := decodeBody(t, rec)
+	require.Equal(t, true, body["mfa_required"])
+	require.Equal(t, true, body["mfa_enrollment_required"])
+	require.NotContains(t, body, "token")
+
+	rec = postSessionJSON(router, "/api/v1/auth/2fa/enroll", "", map[string]any{"challenge_token": body["challenge_token"]})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	enrollment := decodeBody(t, rec)
+	uri, err := url.Parse(enrollment["provisioning_uri"].(string))
+	require.NoError(t, err)
+	require.Equal(t, "otpauth", uri.Scheme)
+	secret := enrollment["secret"].(string)
+	require.Equal(t, secret, uri.Query().Get("secret"))
+
+	rec = postSessionJSON(router, "/api/v1/auth/2fa/verify", "", map[string]any{
+		"challenge_token": enrollment["challenge_token"],
+		"code":            currentCode(t, totp, secret),
+	})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	body = decodeBody(t, rec)
+	require.NotEmpty(t, body["token"])
+	codes := body["recovery_codes"].([]any)
+	require.Len(t, codes, 10)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/me/2fa", nil)
+	req.Header.Set("Authorization", "Bearer "+body["token"].(string))
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.Contains(t, rec.Body.String(), `"enabled":true`)
+
+	// The next login needs the second factor; the TOTP code of this step is
+	// already spent, so a recovery code is used instead.
+	rec = postSessionJSON(router, "/api/v1/auth/login", "", login)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	body = decodeBody(t, rec)
+	require.Equal(t, true, body["mfa_required"])
+	require.Equal(t, false, body["mfa_enrollment_required"])
+
+	rec = postSessionJSON(router, "/api/v1/auth/2fa/verify", "", map[string]any{
+		"challenge_token": body["challenge_token"],
+		"recovery_code":   codes[0],
+	})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	adminToken := decodeBody(t, rec)["token"].(string)
+
+	rec = postSessionJSON(router, "/api/v1/me/2fa/disable", adminToken, map[string]string{"code": "123456"})
+	require.Equal(t, http.StatusForbidden, rec.Code, rec.Body.String())
+}
+
+func TestMFA_CustomerOptIn(t *testing.T) {
+	router, totp := setupMFAAPI(t)
+	login := map[string]string{"email": "jane@example.com", "password": "password123"}
+
+	rec := postSessionJSON(router, "/api/v1/auth/login", "", login)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	token := decodeBody(t, rec)["token"].(string)
+	require.NotEmpty(t, token, "2FA is optional for customers")
+
+	rec = postSessionJSON(router, "/api/v1/me/2fa/enroll", token, nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	secret := decodeBody(t, rec)["secret"].(string)
+
+	rec = postSessionJSON(router, "/api/v1/me/2fa/confirm", token, map[string]string{"code": "12345"})
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = postSessionJSON(router, "/api/v1/me/2fa/confirm", token, map[string]string{"code": currentCode(t, totp, secret)})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.Len(t, decodeBody(t, rec)["recovery_codes"], 10)
+
+	rec = postSessionJSON(router, "/api/v1/me/2fa/enroll", token, nil)
+	require.Equal(t, http.StatusConflict, rec.Code)
+
+	rec = postSessionJSON(router, "/api/v1/auth/login", "", login)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	body := decodeBody(t, rec)
+	require.Equal(t, true, body["mfa_required"])
+	require.NotContains(t, body, "token")
+
+	rec = postSessionJSON(router, "/api/v1/auth/2fa/verify", "", map[string]any{
+		"challenge_token": body["challenge_token"],
+		"code":            "000000",
+	})
+	require.Equal(t, http.StatusUnauthorized, rec.Code)
+}
@@ -0,0 +1,166 @@
+package http
+
+import (
+	"net/http"
+
+	authuc "example.com/my-golang-sample/app/internal/usecase/auth"
+)
+
+type verifyMFARequest struct {
+	ChallengeToken string `json:"challenge_token" validate:"required"`
+	Code           string `json:"code" validate:"required_without=RecoveryCode,omitempty,len=6,numeric"`
+	RecoveryCode   string `json:"recovery_code" validate:"required_without=Code"`
+}
+
+// handleVerifyMFA is the second login step after a password login answered
+// with mfa_required.
+func (a *API) handleVerifyMFA(w http.ResponseWriter, r *http.Request) {
+	var req verifyMFARequest
+	if err := a.decodeAndValidate(r, &req); err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	guestToken := guestCartToken(r)
+	result, err := a.authSvc.VerifyMFA(r.Context(), authuc.VerifyMFAInput{
+		ChallengeToken: req.ChallengeToken,
+		Code:           req.Code,
+		RecoveryCode:   req.RecoveryCode,
+		GuestCartToken: guestToken,
+		IP:             clientIP(r),
+	})
+	if err != nil {
+		setRetryAfter(w, err)
+		handleDomainError(w, err)
+		return
+	}
+	if guestToken != "" && !result.CartMergeFailed {
+		clearGuestCartToken(w)
+	}
+	writeJSON(w, http.StatusOK, mapLoginResult(result))
+}
+
+type challengeRequest struct {
+	ChallengeToken string `json:"challenge_token" validate:"required"`
+}
+
+// handleEnrollMFAWithChallenge lets a user whose role requires 2FA set it
+// up while logging in. The returned challenge_token is then used with the
+// first code on /auth/2fa/verify.
+func (a *API) handleEnrollMFAWithChallenge(w http.ResponseWriter, r *http.Request) {
+	var req challengeRequest
+	if err := a.decodeAndValidate(r, &req); err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+	enrollment, err := a.authSvc.EnrollMFAWithChallenge(r.Context(), req.ChallengeToken)
+	if err != nil {
+		handleDomainError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, mapMFAEnrollment(enrollment))
+}
+
+func (a *API) handleGetMFAStatus(w http.ResponseWriter, r *http.Request) {
+	user := getAuthUser(r.Context())
+	if user == nil {
+		respondError(w, http.StatusUnauthorized, errUnauthenticated)
+		return
+	}
+	status, err := a.authSvc.MFAStatus(r.Context(), user.UserID)
+	if err != nil {
+		handleDomainError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"enabled":              status.Enabled,
+		"required":             status.Required,
+		"pending_confirmation": status.PendingConfirmation,
+		"recovery_codes_left":  status.RecoveryCodesLeft,
+	})
+}
+
+func (a *API) handleStartMFAEnrollment(w http.ResponseWriter, r *http.Request) {
+	user := getAuthUser(r.Context())
+	if user == nil {
+		respondError(w, http.StatusUnauthorized, errUnauthenticated)
+		return
+	}
+	enrollment, err := a.authSvc.StartMFAEnrollment(r.Context(), user.UserID)
+	if err != nil {
+		handleDomainError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, mapMFAEnrollment(enrollment))
+}
+
+type mfaCodeRequest struct {
+	Code string `json:"code" validate:"required,len=6,numeric"`
+}
+
+func (a *API) handleConfirmMFA(w http.ResponseWriter, r *http.Request) {
+	user := getAuthUser(r.Context())
+	if user == nil {
+		respondError(w, http.StatusUnauthorized, errUnauthenticated)
+		return
+	}
+	var req mfaCodeRequest
+	if err := a.decodeAndValidate(r, &req); err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+	codes, err := a.authSvc.ConfirmMFA(r.Context(), user.UserID, req.Code)
+	if err != nil {
+		handleDomainError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"recovery_codes": codes})
+}
+
+func (a *API) handleDisableMFA(w http.ResponseWriter, r *http.Request) {
+	user := getAuthUser(r.Context())
+	if user == nil {
+		respondError(w, http.StatusUnauthorized, errUnauthenticated)
+		return
+	}
+	var req mfaCodeRequest
+	if err := a.decodeAndValidate(r, &req); err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := a.authSvc.DisableMFA(r.Context(), user.UserID, req.Code); err != nil {
+		handleDomainError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (a *API) handleRegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	user := getAuthUser(r.Context())
+	if user == nil {
+		respondError(w, http.StatusUnauthorized, errUnauthenticated)
+		return
+	}
+	var req mfaCodeRequest
+	if err := a.decodeAndValidate(r, &req); err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+	codes, err := a.authSvc.RegenerateRecoveryCodes(r.Context(), user.UserID, req.Code)
+	if err != nil {
+		handleDomainError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"recovery_codes": codes})
+}
+
+func mapMFAEnrollment(e *authuc.MFAEnrollment) map[string]any {
+	resp := map[string]any{
+		"secret":           e.Secret,
+		"provisioning_uri": e.ProvisioningURI,
+	}
+	if e.ChallengeToken != "" {
+		resp["challenge_token"] = e.ChallengeToken
+	}
+	return resp
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -12,7 +13,9 @@ import (
 	"github.com/stretchr/testify/require"
 
 	domcart "example.com/my-golang-sample/app/internal/domain/cart"
+	"example.com/my-golang-sample/app/internal/domain/money"
 	domorder "example.com/my-golang-sample/app/internal/domain/order"
+	"example.com/my-golang-sample/app/internal/domain/page"
 	domuser "example.com/my-golang-sample/app/internal/domain/user"
 	"example.com/my-golang-sample/app/internal/infra/security"
 	orderuc "example.com/my-golang-sample/app/internal/usecase/order"
@@ -36,10 +39,10 @@ func newMockOrderRepository() *mockOrderRepository {
 				UserID:        100,
 				Status:        domorder.StatusPending,
 				PaymentMethod: domorder.PaymentCOD,
-				TotalAmount:   50.0,
+				TotalAmount:   money.MustParse("50.0", "SAR"),
 				Items: []domorder.OrderItem{
-					{ID: 1, OrderID: 1, ProductID: 1, Name: "Product 1", Price: 10.0, Quantity: 2},
-					{ID: 2, OrderID: 1, ProductID: 2, Name: "Product 2", Price: 30.0, Quantity: 1},
+					{ID: 1, OrderID: 1, ProductID: 1, Name: "Product 1", Price: money.MustParse("10.0", "SAR"), Quantity: 2},
+					{ID: 2, OrderID: 1, ProductID: 2, Name: "Product 2", Price: money.MustParse("30.0", "SAR"), Quantity: 1},
 				},
 				CreatedAt: time.Now(),
 			},
@@ -48,9 +51,9 @@ func newMockOrderRepository() *mockOrderRepository {
 				UserID:        101,
 				Status:        domorder.StatusPaid,
 				PaymentMethod: domorder.PaymentTamara,
-				TotalAmount:   100.0,
+				TotalAmount:   money.MustParse("100.0", "SAR"),
 				Items: []domorder.OrderItem{
-					{ID: 3, OrderID: 2, ProductID: 3, Name: "Product 3", Price: 100.0, Quantity: 1},
+					{ID: 3, OrderID: 2, ProductID: 3, Name: "Product 3", Price: money.MustParse("100.0", "SAR"), Quantity: 1},
 				},
 				CreatedAt: time.Now(),
 			},
@@ -59,9 +62,9 @@ func newMockOrderRepository() *mockOrderRepository {
 				UserID:        102,
 				Status:        domorder.StatusShipped,
 				PaymentMethod: domorder.PaymentCOD,
-				TotalAmount:   75.0,
+				TotalAmount:   money.MustParse("75.0", "SAR"),
 				Items: []domorder.OrderItem{
-					{ID: 4, OrderID: 3, ProductID: 4, Name: "Product 4", Price: 75.0, Quantity: 1},
+					{ID: 4, OrderID: 3, ProductID: 4, Name: "Product 4", Price: money.MustParse("75.0", "SAR"), Quantity: 1},
 				},
 				CreatedAt: time.Now(),
 			},
@@ -70,20 +73,20 @@ func newMockOrderRepository() *mockOrderRepository {
 	}
 }
 
-func (m *mockOrderRepository) CreateFromCart(ctx context.Context, userID int64, items []domcart.Item, payment domorder.PaymentMethod) (*domorder.Order, error) {
+func (m *mockOrderRepository) CreateFromCart(ctx context.Context, userID int64, items []domcart.Item, payment domorder.PaymentMethod, currency string) (*domorder.Order, error) {
 	return nil, nil
 }
 
-func (m *mockOrderRepository) List(ctx context.Context) ([]*domorder.Order, error) {
+func (m *mockOrderRepository) List(ctx context.Context, filter domorder.ListFilter, req page.Request) ([]*domorder.Order, page.Info, error) {
 	if m.listErr != nil {
-		return nil, m.listErr
+		return nil, page.Info{}, m.listErr
 	}
 	var result []*domorder.Order
 	for _, order := range m.orders {
 		cloned := *order
 		result = append(result, &cloned)
 	}
-	return result, nil
+	return result, page.Info{Total: int64(len(result)), Page: req.Page, Size: req.Size}, nil
 }
 
 func (m *mockOrderRepository) GetByID(ctx context.Context, id int64) (*domorder.Order, error) {
@@ -97,22 +100,48 @@ func (m *mockOrderRepository) GetByID(ctx context.Context, id int64) (*domorder.
 	return nil, domorder.ErrOrderNotFound
 }
 
-func (m *mockOrderRepository) UpdateStatus(ctx context.Context, id int64, status domorder.Status) (*domorder.Order, error) {
+func (m *mockOrderRepository) ListByUser(ctx context.Context, userID int64, req page.Request) ([]*domorder.Order, page.Info, error) {
+	if m.listErr != nil {
+		return nil, page.Info{}, m.listErr
+	}
+	var result []*domorder.Order
+	for _, order := range m.orders {
+		if order.UserID == userID {
+			cloned := *order
+			result = append(result, &cloned)
+		}
+	}
+	return result, page.Info{Total: int64(len(result)), Page: req.Page, Size: req.Size}, nil
+}
+
+func (m *mockOrderRepository) GetByIDForUser(ctx context.Context, id, userID int64) (*domorder.Order, error) {
+	if m.getErr != nil {
+		return nil, m.getErr
+	}
+	if order, ok := m.orders[id]; ok && order.UserID == userID {
+		cloned := *order
+		return &cloned, nil
+	}
+	return nil, domorder.ErrOrderNotFound
+}
+
+func (m *mockOrderRepository) UpdateStatus(ctx context.Context, change domorder.StatusChange) (*domorder.Order, error) {
 	if m.updateErr != nil {
 		return nil, m.updateErr
 	}
-	order, ok := m.orders[id]
+	order, ok := m.orders[change.OrderID]
 	if !ok {
 		return nil, domorder.ErrOrderNotFound
 	}
-	if !status.IsValid() {
-		return nil, domorder.ErrInvalidStatus
-	}
-	order.Status = status
+	order.Status = change.To
 	cloned := *order
 	return &cloned, nil
 }
 
+func (m *mockOrderRepository) ListStatusHistory(ctx context.Context, orderID int64) ([]domorder.StatusHistory, error) {
+	return nil, nil
+}
+
 // --- Helper Functions ---
 
 func setupOrderAPIWithRole(roleCode domuser.RoleCode, userID int64) (*API, string) {
@@ -300,7 +329,7 @@ func TestAdminUpdateOrderStatus_Valid_Returns200(t *testing.T) {
 				"status": tt.newStatus,
 			}
 
-			req := newAuthenticatedOrderRequest(http.MethodPatch, "/api/v1/admin/orders/1", token, body)
+			req := newAuthenticatedOrderRequest(http.MethodPatch, fmt.Sprintf("/api/v1/admin/orders/%d", tt.orderID), token, body)
 			rec := httptest.NewRecorder()
 
 			router.ServeHTTP(rec, req)
@@ -311,7 +340,7 @@ func TestAdminUpdateOrderStatus_Valid_Returns200(t *testing.T) {
 			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
 
 			require.Equal(t, tt.newStatus, response["status"], "status should be updated")
-			require.Equal(t, float64(1), response["id"], "order id should remain the same")
+			require.Equal(t, float64(tt.orderID), response["id"], "order id should remain the same")
 		})
 	}
 }
@@ -528,23 +557,27 @@ func TestAdminOrders_Unauthenticated_Returns401(t *testing.T) {
 }
 
 func TestAdminUpdateOrderStatus_AllValidStatuses(t *testing.T) {
-	validStatuses := []string{
-		"PENDING",
-		"PAID",
-		"SHIPPED",
-		"CANCELED",
+	// Each target status is reached from an order whose current status allows it
+	tests := []struct {
+		status  string
+		orderID int64
+	}{
+		{status: "PAID", orderID: 1},
+		{status: "CANCELED", orderID: 1},
+		{status: "SHIPPED", orderID: 2},
+		{status: "DELIVERED", orderID: 3},
 	}
 
-	for _, status := range validStatuses {
-		t.Run(status, func(t *testing.T) {
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
 			api, token := setupOrderAPIWithRole(domuser.RoleCodeSuperAdmin, 1)
 			router := api.Router()
 
 			body := map[string]any{
-				"status": status,
+				"status": tt.status,
 			}
 
-			req := newAuthenticatedOrderRequest(http.MethodPatch, "/api/v1/admin/orders/1", token, body)
+			req := newAuthenticatedOrderRequest(http.MethodPatch, fmt.Sprintf("/api/v1/admin/orders/%d", tt.orderID), token, body)
 			rec := httptest.NewRecorder()
 
 			router.ServeHTTP(rec, req)
@@ -553,7 +586,7 @@ func TestAdminUpdateOrderStatus_AllValidStatuses(t *testing.T) {
 
 			var response map[string]any
 			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
-			require.Equal(t, status, response["status"], "status should be updated to "+status)
+			require.Equal(t, tt.status, response["status"], "status should be updated to "+tt.status)
 		})
 	}
 }
@@ -0,0 +1,67 @@
+package http
+
+import "net/http"
+
+func (a *API) handleListMyOrders(w http.ResponseWriter, r *http.Request) {
+	user := getAuthUser(r.Context())
+	if user == nil {
+		respondError(w, http.StatusUnauthorized, errUnauthenticated)
+		return
+	}
+
+	pageReq, err := parsePageRequest(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+	orders, info, err := a.orderSvc.ListForUser(r.Context(), user.UserID, pageReq)
+	if err != nil {
+		handleDomainError(w, err)
+		return
+	}
+	resp := make([]map[string]any, 0, len(orders))
+	for _, o := range orders {
+		resp = append(resp, mapOrder(o))
+	}
+	writePage(w, resp, info)
+}
+
+func (a *API) handleGetMyOrder(w http.ResponseWriter, r *http.Request) {
+	user := getAuthUser(r.Context())
+	if user == nil {
+		respondError(w, http.StatusUnauthorized, errUnauthenticated)
+		return
+	}
+	id, err := parseIDParam(r, "id")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	order, err := a.orderSvc.GetForUser(r.Context(), id, user.UserID)
+	if err != nil {
+		handleDomainError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, mapOrder(order))
+}
+
+func (a *API) handleCancelMyOrder(w http.ResponseWriter, r *http.Request) {
+	user := getAuthUser(r.Context())
+	if user == nil {
+		respondError(w, http.StatusUnauthorized, errUnauthenticated)
+		return
+	}
+	id, err := parseIDParam(r, "id")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	order, err := a.orderSvc.CancelForUser(r.Context(), id, user.UserID)
+	if err != nil {
+		handleDomainError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, mapOrder(order))
+}
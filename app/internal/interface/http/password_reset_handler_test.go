@@ -0,0 +1,101 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// registerVerified signs up jane@example.com and opens the verification link.
+func registerVerified(t *testing.T, router http.Handler, mailer *fakeMailer) {
+	t.Helper()
+	rec := postSessionJSON(router, "/api/v1/auth/register", "", map[string]string{
+		"name":     "Jane",
+		"email":    "jane@example.com",
+		"password": "password123",
+	})
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, mailer.lastLink(t).RequestURI(), nil))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+}
+
+func TestPasswordReset_ChangesPasswordAndEndsSessions(t *testing.T) {
+	router, _, mailer := setupRegisterAPI(t)
+	registerVerified(t, router, mailer)
+
+	rec := postSessionJSON(router, "/api/v1/auth/login", "", map[string]string{"email": "jane@example.com", "password": "password123"})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var login struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &login))
+	refresh := login.RefreshToken
+
+	rec = postSessionJSON(router, "/api/v1/auth/password/forgot", "", map[string]string{"email": "jane@example.com"})
+	require.Equal(t, http.StatusAccepted, rec.Code, rec.Body.String())
+	link := mailer.lastLink(t)
+	require.Equal(t, "/reset-password", link.Path)
+	token := link.Query().Get("token")
+	require.NotEmpty(t, token)
+
+	rec = postSessionJSON(router, "/api/v1/auth/password/reset", "", map[string]string{"token": token, "password": "new-password"})
+	require.Equal(t, http.StatusNoContent, rec.Code, rec.Body.String())
+
+	rec = postSessionJSON(router, "/api/v1/auth/refresh", "", map[string]string{"refresh_token": refresh})
+	require.Equal(t, http.StatusUnauthorized, rec.Code, "sessions from before the reset are ended")
+
+	rec = postSessionJSON(router, "/api/v1/auth/login", "", map[string]string{"email": "jane@example.com", "password": "password123"})
+	require.Equal(t, http.StatusUnauthorized, rec.Code)
+	rec = postSessionJSON(router, "/api/v1/auth/login", "", map[string]string{"email": "jane@example.com", "password": "new-password"})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	// The token was used up
+	rec = postSessionJSON(router, "/api/v1/auth/password/reset", "", map[string]string{"token": token, "password": "another-password"})
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestPasswordReset_ForgotAcceptsUnknownEmail(t *testing.T) {
+	router, _, mailer := setupRegisterAPI(t)
+
+	rec := postSessionJSON(router, "/api/v1/auth/password/forgot", "", map[string]string{"email": "nobody@example.com"})
+
+	require.Equal(t, http.StatusAccepted, rec.Code)
+	require.Empty(t, mailer.bodies)
+}
+
+func TestPasswordReset_ForgotHidesMailerFailure(t *testing.T) {
+	router, _, mailer := setupRegisterAPI(t)
+	registerVerified(t, router, mailer)
+	mailer.err = errors.New("smtp down")
+
+	known := postSessionJSON(router, "/api/v1/auth/password/forgot", "", map[string]string{"email": "jane@example.com"})
+	unknown := postSessionJSON(router, "/api/v1/auth/password/forgot", "", map[string]string{"email": "nobody@example.com"})
+
+	require.Equal(t, http.StatusAccepted, known.Code, known.Body.String())
+	require.Equal(t, unknown.Code, known.Code, "a registered email must not be told apart")
+}
+
+func TestPasswordReset_InvalidRequests(t *testing.T) {
+	router, _, _ := setupRegisterAPI(t)
+
+	tests := []struct {
+		name string
+		body map[string]string
+	}{
+		{"unknown token", map[string]string{"token": "bogus", "password": "new-password"}},
+		{"missing token", map[string]string{"password": "new-password"}},
+		{"short password", map[string]string{"token": "bogus", "password": "short"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := postSessionJSON(router, "/api/v1/auth/password/reset", "", tt.body)
+			require.Equal(t, http.StatusBadRequest, rec.Code, rec.Body.String())
+		})
+	}
+}
@@ -13,8 +13,11 @@ import (
 	"github.com/stretchr/testify/require"
 
 	domcategory "example.com/my-golang-sample/app/internal/domain/category"
+	"example.com/my-golang-sample/app/internal/domain/money"
+	"example.com/my-golang-sample/app/internal/domain/page"
 	domproduct "example.com/my-golang-sample/app/internal/domain/product"
 	domuser "example.com/my-golang-sample/app/internal/domain/user"
+	"example.com/my-golang-sample/app/internal/infra/search"
 	"example.com/my-golang-sample/app/internal/infra/security"
 	authuc "example.com/my-golang-sample/app/internal/usecase/auth"
 	categoryuc "example.com/my-golang-sample/app/internal/usecase/category"
@@ -50,7 +53,7 @@ func (m *mockProductRepository) Create(ctx context.Context, p *domproduct.Produc
 	if p.Name == "" {
 		return nil, fmt.Errorf("product name is required")
 	}
-	if p.Price <= 0 {
+	if !p.Price.IsPositive() {
 		return nil, fmt.Errorf("product price must be greater than 0")
 	}
 	if p.Stock < 0 {
@@ -90,7 +93,7 @@ func (m *mockProductRepository) Update(ctx context.Context, p *domproduct.Produc
 	if p.Description != "" {
 		existing.Description = p.Description
 	}
-	if p.Price > 0 {
+	if p.Price.IsPositive() {
 		existing.Price = p.Price
 	}
 	if p.Stock >= 0 {
@@ -124,9 +127,9 @@ func (m *mockProductRepository) GetByID(ctx context.Context, id int64) (*domprod
 	return nil, domproduct.ErrProductNotFound
 }
 
-func (m *mockProductRepository) List(ctx context.Context, filter domproduct.ListFilter) ([]*domproduct.Product, error) {
+func (m *mockProductRepository) List(ctx context.Context, filter domproduct.ListFilter, req page.Request) ([]*domproduct.Product, page.Info, error) {
 	if m.listErr != nil {
-		return nil, m.listErr
+		return nil, page.Info{}, m.listErr
 	}
 	var result []*domproduct.Product
 	for _, p := range m.products {
@@ -136,6 +139,9 @@ func (m *mockProductRepository) List(ctx context.Context, filter domproduct.List
 		if filter.CategoryID != nil && p.CategoryID != *filter.CategoryID {
 			continue
 		}
+		if _, err := p.PriceIn(filter.Currency); err != nil {
+			continue
+		}
 		if filter.Search != "" {
 			// Simple search - check if search term is in name or description
 			if !contains(p.Name, filter.Search) && !contains(p.Description, filter.Search) {
@@ -145,7 +151,7 @@ func (m *mockProductRepository) List(ctx context.Context, filter domproduct.List
 		cloned := *p
 		result = append(result, &cloned)
 	}
-	return result, nil
+	return result, page.Info{Total: int64(len(result)), Page: req.Page, Size: req.Size}, nil
 }
 
 func (m *mockProductRepository) GetByIDs(ctx context.Context, ids []int64) ([]*domproduct.Product, error) {
@@ -159,6 +165,35 @@ func (m *mockProductRepository) GetByIDs(ctx context.Context, ids []int64) ([]*d
 	return result, nil
 }
 
+func (m *mockProductRepository) SetPrice(ctx context.Context, productID int64, price money.Money) error {
+	p, ok := m.products[productID]
+	if !ok {
+		return domproduct.ErrProductNotFound
+	}
+	for i, existing := range p.Prices {
+		if existing.Currency == price.Currency {
+			p.Prices[i] = price
+			return nil
+		}
+	}
+	p.Prices = append(p.Prices, price)
+	return nil
+}
+
+func (m *mockProductRepository) DeletePrice(ctx context.Context, productID int64, currency string) error {
+	p, ok := m.products[productID]
+	if !ok {
+		return domproduct.ErrProductNotFound
+	}
+	for i, existing := range p.Prices {
+		if existing.Currency == currency {
+			p.Prices = append(p.Prices[:i], p.Prices[i+1:]...)
+			return nil
+		}
+	}
+	return domproduct.ErrPriceNotFound
+}
+
 func contains(s, substr string) bool {
 	if len(substr) == 0 {
 		return true
@@ -218,7 +253,7 @@ func (m *mockCategoryRepository) GetByID(ctx context.Context, id int64) (*domcat
 	return nil, domcategory.ErrCategoryNotFound
 }
 
-func (m *mockCategoryRepository) List(ctx context.Context, filter domcategory.ListFilter) ([]*domcategory.Category, error) {
+func (m *mockCategoryRepository) List(ctx context.Context, filter domcategory.ListFilter, req page.Request) ([]*domcategory.Category, page.Info, error) {
 	var result []*domcategory.Category
 	for _, c := range m.categories {
 		if filter.OnlyActive && !c.IsActive {
@@ -227,7 +262,7 @@ func (m *mockCategoryRepository) List(ctx context.Context, filter domcategory.Li
 		cloned := *c
 		result = append(result, &cloned)
 	}
-	return result, nil
+	return result, page.Info{Total: int64(len(result)), Page: req.Page, Size: req.Size}, nil
 }
 
 // Setup function to create API with product and category services
@@ -244,7 +279,7 @@ func setupProductAPI(productRepo *mockProductRepository, categoryRepo *mockCateg
 		// Create auth service for authenticated requests
 		userRepo := &fakeAuthUserRepo{}
 		passwordSvc := fakePasswordService{}
-		authSvc := authuc.NewService(userRepo, passwordSvc, tokenSvc)
+		authSvc := authuc.NewService(userRepo, passwordSvc, tokenSvc, nil)
 
 		api = NewAPI(Dependencies{
 			ProductService:  productSvc,
@@ -288,7 +323,7 @@ func TestGuestListProducts_Returns200(t *testing.T) {
 	productRepo.Create(context.Background(), &domproduct.Product{
 		Name:        "Laptop",
 		Description: "High-performance laptop",
-		Price:       999.99,
+		Price:       money.MustParse("999.99", "SAR"),
 		Stock:       10,
 		CategoryID:  category.ID,
 		IsActive:    true,
@@ -296,7 +331,7 @@ func TestGuestListProducts_Returns200(t *testing.T) {
 	productRepo.Create(context.Background(), &domproduct.Product{
 		Name:        "Mouse",
 		Description: "Wireless mouse",
-		Price:       29.99,
+		Price:       money.MustParse("29.99", "SAR"),
 		Stock:       50,
 		CategoryID:  category.ID,
 		IsActive:    true,
@@ -337,7 +372,7 @@ func TestGuestGetProduct_Found_Returns200(t *testing.T) {
 	created, _ := productRepo.Create(context.Background(), &domproduct.Product{
 		Name:        "Laptop",
 		Description: "High-performance laptop",
-		Price:       999.99,
+		Price:       money.MustParse("999.99", "SAR"),
 		Stock:       10,
 		CategoryID:  category.ID,
 		IsActive:    true,
@@ -419,8 +454,13 @@ func TestAdminCreateProduct_ValidPayload_Returns201(t *testing.T) {
 	require.NoError(t, err, "response should be valid JSON")
 	require.Equal(t, "New Product", product["name"])
 	require.Equal(t, 99.99, product["price"])
+	require.Equal(t, "SAR", product["currency"])
 	require.Equal(t, float64(10), product["stock"])
 	require.Equal(t, float64(category.ID), product["category_id"])
+
+	// Stored exactly, in minor units
+	stored := productRepo.products[int64(product["id"].(float64))]
+	require.Equal(t, money.New(9999, "SAR"), stored.Price)
 }
 
 // Test 5: Admin Create Product Invalid Price
@@ -437,6 +477,10 @@ func TestAdminCreateProduct_InvalidPrice_Returns422(t *testing.T) {
 			name:  "Negative price",
 			price: -10.50,
 		},
+		{
+			name:  "More decimals than the currency",
+			price: 9.999,
+		},
 	}
 
 	for _, tt := range tests {
@@ -557,7 +601,7 @@ func TestAdminDeleteProduct_Success(t *testing.T) {
 
 	created, _ := productRepo.Create(context.Background(), &domproduct.Product{
 		Name:        "To Delete",
-		Price:       99.99,
+		Price:       money.MustParse("99.99", "SAR"),
 		Stock:       10,
 		CategoryID:  category.ID,
 		IsActive:    true,
@@ -657,14 +701,14 @@ func TestGuestListProducts_OnlyActive(t *testing.T) {
 	// Create active and inactive products
 	productRepo.Create(context.Background(), &domproduct.Product{
 		Name:        "Active Product",
-		Price:       99.99,
+		Price:       money.MustParse("99.99", "SAR"),
 		Stock:       10,
 		CategoryID:  category.ID,
 		IsActive:    true,
 	})
 	productRepo.Create(context.Background(), &domproduct.Product{
 		Name:        "Inactive Product",
-		Price:       49.99,
+		Price:       money.MustParse("49.99", "SAR"),
 		Stock:       5,
 		CategoryID:  category.ID,
 		IsActive:    false,
@@ -804,7 +848,7 @@ func TestAdminUpdateProduct_Success(t *testing.T) {
 	created, _ := productRepo.Create(context.Background(), &domproduct.Product{
 		Name:        "Original Product",
 		Description: "Original description",
-		Price:       99.99,
+		Price:       money.MustParse("99.99", "SAR"),
 		Stock:       10,
 		CategoryID:  category.ID,
 		IsActive:    true,
@@ -878,14 +922,14 @@ func TestGuestListProducts_WithCategoryFilter(t *testing.T) {
 
 	productRepo.Create(context.Background(), &domproduct.Product{
 		Name:        "Laptop",
-		Price:       999.99,
+		Price:       money.MustParse("999.99", "SAR"),
 		Stock:       10,
 		CategoryID:  category1.ID,
 		IsActive:    true,
 	})
 	productRepo.Create(context.Background(), &domproduct.Product{
 		Name:        "T-Shirt",
-		Price:       29.99,
+		Price:       money.MustParse("29.99", "SAR"),
 		Stock:       50,
 		CategoryID:  category2.ID,
 		IsActive:    true,
@@ -910,3 +954,160 @@ func TestGuestListProducts_WithCategoryFilter(t *testing.T) {
 	require.Equal(t, "Laptop", product["name"])
 }
 
+func seedPricedProducts(t *testing.T, productRepo *mockProductRepository) {
+	t.Helper()
+	productRepo.validCategoryIDs[1] = true
+	laptop, err := productRepo.Create(context.Background(), &domproduct.Product{
+		Name: "Laptop", Price: money.MustParse("999.99", "SAR"), Stock: 10, CategoryID: 1, IsActive: true,
+	})
+	require.NoError(t, err)
+	laptop.Prices = []money.Money{money.MustParse("269.99", "USD")}
+	_, err = productRepo.Create(context.Background(), &domproduct.Product{
+		Name: "Mouse", Price: money.MustParse("29.99", "SAR"), Stock: 50, CategoryID: 1, IsActive: true,
+	})
+	require.NoError(t, err)
+}
+
+func TestGuestListProducts_InCurrency(t *testing.T) {
+	productRepo := newMockProductRepository()
+	seedPricedProducts(t, productRepo)
+
+	api, _ := setupProductAPI(productRepo, newMockCategoryRepository(), nil)
+	router := api.Router()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/products?currency=usd", nil)
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	var response map[string]any
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	data := response["data"].([]any)
+	require.Len(t, data, 1, "products without a USD price are not listed")
+	product := data[0].(map[string]any)
+	require.Equal(t, "Laptop", product["name"])
+	require.Equal(t, "USD", product["currency"])
+	require.Equal(t, 269.99, product["price"])
+	require.Len(t, product["prices"], 2)
+}
+
+func TestGuestListProducts_InvalidCurrency_Returns400(t *testing.T) {
+	api, _ := setupProductAPI(newMockProductRepository(), newMockCategoryRepository(), nil)
+	router := api.Router()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/products?currency=dollars", nil)
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestGuestGetProduct_NotSoldInCurrency_Returns422(t *testing.T) {
+	productRepo := newMockProductRepository()
+	seedPricedProducts(t, productRepo)
+
+	api, _ := setupProductAPI(productRepo, newMockCategoryRepository(), nil)
+	router := api.Router()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/products/2?currency=USD", nil)
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+}
+
+func TestAdminSetAndDeleteProductPrice(t *testing.T) {
+	productRepo := newMockProductRepository()
+	seedPricedProducts(t, productRepo)
+
+	role := domuser.RoleCodeSuperAdmin
+	api, token := setupProductAPI(productRepo, newMockCategoryRepository(), &role)
+	router := api.Router()
+
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/admin/products/2/prices/eur", bytes.NewReader([]byte(`{"price": 7.5}`)))
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.Equal(t, []money.Money{money.MustParse("7.50", "EUR")}, productRepo.products[2].Prices)
+
+	req = httptest.NewRequest(http.MethodDelete, "/api/v1/admin/products/2/prices/SAR", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusUnprocessableEntity, rec.Code, "base price cannot be removed")
+
+	req = httptest.NewRequest(http.MethodDelete, "/api/v1/admin/products/2/prices/EUR", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusNoContent, rec.Code)
+	require.Empty(t, productRepo.products[2].Prices)
+}
+
+func TestAdminSetProductPrice_RejectsThreeDecimalCurrency(t *testing.T) {
+	productRepo := newMockProductRepository()
+	seedPricedProducts(t, productRepo)
+
+	role := domuser.RoleCodeSuperAdmin
+	api, token := setupProductAPI(productRepo, newMockCategoryRepository(), &role)
+	router := api.Router()
+
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/admin/products/2/prices/KWD", bytes.NewReader([]byte(`{"price": 82.125}`)))
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusBadRequest, rec.Code, rec.Body.String())
+	require.Empty(t, productRepo.products[2].Prices, "nothing is stored rounded")
+}
+
+func TestGuestListProducts_SearchRanksByRelevance(t *testing.T) {
+	sar := money.MustParse("100", "SAR")
+	index := search.NewMemoryIndex(
+		&domproduct.Product{ID: 1, Name: "Keyboard Stand", Description: "Aluminium", Price: sar, IsActive: true},
+		&domproduct.Product{ID: 2, Name: "Wireless Keyboard", Description: "Bluetooth keyboard", Price: sar, IsActive: true},
+		&domproduct.Product{ID: 3, Name: "Desk Mat", Description: "Fits a full keyboard", Price: sar, IsActive: true},
+		&domproduct.Product{ID: 4, Name: "Keyboard Cover", Description: "Discontinued", Price: sar, IsActive: false},
+	)
+	api := NewAPI(Dependencies{
+		ProductService: productuc.NewService(newMockProductRepository(), productuc.WithSearcher(index)),
+	})
+	router := api.Router()
+	get := func(query string) (int, map[string]any) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/products"+query, nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		var response map[string]any
+		_ = json.Unmarshal(rec.Body.Bytes(), &response)
+		return rec.Code, response
+	}
+	ids := func(response map[string]any) []float64 {
+		var ids []float64
+		for _, item := range response["data"].([]any) {
+			ids = append(ids, item.(map[string]any)["id"].(float64))
+		}
+		return ids
+	}
+
+	code, response := get("?search=wireless+keybaord")
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, []float64{2, 1, 3}, ids(response), "inactive products stay hidden")
+	require.Equal(t, float64(3), response["total"])
+	require.Nil(t, response["next_cursor"])
+
+	code, response = get("?q=keyboard&size=1&page=2")
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, []float64{1}, ids(response), "q is still accepted")
+
+	code, _ = get("?sort=relevance")
+	require.Equal(t, http.StatusBadRequest, code)
+}
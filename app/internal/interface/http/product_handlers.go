@@ -8,9 +8,15 @@ import (
 )
 
 func (a *API) handleListProducts(w http.ResponseWriter, r *http.Request) {
+	currency, err := a.requestCurrency(r.URL.Query().Get("currency"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
 	filter := domproduct.ListFilter{
 		OnlyActive: true,
-		Search:     r.URL.Query().Get("q"),
+		Search:     searchParam(r),
+		Currency:   currency,
 	}
 	if cid := r.URL.Query().Get("category_id"); cid != "" {
 		if id, err := strconv.ParseInt(cid, 10, 64); err == nil {
@@ -18,7 +24,12 @@ func (a *API) handleListProducts(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	products, err := a.productSvc.List(r.Context(), filter)
+	pageReq, err := parsePageRequest(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+	products, info, err := a.productSvc.List(r.Context(), filter, pageReq)
 	if err != nil {
 		handleDomainError(w, err)
 		return
@@ -26,9 +37,22 @@ func (a *API) handleListProducts(w http.ResponseWriter, r *http.Request) {
 
 	resp := make([]map[string]any, 0, len(products))
 	for _, p := range products {
-		resp = append(resp, mapProduct(p))
+		item, err := mapProductIn(p, currency)
+		if err != nil {
+			continue
+		}
+		resp = append(resp, item)
+	}
+	writePage(w, resp, info)
+}
+
+// searchParam reads the product search text from "search", falling back to
+// the older "q".
+func searchParam(r *http.Request) string {
+	if v := r.URL.Query().Get("search"); v != "" {
+		return v
 	}
-	writeJSON(w, http.StatusOK, map[string]any{"data": resp})
+	return r.URL.Query().Get("q")
 }
 
 func (a *API) handleGetProduct(w http.ResponseWriter, r *http.Request) {
@@ -37,17 +61,27 @@ func (a *API) handleGetProduct(w http.ResponseWriter, r *http.Request) {
 		respondError(w, http.StatusBadRequest, err)
 		return
 	}
+	currency, err := a.requestCurrency(r.URL.Query().Get("currency"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
 	p, err := a.productSvc.GetByID(r.Context(), id)
 	if err != nil {
 		handleDomainError(w, err)
 		return
 	}
-	writeJSON(w, http.StatusOK, mapProduct(p))
+	resp, err := mapProductIn(p, currency)
+	if err != nil {
+		handleDomainError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
 }
 
 func (a *API) handleListProductsAdmin(w http.ResponseWriter, r *http.Request) {
 	filter := domproduct.ListFilter{
-		Search: r.URL.Query().Get("q"),
+		Search: searchParam(r),
 	}
 	if cid := r.URL.Query().Get("category_id"); cid != "" {
 		if id, err := strconv.ParseInt(cid, 10, 64); err == nil {
@@ -58,7 +92,12 @@ func (a *API) handleListProductsAdmin(w http.ResponseWriter, r *http.Request) {
 		filter.OnlyActive = true
 	}
 
-	products, err := a.productSvc.List(r.Context(), filter)
+	pageReq, err := parsePageRequest(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+	products, info, err := a.productSvc.List(r.Context(), filter, pageReq)
 	if err != nil {
 		handleDomainError(w, err)
 		return
@@ -68,6 +107,6 @@ func (a *API) handleListProductsAdmin(w http.ResponseWriter, r *http.Request) {
 	for _, p := range products {
 		resp = append(resp, mapProduct(p))
 	}
-	writeJSON(w, http.StatusOK, map[string]any{"data": resp})
+	writePage(w, resp, info)
 }
 
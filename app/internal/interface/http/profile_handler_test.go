@@ -0,0 +1,198 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	domauth "example.com/my-golang-sample/app/internal/domain/auth"
+	domuser "example.com/my-golang-sample/app/internal/domain/user"
+	"example.com/my-golang-sample/app/internal/infra/security"
+	authuc "example.com/my-golang-sample/app/internal/usecase/auth"
+	useruc "example.com/my-golang-sample/app/internal/usecase/user"
+)
+
+func setupProfileAPI(t *testing.T) (http.Handler, *fakeAccountUserRepo, *fakeRefreshTokenRepo, *fakeMailer) {
+	t.Helper()
+	passwordSvc := security.NewBcryptService(4)
+	hash, err := passwordSvc.Hash("password123")
+	require.NoError(t, err)
+
+	verifiedAt := time.Now()
+	repo := &fakeAccountUserRepo{users: map[string]*domuser.User{
+		"jane@example.com": {ID: 2, Name: "Jane", Email: "jane@example.com", PasswordHash: hash, UserRoleID: 3, RoleCode: domuser.RoleCodeCustomer, EmailVerifiedAt: &verifiedAt},
+		"john@example.com": {ID: 3, Name: "John", Email: "john@example.com", PasswordHash: hash, UserRoleID: 3, RoleCode: domuser.RoleCodeCustomer, EmailVerifiedAt: &verifiedAt},
+	}}
+	refresh := &fakeRefreshTokenRepo{}
+	mailer := &fakeMailer{}
+	tokenSvc := security.NewJWTService("test-secret", time.Hour)
+	authSvc := authuc.NewService(repo, passwordSvc, tokenSvc, nil,
+		authuc.WithSessions(refresh, &fakeDenylist{revoked: make(map[string]time.Time)}, time.Hour),
+		authuc.WithAccounts(authuc.AccountConfig{
+			Hasher:    passwordSvc,
+			Tokens:    &fakeOneTimeTokenRepo{},
+			Mailer:    mailer,
+			VerifyURL: "http://localhost:20000/api/v1/auth/verify-email",
+			ResetURL:  "http://localhost:20000/reset-password",
+		}),
+		authuc.WithLockout(&fakeLoginAttemptRepo{counters: make(map[string]*domauth.LoginFailures)}, authuc.LockoutPolicy{MaxAccountFailures: 3}))
+
+	api := NewAPI(Dependencies{
+		AuthService:  authSvc,
+		UserService:  useruc.NewService(repo, passwordSvc, useruc.WithPasswordComparer(passwordSvc), useruc.WithPasswordVerifier(authSvc)),
+		TokenService: tokenSvc,
+	})
+	return api.Router(), repo, refresh, mailer
+}
+
+func loginProfile(t *testing.T, router http.Handler, email, password string) map[string]any {
+	t.Helper()
+	rec := postSessionJSON(router, "/api/v1/auth/login", "", map[string]string{"email": email, "password": password})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	return decodeBody(t, rec)
+}
+
+func sendProfileRequest(router http.Handler, method, token string, body any) *httptest.ResponseRecorder {
+	var payload []byte
+	if body != nil {
+		payload, _ = json.Marshal(body)
+	}
+	req := httptest.NewRequest(method, "/api/v1/me", bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestGetMe(t *testing.T) {
+	router, _, _, _ := setupProfileAPI(t)
+	token := loginProfile(t, router, "jane@example.com", "password123")["token"].(string)
+
+	rec := sendProfileRequest(router, http.MethodGet, token, nil)
+
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	body := decodeBody(t, rec)
+	require.Equal(t, float64(2), body["id"])
+	require.Equal(t, "jane@example.com", body["email"])
+	require.NotContains(t, rec.Body.String(), "password")
+
+	rec = sendProfileRequest(router, http.MethodGet, "", nil)
+	require.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestUpdateMe_ChangesNameAndEmailButNotRole(t *testing.T) {
+	router, repo, _, mailer := setupProfileAPI(t)
+	token := loginProfile(t, router, "jane@example.com", "password123")["token"].(string)
+
+	rec := sendProfileRequest(router, http.MethodPatch, token, map[string]string{
+		"name":             "Jane Doe",
+		"email":            "jane.doe@example.com",
+		"current_password": "password123",
+		"role_code":        "SUPER_ADMIN",
+	})
+
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	body := decodeBody(t, rec)
+	require.Equal(t, "Jane Doe", body["name"])
+	require.Equal(t, "jane.doe@example.com", body["email"])
+	require.Equal(t, string(domuser.RoleCodeCustomer), body["role_code"])
+	require.Equal(t, false, body["email_verified"])
+	require.Equal(t, domuser.RoleCodeCustomer, repo.users["jane.doe@example.com"].RoleCode)
+	require.Equal(t, []string{"jane.doe@example.com"}, mailer.recipients, "verification goes to the new address")
+}
+
+func TestUpdateMe_EmailChangeNeedsCurrentPassword(t *testing.T) {
+	router, repo, _, mailer := setupProfileAPI(t)
+	token := loginProfile(t, router, "jane@example.com", "password123")["token"].(string)
+
+	for _, password := range []string{"", "wrong-password"} {
+		rec := sendProfileRequest(router, http.MethodPatch, token, map[string]string{
+			"email":            "attacker@example.com",
+			"current_password": password,
+		})
+		require.Equal(t, http.StatusUnprocessableEntity, rec.Code, rec.Body.String())
+	}
+	require.Contains(t, repo.users, "jane@example.com")
+	require.NotContains(t, repo.users, "attacker@example.com")
+	require.Empty(t, mailer.recipients)
+
+	rec := sendProfileRequest(router, http.MethodPatch, token, map[string]string{"name": "Jane Doe"})
+	require.Equal(t, http.StatusOK, rec.Code, "other fields need no password")
+	require.Equal(t, true, decodeBody(t, rec)["email_verified"])
+}
+
+func TestUpdateMe_Errors(t *testing.T) {
+	router, _, _, _ := setupProfileAPI(t)
+	token := loginProfile(t, router, "jane@example.com", "password123")["token"].(string)
+
+	rec := sendProfileRequest(router, http.MethodPatch, token, map[string]string{"email": "not-an-email"})
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = sendProfileRequest(router, http.MethodPatch, token, map[string]string{"email": "john@example.com", "current_password": "password123"})
+	require.Equal(t, http.StatusConflict, rec.Code, rec.Body.String())
+}
+
+func TestChangePassword(t *testing.T) {
+	router, _, refresh, _ := setupProfileAPI(t)
+	login := loginProfile(t, router, "jane@example.com", "password123")
+	token := login["token"].(string)
+
+	rec := postSessionJSON(router, "/api/v1/me/password", token, map[string]string{
+		"current_password": "wrong-password",
+		"new_password":     "new-password-456",
+	})
+	require.Equal(t, http.StatusUnprocessableEntity, rec.Code, rec.Body.String())
+
+	rec = postSessionJSON(router, "/api/v1/me/password", token, map[string]string{
+		"current_password": "password123",
+		"new_password":     "short",
+	})
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = postSessionJSON(router, "/api/v1/me/password", token, map[string]string{
+		"current_password": "password123",
+		"new_password":     "new-password-456",
+	})
+	require.Equal(t, http.StatusNoContent, rec.Code, rec.Body.String())
+	for _, rt := range refresh.tokens {
+		require.True(t, rt.IsRevoked(), "other sessions are signed out")
+	}
+	rec = sendProfileRequest(router, http.MethodGet, token, nil)
+	require.Equal(t, http.StatusUnauthorized, rec.Code, "the access token used for the change is cut off too")
+
+	rec = postSessionJSON(router, "/api/v1/auth/login", "", map[string]string{"email": "jane@example.com", "password": "password123"})
+	require.Equal(t, http.StatusUnauthorized, rec.Code)
+	loginProfile(t, router, "jane@example.com", "new-password-456")
+}
+
+func TestChangePassword_WrongPasswordsCountTowardsLockout(t *testing.T) {
+	router, _, _, _ := setupProfileAPI(t)
+	token := loginProfile(t, router, "jane@example.com", "password123")["token"].(string)
+
+	for i := 0; i < 2; i++ {
+		rec := postSessionJSON(router, "/api/v1/me/password", token, map[string]string{
+			"current_password": "wrong-password",
+			"new_password":     "new-password-456",
+		})
+		require.Equal(t, http.StatusUnprocessableEntity, rec.Code, rec.Body.String())
+	}
+	rec := sendProfileRequest(router, http.MethodPatch, token, map[string]string{"email": "jane.doe@example.com", "current_password": "guess"})
+	require.Equal(t, http.StatusTooManyRequests, rec.Code, rec.Body.String())
+	require.NotEmpty(t, rec.Header().Get("Retry-After"))
+
+	rec = postSessionJSON(router, "/api/v1/me/password", token, map[string]string{
+		"current_password": "password123",
+		"new_password":     "new-password-456",
+	})
+	require.Equal(t, http.StatusTooManyRequests, rec.Code, "the right password is refused while locked")
+	rec = postSessionJSON(router, "/api/v1/auth/login", "", map[string]string{"email": "jane@example.com", "password": "password123"})
+	require.Equal(t, http.StatusTooManyRequests, rec.Code, "the lockout is shared with login")
+}
@@ -0,0 +1,99 @@
+package http
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	authuc "example.com/my-golang-sample/app/internal/usecase/auth"
+	useruc "example.com/my-golang-sample/app/internal/usecase/user"
+)
+
+func (a *API) handleGetMe(w http.ResponseWriter, r *http.Request) {
+	user := getAuthUser(r.Context())
+	if user == nil {
+		respondError(w, http.StatusUnauthorized, errUnauthenticated)
+		return
+	}
+	u, err := a.userSvc.GetUser(r.Context(), user.UserID)
+	if err != nil {
+		handleDomainError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, mapUser(u))
+}
+
+// updateProfileRequest has no role_code: a role sent here is ignored.
+// current_password is only needed to change the email.
+type updateProfileRequest struct {
+	Name            *string `json:"name" validate:"omitempty,min=1"`
+	Email           *string `json:"email" validate:"omitempty,email"`
+	CurrentPassword string  `json:"current_password"`
+}
+
+func (a *API) handleUpdateMe(w http.ResponseWriter, r *http.Request) {
+	user := getAuthUser(r.Context())
+	if user == nil {
+		respondError(w, http.StatusUnauthorized, errUnauthenticated)
+		return
+	}
+	var req updateProfileRequest
+	if err := a.decodeAndValidate(r, &req); err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	u, err := a.userSvc.UpdateProfile(r.Context(), useruc.UpdateProfileInput{
+		ID:              user.UserID,
+		Name:            req.Name,
+		Email:           req.Email,
+		CurrentPassword: req.CurrentPassword,
+	})
+	if err != nil {
+		setRetryAfter(w, err)
+		handleDomainError(w, err)
+		return
+	}
+	if req.Email != nil && !u.IsEmailVerified() && a.authSvc != nil {
+		// Email đã đổi xong; gửi mail lỗi thì user có thể yêu cầu gửi lại
+		if err := a.authSvc.SendVerification(r.Context(), u.ID); err != nil && !errors.Is(err, authuc.ErrRegistrationDisabled) {
+			log.Printf("profile: send verification to user %d: %v", u.ID, err)
+		}
+	}
+	writeJSON(w, http.StatusOK, mapUser(u))
+}
+
+type changePasswordRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required,min=8,max=72"`
+}
+
+// handleChangePassword signs the user out everywhere, like a reset; the
+// access token used for this request stops working too.
+func (a *API) handleChangePassword(w http.ResponseWriter, r *http.Request) {
+	user := getAuthUser(r.Context())
+	if user == nil {
+		respondError(w, http.StatusUnauthorized, errUnauthenticated)
+		return
+	}
+	var req changePasswordRequest
+	if err := a.decodeAndValidate(r, &req); err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := a.userSvc.ChangePassword(r.Context(), useruc.ChangePasswordInput{
+		ID:              user.UserID,
+		CurrentPassword: req.CurrentPassword,
+		NewPassword:     req.NewPassword,
+	}); err != nil {
+		setRetryAfter(w, err)
+		handleDomainError(w, err)
+		return
+	}
+	if err := a.authSvc.RevokeUserTokens(r.Context(), user.UserID); err != nil {
+		handleDomainError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,282 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	domauth "example.com/my-golang-sample/app/internal/domain/auth"
+	"example.com/my-golang-sample/app/internal/domain/page"
+	domuser "example.com/my-golang-sample/app/internal/domain/user"
+	"example.com/my-golang-sample/app/internal/infra/security"
+	authuc "example.com/my-golang-sample/app/internal/usecase/auth"
+)
+
+// fakeAccountUserRepo is an in-memory user store keyed by email.
+type fakeAccountUserRepo struct {
+	users map[string]*domuser.User
+}
+
+func (f *fakeAccountUserRepo) Create(ctx context.Context, u *domuser.User) (*domuser.User, error) {
+	if _, ok := f.users[u.Email]; ok {
+		return nil, domuser.ErrEmailAlreadyUsed
+	}
+	cloned := *u
+	cloned.ID = int64(len(f.users) + 1)
+	f.users[u.Email] = &cloned
+	created := cloned
+	return &created, nil
+}
+
+func (f *fakeAccountUserRepo) GetByID(ctx context.Context, id int64) (*domuser.User, error) {
+	for _, u := range f.users {
+		if u.ID == id {
+			cloned := *u
+			return &cloned, nil
+		}
+	}
+	return nil, domuser.ErrUserNotFound
+}
+
+func (f *fakeAccountUserRepo) GetByEmail(ctx context.Context, email string) (*domuser.User, error) {
+	if u, ok := f.users[email]; ok {
+		cloned := *u
+		return &cloned, nil
+	}
+	return nil, domuser.ErrUserNotFound
+}
+
+func (f *fakeAccountUserRepo) List(ctx context.Context, filter domuser.ListUsersFilter, req page.Request) ([]*domuser.User, page.Info, error) {
+	return nil, page.Info{}, nil
+}
+
+func (f *fakeAccountUserRepo) Update(ctx context.Context, u *domuser.User) (*domuser.User, error) {
+	for email, existing := range f.users {
+		if existing.ID != u.ID && email == u.Email {
+			return nil, domuser.ErrEmailAlreadyUsed
+		}
+		if existing.ID == u.ID {
+			delete(f.users, email)
+		}
+	}
+	cloned := *u
+	f.users[u.Email] = &cloned
+	updated := cloned
+	return &updated, nil
+}
+
+func (f *fakeAccountUserRepo) Delete(ctx context.Context, id int64) error {
+	return nil
+}
+
+func (f *fakeAccountUserRepo) GetRoleIDByCode(ctx context.Context, code domuser.RoleCode) (int64, error) {
+	return 3, nil
+}
+
+type fakeOneTimeTokenRepo struct {
+	tokens []*domauth.OneTimeToken
+}
+
+func (f *fakeOneTimeTokenRepo) Create(ctx context.Context, t *domauth.OneTimeToken) error {
+	t.ID = int64(len(f.tokens) + 1)
+	f.tokens = append(f.tokens, t)
+	return nil
+}
+
+func (f *fakeOneTimeTokenRepo) Consume(ctx context.Context, purpose domauth.TokenPurpose, tokenHash string, now time.Time) (*domauth.OneTimeToken, error) {
+	for _, t := range f.tokens {
+		if t.Purpose == purpose && t.TokenHash == tokenHash && t.UsedAt == nil && now.Before(t.ExpiresAt) {
+			t.UsedAt = &now
+			return t, nil
+		}
+	}
+	return nil, domauth.ErrInvalidOneTimeToken
+}
+
+func (f *fakeOneTimeTokenRepo) InvalidateForUser(ctx context.Context, userID int64, purpose domauth.TokenPurpose) error {
+	now := time.Now()
+	for _, t := range f.tokens {
+		if t.UserID == userID && t.Purpose == purpose && t.UsedAt == nil {
+			t.UsedAt = &now
+		}
+	}
+	return nil
+}
+
+type fakeMailer struct {
+	bodies     []string
+	recipients []string
+	err        error
+}
+
+func (f *fakeMailer) Send(ctx context.Context, to, subject, body string) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.bodies = append(f.bodies, body)
+	f.recipients = append(f.recipients, to)
+	return nil
+}
+
+// lastLink returns the first URL found in the most recent mail.
+func (f *fakeMailer) lastLink(t *testing.T) *url.URL {
+	t.Helper()
+	require.NotEmpty(t, f.bodies)
+	for _, field := range strings.Fields(f.bodies[len(f.bodies)-1]) {
+		if strings.HasPrefix(field, "http") {
+			u, err := url.Parse(field)
+			require.NoError(t, err)
+			return u
+		}
+	}
+	t.Fatal("mail has no link")
+	return nil
+}
+
+func setupRegisterAPI(t *testing.T) (http.Handler, *fakeAccountUserRepo, *fakeMailer) {
+	t.Helper()
+	repo := &fakeAccountUserRepo{users: make(map[string]*domuser.User)}
+	mailer := &fakeMailer{}
+	passwordSvc := security.NewBcryptService(4)
+	tokenSvc := security.NewJWTService("test-secret", time.Hour)
+	authSvc := authuc.NewService(repo, passwordSvc, tokenSvc, nil,
+		authuc.WithSessions(&fakeRefreshTokenRepo{}, &fakeDenylist{revoked: make(map[string]time.Time)}, time.Hour),
+		authuc.WithAccounts(authuc.AccountConfig{
+			Hasher:    passwordSvc,
+			Tokens:    &fakeOneTimeTokenRepo{},
+			Mailer:    mailer,
+			VerifyURL: "http://localhost:20000/api/v1/auth/verify-email",
+			ResetURL:  "http://localhost:20000/reset-password",
+		}))
+
+	api := NewAPI(Dependencies{
+		AuthService:  authSvc,
+		TokenService: tokenSvc,
+	})
+	return api.Router(), repo, mailer
+}
+
+func TestRegister_CreatesUnverifiedCustomer(t *testing.T) {
+	router, repo, mailer := setupRegisterAPI(t)
+
+	rec := postSessionJSON(router, "/api/v1/auth/register", "", map[string]string{
+		"name":     "Jane",
+		"email":    "jane@example.com",
+		"password": "password123",
+	})
+
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	var resp struct {
+		User             map[string]any `json:"user"`
+		VerificationSent bool           `json:"verification_sent"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	require.True(t, resp.VerificationSent)
+	require.Equal(t, "jane@example.com", resp.User["email"])
+	require.Equal(t, string(domuser.RoleCodeCustomer), resp.User["role_code"])
+	require.Equal(t, false, resp.User["email_verified"])
+	require.NotContains(t, rec.Body.String(), "password")
+	require.NotEqual(t, "password123", repo.users["jane@example.com"].PasswordHash)
+	require.Len(t, mailer.bodies, 1)
+}
+
+func TestRegister_DuplicateEmailReturns409(t *testing.T) {
+	router, _, _ := setupRegisterAPI(t)
+	body := map[string]string{"name": "Jane", "email": "jane@example.com", "password": "password123"}
+
+	rec := postSessionJSON(router, "/api/v1/auth/register", "", body)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+	rec = postSessionJSON(router, "/api/v1/auth/register", "", body)
+	require.Equal(t, http.StatusConflict, rec.Code, rec.Body.String())
+}
+
+func TestRegister_ValidationErrors(t *testing.T) {
+	router, _, _ := setupRegisterAPI(t)
+
+	tests := []struct {
+		name string
+		body map[string]string
+	}{
+		{"missing name", map[string]string{"email": "jane@example.com", "password": "password123"}},
+		{"invalid email", map[string]string{"name": "Jane", "email": "jane", "password": "password123"}},
+		{"short password", map[string]string{"name": "Jane", "email": "jane@example.com", "password": "short"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := postSessionJSON(router, "/api/v1/auth/register", "", tt.body)
+			require.Equal(t, http.StatusBadRequest, rec.Code, rec.Body.String())
+		})
+	}
+}
+
+func TestVerifyEmail_LoginAllowedOnlyAfterVerification(t *testing.T) {
+	router, _, mailer := setupRegisterAPI(t)
+	rec := postSessionJSON(router, "/api/v1/auth/register", "", map[string]string{
+		"name":     "Jane",
+		"email":    "jane@example.com",
+		"password": "password123",
+	})
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+	login := map[string]string{"email": "jane@example.com", "password": "password123"}
+	rec = postSessionJSON(router, "/api/v1/auth/login", "", login)
+	require.Equal(t, http.StatusForbidden, rec.Code, rec.Body.String())
+
+	link := mailer.lastLink(t)
+	require.Equal(t, "/api/v1/auth/verify-email", link.Path)
+	verify := httptest.NewRequest(http.MethodGet, link.RequestURI(), nil)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, verify)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.Contains(t, rec.Body.String(), `"email_verified":true`)
+
+	rec = postSessionJSON(router, "/api/v1/auth/login", "", login)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	// The link cannot be used twice
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, link.RequestURI(), nil))
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestVerifyEmail_InvalidTokenReturns400(t *testing.T) {
+	router, _, _ := setupRegisterAPI(t)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/auth/verify-email?token=bogus", nil))
+
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestResendVerification_AcceptsUnknownEmail(t *testing.T) {
+	router, _, mailer := setupRegisterAPI(t)
+
+	rec := postSessionJSON(router, "/api/v1/auth/verify-email/resend", "", map[string]string{"email": "nobody@example.com"})
+
+	require.Equal(t, http.StatusAccepted, rec.Code)
+	require.Empty(t, mailer.bodies)
+}
+
+func TestResendVerification_HidesMailerFailure(t *testing.T) {
+	router, _, mailer := setupRegisterAPI(t)
+	rec := postSessionJSON(router, "/api/v1/auth/register", "", map[string]string{
+		"name":     "Jane",
+		"email":    "jane@example.com",
+		"password": "password123",
+	})
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	mailer.err = errors.New("smtp down")
+
+	rec = postSessionJSON(router, "/api/v1/auth/verify-email/resend", "", map[string]string{"email": "jane@example.com"})
+
+	require.Equal(t, http.StatusAccepted, rec.Code, rec.Body.String())
+}
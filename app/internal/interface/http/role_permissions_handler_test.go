@@ -0,0 +1,142 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	domuser "example.com/my-golang-sample/app/internal/domain/user"
+	domrole "example.com/my-golang-sample/app/internal/domain/userrole"
+	"example.com/my-golang-sample/app/internal/infra/security"
+	userroleuc "example.com/my-golang-sample/app/internal/usecase/userrole"
+)
+
+type fakePermissionRepo struct {
+	roles  *fakeRoleRepo
+	grants map[int64]map[domrole.Permission]bool
+}
+
+func (f *fakePermissionRepo) ListByRole(ctx context.Context, roleID int64) ([]domrole.Permission, error) {
+	var perms []domrole.Permission
+	for p := range f.grants[roleID] {
+		perms = append(perms, p)
+	}
+	return perms, nil
+}
+
+func (f *fakePermissionRepo) ListByRoleCode(ctx context.Context, code domuser.RoleCode) ([]domrole.Permission, error) {
+	for id, role := range f.roles.roles {
+		if role.Code == code {
+			return f.ListByRole(ctx, id)
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakePermissionRepo) Grant(ctx context.Context, roleID int64, p domrole.Permission) error {
+	if f.grants[roleID] == nil {
+		f.grants[roleID] = make(map[domrole.Permission]bool)
+	}
+	f.grants[roleID][p] = true
+	return nil
+}
+
+func (f *fakePermissionRepo) Revoke(ctx context.Context, roleID int64, p domrole.Permission) error {
+	delete(f.grants[roleID], p)
+	return nil
+}
+
+// setupPermissionAPI has a custom SUPPORT role (ID 4) with no permissions
+// and an ADMIN role limited to roles:read and roles:write.
+func setupPermissionAPI(t *testing.T) (http.Handler, map[domuser.RoleCode]string) {
+	t.Helper()
+	roles := newFakeRoleRepo()
+	roles.roles[4] = &domrole.UserRole{ID: 4, Code: "SUPPORT", Name: "Support"}
+	roles.nextID = 5
+	perms := &fakePermissionRepo{roles: roles, grants: map[int64]map[domrole.Permission]bool{
+		2: {domrole.PermRolesRead: true, domrole.PermRolesWrite: true},
+	}}
+	tokenSvc := security.NewJWTService("secret", time.Hour)
+	api := NewAPI(Dependencies{
+		UserRoleService: userroleuc.NewService(roles, userroleuc.WithPermissions(perms)),
+		TokenService:    tokenSvc,
+	})
+
+	tokens := make(map[domuser.RoleCode]string)
+	for i, code := range []domuser.RoleCode{domuser.RoleCodeSuperAdmin, domuser.RoleCodeAdmin, domuser.RoleCodeCustomer, "SUPPORT"} {
+		token, err := tokenSvc.GenerateToken(&domuser.User{ID: int64(i + 1), Email: "u@example.com", RoleCode: code})
+		require.NoError(t, err)
+		tokens[code] = token
+	}
+	return api.Router(), tokens
+}
+
+func sendPermissionRequest(router http.Handler, method, path, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestRolePermissions_GrantOpensRouteForCustomRole(t *testing.T) {
+	router, tokens := setupPermissionAPI(t)
+
+	rec := sendPermissionRequest(router, http.MethodGet, "/api/v1/admin/user-roles", tokens["SUPPORT"])
+	require.Equal(t, http.StatusForbidden, rec.Code)
+
+	rec = sendPermissionRequest(router, http.MethodPut, "/api/v1/admin/user-roles/4/permissions/roles:read", tokens[domuser.RoleCodeSuperAdmin])
+	require.Equal(t, http.StatusNoContent, rec.Code, rec.Body.String())
+
+	rec = sendPermissionRequest(router, http.MethodGet, "/api/v1/admin/user-roles/4/permissions", tokens["SUPPORT"])
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.JSONEq(t, `{"permissions":["roles:read"]}`, rec.Body.String())
+
+	rec = sendPermissionRequest(router, http.MethodGet, "/api/v1/admin/user-roles", tokens["SUPPORT"])
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	rec = sendPermissionRequest(router, http.MethodPost, "/api/v1/admin/user-roles", tokens["SUPPORT"])
+	require.Equal(t, http.StatusForbidden, rec.Code, "roles:write was not granted")
+
+	rec = sendPermissionRequest(router, http.MethodDelete, "/api/v1/admin/user-roles/4/permissions/roles:read", tokens[domuser.RoleCodeSuperAdmin])
+	require.Equal(t, http.StatusNoContent, rec.Code, rec.Body.String())
+	rec = sendPermissionRequest(router, http.MethodGet, "/api/v1/admin/user-roles", tokens["SUPPORT"])
+	require.Equal(t, http.StatusForbidden, rec.Code)
+}
+
+func TestRolePermissions_AdminOnlyGrantsWhatItHolds(t *testing.T) {
+	router, tokens := setupPermissionAPI(t)
+
+	rec := sendPermissionRequest(router, http.MethodPut, "/api/v1/admin/user-roles/4/permissions/roles:read", tokens[domuser.RoleCodeAdmin])
+	require.Equal(t, http.StatusNoContent, rec.Code, rec.Body.String())
+
+	rec = sendPermissionRequest(router, http.MethodPut, "/api/v1/admin/user-roles/4/permissions/orders:read", tokens[domuser.RoleCodeAdmin])
+	require.Equal(t, http.StatusForbidden, rec.Code, rec.Body.String())
+
+	rec = sendPermissionRequest(router, http.MethodGet, "/api/v1/admin/users", tokens[domuser.RoleCodeAdmin])
+	require.Equal(t, http.StatusForbidden, rec.Code, "users:read was revoked from ADMIN")
+}
+
+func TestRolePermissions_Errors(t *testing.T) {
+	router, tokens := setupPermissionAPI(t)
+	superAdmin := tokens[domuser.RoleCodeSuperAdmin]
+
+	rec := sendPermissionRequest(router, http.MethodPut, "/api/v1/admin/user-roles/4/permissions/orders:delete", superAdmin)
+	require.Equal(t, http.StatusUnprocessableEntity, rec.Code, rec.Body.String())
+
+	rec = sendPermissionRequest(router, http.MethodDelete, "/api/v1/admin/user-roles/1/permissions/roles:read", superAdmin)
+	require.Equal(t, http.StatusUnprocessableEntity, rec.Code, "SUPER_ADMIN cannot be changed")
+
+	rec = sendPermissionRequest(router, http.MethodPut, "/api/v1/admin/user-roles/99/permissions/roles:read", superAdmin)
+	require.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = sendPermissionRequest(router, http.MethodGet, "/api/v1/admin/permissions", tokens[domuser.RoleCodeCustomer])
+	require.Equal(t, http.StatusForbidden, rec.Code)
+
+	rec = sendPermissionRequest(router, http.MethodGet, "/api/v1/admin/permissions", superAdmin)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Contains(t, rec.Body.String(), `"orders:update_status"`)
+}
@@ -12,6 +12,7 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"example.com/my-golang-sample/app/internal/domain/page"
 	domuser "example.com/my-golang-sample/app/internal/domain/user"
 	"example.com/my-golang-sample/app/internal/infra/security"
 	authuc "example.com/my-golang-sample/app/internal/usecase/auth"
@@ -25,7 +26,7 @@ func setupUserAPIWithRole(repo domuser.Repository, role domuser.RoleCode) (*API,
 	userSvc := useruc.NewService(repo, passwordSvc)
 
 	tokenSvc := security.NewJWTService("test-secret", time.Hour)
-	authSvc := authuc.NewService(repo, passwordSvc, tokenSvc)
+	authSvc := authuc.NewService(repo, passwordSvc, tokenSvc, nil)
 
 	api := NewAPI(Dependencies{
 		AuthService:  authSvc,
@@ -168,7 +169,7 @@ func TestCreateUser_AdminAsAdmin_Returns422(t *testing.T) {
 	require.Equal(t, http.StatusUnprocessableEntity, rec.Code, "should return 422 Unprocessable Entity")
 
 	// Verify user was NOT created
-	users, _ := repo.List(context.Background(), domuser.ListUsersFilter{})
+	users, _, _ := repo.List(context.Background(), domuser.ListUsersFilter{}, page.Request{})
 	require.Len(t, users, 0, "user should NOT be created")
 }
 
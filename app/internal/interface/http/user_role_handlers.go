@@ -1,7 +1,14 @@
 package http
 
 import (
+	"context"
 	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	domaudit "example.com/my-golang-sample/app/internal/domain/audit"
+	domrole "example.com/my-golang-sample/app/internal/domain/userrole"
+	userroleuc "example.com/my-golang-sample/app/internal/usecase/userrole"
 )
 
 // GET /api/v1/admin/user-roles/{id}
@@ -31,3 +38,67 @@ func (a *API) handleGetUserRole(w http.ResponseWriter, r *http.Request) {
 	// Trả JSON ra client
 	writeJSON(w, http.StatusOK, mapRole(role))
 }
+
+// GET /api/v1/admin/permissions
+func (a *API) handleListPermissions(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{"permissions": domrole.AllPermissions()})
+}
+
+// GET /api/v1/admin/user-roles/{id}/permissions
+func (a *API) handleListRolePermissions(w http.ResponseWriter, r *http.Request) {
+	id, err := parseIDParam(r, "id")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+	perms, err := a.roleSvc.ListPermissions(r.Context(), id)
+	if err != nil {
+		handleDomainError(w, err)
+		return
+	}
+	if perms == nil {
+		perms = []domrole.Permission{}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"permissions": perms})
+}
+
+// PUT /api/v1/admin/user-roles/{id}/permissions/{permission}
+func (a *API) handleGrantRolePermission(w http.ResponseWriter, r *http.Request) {
+	a.changeRolePermission(w, r, a.roleSvc.GrantPermission, domaudit.ActionPermissionGranted)
+}
+
+// DELETE /api/v1/admin/user-roles/{id}/permissions/{permission}
+func (a *API) handleRevokeRolePermission(w http.ResponseWriter, r *http.Request) {
+	a.changeRolePermission(w, r, a.roleSvc.RevokePermission, domaudit.ActionPermissionRevoked)
+}
+
+func (a *API) changeRolePermission(w http.ResponseWriter, r *http.Request, change func(context.Context, userroleuc.ChangePermissionInput) error, action domaudit.Action) {
+	executor := getAuthUser(r.Context())
+	if executor == nil {
+		respondError(w, http.StatusUnauthorized, errUnauthenticated)
+		return
+	}
+	id, err := parseIDParam(r, "id")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := change(r.Context(), userroleuc.ChangePermissionInput{
+		ExecutorRole: executor.RoleCode,
+		RoleID:       id,
+		Permission:   chi.URLParam(r, "permission"),
+	}); err != nil {
+		handleDomainError(w, err)
+		return
+	}
+
+	perm, _ := domrole.ParsePermission(chi.URLParam(r, "permission"))
+	held := map[string]any{"permission": perm}
+	if action == domaudit.ActionPermissionGranted {
+		a.recordAudit(r, action, "role", id, nil, held)
+	} else {
+		a.recordAudit(r, action, "role", id, held, nil)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,135 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	domorder "example.com/my-golang-sample/app/internal/domain/order"
+	dompayment "example.com/my-golang-sample/app/internal/domain/payment"
+	paymentgw "example.com/my-golang-sample/app/internal/infra/payment"
+	orderuc "example.com/my-golang-sample/app/internal/usecase/order"
+	paymentuc "example.com/my-golang-sample/app/internal/usecase/payment"
+)
+
+func setupWebhookAPI(t *testing.T) (*API, *fakeOrderRepo, *paymentgw.FakeGateway, string) {
+	orderRepo := newFakeOrderRepo()
+	orderRepo.orders[2].Status = domorder.StatusPending
+
+	gateway := paymentgw.NewFakeGateway(dompayment.ProviderTamara, "https://pay.test/checkout")
+	gateway.WebhookSecret = "whsec"
+	orderSvc := orderuc.NewService(orderRepo)
+	paymentSvc := paymentuc.NewService(&memoryPaymentRepo{}, staticUserReader{}, orderSvc, gateway)
+
+	order, err := orderSvc.GetByID(context.Background(), 2)
+	require.NoError(t, err)
+	p, err := paymentSvc.StartCheckout(context.Background(), order)
+	require.NoError(t, err)
+
+	api := NewAPI(Dependencies{
+		OrderService:   orderSvc,
+		PaymentService: paymentSvc,
+	})
+	return api, orderRepo, gateway, p.Reference
+}
+
+func newWebhookRequest(t *testing.T, provider string, gateway *paymentgw.FakeGateway, body paymentgw.FakeWebhook) *http.Request {
+	payload, err := json.Marshal(body)
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/webhooks/payments/"+provider, bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Webhook-Signature", gateway.SignWebhook(payload))
+	return req
+}
+
+func TestPaymentWebhook_ApprovedMarksOrderPaid(t *testing.T) {
+	api, orderRepo, gateway, ref := setupWebhookAPI(t)
+
+	rec := httptest.NewRecorder()
+	api.Router().ServeHTTP(rec, newWebhookRequest(t, "tamara", gateway, paymentgw.FakeWebhook{
+		ID: "evt_1", Type: dompayment.EventApproved, Reference: ref, OrderID: 2,
+	}))
+
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var response map[string]any
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	require.Equal(t, "processed", response["status"])
+	require.Equal(t, domorder.StatusPaid, orderRepo.orders[2].Status)
+}
+
+func TestPaymentWebhook_ReplayReturnsDuplicate(t *testing.T) {
+	api, _, gateway, ref := setupWebhookAPI(t)
+	event := paymentgw.FakeWebhook{ID: "evt_1", Type: dompayment.EventApproved, Reference: ref, OrderID: 2}
+
+	first := httptest.NewRecorder()
+	api.Router().ServeHTTP(first, newWebhookRequest(t, "tamara", gateway, event))
+	require.Equal(t, http.StatusOK, first.Code, first.Body.String())
+
+	second := httptest.NewRecorder()
+	api.Router().ServeHTTP(second, newWebhookRequest(t, "tamara", gateway, event))
+	require.Equal(t, http.StatusOK, second.Code, second.Body.String())
+
+	var response map[string]any
+	require.NoError(t, json.Unmarshal(second.Body.Bytes(), &response))
+	require.Equal(t, "duplicate", response["status"])
+}
+
+func TestPaymentWebhook_BadSignatureReturns401(t *testing.T) {
+	api, orderRepo, gateway, ref := setupWebhookAPI(t)
+	req := newWebhookRequest(t, "tamara", gateway, paymentgw.FakeWebhook{
+		ID: "evt_1", Type: dompayment.EventApproved, Reference: ref, OrderID: 2,
+	})
+	req.Header.Set("X-Webhook-Signature", "bogus")
+
+	rec := httptest.NewRecorder()
+	api.Router().ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusUnauthorized, rec.Code, rec.Body.String())
+	require.Equal(t, domorder.StatusPending, orderRepo.orders[2].Status)
+}
+
+func TestPaymentWebhook_SignatureOnlyReadFromGatewayHeader(t *testing.T) {
+	api, orderRepo, gateway, ref := setupWebhookAPI(t)
+	req := newWebhookRequest(t, "tamara", gateway, paymentgw.FakeWebhook{
+		ID: "evt_1", Type: dompayment.EventApproved, Reference: ref, OrderID: 2,
+	})
+	signature := req.Header.Get("X-Webhook-Signature")
+	req.Header.Del("X-Webhook-Signature")
+	req.Header.Set("Authorization", "Bearer "+signature)
+	req.URL.RawQuery = "tamaraToken=" + signature
+
+	rec := httptest.NewRecorder()
+	api.Router().ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusUnauthorized, rec.Code, rec.Body.String())
+	require.Equal(t, domorder.StatusPending, orderRepo.orders[2].Status)
+}
+
+func TestRedactQuery_DropsSecretsBeforeLogging(t *testing.T) {
+	var seen *http.Request
+	handler := redactQuery("tamaraToken")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = r
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/webhooks/payments/tamara?tamaraToken=secret.jwt&x=1", nil)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	require.Equal(t, "/api/v1/webhooks/payments/tamara?x=1", seen.RequestURI)
+	require.NotContains(t, seen.URL.String(), "secret")
+}
+
+func TestPaymentWebhook_UnknownProviderReturns422(t *testing.T) {
+	api, _, gateway, ref := setupWebhookAPI(t)
+
+	rec := httptest.NewRecorder()
+	api.Router().ServeHTTP(rec, newWebhookRequest(t, "paypal", gateway, paymentgw.FakeWebhook{
+		ID: "evt_1", Type: dompayment.EventApproved, Reference: ref, OrderID: 2,
+	}))
+
+	require.Equal(t, http.StatusUnprocessableEntity, rec.Code, rec.Body.String())
+}
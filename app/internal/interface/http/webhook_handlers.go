@@ -0,0 +1,41 @@
+package http
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	dompayment "example.com/my-golang-sample/app/internal/domain/payment"
+)
+
+const maxWebhookBodyBytes = 1 << 20
+
+// POST /api/v1/webhooks/payments/{provider}
+func (a *API) handlePaymentWebhook(w http.ResponseWriter, r *http.Request) {
+	provider, err := dompayment.ParseProvider(chi.URLParam(r, "provider"))
+	if err != nil {
+		handleDomainError(w, err)
+		return
+	}
+
+	header, err := a.paymentSvc.SignatureHeader(provider)
+	if err != nil {
+		handleDomainError(w, err)
+		return
+	}
+
+	defer r.Body.Close()
+	payload, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookBodyBytes))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	result, err := a.paymentSvc.HandleWebhook(r.Context(), provider, payload, r.Header.Get(header))
+	if err != nil {
+		handleDomainError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": string(result)})
+}
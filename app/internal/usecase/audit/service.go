@@ -0,0 +1,36 @@
+package audit
+
+import (
+	"context"
+
+	dom "example.com/my-golang-sample/app/internal/domain/audit"
+	"example.com/my-golang-sample/app/internal/domain/page"
+)
+
+type Service struct {
+	repo dom.Repository
+}
+
+func NewService(repo dom.Repository) *Service {
+	return &Service{repo: repo}
+}
+
+// Record appends e, stamping it with the request ID carried by ctx.
+func (s *Service) Record(ctx context.Context, e *dom.Entry) error {
+	if e.RequestID == "" {
+		e.RequestID = dom.RequestIDFromContext(ctx)
+	}
+	return s.repo.Append(ctx, e)
+}
+
+// List returns one page of matching entries, newest first by default.
+func (s *Service) List(ctx context.Context, filter dom.ListFilter, p page.Request) ([]*dom.Entry, page.Info, error) {
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return nil, page.Info{}, dom.ErrInvalidTimeRange
+	}
+	p, err := p.Normalize(dom.SortFields)
+	if err != nil {
+		return nil, page.Info{}, err
+	}
+	return s.repo.List(ctx, filter, p)
+}
@@ -0,0 +1,103 @@
+package audit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	dom "example.com/my-golang-sample/app/internal/domain/audit"
+	"example.com/my-golang-sample/app/internal/domain/page"
+)
+
+type mockAuditRepository struct {
+	entries    []*dom.Entry
+	lastFilter dom.ListFilter
+	lastPage   page.Request
+}
+
+func (m *mockAuditRepository) Append(ctx context.Context, e *dom.Entry) error {
+	m.entries = append(m.entries, e)
+	return nil
+}
+
+func (m *mockAuditRepository) List(ctx context.Context, filter dom.ListFilter, req page.Request) ([]*dom.Entry, page.Info, error) {
+	m.lastFilter = filter
+	m.lastPage = req
+	return m.entries, page.Info{Total: int64(len(m.entries)), Page: req.Page, Size: req.Size}, nil
+}
+
+func TestService_Record_StampsRequestID(t *testing.T) {
+	repo := &mockAuditRepository{}
+	svc := NewService(repo)
+	ctx := dom.ContextWithRequestID(context.Background(), "req-1")
+
+	require.NoError(t, svc.Record(ctx, &dom.Entry{Action: dom.ActionUserCreated}))
+	require.NoError(t, svc.Record(ctx, &dom.Entry{Action: dom.ActionUserUpdated, RequestID: "explicit"}))
+
+	require.Equal(t, "req-1", repo.entries[0].RequestID)
+	require.Equal(t, "explicit", repo.entries[1].RequestID)
+}
+
+func TestService_List_NormalizesPage(t *testing.T) {
+	repo := &mockAuditRepository{}
+	svc := NewService(repo)
+
+	_, _, err := svc.List(context.Background(), dom.ListFilter{}, page.Request{})
+	require.NoError(t, err)
+	require.Equal(t, page.Request{Page: 1, Size: page.DefaultSize, Sort: "id", Desc: true}, repo.lastPage)
+
+	_, _, err = svc.List(context.Background(), dom.ListFilter{}, page.Request{Size: 10000})
+	require.NoError(t, err)
+	require.Equal(t, page.MaxSize, repo.lastPage.Size)
+
+	cursor := page.EncodeCursor(page.Cursor{Sort: "id", Desc: true, ID: 42})
+	_, _, err = svc.List(context.Background(), dom.ListFilter{}, page.Request{Sort: "id", Desc: true, Cursor: cursor})
+	require.NoError(t, err)
+	require.Equal(t, int64(42), repo.lastPage.After.ID)
+}
+
+func TestService_List_RejectsBadPageRequests(t *testing.T) {
+	svc := NewService(&mockAuditRepository{})
+	ascending := page.EncodeCursor(page.Cursor{Sort: "id", ID: 42})
+
+	for name, tc := range map[string]struct {
+		req  page.Request
+		want error
+	}{
+		"unknown sort field":     {page.Request{Sort: "actor_id"}, page.ErrInvalidSort},
+		"garbage cursor":         {page.Request{Cursor: "not-a-cursor"}, page.ErrInvalidCursor},
+		"cursor of another sort": {page.Request{Sort: "id", Desc: true, Cursor: ascending}, page.ErrInvalidCursor},
+		"negative page":          {page.Request{Page: -1}, page.ErrInvalidPage},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, _, err := svc.List(context.Background(), dom.ListFilter{}, tc.req)
+			require.ErrorIs(t, err, tc.want)
+		})
+	}
+}
+
+func TestService_List_RejectsInvertedRange(t *testing.T) {
+	svc := NewService(&mockAuditRepository{})
+	from := time.Now()
+	to := from.Add(-time.Hour)
+
+	_, _, err := svc.List(context.Background(), dom.ListFilter{From: &from, To: &to}, page.Request{})
+
+	require.ErrorIs(t, err, dom.ErrInvalidTimeRange)
+}
+
+func TestDiff_KeepsOnlyChangedFields(t *testing.T) {
+	before := map[string]any{"name": "Old", "email": "a@example.com", "removed": 1}
+	after := map[string]any{"name": "New", "email": "a@example.com", "added": true}
+
+	changes := dom.Diff(before, after)
+
+	require.Equal(t, map[string]dom.Change{
+		"name":    {Before: "Old", After: "New"},
+		"removed": {Before: 1},
+		"added":   {After: true},
+	}, changes)
+	require.Len(t, dom.Diff(nil, after), 3)
+}
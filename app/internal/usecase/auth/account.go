@@ -0,0 +1,226 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	domauth "example.com/my-golang-sample/app/internal/domain/auth"
+	domuser "example.com/my-golang-sample/app/internal/domain/user"
+)
+
+const (
+	// DefaultVerifyTTL is how long an email verification link stays valid.
+	DefaultVerifyTTL = 24 * time.Hour
+	// DefaultResetTTL is how long a password reset link stays valid.
+	DefaultResetTTL = time.Hour
+)
+
+var ErrRegistrationDisabled = errors.New("registration is disabled")
+
+type PasswordHasher interface {
+	Hash(password string) (string, error)
+}
+
+// Mailer delivers plain-text emails.
+type Mailer interface {
+	Send(ctx context.Context, to, subject, body string) error
+}
+
+// AccountConfig enables self-service accounts: registration with email
+// verification and password reset. Login then requires a verified email.
+type AccountConfig struct {
+	Hasher PasswordHasher
+	Tokens domauth.OneTimeTokenRepository
+	Mailer Mailer
+	// VerifyURL is the link sent to new users; the token is added as ?token=.
+	VerifyURL string
+	VerifyTTL time.Duration
+	// ResetURL is the password reset link; the token is added as ?token=.
+	ResetURL string
+	ResetTTL time.Duration
+}
+
+func WithAccounts(cfg AccountConfig) Option {
+	return func(s *Service) {
+		if cfg.VerifyTTL <= 0 {
+			cfg.VerifyTTL = DefaultVerifyTTL
+		}
+		if cfg.ResetTTL <= 0 {
+			cfg.ResetTTL = DefaultResetTTL
+		}
+		s.accounts = &cfg
+	}
+}
+
+type RegisterInput struct {
+	Name     string
+	Email    string
+	Password string
+}
+
+type RegisterResult struct {
+	User *domuser.User
+	// VerificationSent is false when the email could not be delivered; the
+	// user can ask for it again with ResendVerification.
+	VerificationSent bool
+}
+
+// Register creates a CUSTOMER account and emails a verification link.
+func (s *Service) Register(ctx context.Context, in RegisterInput) (*RegisterResult, error) {
+	if s.accounts == nil {
+		return nil, ErrRegistrationDisabled
+	}
+	email := strings.TrimSpace(strings.ToLower(in.Email))
+	name := strings.TrimSpace(in.Name)
+	if email == "" || name == "" || in.Password == "" {
+		return nil, domuser.ErrInvalidCredential
+	}
+
+	roleID, err := s.userRepo.GetRoleIDByCode(ctx, domuser.RoleCodeCustomer)
+	if err != nil {
+		return nil, err
+	}
+	hash, err := s.accounts.Hasher.Hash(in.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	u, err := s.userRepo.Create(ctx, &domuser.User{
+		Name:         name,
+		Email:        email,
+		PasswordHash: hash,
+		UserRoleID:   roleID,
+		RoleCode:     domuser.RoleCodeCustomer,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// User đã được tạo, lỗi gửi mail không làm hỏng việc đăng ký
+	sent := s.sendVerification(ctx, u) == nil
+	return &RegisterResult{User: u, VerificationSent: sent}, nil
+}
+
+// VerifyEmail consumes a verification token and marks the email as verified.
+func (s *Service) VerifyEmail(ctx context.Context, token string) (*domuser.User, error) {
+	if s.accounts == nil || token == "" {
+		return nil, domauth.ErrInvalidOneTimeToken
+	}
+	t, err := s.accounts.Tokens.Consume(ctx, domauth.PurposeVerifyEmail, hashToken(token), s.now())
+	if err != nil {
+		return nil, err
+	}
+
+	u, err := s.userRepo.GetByID(ctx, t.UserID)
+	if err != nil {
+		if errors.Is(err, domuser.ErrUserNotFound) {
+			return nil, domauth.ErrInvalidOneTimeToken
+		}
+		return nil, err
+	}
+	if u.IsEmailVerified() {
+		return u, nil
+	}
+	now := s.now()
+	u.EmailVerifiedAt = &now
+	return s.userRepo.Update(ctx, u)
+}
+
+// ResendVerification emails a fresh link to an unverified user. Unknown or
+// already verified addresses are ignored so the endpoint cannot be used to
+// probe which emails are registered; a delivery error should be logged, not shown.
+func (s *Service) ResendVerification(ctx context.Context, email string) error {
+	if s.accounts == nil {
+		return ErrRegistrationDisabled
+	}
+	u, err := s.userRepo.GetByEmail(ctx, strings.TrimSpace(strings.ToLower(email)))
+	if err != nil {
+		if errors.Is(err, domuser.ErrUserNotFound) {
+			return nil
+		}
+		return err
+	}
+	if u.IsEmailVerified() {
+		return nil
+	}
+	return s.sendVerification(ctx, u)
+}
+
+// SendVerification emails a fresh link to the user's current address if it
+// is not verified yet, e.g. right after the user changed it.
+func (s *Service) SendVerification(ctx context.Context, userID int64) error {
+	if s.accounts == nil {
+		return ErrRegistrationDisabled
+	}
+	u, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if u.IsEmailVerified() {
+		return nil
+	}
+	return s.sendVerification(ctx, u)
+}
+
+func (s *Service) sendVerification(ctx context.Context, u *domuser.User) error {
+	link, err := s.issueOneTimeToken(ctx, u.ID, domauth.PurposeVerifyEmail, s.accounts.VerifyURL, s.accounts.VerifyTTL)
+	if err != nil {
+		return err
+	}
+	body := fmt.Sprintf("Hi %s,\n\nPlease confirm your email address by opening this link:\n\n%s\n\nThe link expires in %s.\n",
+		u.Name, link, formatTTL(s.accounts.VerifyTTL))
+	return s.accounts.Mailer.Send(ctx, u.Email, "Confirm your email address", body)
+}
+
+// issueOneTimeToken voids the user's previous tokens of purpose, stores a new
+// one and returns baseURL with the token appended.
+func (s *Service) issueOneTimeToken(ctx context.Context, userID int64, purpose domauth.TokenPurpose, baseURL string, ttl time.Duration) (string, error) {
+	raw, err := s.storeOneTimeToken(ctx, s.accounts.Tokens, userID, purpose, ttl)
+	if err != nil {
+		return "", err
+	}
+
+	link, err := url.Parse(baseURL)
+	if err != nil {
+		return "", err
+	}
+	q := link.Query()
+	q.Set("token", raw)
+	link.RawQuery = q.Encode()
+	return link.String(), nil
+}
+
+// storeOneTimeToken replaces the user's outstanding tokens of purpose with a
+// new one and returns the raw token.
+func (s *Service) storeOneTimeToken(ctx context.Context, repo domauth.OneTimeTokenRepository, userID int64, purpose domauth.TokenPurpose, ttl time.Duration) (string, error) {
+	if err := repo.InvalidateForUser(ctx, userID, purpose); err != nil {
+		return "", err
+	}
+	raw, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+	if err := repo.Create(ctx, &domauth.OneTimeToken{
+		UserID:    userID,
+		Purpose:   purpose,
+		TokenHash: hashToken(raw),
+		ExpiresAt: s.now().Add(ttl),
+	}); err != nil {
+		return "", err
+	}
+	return raw, nil
+}
+
+func formatTTL(d time.Duration) string {
+	if d >= time.Hour && d%time.Hour == 0 {
+		if h := int(d / time.Hour); h != 1 {
+			return fmt.Sprintf("%d hours", h)
+		}
+		return "1 hour"
+	}
+	return fmt.Sprintf("%d minutes", int(d/time.Minute))
+}
@@ -0,0 +1,248 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	domaudit "example.com/my-golang-sample/app/internal/domain/audit"
+	domauth "example.com/my-golang-sample/app/internal/domain/auth"
+	domuser "example.com/my-golang-sample/app/internal/domain/user"
+)
+
+var ErrTooManyLoginAttempts = errors.New("too many failed login attempts")
+
+// LoginThrottledError is returned by Login while an email or client IP has
+// to wait before trying again.
+type LoginThrottledError struct {
+	RetryAfter time.Duration
+	// Locked is true for a lockout, false for a progressive delay.
+	Locked bool
+}
+
+func (e *LoginThrottledError) Error() string {
+	if e.Locked {
+		return fmt.Sprintf("%s, temporarily locked for %s", ErrTooManyLoginAttempts, e.RetryAfter.Round(time.Second))
+	}
+	return fmt.Sprintf("%s, retry in %s", ErrTooManyLoginAttempts, e.RetryAfter.Round(time.Second))
+}
+
+func (e *LoginThrottledError) Unwrap() error {
+	return ErrTooManyLoginAttempts
+}
+
+// LockoutPolicy limits password guessing. Failures are counted per email and
+// per client IP; a count restarts once Window passes without a failure.
+type LockoutPolicy struct {
+	MaxAccountFailures int
+	// MaxIPFailures is higher since many users may share one address.
+	MaxIPFailures   int
+	Window          time.Duration
+	LockoutDuration time.Duration
+	// From the second failure on an email, the next attempt has to wait
+	// BaseDelay, doubled for every further failure up to MaxDelay.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+}
+
+func DefaultLockoutPolicy() LockoutPolicy {
+	return LockoutPolicy{
+		MaxAccountFailures: 5,
+		MaxIPFailures:      20,
+		Window:             15 * time.Minute,
+		LockoutDuration:    15 * time.Minute,
+		BaseDelay:          time.Second,
+		MaxDelay:           30 * time.Second,
+	}
+}
+
+func (p LockoutPolicy) delay(failures int) time.Duration {
+	if failures < 2 || p.BaseDelay <= 0 {
+		return 0
+	}
+	d := p.BaseDelay
+	for i := 2; i < failures && d < p.MaxDelay; i++ {
+		d *= 2
+	}
+	if p.MaxDelay > 0 && d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	return d
+}
+
+// WithLockout enables brute-force protection on Login. Zero thresholds and
+// durations take their DefaultLockoutPolicy values; a zero BaseDelay turns
+// the progressive delay off.
+func WithLockout(attempts domauth.LoginAttemptRepository, policy LockoutPolicy) Option {
+	return func(s *Service) {
+		def := DefaultLockoutPolicy()
+		if policy.MaxAccountFailures <= 0 {
+			policy.MaxAccountFailures = def.MaxAccountFailures
+		}
+		if policy.MaxIPFailures <= 0 {
+			policy.MaxIPFailures = def.MaxIPFailures
+		}
+		if policy.Window <= 0 {
+			policy.Window = def.Window
+		}
+		if policy.LockoutDuration <= 0 {
+			policy.LockoutDuration = def.LockoutDuration
+		}
+		s.attempts = attempts
+		s.lockout = policy
+	}
+}
+
+// WithAudit records security events such as lockouts.
+func WithAudit(log domaudit.Repository) Option {
+	return func(s *Service) {
+		s.audit = log
+	}
+}
+
+// checkLoginAllowed rejects an attempt while the IP or email is locked or
+// still inside its progressive delay.
+func (s *Service) checkLoginAllowed(ctx context.Context, email, ip string) error {
+	if s.attempts == nil {
+		return nil
+	}
+	now := s.now()
+
+	if ip != "" {
+		f, err := s.attempts.Get(ctx, domauth.ScopeIP, ip)
+		if err != nil {
+			return err
+		}
+		if f.IsLocked(now) {
+			return &LoginThrottledError{RetryAfter: f.LockedUntil.Sub(now), Locked: true}
+		}
+	}
+
+	f, err := s.attempts.Get(ctx, domauth.ScopeAccount, email)
+	if err != nil {
+		return err
+	}
+	if f.IsLocked(now) {
+		return &LoginThrottledError{RetryAfter: f.LockedUntil.Sub(now), Locked: true}
+	}
+	if wait := s.lockout.delay(f.Count) - now.Sub(f.LastFailedAt); wait > 0 {
+		return &LoginThrottledError{RetryAfter: wait}
+	}
+	return nil
+}
+
+// recordLoginFailure counts a failed attempt and locks the email and/or IP
+// once a threshold is reached. u is nil when the email is not registered;
+// unknown emails are locked too so lockouts do not reveal which exist.
+func (s *Service) recordLoginFailure(ctx context.Context, email, ip string, u *domuser.User) error {
+	if s.attempts == nil {
+		return nil
+	}
+	now := s.now()
+	var throttled *LoginThrottledError
+
+	f, err := s.attempts.RecordFailure(ctx, domauth.ScopeAccount, email, now, s.lockout.Window)
+	if err != nil {
+		return err
+	}
+	if f.Count >= s.lockout.MaxAccountFailures {
+		entry := &domaudit.Entry{
+			Action:     domaudit.ActionAccountLocked,
+			TargetType: "email",
+			TargetID:   email,
+			IP:         ip,
+			Details:    map[string]any{"email": email},
+		}
+		if u != nil {
+			entry.TargetType, entry.TargetID = "user", strconv.FormatInt(u.ID, 10)
+		}
+		if err := s.lock(ctx, f, now, entry); err != nil {
+			return err
+		}
+		throttled = &LoginThrottledError{RetryAfter: s.lockout.LockoutDuration, Locked: true}
+	}
+
+	if ip != "" {
+		f, err := s.attempts.RecordFailure(ctx, domauth.ScopeIP, ip, now, s.lockout.Window)
+		if err != nil {
+			return err
+		}
+		if f.Count >= s.lockout.MaxIPFailures {
+			entry := &domaudit.Entry{
+				Action:     domaudit.ActionIPLocked,
+				TargetType: "ip",
+				TargetID:   ip,
+				IP:         ip,
+				Details:    map[string]any{},
+			}
+			if err := s.lock(ctx, f, now, entry); err != nil {
+				return err
+			}
+			throttled = &LoginThrottledError{RetryAfter: s.lockout.LockoutDuration, Locked: true}
+		}
+	}
+
+	if throttled != nil {
+		return throttled
+	}
+	return nil
+}
+
+// VerifyPassword checks a signed-in user's current password before a
+// sensitive change. Failures count against the same per-email limit as
+// logins, so a stolen access token cannot be used to guess the password.
+func (s *Service) VerifyPassword(ctx context.Context, u *domuser.User, password string) error {
+	if err := s.checkLoginAllowed(ctx, u.Email, ""); err != nil {
+		return err
+	}
+	if err := s.checker.Compare(u.PasswordHash, password); err != nil {
+		if err := s.recordLoginFailure(ctx, u.Email, "", u); err != nil {
+			return err
+		}
+		return domuser.ErrInvalidCredential
+	}
+	return nil
+}
+
+func (s *Service) lock(ctx context.Context, f *domauth.LoginFailures, now time.Time, entry *domaudit.Entry) error {
+	until := now.Add(s.lockout.LockoutDuration)
+	if err := s.attempts.Lock(ctx, f.Scope, f.Key, until); err != nil {
+		return err
+	}
+	entry.Details["failures"] = f.Count
+	entry.Details["locked_until"] = until.UTC().Format(time.RFC3339)
+	return s.appendAudit(ctx, entry)
+}
+
+// UnlockAccount lifts a lockout on the user's email before it expires.
+func (s *Service) UnlockAccount(ctx context.Context, actorID, userID int64) error {
+	u, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if s.attempts == nil {
+		return nil
+	}
+	if err := s.attempts.Clear(ctx, domauth.ScopeAccount, u.Email); err != nil {
+		return err
+	}
+	return s.appendAudit(ctx, &domaudit.Entry{
+		ActorID:    &actorID,
+		Action:     domaudit.ActionAccountUnlocked,
+		TargetType: "user",
+		TargetID:   strconv.FormatInt(u.ID, 10),
+		Details:    map[string]any{"email": u.Email},
+	})
+}
+
+func (s *Service) appendAudit(ctx context.Context, e *domaudit.Entry) error {
+	if s.audit == nil {
+		return nil
+	}
+	if e.RequestID == "" {
+		e.RequestID = domaudit.RequestIDFromContext(ctx)
+	}
+	return s.audit.Append(ctx, e)
+}
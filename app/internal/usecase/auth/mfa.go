@@ -0,0 +1,377 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"strings"
+	"time"
+
+	domauth "example.com/my-golang-sample/app/internal/domain/auth"
+	domuser "example.com/my-golang-sample/app/internal/domain/user"
+)
+
+// DefaultChallengeTTL is how long the second login step may take.
+const DefaultChallengeTTL = 5 * time.Minute
+
+const recoveryCodeCount = 10
+
+var (
+	ErrMFAUnavailable = errors.New("two-factor authentication is not configured")
+	ErrMFARequired    = errors.New("two-factor authentication is mandatory for this role")
+)
+
+// TOTPProvider generates and checks authenticator app codes.
+type TOTPProvider interface {
+	GenerateSecret() (string, error)
+	ProvisioningURI(secret, account string) string
+	// Validate returns the time step the code belongs to.
+	Validate(secret, code string, now time.Time) (int64, bool)
+}
+
+// MFAConfig enables TOTP two-factor authentication.
+type MFAConfig struct {
+	TOTP TOTPProvider
+	Repo domauth.MFARepository
+	// Challenges stores the tokens linking the password step to the code step.
+	Challenges   domauth.OneTimeTokenRepository
+	ChallengeTTL time.Duration
+	// RequiredRoles cannot log in without 2FA; users of these roles who have
+	// not enrolled yet do so during login.
+	RequiredRoles []domuser.RoleCode
+}
+
+func WithMFA(cfg MFAConfig) Option {
+	return func(s *Service) {
+		if cfg.ChallengeTTL <= 0 {
+			cfg.ChallengeTTL = DefaultChallengeTTL
+		}
+		s.mfa = &cfg
+	}
+}
+
+// MFAEnrollment is a new, unconfirmed secret to add to an authenticator app.
+type MFAEnrollment struct {
+	Secret          string
+	ProvisioningURI string
+	// ChallengeToken replaces the one used to enroll during login.
+	ChallengeToken string
+}
+
+type MFAStatus struct {
+	Enabled bool
+	// Required is true when the user's role cannot turn 2FA off.
+	Required            bool
+	RecoveryCodesLeft   int
+	PendingConfirmation bool
+}
+
+type VerifyMFAInput struct {
+	ChallengeToken string
+	// Code is a TOTP code; RecoveryCode may be used instead once 2FA is enabled.
+	Code           string
+	RecoveryCode   string
+	GuestCartToken string
+	IP             string
+}
+
+func (s *Service) mfaRequired(role domuser.RoleCode) bool {
+	for _, r := range s.mfa.RequiredRoles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// mfaChallenge returns the intermediate login result when u has to pass a
+// second factor, or nil when the password is enough.
+func (s *Service) mfaChallenge(ctx context.Context, u *domuser.User) (*LoginResult, error) {
+	enabled, err := s.mfaEnabled(ctx, u.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !enabled && !s.mfaRequired(u.RoleCode) {
+		return nil, nil
+	}
+	challenge, err := s.storeOneTimeToken(ctx, s.mfa.Challenges, u.ID, domauth.PurposeMFAChallenge, s.mfa.ChallengeTTL)
+	if err != nil {
+		return nil, err
+	}
+	return &LoginResult{
+		User:                  u,
+		MFARequired:           true,
+		MFAEnrollmentRequired: !enabled,
+		ChallengeToken:        challenge,
+	}, nil
+}
+
+func (s *Service) mfaEnabled(ctx context.Context, userID int64) (bool, error) {
+	t, err := s.mfa.Repo.GetTOTP(ctx, userID)
+	if err != nil {
+		if errors.Is(err, domauth.ErrMFANotEnrolled) {
+			return false, nil
+		}
+		return false, err
+	}
+	return t.IsConfirmed(), nil
+}
+
+// consumeChallenge turns a challenge token back into its user.
+func (s *Service) consumeChallenge(ctx context.Context, token string) (*domuser.User, error) {
+	if s.mfa == nil || token == "" {
+		return nil, domauth.ErrInvalidOneTimeToken
+	}
+	t, err := s.mfa.Challenges.Consume(ctx, domauth.PurposeMFAChallenge, hashToken(token), s.now())
+	if err != nil {
+		return nil, err
+	}
+	u, err := s.userRepo.GetByID(ctx, t.UserID)
+	if err != nil {
+		if errors.Is(err, domuser.ErrUserNotFound) {
+			return nil, domauth.ErrInvalidOneTimeToken
+		}
+		return nil, err
+	}
+	return u, nil
+}
+
+// EnrollMFAWithChallenge starts enrollment for a user whose role requires
+// 2FA but who has none yet, in the middle of logging in.
+func (s *Service) EnrollMFAWithChallenge(ctx context.Context, challengeToken string) (*MFAEnrollment, error) {
+	u, err := s.consumeChallenge(ctx, challengeToken)
+	if err != nil {
+		return nil, err
+	}
+	enrollment, err := s.startEnrollment(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+	enrollment.ChallengeToken, err = s.storeOneTimeToken(ctx, s.mfa.Challenges, u.ID, domauth.PurposeMFAChallenge, s.mfa.ChallengeTTL)
+	if err != nil {
+		return nil, err
+	}
+	return enrollment, nil
+}
+
+// StartMFAEnrollment creates a new secret for a signed-in user. It stays
+// inactive until ConfirmMFA.
+func (s *Service) StartMFAEnrollment(ctx context.Context, userID int64) (*MFAEnrollment, error) {
+	if s.mfa == nil {
+		return nil, ErrMFAUnavailable
+	}
+	u, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.startEnrollment(ctx, u)
+}
+
+func (s *Service) startEnrollment(ctx context.Context, u *domuser.User) (*MFAEnrollment, error) {
+	enabled, err := s.mfaEnabled(ctx, u.ID)
+	if err != nil {
+		return nil, err
+	}
+	if enabled {
+		return nil, domauth.ErrMFAAlreadyEnabled
+	}
+	secret, err := s.mfa.TOTP.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.mfa.Repo.SaveTOTP(ctx, &domauth.TOTPSecret{UserID: u.ID, Secret: secret}); err != nil {
+		return nil, err
+	}
+	return &MFAEnrollment{
+		Secret:          secret,
+		ProvisioningURI: s.mfa.TOTP.ProvisioningURI(secret, u.Email),
+	}, nil
+}
+
+// ConfirmMFA activates a pending secret once the user enters a valid code,
+// and returns the recovery codes. They are shown only this once.
+func (s *Service) ConfirmMFA(ctx context.Context, userID int64, code string) ([]string, error) {
+	if s.mfa == nil {
+		return nil, ErrMFAUnavailable
+	}
+	t, err := s.mfa.Repo.GetTOTP(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if t.IsConfirmed() {
+		return nil, domauth.ErrMFAAlreadyEnabled
+	}
+	return s.confirmTOTP(ctx, t, code)
+}
+
+func (s *Service) confirmTOTP(ctx context.Context, t *domauth.TOTPSecret, code string) ([]string, error) {
+	step, ok := s.mfa.TOTP.Validate(t.Secret, code, s.now())
+	if !ok {
+		return nil, domauth.ErrInvalidMFACode
+	}
+	now := s.now()
+	t.ConfirmedAt = &now
+	t.LastUsedStep = step
+	if err := s.mfa.Repo.SaveTOTP(ctx, t); err != nil {
+		return nil, err
+	}
+	return s.newRecoveryCodes(ctx, t.UserID)
+}
+
+// VerifyMFA is the second login step. During a mandatory enrollment the
+// code also confirms the new secret and the recovery codes are returned.
+func (s *Service) VerifyMFA(ctx context.Context, in VerifyMFAInput) (*LoginResult, error) {
+	u, err := s.consumeChallenge(ctx, in.ChallengeToken)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkLoginAllowed(ctx, u.Email, in.IP); err != nil {
+		return nil, err
+	}
+
+	t, err := s.mfa.Repo.GetTOTP(ctx, u.ID)
+	if err != nil {
+		if errors.Is(err, domauth.ErrMFANotEnrolled) {
+			return nil, domauth.ErrInvalidMFACode
+		}
+		return nil, err
+	}
+
+	var recoveryCodes []string
+	if t.IsConfirmed() {
+		err = s.checkSecondFactor(ctx, t, in.Code, in.RecoveryCode)
+	} else {
+		recoveryCodes, err = s.confirmTOTP(ctx, t, in.Code)
+	}
+	if err != nil {
+		if errors.Is(err, domauth.ErrInvalidMFACode) {
+			if err := s.recordLoginFailure(ctx, u.Email, in.IP, u); err != nil {
+				return nil, err
+			}
+		}
+		return nil, err
+	}
+
+	result, err := s.completeLogin(ctx, u, in.GuestCartToken)
+	if err != nil {
+		return nil, err
+	}
+	result.RecoveryCodes = recoveryCodes
+	return result, nil
+}
+
+// checkSecondFactor accepts a TOTP code not used before, or an unused
+// recovery code.
+func (s *Service) checkSecondFactor(ctx context.Context, t *domauth.TOTPSecret, code, recoveryCode string) error {
+	if recoveryCode != "" {
+		return s.mfa.Repo.UseRecoveryCode(ctx, t.UserID, hashRecoveryCode(recoveryCode), s.now())
+	}
+	step, ok := s.mfa.TOTP.Validate(t.Secret, code, s.now())
+	if !ok {
+		return domauth.ErrInvalidMFACode
+	}
+	fresh, err := s.mfa.Repo.UseStep(ctx, t.UserID, step)
+	if err != nil {
+		return err
+	}
+	if !fresh {
+		return domauth.ErrInvalidMFACode
+	}
+	return nil
+}
+
+// DisableMFA turns 2FA off after checking a current code. Roles that
+// require 2FA cannot turn it off.
+func (s *Service) DisableMFA(ctx context.Context, userID int64, code string) error {
+	if s.mfa == nil {
+		return ErrMFAUnavailable
+	}
+	u, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if s.mfaRequired(u.RoleCode) {
+		return ErrMFARequired
+	}
+	t, err := s.mfa.Repo.GetTOTP(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if t.IsConfirmed() {
+		if err := s.checkSecondFactor(ctx, t, code, ""); err != nil {
+			return err
+		}
+	}
+	return s.mfa.Repo.DeleteTOTP(ctx, userID)
+}
+
+// RegenerateRecoveryCodes replaces all recovery codes after checking a
+// current TOTP code.
+func (s *Service) RegenerateRecoveryCodes(ctx context.Context, userID int64, code string) ([]string, error) {
+	if s.mfa == nil {
+		return nil, ErrMFAUnavailable
+	}
+	t, err := s.mfa.Repo.GetTOTP(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !t.IsConfirmed() {
+		return nil, domauth.ErrMFANotEnrolled
+	}
+	if err := s.checkSecondFactor(ctx, t, code, ""); err != nil {
+		return nil, err
+	}
+	return s.newRecoveryCodes(ctx, userID)
+}
+
+func (s *Service) MFAStatus(ctx context.Context, userID int64) (*MFAStatus, error) {
+	if s.mfa == nil {
+		return nil, ErrMFAUnavailable
+	}
+	u, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	status := &MFAStatus{Required: s.mfaRequired(u.RoleCode)}
+	t, err := s.mfa.Repo.GetTOTP(ctx, userID)
+	if err != nil {
+		if errors.Is(err, domauth.ErrMFANotEnrolled) {
+			return status, nil
+		}
+		return nil, err
+	}
+	status.Enabled = t.IsConfirmed()
+	status.PendingConfirmation = !t.IsConfirmed()
+	if status.Enabled {
+		if status.RecoveryCodesLeft, err = s.mfa.Repo.CountRecoveryCodes(ctx, userID); err != nil {
+			return nil, err
+		}
+	}
+	return status, nil
+}
+
+func (s *Service) newRecoveryCodes(ctx context.Context, userID int64) ([]string, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		b := make([]byte, 6)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+		raw := strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(b))
+		codes[i] = raw[:5] + "-" + raw[5:10]
+		hashes[i] = hashRecoveryCode(codes[i])
+	}
+	if err := s.mfa.Repo.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// hashRecoveryCode ignores case, dashes and spaces the user may type.
+func hashRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	code = strings.NewReplacer("-", "", " ", "").Replace(code)
+	return hashToken(code)
+}
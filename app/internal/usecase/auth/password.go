@@ -0,0 +1,87 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	domauth "example.com/my-golang-sample/app/internal/domain/auth"
+	domuser "example.com/my-golang-sample/app/internal/domain/user"
+)
+
+var ErrPasswordResetDisabled = errors.New("password reset is disabled")
+
+// ForgotPassword emails a password reset link. As with ResendVerification,
+// unknown addresses are ignored so callers cannot tell which emails exist;
+// for the same reason a delivery error should be logged, not shown.
+func (s *Service) ForgotPassword(ctx context.Context, email string) error {
+	if s.accounts == nil {
+		return ErrPasswordResetDisabled
+	}
+	u, err := s.userRepo.GetByEmail(ctx, strings.TrimSpace(strings.ToLower(email)))
+	if err != nil {
+		if errors.Is(err, domuser.ErrUserNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	link, err := s.issueOneTimeToken(ctx, u.ID, domauth.PurposeResetPassword, s.accounts.ResetURL, s.accounts.ResetTTL)
+	if err != nil {
+		return err
+	}
+	body := fmt.Sprintf("Hi %s,\n\nSomeone asked to reset the password of your account. To choose a new password, open this link:\n\n%s\n\nThe link expires in %s. If you did not ask for it, you can ignore this email.\n",
+		u.Name, link, formatTTL(s.accounts.ResetTTL))
+	return s.accounts.Mailer.Send(ctx, u.Email, "Reset your password", body)
+}
+
+type ResetPasswordInput struct {
+	Token       string
+	NewPassword string
+}
+
+// ResetPassword consumes a reset token, stores the new password hash and
+// signs the user out of every session.
+func (s *Service) ResetPassword(ctx context.Context, in ResetPasswordInput) error {
+	if s.accounts == nil {
+		return ErrPasswordResetDisabled
+	}
+	if in.Token == "" {
+		return domauth.ErrInvalidOneTimeToken
+	}
+	if in.NewPassword == "" {
+		return domuser.ErrInvalidCredential
+	}
+
+	t, err := s.accounts.Tokens.Consume(ctx, domauth.PurposeResetPassword, hashToken(in.Token), s.now())
+	if err != nil {
+		return err
+	}
+	u, err := s.userRepo.GetByID(ctx, t.UserID)
+	if err != nil {
+		if errors.Is(err, domuser.ErrUserNotFound) {
+			return domauth.ErrInvalidOneTimeToken
+		}
+		return err
+	}
+
+	hash, err := s.accounts.Hasher.Hash(in.NewPassword)
+	if err != nil {
+		return err
+	}
+	u.PasswordHash = hash
+	// Link đến được email nên coi như email đã được xác minh
+	if !u.IsEmailVerified() {
+		now := s.now()
+		u.EmailVerifiedAt = &now
+	}
+	if _, err := s.userRepo.Update(ctx, u); err != nil {
+		return err
+	}
+
+	if err := s.accounts.Tokens.InvalidateForUser(ctx, u.ID, domauth.PurposeResetPassword); err != nil {
+		return err
+	}
+	return s.RevokeUserTokens(ctx, u.ID)
+}
@@ -2,8 +2,13 @@ package auth
 
 import (
 	"context"
+	"errors"
+	"log"
 	"strings"
+	"time"
 
+	domaudit "example.com/my-golang-sample/app/internal/domain/audit"
+	domauth "example.com/my-golang-sample/app/internal/domain/auth"
 	domuser "example.com/my-golang-sample/app/internal/domain/user"
 )
 
@@ -16,6 +21,10 @@ type Claims struct {
 	RoleCode domuser.RoleCode
 	Email    string
 	Name     string
+	// ID is the token's jti, used to revoke it before ExpiresAt.
+	ID        string
+	IssuedAt  time.Time
+	ExpiresAt time.Time
 }
 
 type TokenService interface {
@@ -23,32 +32,97 @@ type TokenService interface {
 	ParseToken(token string) (*Claims, error)
 }
 
+// JSONWebKey is a public verification key as published in a JWKS document
+// (RFC 7517). RSA keys set N and E, Ed25519 keys set Crv and X.
+type JSONWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// KeyPublisher is implemented by token services signing with asymmetric keys,
+// so other services can verify tokens without sharing a secret.
+type KeyPublisher interface {
+	PublicKeys() []JSONWebKey
+}
+
+// CartMerger folds an anonymous cart into the user's cart after login.
+type CartMerger interface {
+	MergeGuestCart(ctx context.Context, token string, userID int64) error
+}
+
 type Service struct {
 	userRepo domuser.Repository
 	checker  PasswordComparer
 	tokens   TokenService
+	carts    CartMerger
+
+	refresh    domauth.RefreshTokenRepository
+	denylist   domauth.Denylist
+	refreshTTL time.Duration
+	accounts   *AccountConfig
+	mfa        *MFAConfig
+	attempts   domauth.LoginAttemptRepository
+	lockout    LockoutPolicy
+	audit      domaudit.Repository
+	now        func() time.Time
 }
 
+// NewService wires the auth usecase. carts may be nil when guest carts are
+// not in use.
 func NewService(
 	userRepo domuser.Repository,
 	checker PasswordComparer,
 	tokens TokenService,
+	carts CartMerger,
+	opts ...Option,
 ) *Service {
-	return &Service{
-		userRepo: userRepo,
-		checker:  checker,
-		tokens:   tokens,
+	s := &Service{
+		userRepo:   userRepo,
+		checker:    checker,
+		tokens:     tokens,
+		carts:      carts,
+		refreshTTL: DefaultRefreshTTL,
+		now:        time.Now,
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 type LoginInput struct {
 	Email    string
 	Password string
+	// GuestCartToken, when set, is the anonymous cart to merge into the user's.
+	GuestCartToken string
+	// IP is the client address, used to throttle guessing across emails.
+	IP string
 }
 
 type LoginResult struct {
 	Token string
-	User  *domuser.User
+	// RefreshToken is empty when sessions are not enabled.
+	RefreshToken string
+	User         *domuser.User
+	// CartMergeFailed means the guest cart was left as is; the client keeps
+	// its token so the next login merges it.
+	CartMergeFailed bool
+
+	// MFARequired means the password was right but no token was issued yet:
+	// the client has to finish with VerifyMFA and ChallengeToken.
+	MFARequired bool
+	// MFAEnrollmentRequired means 2FA is mandatory for the user's role and
+	// not set up yet; the client enrolls with EnrollMFAWithChallenge first.
+	MFAEnrollmentRequired bool
+	ChallengeToken        string
+	// RecoveryCodes are only set when 2FA was enrolled during this login.
+	RecoveryCodes []string
 }
 
 func (s *Service) Login(ctx context.Context, in LoginInput) (*LoginResult, error) {
@@ -57,22 +131,74 @@ func (s *Service) Login(ctx context.Context, in LoginInput) (*LoginResult, error
 		return nil, domuser.ErrInvalidCredential
 	}
 
+	if err := s.checkLoginAllowed(ctx, email, in.IP); err != nil {
+		return nil, err
+	}
+
 	u, err := s.userRepo.GetByEmail(ctx, email)
 	if err != nil {
+		if errors.Is(err, domuser.ErrUserNotFound) {
+			if err := s.recordLoginFailure(ctx, email, in.IP, nil); err != nil {
+				return nil, err
+			}
+		}
 		return nil, domuser.ErrUnauthorized
 	}
 
 	if err := s.checker.Compare(u.PasswordHash, in.Password); err != nil {
+		if err := s.recordLoginFailure(ctx, email, in.IP, u); err != nil {
+			return nil, err
+		}
 		return nil, domuser.ErrUnauthorized
 	}
 
+	if s.accounts != nil && !u.IsEmailVerified() {
+		return nil, domuser.ErrEmailNotVerified
+	}
+
+	if s.mfa != nil {
+		challenge, err := s.mfaChallenge(ctx, u)
+		if err != nil || challenge != nil {
+			return challenge, err
+		}
+	}
+
+	return s.completeLogin(ctx, u, in.GuestCartToken)
+}
+
+// completeLogin issues the tokens once every login step has passed. Failed
+// attempts are only forgotten here, so a known password does not reset the
+// counter that guards the second factor.
+func (s *Service) completeLogin(ctx context.Context, u *domuser.User, guestCartToken string) (*LoginResult, error) {
+	if s.attempts != nil {
+		if err := s.attempts.Clear(ctx, domauth.ScopeAccount, u.Email); err != nil {
+			return nil, err
+		}
+	}
+
 	token, err := s.tokens.GenerateToken(u)
 	if err != nil {
 		return nil, err
 	}
 
+	// Giỏ khách không gộp được thì vẫn cho đăng nhập; giỏ còn nguyên để lần sau gộp lại
+	cartMergeFailed := false
+	if s.carts != nil && guestCartToken != "" {
+		if err := s.carts.MergeGuestCart(ctx, guestCartToken, u.ID); err != nil {
+			log.Printf("auth: merge guest cart for user %d: %v", u.ID, err)
+			cartMergeFailed = true
+		}
+	}
+
+	refreshToken, err := s.startSession(ctx, u.ID)
+	if err != nil {
+		return nil, err
+	}
+
 	return &LoginResult{
-		Token: token,
-		User:  u,
+		Token:           token,
+		RefreshToken:    refreshToken,
+		User:            u,
+		CartMergeFailed: cartMergeFailed,
 	}, nil
 }
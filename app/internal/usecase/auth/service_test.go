@@ -3,10 +3,15 @@ package auth
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
+	domaudit "example.com/my-golang-sample/app/internal/domain/audit"
+	domauth "example.com/my-golang-sample/app/internal/domain/auth"
+	"example.com/my-golang-sample/app/internal/domain/page"
 	domuser "example.com/my-golang-sample/app/internal/domain/user"
 )
 
@@ -22,11 +27,24 @@ func newMockUserRepository() *mockUserRepository {
 }
 
 func (m *mockUserRepository) Create(ctx context.Context, u *domuser.User) (*domuser.User, error) {
-	return nil, nil
+	if _, ok := m.usersByEmail[u.Email]; ok {
+		return nil, domuser.ErrEmailAlreadyUsed
+	}
+	cloned := *u
+	cloned.ID = int64(len(m.usersByEmail) + 100)
+	m.usersByEmail[u.Email] = &cloned
+	created := cloned
+	return &created, nil
 }
 
 func (m *mockUserRepository) GetByID(ctx context.Context, id int64) (*domuser.User, error) {
-	return nil, nil
+	for _, user := range m.usersByEmail {
+		if user.ID == id {
+			cloned := *user
+			return &cloned, nil
+		}
+	}
+	return nil, domuser.ErrUserNotFound
 }
 
 func (m *mockUserRepository) GetByEmail(ctx context.Context, email string) (*domuser.User, error) {
@@ -40,12 +58,15 @@ func (m *mockUserRepository) GetByEmail(ctx context.Context, email string) (*dom
 	return nil, domuser.ErrUserNotFound
 }
 
-func (m *mockUserRepository) List(ctx context.Context, filter domuser.ListUsersFilter) ([]*domuser.User, error) {
-	return nil, nil
+func (m *mockUserRepository) List(ctx context.Context, filter domuser.ListUsersFilter, req page.Request) ([]*domuser.User, page.Info, error) {
+	return nil, page.Info{}, nil
 }
 
 func (m *mockUserRepository) Update(ctx context.Context, u *domuser.User) (*domuser.User, error) {
-	return nil, nil
+	cloned := *u
+	m.usersByEmail[u.Email] = &cloned
+	updated := cloned
+	return &updated, nil
 }
 
 func (m *mockUserRepository) Delete(ctx context.Context, id int64) error {
@@ -53,6 +74,9 @@ func (m *mockUserRepository) Delete(ctx context.Context, id int64) error {
 }
 
 func (m *mockUserRepository) GetRoleIDByCode(ctx context.Context, code domuser.RoleCode) (int64, error) {
+	if code == domuser.RoleCodeCustomer {
+		return 3, nil
+	}
 	return 0, nil
 }
 
@@ -83,6 +107,306 @@ func (m *mockTokenService) ParseToken(token string) (*Claims, error) {
 	return nil, nil
 }
 
+type mockCartMerger struct {
+	token  string
+	userID int64
+	err    error
+}
+
+func (m *mockCartMerger) MergeGuestCart(ctx context.Context, token string, userID int64) error {
+	m.token = token
+	m.userID = userID
+	return m.err
+}
+
+type mockRefreshTokenRepository struct {
+	tokens []*domauth.RefreshToken
+}
+
+func (m *mockRefreshTokenRepository) Create(ctx context.Context, t *domauth.RefreshToken) error {
+	t.ID = int64(len(m.tokens) + 1)
+	m.tokens = append(m.tokens, t)
+	return nil
+}
+
+func (m *mockRefreshTokenRepository) GetByHash(ctx context.Context, tokenHash string) (*domauth.RefreshToken, error) {
+	for _, t := range m.tokens {
+		if t.TokenHash == tokenHash {
+			cloned := *t
+			return &cloned, nil
+		}
+	}
+	return nil, domauth.ErrRefreshTokenNotFound
+}
+
+func (m *mockRefreshTokenRepository) Rotate(ctx context.Context, oldID int64, next *domauth.RefreshToken) error {
+	old := m.tokens[oldID-1]
+	if old.IsRevoked() {
+		return domauth.ErrInvalidRefreshToken
+	}
+	now := time.Now()
+	old.RevokedAt = &now
+	return m.Create(ctx, next)
+}
+
+func (m *mockRefreshTokenRepository) RevokeFamily(ctx context.Context, familyID string) error {
+	now := time.Now()
+	for _, t := range m.tokens {
+		if t.FamilyID == familyID && !t.IsRevoked() {
+			t.RevokedAt = &now
+		}
+	}
+	return nil
+}
+
+func (m *mockRefreshTokenRepository) RevokeAllForUser(ctx context.Context, userID int64) error {
+	now := time.Now()
+	for _, t := range m.tokens {
+		if t.UserID == userID && !t.IsRevoked() {
+			t.RevokedAt = &now
+		}
+	}
+	return nil
+}
+
+func (m *mockRefreshTokenRepository) active() int {
+	n := 0
+	for _, t := range m.tokens {
+		if !t.IsRevoked() {
+			n++
+		}
+	}
+	return n
+}
+
+type mockDenylist struct {
+	revoked map[string]time.Time
+	cutoffs map[int64]time.Time
+}
+
+func (m *mockDenylist) Revoke(ctx context.Context, jti string, expiresAt time.Time) error {
+	if m.revoked == nil {
+		m.revoked = make(map[string]time.Time)
+	}
+	m.revoked[jti] = expiresAt
+	return nil
+}
+
+func (m *mockDenylist) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	_, ok := m.revoked[jti]
+	return ok, nil
+}
+
+func (m *mockDenylist) RevokeUser(ctx context.Context, userID int64, at time.Time) error {
+	if m.cutoffs == nil {
+		m.cutoffs = make(map[int64]time.Time)
+	}
+	m.cutoffs[userID] = at
+	return nil
+}
+
+func (m *mockDenylist) TokensValidAfter(ctx context.Context, userID int64) (time.Time, error) {
+	return m.cutoffs[userID], nil
+}
+
+type mockOneTimeTokenRepository struct {
+	tokens []*domauth.OneTimeToken
+}
+
+func (m *mockOneTimeTokenRepository) Create(ctx context.Context, t *domauth.OneTimeToken) error {
+	t.ID = int64(len(m.tokens) + 1)
+	m.tokens = append(m.tokens, t)
+	return nil
+}
+
+func (m *mockOneTimeTokenRepository) Consume(ctx context.Context, purpose domauth.TokenPurpose, tokenHash string, now time.Time) (*domauth.OneTimeToken, error) {
+	for _, t := range m.tokens {
+		if t.Purpose == purpose && t.TokenHash == tokenHash && t.UsedAt == nil && now.Before(t.ExpiresAt) {
+			t.UsedAt = &now
+			return t, nil
+		}
+	}
+	return nil, domauth.ErrInvalidOneTimeToken
+}
+
+func (m *mockOneTimeTokenRepository) InvalidateForUser(ctx context.Context, userID int64, purpose domauth.TokenPurpose) error {
+	now := time.Now()
+	for _, t := range m.tokens {
+		if t.UserID == userID && t.Purpose == purpose && t.UsedAt == nil {
+			t.UsedAt = &now
+		}
+	}
+	return nil
+}
+
+type sentMail struct {
+	to, subject, body string
+}
+
+type mockMailer struct {
+	sent    []sentMail
+	sendErr error
+}
+
+func (m *mockMailer) Send(ctx context.Context, to, subject, body string) error {
+	if m.sendErr != nil {
+		return m.sendErr
+	}
+	m.sent = append(m.sent, sentMail{to: to, subject: subject, body: body})
+	return nil
+}
+
+// lastToken extracts the token from the link in the most recent mail.
+func (m *mockMailer) lastToken(t *testing.T) string {
+	t.Helper()
+	require.NotEmpty(t, m.sent)
+	body := m.sent[len(m.sent)-1].body
+	_, after, ok := strings.Cut(body, "token=")
+	require.True(t, ok, "mail contains a token link")
+	token, _, _ := strings.Cut(after, "\n")
+	return token
+}
+
+type mockHasher struct{}
+
+func (mockHasher) Hash(password string) (string, error) {
+	return "hashed:" + password, nil
+}
+
+type mockLoginAttemptRepository struct {
+	counters map[string]*domauth.LoginFailures
+}
+
+func newMockLoginAttemptRepository() *mockLoginAttemptRepository {
+	return &mockLoginAttemptRepository{counters: make(map[string]*domauth.LoginFailures)}
+}
+
+func (m *mockLoginAttemptRepository) Get(ctx context.Context, scope domauth.LoginScope, key string) (*domauth.LoginFailures, error) {
+	if f, ok := m.counters[string(scope)+":"+key]; ok {
+		cloned := *f
+		return &cloned, nil
+	}
+	return &domauth.LoginFailures{Scope: scope, Key: key}, nil
+}
+
+func (m *mockLoginAttemptRepository) RecordFailure(ctx context.Context, scope domauth.LoginScope, key string, now time.Time, window time.Duration) (*domauth.LoginFailures, error) {
+	f, ok := m.counters[string(scope)+":"+key]
+	if !ok {
+		f = &domauth.LoginFailures{Scope: scope, Key: key}
+		m.counters[string(scope)+":"+key] = f
+	}
+	if f.LastFailedAt.Before(now.Add(-window)) {
+		f.Count = 0
+	}
+	f.Count++
+	f.LastFailedAt = now
+	cloned := *f
+	return &cloned, nil
+}
+
+func (m *mockLoginAttemptRepository) Lock(ctx context.Context, scope domauth.LoginScope, key string, until time.Time) error {
+	f := m.counters[string(scope)+":"+key]
+	f.Count = 0
+	f.LockedUntil = &until
+	return nil
+}
+
+func (m *mockLoginAttemptRepository) Clear(ctx context.Context, scope domauth.LoginScope, key string) error {
+	delete(m.counters, string(scope)+":"+key)
+	return nil
+}
+
+type mockAuditLog struct {
+	entries []*domaudit.Entry
+}
+
+func (m *mockAuditLog) Append(ctx context.Context, e *domaudit.Entry) error {
+	m.entries = append(m.entries, e)
+	return nil
+}
+
+func (m *mockAuditLog) List(ctx context.Context, filter domaudit.ListFilter, req page.Request) ([]*domaudit.Entry, page.Info, error) {
+	return m.entries, page.Info{Total: int64(len(m.entries)), Page: req.Page, Size: req.Size}, nil
+}
+
+type mockMFARepository struct {
+	secrets map[int64]*domauth.TOTPSecret
+	codes   map[int64]map[string]bool
+}
+
+func newMockMFARepository() *mockMFARepository {
+	return &mockMFARepository{secrets: make(map[int64]*domauth.TOTPSecret), codes: make(map[int64]map[string]bool)}
+}
+
+func (m *mockMFARepository) GetTOTP(ctx context.Context, userID int64) (*domauth.TOTPSecret, error) {
+	if t, ok := m.secrets[userID]; ok {
+		cloned := *t
+		return &cloned, nil
+	}
+	return nil, domauth.ErrMFANotEnrolled
+}
+
+func (m *mockMFARepository) SaveTOTP(ctx context.Context, t *domauth.TOTPSecret) error {
+	cloned := *t
+	m.secrets[t.UserID] = &cloned
+	return nil
+}
+
+func (m *mockMFARepository) DeleteTOTP(ctx context.Context, userID int64) error {
+	delete(m.secrets, userID)
+	delete(m.codes, userID)
+	return nil
+}
+
+func (m *mockMFARepository) UseStep(ctx context.Context, userID int64, step int64) (bool, error) {
+	t := m.secrets[userID]
+	if t.LastUsedStep >= step {
+		return false, nil
+	}
+	t.LastUsedStep = step
+	return true, nil
+}
+
+func (m *mockMFARepository) ReplaceRecoveryCodes(ctx context.Context, userID int64, hashes []string) error {
+	m.codes[userID] = make(map[string]bool)
+	for _, h := range hashes {
+		m.codes[userID][h] = false
+	}
+	return nil
+}
+
+func (m *mockMFARepository) UseRecoveryCode(ctx context.Context, userID int64, hash string, now time.Time) error {
+	used, ok := m.codes[userID][hash]
+	if !ok || used {
+		return domauth.ErrInvalidMFACode
+	}
+	m.codes[userID][hash] = true
+	return nil
+}
+
+func (m *mockMFARepository) CountRecoveryCodes(ctx context.Context, userID int64) (int, error) {
+	n := 0
+	for _, used := range m.codes[userID] {
+		if !used {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// mockTOTP accepts "123456" for any secret, in the 30s step of now.
+type mockTOTP struct{}
+
+func (mockTOTP) GenerateSecret() (string, error) { return "SECRET", nil }
+
+func (mockTOTP) ProvisioningURI(secret, account string) string {
+	return "otpauth://totp/test:" + account + "?secret=" + secret
+}
+
+func (mockTOTP) Validate(secret, code string, now time.Time) (int64, bool) {
+	return now.Unix() / 30, code == "123456"
+}
+
 func TestLogin_Success(t *testing.T) {
 	repo := newMockUserRepository()
 	user := &domuser.User{
@@ -98,7 +422,7 @@ func TestLogin_Success(t *testing.T) {
 	checker := &mockPasswordComparer{compareErr: nil}
 	tokenSvc := &mockTokenService{token: "valid-jwt-token"}
 
-	svc := NewService(repo, checker, tokenSvc)
+	svc := NewService(repo, checker, tokenSvc, nil)
 
 	result, err := svc.Login(context.Background(), LoginInput{
 		Email:    "john@example.com",
@@ -154,7 +478,7 @@ func TestLogin_EmailNormalization(t *testing.T) {
 			checker := &mockPasswordComparer{compareErr: nil}
 			tokenSvc := &mockTokenService{token: "valid-token"}
 
-			svc := NewService(repo, checker, tokenSvc)
+			svc := NewService(repo, checker, tokenSvc, nil)
 
 			result, err := svc.Login(context.Background(), LoginInput{
 				Email:    tt.inputEmail,
@@ -173,7 +497,7 @@ func TestLogin_UserNotFound(t *testing.T) {
 	checker := &mockPasswordComparer{}
 	tokenSvc := &mockTokenService{}
 
-	svc := NewService(repo, checker, tokenSvc)
+	svc := NewService(repo, checker, tokenSvc, nil)
 
 	result, err := svc.Login(context.Background(), LoginInput{
 		Email:    "nonexistent@example.com",
@@ -200,7 +524,7 @@ func TestLogin_WrongPassword(t *testing.T) {
 	checker := &mockPasswordComparer{compareErr: errors.New("password mismatch")}
 	tokenSvc := &mockTokenService{}
 
-	svc := NewService(repo, checker, tokenSvc)
+	svc := NewService(repo, checker, tokenSvc, nil)
 
 	result, err := svc.Login(context.Background(), LoginInput{
 		Email:    "john@example.com",
@@ -250,7 +574,7 @@ func TestLogin_EmptyEmailOrPassword(t *testing.T) {
 			checker := &mockPasswordComparer{}
 			tokenSvc := &mockTokenService{}
 
-			svc := NewService(repo, checker, tokenSvc)
+			svc := NewService(repo, checker, tokenSvc, nil)
 
 			result, err := svc.Login(context.Background(), LoginInput{
 				Email:    tt.email,
@@ -280,7 +604,7 @@ func TestLogin_TokenGenerationError(t *testing.T) {
 		generateErr: errors.New("token generation failed"),
 	}
 
-	svc := NewService(repo, checker, tokenSvc)
+	svc := NewService(repo, checker, tokenSvc, nil)
 
 	result, err := svc.Login(context.Background(), LoginInput{
 		Email:    "john@example.com",
@@ -327,7 +651,7 @@ func TestLogin_DifferentUserRoles(t *testing.T) {
 			checker := &mockPasswordComparer{compareErr: nil}
 			tokenSvc := &mockTokenService{token: "valid-token"}
 
-			svc := NewService(repo, checker, tokenSvc)
+			svc := NewService(repo, checker, tokenSvc, nil)
 
 			result, err := svc.Login(context.Background(), LoginInput{
 				Email:    "user@example.com",
@@ -341,3 +665,722 @@ func TestLogin_DifferentUserRoles(t *testing.T) {
 	}
 }
 
+func TestLogin_MergesGuestCart(t *testing.T) {
+	repo := newMockUserRepository()
+	repo.usersByEmail["john@example.com"] = &domuser.User{ID: 7, Email: "john@example.com", PasswordHash: "hash", RoleCode: domuser.RoleCodeCustomer}
+	merger := &mockCartMerger{}
+
+	svc := NewService(repo, &mockPasswordComparer{}, &mockTokenService{token: "jwt"}, merger)
+
+	_, err := svc.Login(context.Background(), LoginInput{
+		Email:          "john@example.com",
+		Password:       "secret",
+		GuestCartToken: "guest-token",
+	})
+
+	require.NoError(t, err)
+	require.Equal(t, "guest-token", merger.token)
+	require.Equal(t, int64(7), merger.userID)
+}
+
+func TestLogin_MergeFailureDoesNotFailLogin(t *testing.T) {
+	repo := newMockUserRepository()
+	repo.usersByEmail["john@example.com"] = &domuser.User{ID: 7, Email: "john@example.com", PasswordHash: "hash", RoleCode: domuser.RoleCodeCustomer}
+	merger := &mockCartMerger{err: errors.New("db down")}
+
+	svc := NewService(repo, &mockPasswordComparer{}, &mockTokenService{token: "jwt"}, merger)
+
+	result, err := svc.Login(context.Background(), LoginInput{
+		Email:          "john@example.com",
+		Password:       "secret",
+		GuestCartToken: "guest-token",
+	})
+
+	require.NoError(t, err)
+	require.Equal(t, "jwt", result.Token)
+	require.True(t, result.CartMergeFailed, "the client keeps its guest cart token")
+}
+
+func TestLogin_WithoutGuestTokenSkipsMerge(t *testing.T) {
+	repo := newMockUserRepository()
+	repo.usersByEmail["john@example.com"] = &domuser.User{ID: 7, Email: "john@example.com", PasswordHash: "hash", RoleCode: domuser.RoleCodeCustomer}
+	merger := &mockCartMerger{err: errors.New("should not be called")}
+
+	svc := NewService(repo, &mockPasswordComparer{}, &mockTokenService{token: "jwt"}, merger)
+
+	_, err := svc.Login(context.Background(), LoginInput{Email: "john@example.com", Password: "secret"})
+
+	require.NoError(t, err)
+}
+
+func setupSessionService() (*Service, *mockRefreshTokenRepository, *mockDenylist) {
+	repo := newMockUserRepository()
+	repo.usersByEmail["john@example.com"] = &domuser.User{ID: 7, Email: "john@example.com", PasswordHash: "hash", RoleCode: domuser.RoleCodeCustomer}
+	refresh := &mockRefreshTokenRepository{}
+	denylist := &mockDenylist{}
+
+	svc := NewService(repo, &mockPasswordComparer{}, &mockTokenService{}, nil, WithSessions(refresh, denylist, time.Hour))
+	return svc, refresh, denylist
+}
+
+func TestLogin_IssuesRefreshToken(t *testing.T) {
+	svc, refresh, _ := setupSessionService()
+
+	result, err := svc.Login(context.Background(), LoginInput{Email: "john@example.com", Password: "secret"})
+
+	require.NoError(t, err)
+	require.NotEmpty(t, result.RefreshToken)
+	require.Len(t, refresh.tokens, 1)
+	require.NotEqual(t, result.RefreshToken, refresh.tokens[0].TokenHash, "only the hash is stored")
+	require.Equal(t, int64(7), refresh.tokens[0].UserID)
+}
+
+func TestLogin_WithoutSessionsHasNoRefreshToken(t *testing.T) {
+	repo := newMockUserRepository()
+	repo.usersByEmail["john@example.com"] = &domuser.User{ID: 7, Email: "john@example.com", PasswordHash: "hash"}
+	svc := NewService(repo, &mockPasswordComparer{}, &mockTokenService{}, nil)
+
+	result, err := svc.Login(context.Background(), LoginInput{Email: "john@example.com", Password: "secret"})
+	require.NoError(t, err)
+	require.Empty(t, result.RefreshToken)
+
+	_, err = svc.Refresh(context.Background(), "anything")
+	require.ErrorIs(t, err, domauth.ErrInvalidRefreshToken)
+}
+
+func TestRefresh_RotatesToken(t *testing.T) {
+	svc, refresh, _ := setupSessionService()
+	login, err := svc.Login(context.Background(), LoginInput{Email: "john@example.com", Password: "secret"})
+	require.NoError(t, err)
+
+	result, err := svc.Refresh(context.Background(), login.RefreshToken)
+
+	require.NoError(t, err)
+	require.Equal(t, "mock-token-john@example.com", result.Token)
+	require.NotEmpty(t, result.RefreshToken)
+	require.NotEqual(t, login.RefreshToken, result.RefreshToken)
+	require.Equal(t, int64(7), result.User.ID)
+	require.Len(t, refresh.tokens, 2)
+	require.True(t, refresh.tokens[0].IsRevoked())
+	require.Equal(t, refresh.tokens[0].FamilyID, refresh.tokens[1].FamilyID)
+}
+
+func TestRefresh_ReusedTokenRevokesFamily(t *testing.T) {
+	svc, refresh, _ := setupSessionService()
+	login, err := svc.Login(context.Background(), LoginInput{Email: "john@example.com", Password: "secret"})
+	require.NoError(t, err)
+	rotated, err := svc.Refresh(context.Background(), login.RefreshToken)
+	require.NoError(t, err)
+
+	_, err = svc.Refresh(context.Background(), login.RefreshToken)
+	require.ErrorIs(t, err, domauth.ErrInvalidRefreshToken)
+	require.Zero(t, refresh.active(), "the stolen family is revoked")
+
+	_, err = svc.Refresh(context.Background(), rotated.RefreshToken)
+	require.ErrorIs(t, err, domauth.ErrInvalidRefreshToken)
+}
+
+func TestRefresh_InvalidOrExpiredToken(t *testing.T) {
+	svc, refresh, _ := setupSessionService()
+	login, err := svc.Login(context.Background(), LoginInput{Email: "john@example.com", Password: "secret"})
+	require.NoError(t, err)
+
+	_, err = svc.Refresh(context.Background(), "not-a-token")
+	require.ErrorIs(t, err, domauth.ErrInvalidRefreshToken)
+
+	refresh.tokens[0].ExpiresAt = time.Now().Add(-time.Minute)
+	_, err = svc.Refresh(context.Background(), login.RefreshToken)
+	require.ErrorIs(t, err, domauth.ErrInvalidRefreshToken)
+}
+
+func TestLogout_RevokesAccessTokenAndSession(t *testing.T) {
+	svc, refresh, denylist := setupSessionService()
+	login, err := svc.Login(context.Background(), LoginInput{Email: "john@example.com", Password: "secret"})
+	require.NoError(t, err)
+	other, err := svc.Login(context.Background(), LoginInput{Email: "john@example.com", Password: "secret"})
+	require.NoError(t, err)
+
+	err = svc.Logout(context.Background(), LogoutInput{
+		UserID:         7,
+		TokenID:        "jti-1",
+		TokenExpiresAt: time.Now().Add(time.Minute),
+		RefreshToken:   login.RefreshToken,
+	})
+
+	require.NoError(t, err)
+	revoked, err := svc.IsTokenRevoked(context.Background(), &Claims{UserID: 7, ID: "jti-1"})
+	require.NoError(t, err)
+	require.True(t, revoked)
+	require.Contains(t, denylist.revoked, "jti-1")
+
+	_, err = svc.Refresh(context.Background(), login.RefreshToken)
+	require.ErrorIs(t, err, domauth.ErrInvalidRefreshToken)
+	require.Equal(t, 1, refresh.active(), "other sessions stay signed in")
+
+	require.NoError(t, svc.Logout(context.Background(), LogoutInput{UserID: 7, AllSessions: true}))
+	_, err = svc.Refresh(context.Background(), other.RefreshToken)
+	require.ErrorIs(t, err, domauth.ErrInvalidRefreshToken)
+}
+
+func TestLogout_IgnoresOtherUsersRefreshToken(t *testing.T) {
+	svc, refresh, _ := setupSessionService()
+	login, err := svc.Login(context.Background(), LoginInput{Email: "john@example.com", Password: "secret"})
+	require.NoError(t, err)
+
+	err = svc.Logout(context.Background(), LogoutInput{UserID: 99, RefreshToken: login.RefreshToken})
+
+	require.NoError(t, err)
+	require.Equal(t, 1, refresh.active())
+}
+
+func TestRevokeUserTokens_VoidsEarlierAccessTokens(t *testing.T) {
+	svc, refresh, _ := setupSessionService()
+	now := time.Date(2026, 3, 1, 12, 0, 0, 500_000_000, time.UTC)
+	svc.now = func() time.Time { return now }
+	_, err := svc.Login(context.Background(), LoginInput{Email: "john@example.com", Password: "secret"})
+	require.NoError(t, err)
+
+	require.NoError(t, svc.RevokeUserTokens(context.Background(), 7))
+
+	require.Equal(t, 0, refresh.active())
+	tests := []struct {
+		name    string
+		claims  Claims
+		revoked bool
+	}{
+		{"issued earlier", Claims{UserID: 7, ID: "a", IssuedAt: now.Add(-time.Minute)}, true},
+		{"issued in the same second", Claims{UserID: 7, ID: "b", IssuedAt: now.Truncate(time.Second)}, true},
+		{"issued after", Claims{UserID: 7, ID: "c", IssuedAt: now.Add(time.Second)}, false},
+		{"other user", Claims{UserID: 8, ID: "d", IssuedAt: now.Add(-time.Minute)}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			revoked, err := svc.IsTokenRevoked(context.Background(), &tt.claims)
+
+			require.NoError(t, err)
+			require.Equal(t, tt.revoked, revoked)
+		})
+	}
+}
+
+func setupAccountService() (*Service, *mockUserRepository, *mockOneTimeTokenRepository, *mockMailer) {
+	repo := newMockUserRepository()
+	tokens := &mockOneTimeTokenRepository{}
+	mailer := &mockMailer{}
+	svc := NewService(repo, &mockPasswordComparer{}, &mockTokenService{}, nil, WithAccounts(AccountConfig{
+		Hasher:    mockHasher{},
+		Tokens:    tokens,
+		Mailer:    mailer,
+		VerifyURL: "http://localhost/verify",
+		ResetURL:  "http://localhost/reset",
+	}))
+	return svc, repo, tokens, mailer
+}
+
+func TestRegister_CreatesCustomerAndSendsVerification(t *testing.T) {
+	svc, repo, tokens, mailer := setupAccountService()
+
+	result, err := svc.Register(context.Background(), RegisterInput{Name: " Jane ", Email: "Jane@Example.com", Password: "secret123"})
+
+	require.NoError(t, err)
+	require.True(t, result.VerificationSent)
+	require.Equal(t, "jane@example.com", result.User.Email)
+	require.Equal(t, domuser.RoleCodeCustomer, result.User.RoleCode)
+	require.False(t, result.User.IsEmailVerified())
+	require.Equal(t, "hashed:secret123", repo.usersByEmail["jane@example.com"].PasswordHash)
+
+	require.Len(t, mailer.sent, 1)
+	require.Equal(t, "jane@example.com", mailer.sent[0].to)
+	require.Contains(t, mailer.sent[0].body, "http://localhost/verify?token=")
+	require.Len(t, tokens.tokens, 1)
+	require.Equal(t, domauth.PurposeVerifyEmail, tokens.tokens[0].Purpose)
+	require.NotEqual(t, mailer.lastToken(t), tokens.tokens[0].TokenHash, "only the hash is stored")
+}
+
+func TestRegister_DuplicateEmail(t *testing.T) {
+	svc, repo, _, _ := setupAccountService()
+	repo.usersByEmail["jane@example.com"] = &domuser.User{ID: 1, Email: "jane@example.com"}
+
+	_, err := svc.Register(context.Background(), RegisterInput{Name: "Jane", Email: "jane@example.com", Password: "secret123"})
+
+	require.ErrorIs(t, err, domuser.ErrEmailAlreadyUsed)
+}
+
+func TestRegister_MailFailureStillCreatesUser(t *testing.T) {
+	svc, repo, _, mailer := setupAccountService()
+	mailer.sendErr = errors.New("smtp down")
+
+	result, err := svc.Register(context.Background(), RegisterInput{Name: "Jane", Email: "jane@example.com", Password: "secret123"})
+
+	require.NoError(t, err)
+	require.False(t, result.VerificationSent)
+	require.Contains(t, repo.usersByEmail, "jane@example.com")
+}
+
+func TestRegister_Disabled(t *testing.T) {
+	svc := NewService(newMockUserRepository(), &mockPasswordComparer{}, &mockTokenService{}, nil)
+
+	_, err := svc.Register(context.Background(), RegisterInput{Name: "Jane", Email: "jane@example.com", Password: "secret123"})
+
+	require.ErrorIs(t, err, ErrRegistrationDisabled)
+}
+
+func TestVerifyEmail_UnlocksLoginAndIsSingleUse(t *testing.T) {
+	svc, _, _, mailer := setupAccountService()
+	_, err := svc.Register(context.Background(), RegisterInput{Name: "Jane", Email: "jane@example.com", Password: "secret123"})
+	require.NoError(t, err)
+
+	_, err = svc.Login(context.Background(), LoginInput{Email: "jane@example.com", Password: "secret123"})
+	require.ErrorIs(t, err, domuser.ErrEmailNotVerified)
+
+	token := mailer.lastToken(t)
+	u, err := svc.VerifyEmail(context.Background(), token)
+	require.NoError(t, err)
+	require.True(t, u.IsEmailVerified())
+
+	_, err = svc.Login(context.Background(), LoginInput{Email: "jane@example.com", Password: "secret123"})
+	require.NoError(t, err)
+
+	_, err = svc.VerifyEmail(context.Background(), token)
+	require.ErrorIs(t, err, domauth.ErrInvalidOneTimeToken)
+}
+
+func TestVerifyEmail_ExpiredToken(t *testing.T) {
+	svc, _, tokens, mailer := setupAccountService()
+	_, err := svc.Register(context.Background(), RegisterInput{Name: "Jane", Email: "jane@example.com", Password: "secret123"})
+	require.NoError(t, err)
+	tokens.tokens[0].ExpiresAt = time.Now().Add(-time.Minute)
+
+	_, err = svc.VerifyEmail(context.Background(), mailer.lastToken(t))
+
+	require.ErrorIs(t, err, domauth.ErrInvalidOneTimeToken)
+}
+
+func TestResendVerification_ReplacesPreviousLink(t *testing.T) {
+	svc, _, _, mailer := setupAccountService()
+	_, err := svc.Register(context.Background(), RegisterInput{Name: "Jane", Email: "jane@example.com", Password: "secret123"})
+	require.NoError(t, err)
+	first := mailer.lastToken(t)
+
+	require.NoError(t, svc.ResendVerification(context.Background(), "JANE@example.com"))
+	require.Len(t, mailer.sent, 2)
+
+	_, err = svc.VerifyEmail(context.Background(), first)
+	require.ErrorIs(t, err, domauth.ErrInvalidOneTimeToken)
+	_, err = svc.VerifyEmail(context.Background(), mailer.lastToken(t))
+	require.NoError(t, err)
+}
+
+func TestResendVerification_IgnoresUnknownAndVerifiedEmails(t *testing.T) {
+	svc, repo, _, mailer := setupAccountService()
+	verifiedAt := time.Now()
+	repo.usersByEmail["admin@example.com"] = &domuser.User{ID: 1, Email: "admin@example.com", EmailVerifiedAt: &verifiedAt}
+
+	require.NoError(t, svc.ResendVerification(context.Background(), "nobody@example.com"))
+	require.NoError(t, svc.ResendVerification(context.Background(), "admin@example.com"))
+	require.Empty(t, mailer.sent)
+}
+
+func TestForgotPassword_SendsResetLink(t *testing.T) {
+	svc, repo, tokens, mailer := setupAccountService()
+	repo.usersByEmail["john@example.com"] = &domuser.User{ID: 7, Name: "John", Email: "john@example.com", PasswordHash: "old"}
+
+	require.NoError(t, svc.ForgotPassword(context.Background(), " John@Example.com "))
+
+	require.Len(t, mailer.sent, 1)
+	require.Equal(t, "john@example.com", mailer.sent[0].to)
+	require.Contains(t, mailer.sent[0].body, "http://localhost/reset?token=")
+	require.Contains(t, mailer.sent[0].body, "1 hour")
+	require.Len(t, tokens.tokens, 1)
+	require.Equal(t, domauth.PurposeResetPassword, tokens.tokens[0].Purpose)
+}
+
+func TestForgotPassword_IgnoresUnknownEmail(t *testing.T) {
+	svc, _, tokens, mailer := setupAccountService()
+
+	require.NoError(t, svc.ForgotPassword(context.Background(), "nobody@example.com"))
+
+	require.Empty(t, mailer.sent)
+	require.Empty(t, tokens.tokens)
+}
+
+func TestResetPassword_ChangesPasswordAndRevokesSessions(t *testing.T) {
+	repo := newMockUserRepository()
+	verifiedAt := time.Now()
+	repo.usersByEmail["john@example.com"] = &domuser.User{ID: 7, Name: "John", Email: "john@example.com", PasswordHash: "old", EmailVerifiedAt: &verifiedAt}
+	refresh := &mockRefreshTokenRepository{}
+	denylist := &mockDenylist{}
+	mailer := &mockMailer{}
+	svc := NewService(repo, &mockPasswordComparer{}, &mockTokenService{}, nil,
+		WithSessions(refresh, denylist, time.Hour),
+		WithAccounts(AccountConfig{
+			Hasher:   mockHasher{},
+			Tokens:   &mockOneTimeTokenRepository{},
+			Mailer:   mailer,
+			ResetURL: "http://localhost/reset",
+		}))
+	login, err := svc.Login(context.Background(), LoginInput{Email: "john@example.com", Password: "old"})
+	require.NoError(t, err)
+	require.NoError(t, svc.ForgotPassword(context.Background(), "john@example.com"))
+	token := mailer.lastToken(t)
+
+	err = svc.ResetPassword(context.Background(), ResetPasswordInput{Token: token, NewPassword: "new-secret"})
+
+	require.NoError(t, err)
+	require.Equal(t, "hashed:new-secret", repo.usersByEmail["john@example.com"].PasswordHash)
+	require.Zero(t, refresh.active(), "every session is signed out")
+	_, err = svc.Refresh(context.Background(), login.RefreshToken)
+	require.ErrorIs(t, err, domauth.ErrInvalidRefreshToken)
+	require.Contains(t, denylist.cutoffs, int64(7), "access tokens issued before the reset stop working")
+
+	err = svc.ResetPassword(context.Background(), ResetPasswordInput{Token: token, NewPassword: "again"})
+	require.ErrorIs(t, err, domauth.ErrInvalidOneTimeToken, "reset tokens are single-use")
+}
+
+func TestResetPassword_RejectsExpiredAndSupersededTokens(t *testing.T) {
+	svc, repo, tokens, mailer := setupAccountService()
+	repo.usersByEmail["john@example.com"] = &domuser.User{ID: 7, Name: "John", Email: "john@example.com", PasswordHash: "old"}
+
+	require.NoError(t, svc.ForgotPassword(context.Background(), "john@example.com"))
+	first := mailer.lastToken(t)
+	require.NoError(t, svc.ForgotPassword(context.Background(), "john@example.com"))
+	second := mailer.lastToken(t)
+
+	err := svc.ResetPassword(context.Background(), ResetPasswordInput{Token: first, NewPassword: "new-secret"})
+	require.ErrorIs(t, err, domauth.ErrInvalidOneTimeToken)
+
+	tokens.tokens[1].ExpiresAt = time.Now().Add(-time.Minute)
+	err = svc.ResetPassword(context.Background(), ResetPasswordInput{Token: second, NewPassword: "new-secret"})
+	require.ErrorIs(t, err, domauth.ErrInvalidOneTimeToken)
+	require.Equal(t, "old", repo.usersByEmail["john@example.com"].PasswordHash)
+}
+
+func TestResetPassword_VerifyTokenCannotResetPassword(t *testing.T) {
+	svc, repo, _, mailer := setupAccountService()
+	_, err := svc.Register(context.Background(), RegisterInput{Name: "Jane", Email: "jane@example.com", Password: "secret123"})
+	require.NoError(t, err)
+
+	err = svc.ResetPassword(context.Background(), ResetPasswordInput{Token: mailer.lastToken(t), NewPassword: "new-secret"})
+
+	require.ErrorIs(t, err, domauth.ErrInvalidOneTimeToken)
+	require.Equal(t, "hashed:secret123", repo.usersByEmail["jane@example.com"].PasswordHash)
+}
+
+// setupLockoutService has no progressive delay unless a test sets one, and a
+// clock the test can move.
+func setupLockoutService(policy LockoutPolicy) (*Service, *mockPasswordComparer, *mockAuditLog, *time.Time) {
+	repo := newMockUserRepository()
+	repo.usersByEmail["john@example.com"] = &domuser.User{ID: 7, Email: "john@example.com", PasswordHash: "hash"}
+	checker := &mockPasswordComparer{compareErr: domuser.ErrInvalidCredential}
+	audit := &mockAuditLog{}
+	svc := NewService(repo, checker, &mockTokenService{}, nil,
+		WithLockout(newMockLoginAttemptRepository(), policy),
+		WithAudit(audit))
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+	return svc, checker, audit, &now
+}
+
+func TestLogin_LocksAccountAfterMaxFailures(t *testing.T) {
+	svc, checker, audit, now := setupLockoutService(LockoutPolicy{MaxAccountFailures: 3, LockoutDuration: 10 * time.Minute})
+	in := LoginInput{Email: "john@example.com", Password: "wrong", IP: "10.0.0.1"}
+
+	for i := 0; i < 2; i++ {
+		_, err := svc.Login(context.Background(), in)
+		require.ErrorIs(t, err, domuser.ErrUnauthorized)
+	}
+	_, err := svc.Login(context.Background(), in)
+	var throttled *LoginThrottledError
+	require.ErrorAs(t, err, &throttled)
+	require.True(t, throttled.Locked)
+	require.Equal(t, 10*time.Minute, throttled.RetryAfter)
+
+	require.Len(t, audit.entries, 1)
+	require.Equal(t, domaudit.ActionAccountLocked, audit.entries[0].Action)
+	require.Equal(t, "user", audit.entries[0].TargetType)
+	require.Equal(t, "7", audit.entries[0].TargetID)
+	require.Equal(t, "10.0.0.1", audit.entries[0].IP)
+	require.Nil(t, audit.entries[0].ActorID)
+
+	// Even the right password is refused while locked
+	checker.compareErr = nil
+	_, err = svc.Login(context.Background(), in)
+	require.ErrorIs(t, err, ErrTooManyLoginAttempts)
+
+	*now = now.Add(10 * time.Minute)
+	_, err = svc.Login(context.Background(), in)
+	require.NoError(t, err)
+}
+
+func TestVerifyPassword_SharesLockoutWithLogin(t *testing.T) {
+	svc, checker, _, _ := setupLockoutService(LockoutPolicy{MaxAccountFailures: 3})
+	u := &domuser.User{ID: 7, Email: "john@example.com", PasswordHash: "hash"}
+
+	for i := 0; i < 2; i++ {
+		require.ErrorIs(t, svc.VerifyPassword(context.Background(), u, "wrong"), domuser.ErrInvalidCredential)
+	}
+	_, err := svc.Login(context.Background(), LoginInput{Email: "john@example.com", Password: "wrong"})
+	require.ErrorIs(t, err, ErrTooManyLoginAttempts, "profile and login failures add up")
+
+	checker.compareErr = nil
+	require.ErrorIs(t, svc.VerifyPassword(context.Background(), u, "right"), ErrTooManyLoginAttempts)
+}
+
+func TestLogin_LocksUnknownEmailsToo(t *testing.T) {
+	svc, _, audit, _ := setupLockoutService(LockoutPolicy{MaxAccountFailures: 2})
+	in := LoginInput{Email: "ghost@example.com", Password: "wrong"}
+
+	_, err := svc.Login(context.Background(), in)
+	require.ErrorIs(t, err, domuser.ErrUnauthorized)
+	_, err = svc.Login(context.Background(), in)
+	require.ErrorIs(t, err, ErrTooManyLoginAttempts)
+
+	require.Len(t, audit.entries, 1)
+	require.Equal(t, "email", audit.entries[0].TargetType)
+	require.Equal(t, "ghost@example.com", audit.entries[0].TargetID)
+}
+
+func TestLogin_ProgressiveDelay(t *testing.T) {
+	svc, checker, _, now := setupLockoutService(LockoutPolicy{BaseDelay: time.Second, MaxDelay: 4 * time.Second})
+	in := LoginInput{Email: "john@example.com", Password: "wrong"}
+
+	_, err := svc.Login(context.Background(), in)
+	require.ErrorIs(t, err, domuser.ErrUnauthorized)
+	_, err = svc.Login(context.Background(), in)
+	require.ErrorIs(t, err, domuser.ErrUnauthorized, "the first failure costs no delay")
+
+	_, err = svc.Login(context.Background(), in)
+	var throttled *LoginThrottledError
+	require.ErrorAs(t, err, &throttled)
+	require.False(t, throttled.Locked)
+	require.Equal(t, time.Second, throttled.RetryAfter)
+
+	*now = now.Add(time.Second)
+	_, err = svc.Login(context.Background(), in)
+	require.ErrorIs(t, err, domuser.ErrUnauthorized)
+	_, err = svc.Login(context.Background(), in)
+	require.ErrorAs(t, err, &throttled)
+	require.Equal(t, 2*time.Second, throttled.RetryAfter, "the delay doubles")
+
+	*now = now.Add(2 * time.Second)
+	checker.compareErr = nil
+	_, err = svc.Login(context.Background(), in)
+	require.NoError(t, err)
+
+	// A successful login resets the counter
+	checker.compareErr = domuser.ErrInvalidCredential
+	_, err = svc.Login(context.Background(), in)
+	require.ErrorIs(t, err, domuser.ErrUnauthorized)
+}
+
+func TestLogin_LocksIPAcrossEmails(t *testing.T) {
+	svc, checker, audit, _ := setupLockoutService(LockoutPolicy{MaxIPFailures: 3})
+
+	for _, email := range []string{"a@example.com", "b@example.com"} {
+		_, err := svc.Login(context.Background(), LoginInput{Email: email, Password: "wrong", IP: "10.0.0.9"})
+		require.ErrorIs(t, err, domuser.ErrUnauthorized)
+	}
+	_, err := svc.Login(context.Background(), LoginInput{Email: "c@example.com", Password: "wrong", IP: "10.0.0.9"})
+	require.ErrorIs(t, err, ErrTooManyLoginAttempts)
+	require.Len(t, audit.entries, 1)
+	require.Equal(t, domaudit.ActionIPLocked, audit.entries[0].Action)
+	require.Equal(t, "10.0.0.9", audit.entries[0].TargetID)
+
+	checker.compareErr = nil
+	_, err = svc.Login(context.Background(), LoginInput{Email: "john@example.com", Password: "secret", IP: "10.0.0.9"})
+	require.ErrorIs(t, err, ErrTooManyLoginAttempts)
+	_, err = svc.Login(context.Background(), LoginInput{Email: "john@example.com", Password: "secret", IP: "10.0.0.10"})
+	require.NoError(t, err, "other addresses are not affected")
+}
+
+func TestUnlockAccount_ClearsLockoutAndAudits(t *testing.T) {
+	svc, checker, audit, _ := setupLockoutService(LockoutPolicy{MaxAccountFailures: 1})
+	_, err := svc.Login(context.Background(), LoginInput{Email: "john@example.com", Password: "wrong"})
+	require.ErrorIs(t, err, ErrTooManyLoginAttempts)
+
+	require.NoError(t, svc.UnlockAccount(context.Background(), 1, 7))
+
+	checker.compareErr = nil
+	_, err = svc.Login(context.Background(), LoginInput{Email: "john@example.com", Password: "secret"})
+	require.NoError(t, err)
+	require.Len(t, audit.entries, 2)
+	unlock := audit.entries[1]
+	require.Equal(t, domaudit.ActionAccountUnlocked, unlock.Action)
+	require.Equal(t, int64(1), *unlock.ActorID)
+	require.Equal(t, "7", unlock.TargetID)
+
+	require.ErrorIs(t, svc.UnlockAccount(context.Background(), 1, 404), domuser.ErrUserNotFound)
+}
+
+func setupMFAService(t *testing.T) (*Service, *mockUserRepository, *mockMFARepository, *time.Time) {
+	t.Helper()
+	repo := newMockUserRepository()
+	repo.usersByEmail["admin@example.com"] = &domuser.User{ID: 1, Email: "admin@example.com", PasswordHash: "hash", RoleCode: domuser.RoleCodeAdmin}
+	repo.usersByEmail["john@example.com"] = &domuser.User{ID: 7, Email: "john@example.com", PasswordHash: "hash", RoleCode: domuser.RoleCodeCustomer}
+	mfaRepo := newMockMFARepository()
+	svc := NewService(repo, &mockPasswordComparer{}, &mockTokenService{}, nil,
+		WithLockout(newMockLoginAttemptRepository(), LockoutPolicy{MaxAccountFailures: 3}),
+		WithMFA(MFAConfig{
+			TOTP:          mockTOTP{},
+			Repo:          mfaRepo,
+			Challenges:    &mockOneTimeTokenRepository{},
+			RequiredRoles: []domuser.RoleCode{domuser.RoleCodeAdmin, domuser.RoleCodeSuperAdmin},
+		}))
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+	return svc, repo, mfaRepo, &now
+}
+
+// enableMFA gives the user a confirmed secret with recovery codes.
+func enableMFA(t *testing.T, svc *Service, userID int64) []string {
+	t.Helper()
+	_, err := svc.StartMFAEnrollment(context.Background(), userID)
+	require.NoError(t, err)
+	codes, err := svc.ConfirmMFA(context.Background(), userID, "123456")
+	require.NoError(t, err)
+	return codes
+}
+
+func TestLogin_WithoutMFAIssuesTokens(t *testing.T) {
+	svc, _, _, _ := setupMFAService(t)
+
+	result, err := svc.Login(context.Background(), LoginInput{Email: "john@example.com", Password: "secret"})
+
+	require.NoError(t, err)
+	require.False(t, result.MFARequired)
+	require.NotEmpty(t, result.Token)
+}
+
+func TestLogin_WithMFARequiresSecondStep(t *testing.T) {
+	svc, _, _, now := setupMFAService(t)
+	enableMFA(t, svc, 7)
+	*now = now.Add(time.Minute)
+
+	result, err := svc.Login(context.Background(), LoginInput{Email: "john@example.com", Password: "secret"})
+	require.NoError(t, err)
+	require.True(t, result.MFARequired)
+	require.False(t, result.MFAEnrollmentRequired)
+	require.Empty(t, result.Token, "no token before the second factor")
+	require.NotEmpty(t, result.ChallengeToken)
+
+	verified, err := svc.VerifyMFA(context.Background(), VerifyMFAInput{ChallengeToken: result.ChallengeToken, Code: "123456"})
+	require.NoError(t, err)
+	require.Equal(t, "mock-token-john@example.com", verified.Token)
+	require.Empty(t, verified.RecoveryCodes)
+
+	_, err = svc.VerifyMFA(context.Background(), VerifyMFAInput{ChallengeToken: result.ChallengeToken, Code: "123456"})
+	require.ErrorIs(t, err, domauth.ErrInvalidOneTimeToken, "challenges are single-use")
+}
+
+func TestVerifyMFA_RejectsReplayedCode(t *testing.T) {
+	svc, _, _, now := setupMFAService(t)
+	enableMFA(t, svc, 7)
+	*now = now.Add(time.Minute)
+
+	first, err := svc.Login(context.Background(), LoginInput{Email: "john@example.com", Password: "secret"})
+	require.NoError(t, err)
+	_, err = svc.VerifyMFA(context.Background(), VerifyMFAInput{ChallengeToken: first.ChallengeToken, Code: "123456"})
+	require.NoError(t, err)
+
+	second, err := svc.Login(context.Background(), LoginInput{Email: "john@example.com", Password: "secret"})
+	require.NoError(t, err)
+	_, err = svc.VerifyMFA(context.Background(), VerifyMFAInput{ChallengeToken: second.ChallengeToken, Code: "123456"})
+	require.ErrorIs(t, err, domauth.ErrInvalidMFACode, "the same code cannot be used twice")
+}
+
+func TestVerifyMFA_RecoveryCodeWorksOnce(t *testing.T) {
+	svc, _, _, _ := setupMFAService(t)
+	codes := enableMFA(t, svc, 7)
+	require.Len(t, codes, 10)
+
+	login, err := svc.Login(context.Background(), LoginInput{Email: "john@example.com", Password: "secret"})
+	require.NoError(t, err)
+	_, err = svc.VerifyMFA(context.Background(), VerifyMFAInput{ChallengeToken: login.ChallengeToken, RecoveryCode: strings.ToUpper(codes[0])})
+	require.NoError(t, err)
+
+	login, err = svc.Login(context.Background(), LoginInput{Email: "john@example.com", Password: "secret"})
+	require.NoError(t, err)
+	_, err = svc.VerifyMFA(context.Background(), VerifyMFAInput{ChallengeToken: login.ChallengeToken, RecoveryCode: codes[0]})
+	require.ErrorIs(t, err, domauth.ErrInvalidMFACode)
+
+	status, err := svc.MFAStatus(context.Background(), 7)
+	require.NoError(t, err)
+	require.True(t, status.Enabled)
+	require.Equal(t, 9, status.RecoveryCodesLeft)
+}
+
+func TestVerifyMFA_WrongCodesLockTheAccount(t *testing.T) {
+	svc, _, _, _ := setupMFAService(t)
+	enableMFA(t, svc, 7)
+
+	var err error
+	for i := 0; i < 3; i++ {
+		login, loginErr := svc.Login(context.Background(), LoginInput{Email: "john@example.com", Password: "secret"})
+		require.NoError(t, loginErr, "a known password does not reset the counter")
+		_, err = svc.VerifyMFA(context.Background(), VerifyMFAInput{ChallengeToken: login.ChallengeToken, Code: "000000"})
+	}
+	require.ErrorIs(t, err, ErrTooManyLoginAttempts)
+
+	_, err = svc.Login(context.Background(), LoginInput{Email: "john@example.com", Password: "secret"})
+	require.ErrorIs(t, err, ErrTooManyLoginAttempts)
+}
+
+func TestLogin_AdminMustEnrollMFA(t *testing.T) {
+	svc, _, mfaRepo, _ := setupMFAService(t)
+
+	login, err := svc.Login(context.Background(), LoginInput{Email: "admin@example.com", Password: "secret"})
+	require.NoError(t, err)
+	require.True(t, login.MFARequired)
+	require.True(t, login.MFAEnrollmentRequired)
+	require.Empty(t, login.Token)
+
+	_, err = svc.VerifyMFA(context.Background(), VerifyMFAInput{ChallengeToken: login.ChallengeToken, Code: "123456"})
+	require.ErrorIs(t, err, domauth.ErrInvalidMFACode, "there is no secret to check yet")
+
+	login, err = svc.Login(context.Background(), LoginInput{Email: "admin@example.com", Password: "secret"})
+	require.NoError(t, err)
+	enrollment, err := svc.EnrollMFAWithChallenge(context.Background(), login.ChallengeToken)
+	require.NoError(t, err)
+	require.Equal(t, "SECRET", enrollment.Secret)
+	require.Contains(t, enrollment.ProvisioningURI, "admin@example.com")
+	require.NotEqual(t, login.ChallengeToken, enrollment.ChallengeToken)
+
+	result, err := svc.VerifyMFA(context.Background(), VerifyMFAInput{ChallengeToken: enrollment.ChallengeToken, Code: "123456"})
+	require.NoError(t, err)
+	require.Equal(t, "mock-token-admin@example.com", result.Token)
+	require.Len(t, result.RecoveryCodes, 10)
+	require.True(t, mfaRepo.secrets[1].IsConfirmed())
+}
+
+func TestDisableMFA(t *testing.T) {
+	svc, _, mfaRepo, now := setupMFAService(t)
+	enableMFA(t, svc, 7)
+	*now = now.Add(time.Minute)
+
+	require.ErrorIs(t, svc.DisableMFA(context.Background(), 7, "000000"), domauth.ErrInvalidMFACode)
+	require.NoError(t, svc.DisableMFA(context.Background(), 7, "123456"))
+	require.NotContains(t, mfaRepo.secrets, int64(7))
+
+	enableMFA(t, svc, 1)
+	*now = now.Add(time.Minute)
+	require.ErrorIs(t, svc.DisableMFA(context.Background(), 1, "123456"), ErrMFARequired)
+}
+
+func TestStartMFAEnrollment_AlreadyEnabledAndWrongCode(t *testing.T) {
+	svc, _, _, _ := setupMFAService(t)
+
+	_, err := svc.StartMFAEnrollment(context.Background(), 7)
+	require.NoError(t, err)
+	_, err = svc.ConfirmMFA(context.Background(), 7, "000000")
+	require.ErrorIs(t, err, domauth.ErrInvalidMFACode)
+
+	status, err := svc.MFAStatus(context.Background(), 7)
+	require.NoError(t, err)
+	require.False(t, status.Enabled)
+	require.True(t, status.PendingConfirmation)
+
+	_, err = svc.ConfirmMFA(context.Background(), 7, "123456")
+	require.NoError(t, err)
+	_, err = svc.StartMFAEnrollment(context.Background(), 7)
+	require.ErrorIs(t, err, domauth.ErrMFAAlreadyEnabled)
+}
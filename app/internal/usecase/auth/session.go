@@ -0,0 +1,213 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	domauth "example.com/my-golang-sample/app/internal/domain/auth"
+	domuser "example.com/my-golang-sample/app/internal/domain/user"
+)
+
+// DefaultRefreshTTL is how long a refresh token stays valid when unused.
+const DefaultRefreshTTL = 30 * 24 * time.Hour
+
+type Option func(*Service)
+
+// WithSessions enables refresh tokens and access token revocation. Without it
+// Login only returns an access token and Refresh always fails.
+func WithSessions(refresh domauth.RefreshTokenRepository, denylist domauth.Denylist, refreshTTL time.Duration) Option {
+	return func(s *Service) {
+		s.refresh = refresh
+		s.denylist = denylist
+		if refreshTTL > 0 {
+			s.refreshTTL = refreshTTL
+		}
+	}
+}
+
+type LogoutInput struct {
+	UserID int64
+	// TokenID and TokenExpiresAt identify the access token used for the call.
+	TokenID        string
+	TokenExpiresAt time.Time
+	// RefreshToken, when set, ends that session too.
+	RefreshToken string
+	// AllSessions revokes every refresh token of the user.
+	AllSessions bool
+}
+
+// Refresh exchanges a refresh token for a new access token and a new refresh
+// token. Presenting a token that was already rotated means it leaked, so the
+// whole family is revoked.
+func (s *Service) Refresh(ctx context.Context, refreshToken string) (*LoginResult, error) {
+	if s.refresh == nil || refreshToken == "" {
+		return nil, domauth.ErrInvalidRefreshToken
+	}
+
+	current, err := s.refresh.GetByHash(ctx, hashToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, domauth.ErrRefreshTokenNotFound) {
+			return nil, domauth.ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+	if current.IsRevoked() {
+		if err := s.refresh.RevokeFamily(ctx, current.FamilyID); err != nil {
+			return nil, err
+		}
+		return nil, domauth.ErrInvalidRefreshToken
+	}
+	if current.IsExpired(s.now()) {
+		return nil, domauth.ErrInvalidRefreshToken
+	}
+
+	u, err := s.userRepo.GetByID(ctx, current.UserID)
+	if err != nil {
+		if errors.Is(err, domuser.ErrUserNotFound) {
+			return nil, domauth.ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+
+	raw, next, err := s.newRefreshToken(u.ID, current.FamilyID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.refresh.Rotate(ctx, current.ID, next); err != nil {
+		return nil, err
+	}
+
+	token, err := s.tokens.GenerateToken(u)
+	if err != nil {
+		return nil, err
+	}
+	return &LoginResult{
+		Token:        token,
+		RefreshToken: raw,
+		User:         u,
+	}, nil
+}
+
+// Logout revokes the access token immediately and ends the given session
+// (or all of the user's sessions).
+func (s *Service) Logout(ctx context.Context, in LogoutInput) error {
+	if s.denylist != nil && in.TokenID != "" {
+		if err := s.denylist.Revoke(ctx, in.TokenID, in.TokenExpiresAt); err != nil {
+			return err
+		}
+	}
+	if s.refresh == nil {
+		return nil
+	}
+
+	if in.AllSessions {
+		return s.RevokeSessions(ctx, in.UserID)
+	}
+	if in.RefreshToken == "" {
+		return nil
+	}
+	rt, err := s.refresh.GetByHash(ctx, hashToken(in.RefreshToken))
+	if err != nil {
+		if errors.Is(err, domauth.ErrRefreshTokenNotFound) {
+			return nil
+		}
+		return err
+	}
+	// Không cho phép logout session của user khác
+	if rt.UserID != in.UserID {
+		return nil
+	}
+	return s.refresh.RevokeFamily(ctx, rt.FamilyID)
+}
+
+// RevokeSessions ends every refresh token of the user, e.g. after a password
+// change. Access tokens already issued stay valid until they expire.
+func (s *Service) RevokeSessions(ctx context.Context, userID int64) error {
+	if s.refresh == nil {
+		return nil
+	}
+	return s.refresh.RevokeAllForUser(ctx, userID)
+}
+
+// RevokeUserTokens ends every session of the user and voids the access
+// tokens already issued, e.g. after the user's role changed or the account
+// was deleted.
+func (s *Service) RevokeUserTokens(ctx context.Context, userID int64) error {
+	if err := s.RevokeSessions(ctx, userID); err != nil {
+		return err
+	}
+	if s.denylist == nil {
+		return nil
+	}
+	return s.denylist.RevokeUser(ctx, userID, s.now().Truncate(time.Second))
+}
+
+// IsTokenRevoked reports whether the access token was revoked on its own
+// (by jti) or together with every token of its user.
+func (s *Service) IsTokenRevoked(ctx context.Context, claims *Claims) (bool, error) {
+	if s.denylist == nil {
+		return false, nil
+	}
+	if claims.ID != "" {
+		revoked, err := s.denylist.IsRevoked(ctx, claims.ID)
+		if err != nil || revoked {
+			return revoked, err
+		}
+	}
+	cutoff, err := s.denylist.TokensValidAfter(ctx, claims.UserID)
+	if err != nil {
+		return false, err
+	}
+	// iat chỉ chính xác đến giây nên token cấp trong cùng giây với cut-off cũng bị từ chối
+	return !cutoff.IsZero() && !claims.IssuedAt.After(cutoff), nil
+}
+
+// startSession issues the first refresh token of a new family after login.
+func (s *Service) startSession(ctx context.Context, userID int64) (string, error) {
+	if s.refresh == nil {
+		return "", nil
+	}
+	family, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+	raw, rt, err := s.newRefreshToken(userID, family)
+	if err != nil {
+		return "", err
+	}
+	if err := s.refresh.Create(ctx, rt); err != nil {
+		return "", err
+	}
+	return raw, nil
+}
+
+func (s *Service) newRefreshToken(userID int64, familyID string) (string, *domauth.RefreshToken, error) {
+	raw, err := randomToken()
+	if err != nil {
+		return "", nil, err
+	}
+	return raw, &domauth.RefreshToken{
+		UserID:    userID,
+		TokenHash: hashToken(raw),
+		FamilyID:  familyID,
+		ExpiresAt: s.now().Add(s.refreshTTL),
+	}, nil
+}
+
+func randomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashToken(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,127 @@
+package cart
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+
+	domcart "example.com/my-golang-sample/app/internal/domain/cart"
+	domproduct "example.com/my-golang-sample/app/internal/domain/product"
+)
+
+// MergeStrategy decides the quantity of a product that is both in the guest
+// cart and in the user's cart when the guest logs in.
+type MergeStrategy string
+
+const (
+	MergeSum       MergeStrategy = "sum"        // add both quantities
+	MergeMax       MergeStrategy = "max"        // keep the larger quantity
+	MergeKeepUser  MergeStrategy = "keep_user"  // the user's line wins
+	MergeKeepGuest MergeStrategy = "keep_guest" // the guest line wins
+)
+
+func ParseMergeStrategy(s string) (MergeStrategy, error) {
+	switch st := MergeStrategy(s); st {
+	case MergeSum, MergeMax, MergeKeepUser, MergeKeepGuest:
+		return st, nil
+	default:
+		return "", fmt.Errorf("unknown cart merge strategy %q", s)
+	}
+}
+
+type MergeRules struct {
+	Strategy MergeStrategy
+	// CapAtStock lowers merged quantities to the product's available stock.
+	CapAtStock bool
+}
+
+var DefaultMergeRules = MergeRules{Strategy: MergeSum, CapAtStock: true}
+
+// NewGuestToken returns a random opaque token identifying an anonymous cart.
+func NewGuestToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// MergeGuestCart moves the guest cart identified by token into the user's cart
+// and empties it, atomically. Inactive or deleted products are dropped.
+func (s *Service) MergeGuestCart(ctx context.Context, token string, userID int64) error {
+	if token == "" {
+		return nil
+	}
+	guest := domcart.GuestOwner(token)
+	user := domcart.UserOwner(userID)
+
+	guestItems, err := s.cartRepo.ListItems(ctx, guest)
+	if err != nil {
+		return err
+	}
+	if len(guestItems) == 0 {
+		return nil
+	}
+
+	userItems, err := s.cartRepo.ListItems(ctx, user)
+	if err != nil {
+		return err
+	}
+	current := make(map[int64]int64, len(userItems))
+	for _, item := range userItems {
+		current[item.ProductID] = item.Quantity
+	}
+
+	ids := make([]int64, 0, len(guestItems))
+	for _, item := range guestItems {
+		ids = append(ids, item.ProductID)
+	}
+	products, err := s.productRepo.GetByIDs(ctx, ids)
+	if err != nil {
+		return err
+	}
+	productMap := make(map[int64]*domproduct.Product, len(products))
+	for _, p := range products {
+		productMap[p.ID] = p
+	}
+
+	merged := make([]domcart.Item, 0, len(guestItems))
+	for _, item := range guestItems {
+		p, ok := productMap[item.ProductID]
+		if !ok || !p.IsActive {
+			continue
+		}
+
+		existing, inCart := current[item.ProductID]
+		quantity := s.mergeRules.merge(existing, item.Quantity, inCart)
+		if s.mergeRules.CapAtStock && quantity > p.Stock {
+			quantity = p.Stock
+		}
+		if quantity == existing || quantity <= 0 {
+			// nothing to add (or out of stock): leave the user's line untouched
+			continue
+		}
+		merged = append(merged, domcart.Item{ProductID: item.ProductID, Quantity: quantity})
+	}
+
+	// Gộp và xóa giỏ khách trong một transaction: lỗi giữa chừng không để lại
+	// giỏ khách đã cộng một phần, nên lần thử lại không nhân đôi số lượng
+	return s.cartRepo.MergeItems(ctx, guest, user, merged)
+}
+
+func (r MergeRules) merge(userQty, guestQty int64, inCart bool) int64 {
+	if !inCart {
+		return guestQty
+	}
+	switch r.Strategy {
+	case MergeMax:
+		return max(userQty, guestQty)
+	case MergeKeepUser:
+		return userQty
+	case MergeKeepGuest:
+		return guestQty
+	default:
+		return userQty + guestQty
+	}
+}
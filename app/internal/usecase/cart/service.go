@@ -3,9 +3,12 @@ package cart
 import (
 	"context"
 	"errors"
+	"fmt"
 
 	domcart "example.com/my-golang-sample/app/internal/domain/cart"
+	"example.com/my-golang-sample/app/internal/domain/money"
 	domorder "example.com/my-golang-sample/app/internal/domain/order"
+	dompayment "example.com/my-golang-sample/app/internal/domain/payment"
 	domproduct "example.com/my-golang-sample/app/internal/domain/product"
 )
 
@@ -19,24 +22,54 @@ type ProductRepository interface {
 }
 
 type OrderRepository interface {
-	CreateFromCart(ctx context.Context, userID int64, items []domcart.Item, payment domorder.PaymentMethod) (*domorder.Order, error)
+	CreateFromCart(ctx context.Context, userID int64, items []domcart.Item, payment domorder.PaymentMethod, currency string) (*domorder.Order, error)
+	UpdateStatus(ctx context.Context, change domorder.StatusChange) (*domorder.Order, error)
+}
+
+// PaymentStarter opens an online payment session for a freshly created order.
+type PaymentStarter interface {
+	StartCheckout(ctx context.Context, order *domorder.Order) (*dompayment.Payment, error)
 }
 
 type Service struct {
 	cartRepo    CartRepository
 	productRepo ProductRepository
 	orderRepo   OrderRepository
+	payments    PaymentStarter
+	mergeRules  MergeRules
+}
+
+type Option func(*Service)
+
+// WithMergeRules overrides DefaultMergeRules for guest carts merged on login.
+func WithMergeRules(rules MergeRules) Option {
+	return func(s *Service) {
+		s.mergeRules = rules
+	}
 }
 
-func NewService(cartRepo CartRepository, productRepo ProductRepository, orderRepo OrderRepository) *Service {
-	return &Service{
+// NewService wires the cart usecase. payments may be nil, in which case
+// every order is left to be settled offline.
+func NewService(cartRepo CartRepository, productRepo ProductRepository, orderRepo OrderRepository, payments PaymentStarter, opts ...Option) *Service {
+	s := &Service{
 		cartRepo:    cartRepo,
 		productRepo: productRepo,
 		orderRepo:   orderRepo,
+		payments:    payments,
+		mergeRules:  DefaultMergeRules,
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
-func (s *Service) AddToCart(ctx context.Context, userID, productID int64, quantity int64) error {
+type CheckoutResult struct {
+	Order   *domorder.Order
+	Payment *dompayment.Payment
+}
+
+func (s *Service) AddToCart(ctx context.Context, owner domcart.Owner, productID int64, quantity int64) error {
 	if quantity <= 0 {
 		return errors.New("quantity must be positive")
 	}
@@ -50,7 +83,7 @@ func (s *Service) AddToCart(ctx context.Context, userID, productID int64, quanti
 		return domproduct.ErrProductNotFound
 	}
 
-	currentItems, _ := s.cartRepo.ListItems(ctx, userID)
+	currentItems, _ := s.cartRepo.ListItems(ctx, owner)
 	var currentQuantity int64
 	for _, item := range currentItems {
 		if item.ProductID == productID {
@@ -63,16 +96,68 @@ func (s *Service) AddToCart(ctx context.Context, userID, productID int64, quanti
 		return domproduct.ErrOutOfStock
 	}
 
-	return s.cartRepo.AddOrUpdateItem(ctx, userID, productID, quantity)
+	return s.cartRepo.AddOrUpdateItem(ctx, owner, productID, quantity)
+}
+
+// UpdateItemQuantity sets the absolute quantity of a cart line; zero removes it.
+func (s *Service) UpdateItemQuantity(ctx context.Context, owner domcart.Owner, productID int64, quantity int64) error {
+	if quantity < 0 {
+		return errors.New("quantity must not be negative")
+	}
+	if quantity == 0 {
+		return s.cartRepo.RemoveItem(ctx, owner, productID)
+	}
+
+	items, err := s.cartRepo.ListItems(ctx, owner)
+	if err != nil {
+		return err
+	}
+	inCart := false
+	for _, item := range items {
+		if item.ProductID == productID {
+			inCart = true
+			break
+		}
+	}
+	if !inCart {
+		return domcart.ErrCartItemNotFound
+	}
+
+	product, err := s.productRepo.GetByID(ctx, productID)
+	if err != nil {
+		return err
+	}
+	if !product.IsActive {
+		return domproduct.ErrProductNotFound
+	}
+	if quantity > product.Stock {
+		return domproduct.ErrOutOfStock
+	}
+
+	return s.cartRepo.SetItemQuantity(ctx, owner, productID, quantity)
+}
+
+func (s *Service) RemoveItem(ctx context.Context, owner domcart.Owner, productID int64) error {
+	return s.cartRepo.RemoveItem(ctx, owner, productID)
+}
+
+func (s *Service) ClearCart(ctx context.Context, owner domcart.Owner) error {
+	return s.cartRepo.Clear(ctx, owner)
 }
 
-func (s *Service) GetCart(ctx context.Context, userID int64) (*domcart.Cart, error) {
-	items, err := s.cartRepo.ListItems(ctx, userID)
+func (s *Service) GetCart(ctx context.Context, owner domcart.Owner, currency string) (*domcart.Cart, error) {
+	items, err := s.cartRepo.ListItems(ctx, owner)
 	if err != nil {
 		return nil, err
 	}
+	cart := &domcart.Cart{
+		UserID:   owner.UserID,
+		Items:    make([]domcart.DetailedItem, 0, len(items)),
+		Total:    money.Zero(currency),
+		Warnings: []domcart.Warning{},
+	}
 	if len(items) == 0 {
-		return &domcart.Cart{UserID: userID, Items: []domcart.DetailedItem{}}, nil
+		return cart, nil
 	}
 
 	ids := make([]int64, 0, len(items))
@@ -90,33 +175,79 @@ func (s *Service) GetCart(ctx context.Context, userID int64) (*domcart.Cart, err
 		productMap[p.ID] = p
 	}
 
-	cart := &domcart.Cart{
-		UserID: userID,
-		Items:  make([]domcart.DetailedItem, 0, len(items)),
-	}
-
 	for _, item := range items {
-		if p, ok := productMap[item.ProductID]; ok {
-			cart.Items = append(cart.Items, domcart.DetailedItem{
-				Item: domcart.Item{
-					ProductID: item.ProductID,
-					Quantity:  item.Quantity,
-				},
-				ProductName:  p.Name,
-				ProductPrice: p.Price,
+		p, ok := productMap[item.ProductID]
+		if !ok {
+			cart.Warnings = append(cart.Warnings, domcart.Warning{
+				ProductID: item.ProductID,
+				Code:      domcart.WarningProductDeleted,
+				Message:   "product is no longer available",
+			})
+			continue
+		}
+		if !p.IsActive {
+			cart.Warnings = append(cart.Warnings, domcart.Warning{
+				ProductID: item.ProductID,
+				Code:      domcart.WarningProductInactive,
+				Message:   fmt.Sprintf("%s is currently not for sale", p.Name),
 			})
+			continue
 		}
+
+		price, err := p.PriceIn(currency)
+		if err != nil {
+			cart.Warnings = append(cart.Warnings, domcart.Warning{
+				ProductID: item.ProductID,
+				Code:      domcart.WarningPriceUnavailable,
+				Message:   fmt.Sprintf("%s is not sold in %s", p.Name, currency),
+			})
+			continue
+		}
+
+		// The snapshot is in the base currency, so repricing is detected there
+		if !item.UnitPrice.IsZero() && item.UnitPrice != p.Price {
+			cart.Warnings = append(cart.Warnings, domcart.Warning{
+				ProductID: item.ProductID,
+				Code:      domcart.WarningPriceChanged,
+				Message:   fmt.Sprintf("price of %s changed from %s to %s", p.Name, item.UnitPrice, p.Price),
+			})
+		}
+		if item.Quantity > p.Stock {
+			cart.Warnings = append(cart.Warnings, domcart.Warning{
+				ProductID: item.ProductID,
+				Code:      domcart.WarningInsufficientStock,
+				Message:   fmt.Sprintf("only %d of %s left in stock", p.Stock, p.Name),
+			})
+		}
+
+		subtotal, err := price.Mul(item.Quantity)
+		if err != nil {
+			return nil, err
+		}
+		cart.Items = append(cart.Items, domcart.DetailedItem{
+			Item:         item,
+			ProductName:  p.Name,
+			ProductPrice: price,
+			Subtotal:     subtotal,
+		})
+		if cart.Total, err = cart.Total.Add(subtotal); err != nil {
+			return nil, err
+		}
+		cart.ItemCount += item.Quantity
 	}
 
 	return cart, nil
 }
 
-func (s *Service) Checkout(ctx context.Context, userID int64, method domorder.PaymentMethod) (*domorder.Order, error) {
+// Checkout places an order for the user's cart, priced in currency (the
+// store currency if empty).
+func (s *Service) Checkout(ctx context.Context, userID int64, method domorder.PaymentMethod, currency string) (*CheckoutResult, error) {
 	if !method.IsValid() {
 		return nil, domorder.ErrInvalidPayment
 	}
 
-	items, err := s.cartRepo.ListItems(ctx, userID)
+	owner := domcart.UserOwner(userID)
+	items, err := s.cartRepo.ListItems(ctx, owner)
 	if err != nil {
 		return nil, err
 	}
@@ -124,14 +255,30 @@ func (s *Service) Checkout(ctx context.Context, userID int64, method domorder.Pa
 		return nil, domorder.ErrEmptyOrderItems
 	}
 
-	order, err := s.orderRepo.CreateFromCart(ctx, userID, items, method)
+	order, err := s.orderRepo.CreateFromCart(ctx, userID, items, method, currency)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := s.cartRepo.Clear(ctx, userID); err != nil {
+	var payment *dompayment.Payment
+	if s.payments != nil {
+		payment, err = s.payments.StartCheckout(ctx, order)
+		if err != nil {
+			// Không mở được phiên thanh toán → huỷ đơn, giữ nguyên giỏ hàng để user thử lại
+			_, _ = s.orderRepo.UpdateStatus(ctx, domorder.StatusChange{
+				OrderID:   order.ID,
+				From:      order.Status,
+				To:        domorder.StatusCanceled,
+				ChangedBy: userID,
+				Note:      "payment session could not be opened",
+			})
+			return nil, err
+		}
+	}
+
+	if err := s.cartRepo.Clear(ctx, owner); err != nil {
 		return nil, err
 	}
 
-	return order, nil
+	return &CheckoutResult{Order: order, Payment: payment}, nil
 }
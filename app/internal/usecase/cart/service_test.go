@@ -2,34 +2,37 @@ package cart
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 
 	domcart "example.com/my-golang-sample/app/internal/domain/cart"
+	"example.com/my-golang-sample/app/internal/domain/money"
 	domorder "example.com/my-golang-sample/app/internal/domain/order"
 	domproduct "example.com/my-golang-sample/app/internal/domain/product"
 )
 
 type mockCartRepository struct {
-	itemsByUser map[int64][]domcart.Item
+	itemsByUser map[domcart.Owner][]domcart.Item
 	addErr      error
 	listErr     error
 	clearErr    error
+	mergeErr    error
 }
 
 func newMockCartRepository() *mockCartRepository {
 	return &mockCartRepository{
-		itemsByUser: make(map[int64][]domcart.Item),
+		itemsByUser: make(map[domcart.Owner][]domcart.Item),
 	}
 }
 
-func (m *mockCartRepository) AddOrUpdateItem(ctx context.Context, userID int64, productID int64, quantity int64) error {
+func (m *mockCartRepository) AddOrUpdateItem(ctx context.Context, owner domcart.Owner, productID int64, quantity int64) error {
 	if m.addErr != nil {
 		return m.addErr
 	}
 
-	items := m.itemsByUser[userID]
+	items := m.itemsByUser[owner]
 	found := false
 	for i, item := range items {
 		if item.ProductID == productID {
@@ -44,15 +47,36 @@ func (m *mockCartRepository) AddOrUpdateItem(ctx context.Context, userID int64,
 			Quantity:  quantity,
 		})
 	}
-	m.itemsByUser[userID] = items
+	m.itemsByUser[owner] = items
 	return nil
 }
 
-func (m *mockCartRepository) ListItems(ctx context.Context, userID int64) ([]domcart.Item, error) {
+func (m *mockCartRepository) SetItemQuantity(ctx context.Context, owner domcart.Owner, productID int64, quantity int64) error {
+	for i, item := range m.itemsByUser[owner] {
+		if item.ProductID == productID {
+			m.itemsByUser[owner][i].Quantity = quantity
+			return nil
+		}
+	}
+	return domcart.ErrCartItemNotFound
+}
+
+func (m *mockCartRepository) RemoveItem(ctx context.Context, owner domcart.Owner, productID int64) error {
+	items := m.itemsByUser[owner]
+	for i, item := range items {
+		if item.ProductID == productID {
+			m.itemsByUser[owner] = append(items[:i], items[i+1:]...)
+			return nil
+		}
+	}
+	return domcart.ErrCartItemNotFound
+}
+
+func (m *mockCartRepository) ListItems(ctx context.Context, owner domcart.Owner) ([]domcart.Item, error) {
 	if m.listErr != nil {
 		return nil, m.listErr
 	}
-	items := m.itemsByUser[userID]
+	items := m.itemsByUser[owner]
 	if items == nil {
 		return []domcart.Item{}, nil
 	}
@@ -61,11 +85,27 @@ func (m *mockCartRepository) ListItems(ctx context.Context, userID int64) ([]dom
 	return result, nil
 }
 
-func (m *mockCartRepository) Clear(ctx context.Context, userID int64) error {
+func (m *mockCartRepository) Clear(ctx context.Context, owner domcart.Owner) error {
 	if m.clearErr != nil {
 		return m.clearErr
 	}
-	delete(m.itemsByUser, userID)
+	delete(m.itemsByUser, owner)
+	return nil
+}
+
+func (m *mockCartRepository) MergeItems(ctx context.Context, from, to domcart.Owner, items []domcart.Item) error {
+	if m.mergeErr != nil {
+		return m.mergeErr
+	}
+	if len(m.itemsByUser[from]) == 0 {
+		return nil
+	}
+	delete(m.itemsByUser, from)
+	for _, item := range items {
+		if err := m.SetItemQuantity(ctx, to, item.ProductID, item.Quantity); err != nil {
+			m.itemsByUser[to] = append(m.itemsByUser[to], domcart.Item{ProductID: item.ProductID, Quantity: item.Quantity})
+		}
+	}
 	return nil
 }
 
@@ -104,7 +144,11 @@ func (m *mockProductRepository) GetByIDs(ctx context.Context, ids []int64) ([]*d
 
 type mockOrderRepository struct{}
 
-func (m *mockOrderRepository) CreateFromCart(ctx context.Context, userID int64, items []domcart.Item, payment domorder.PaymentMethod) (*domorder.Order, error) {
+func (m *mockOrderRepository) CreateFromCart(ctx context.Context, userID int64, items []domcart.Item, payment domorder.PaymentMethod, currency string) (*domorder.Order, error) {
+	return nil, nil
+}
+
+func (m *mockOrderRepository) UpdateStatus(ctx context.Context, change domorder.StatusChange) (*domorder.Order, error) {
 	return nil, nil
 }
 
@@ -117,19 +161,19 @@ func TestAddItem_ValidProductAndQuantity(t *testing.T) {
 	productRepo.products[1] = &domproduct.Product{
 		ID:       1,
 		Name:     "Laptop",
-		Price:    999.99,
+		Price:    money.MustParse("999.99", "SAR"),
 		Stock:    10,
 		IsActive: true,
 	}
 
-	svc := NewService(cartRepo, productRepo, orderRepo)
+	svc := NewService(cartRepo, productRepo, orderRepo, nil)
 
-	err := svc.AddToCart(context.Background(), 100, 1, 3)
+	err := svc.AddToCart(context.Background(), domcart.UserOwner(100), 1, 3)
 
 	require.NoError(t, err)
 
 	// Verify item was added
-	items, err := cartRepo.ListItems(context.Background(), 100)
+	items, err := cartRepo.ListItems(context.Background(), domcart.UserOwner(100))
 	require.NoError(t, err)
 	require.Len(t, items, 1)
 	require.Equal(t, int64(1), items[0].ProductID)
@@ -141,14 +185,14 @@ func TestAddItem_ProductNotFound(t *testing.T) {
 	productRepo := newMockProductRepository()
 	orderRepo := &mockOrderRepository{}
 
-	svc := NewService(cartRepo, productRepo, orderRepo)
+	svc := NewService(cartRepo, productRepo, orderRepo, nil)
 
-	err := svc.AddToCart(context.Background(), 100, 999, 1)
+	err := svc.AddToCart(context.Background(), domcart.UserOwner(100), 999, 1)
 
 	require.ErrorIs(t, err, domproduct.ErrProductNotFound)
 
 	// Verify no item was added
-	items, err := cartRepo.ListItems(context.Background(), 100)
+	items, err := cartRepo.ListItems(context.Background(), domcart.UserOwner(100))
 	require.NoError(t, err)
 	require.Len(t, items, 0)
 }
@@ -162,19 +206,19 @@ func TestAddItem_ProductInactive(t *testing.T) {
 	productRepo.products[1] = &domproduct.Product{
 		ID:       1,
 		Name:     "Inactive Product",
-		Price:    99.99,
+		Price:    money.MustParse("99.99", "SAR"),
 		Stock:    10,
 		IsActive: false,
 	}
 
-	svc := NewService(cartRepo, productRepo, orderRepo)
+	svc := NewService(cartRepo, productRepo, orderRepo, nil)
 
-	err := svc.AddToCart(context.Background(), 100, 1, 1)
+	err := svc.AddToCart(context.Background(), domcart.UserOwner(100), 1, 1)
 
 	require.ErrorIs(t, err, domproduct.ErrProductNotFound)
 
 	// Verify no item was added
-	items, err := cartRepo.ListItems(context.Background(), 100)
+	items, err := cartRepo.ListItems(context.Background(), domcart.UserOwner(100))
 	require.NoError(t, err)
 	require.Len(t, items, 0)
 }
@@ -204,15 +248,15 @@ func TestAddItem_InvalidQuantity(t *testing.T) {
 			productRepo := newMockProductRepository()
 			orderRepo := &mockOrderRepository{}
 
-			svc := NewService(cartRepo, productRepo, orderRepo)
+			svc := NewService(cartRepo, productRepo, orderRepo, nil)
 
-			err := svc.AddToCart(context.Background(), 100, 1, tt.quantity)
+			err := svc.AddToCart(context.Background(), domcart.UserOwner(100), 1, tt.quantity)
 
 			require.Error(t, err)
 			require.Contains(t, err.Error(), "quantity must be positive")
 
 			// Verify no item was added
-			items, err := cartRepo.ListItems(context.Background(), 100)
+			items, err := cartRepo.ListItems(context.Background(), domcart.UserOwner(100))
 			require.NoError(t, err)
 			require.Len(t, items, 0)
 		})
@@ -228,20 +272,20 @@ func TestAddItem_OutOfStock(t *testing.T) {
 	productRepo.products[1] = &domproduct.Product{
 		ID:       1,
 		Name:     "Limited Stock Product",
-		Price:    99.99,
+		Price:    money.MustParse("99.99", "SAR"),
 		Stock:    5,
 		IsActive: true,
 	}
 
-	svc := NewService(cartRepo, productRepo, orderRepo)
+	svc := NewService(cartRepo, productRepo, orderRepo, nil)
 
 	// Try to add more than available stock
-	err := svc.AddToCart(context.Background(), 100, 1, 10)
+	err := svc.AddToCart(context.Background(), domcart.UserOwner(100), 1, 10)
 
 	require.ErrorIs(t, err, domproduct.ErrOutOfStock)
 
 	// Verify no item was added
-	items, err := cartRepo.ListItems(context.Background(), 100)
+	items, err := cartRepo.ListItems(context.Background(), domcart.UserOwner(100))
 	require.NoError(t, err)
 	require.Len(t, items, 0)
 }
@@ -255,23 +299,23 @@ func TestAddItem_UpdateExistingItem(t *testing.T) {
 	productRepo.products[1] = &domproduct.Product{
 		ID:       1,
 		Name:     "Product",
-		Price:    99.99,
+		Price:    money.MustParse("99.99", "SAR"),
 		Stock:    20,
 		IsActive: true,
 	}
 
-	svc := NewService(cartRepo, productRepo, orderRepo)
+	svc := NewService(cartRepo, productRepo, orderRepo, nil)
 
 	// Add item first time
-	err := svc.AddToCart(context.Background(), 100, 1, 3)
+	err := svc.AddToCart(context.Background(), domcart.UserOwner(100), 1, 3)
 	require.NoError(t, err)
 
 	// Add same product again (should update quantity)
-	err = svc.AddToCart(context.Background(), 100, 1, 2)
+	err = svc.AddToCart(context.Background(), domcart.UserOwner(100), 1, 2)
 	require.NoError(t, err)
 
 	// Verify quantity was updated (3 + 2 = 5)
-	items, err := cartRepo.ListItems(context.Background(), 100)
+	items, err := cartRepo.ListItems(context.Background(), domcart.UserOwner(100))
 	require.NoError(t, err)
 	require.Len(t, items, 1)
 	require.Equal(t, int64(1), items[0].ProductID)
@@ -287,23 +331,23 @@ func TestAddItem_UpdateExistingItemExceedsStock(t *testing.T) {
 	productRepo.products[1] = &domproduct.Product{
 		ID:       1,
 		Name:     "Limited Product",
-		Price:    99.99,
+		Price:    money.MustParse("99.99", "SAR"),
 		Stock:    5,
 		IsActive: true,
 	}
 
-	svc := NewService(cartRepo, productRepo, orderRepo)
+	svc := NewService(cartRepo, productRepo, orderRepo, nil)
 
 	// Add item first time
-	err := svc.AddToCart(context.Background(), 100, 1, 3)
+	err := svc.AddToCart(context.Background(), domcart.UserOwner(100), 1, 3)
 	require.NoError(t, err)
 
 	// Try to add more than remaining stock (3 + 3 = 6 > 5)
-	err = svc.AddToCart(context.Background(), 100, 1, 3)
+	err = svc.AddToCart(context.Background(), domcart.UserOwner(100), 1, 3)
 	require.ErrorIs(t, err, domproduct.ErrOutOfStock)
 
 	// Verify quantity was not updated
-	items, err := cartRepo.ListItems(context.Background(), 100)
+	items, err := cartRepo.ListItems(context.Background(), domcart.UserOwner(100))
 	require.NoError(t, err)
 	require.Len(t, items, 1)
 	require.Equal(t, int64(3), items[0].Quantity, "quantity should remain at 3")
@@ -318,32 +362,32 @@ func TestGetCart_ByUserID_ReturnsOnlyUserItems(t *testing.T) {
 	productRepo.products[1] = &domproduct.Product{
 		ID:       1,
 		Name:     "Product 1",
-		Price:    99.99,
+		Price:    money.MustParse("99.99", "SAR"),
 		Stock:    10,
 		IsActive: true,
 	}
 	productRepo.products[2] = &domproduct.Product{
 		ID:       2,
 		Name:     "Product 2",
-		Price:    149.99,
+		Price:    money.MustParse("149.99", "SAR"),
 		Stock:    5,
 		IsActive: true,
 	}
 
-	svc := NewService(cartRepo, productRepo, orderRepo)
+	svc := NewService(cartRepo, productRepo, orderRepo, nil)
 
 	// Add items for user 100
-	err := svc.AddToCart(context.Background(), 100, 1, 2)
+	err := svc.AddToCart(context.Background(), domcart.UserOwner(100), 1, 2)
 	require.NoError(t, err)
-	err = svc.AddToCart(context.Background(), 100, 2, 1)
+	err = svc.AddToCart(context.Background(), domcart.UserOwner(100), 2, 1)
 	require.NoError(t, err)
 
 	// Add items for user 200
-	err = svc.AddToCart(context.Background(), 200, 1, 5)
+	err = svc.AddToCart(context.Background(), domcart.UserOwner(200), 1, 5)
 	require.NoError(t, err)
 
 	// Get cart for user 100
-	cart, err := svc.GetCart(context.Background(), 100)
+	cart, err := svc.GetCart(context.Background(), domcart.UserOwner(100), "")
 
 	require.NoError(t, err)
 	require.NotNil(t, cart)
@@ -356,13 +400,13 @@ func TestGetCart_ByUserID_ReturnsOnlyUserItems(t *testing.T) {
 		productIDs[item.ProductID] = true
 		require.Greater(t, item.Quantity, int64(0))
 		require.NotEmpty(t, item.ProductName)
-		require.Greater(t, item.ProductPrice, 0.0)
+		require.True(t, item.ProductPrice.IsPositive())
 	}
 	require.True(t, productIDs[1], "should contain product 1")
 	require.True(t, productIDs[2], "should contain product 2")
 
 	// Get cart for user 200
-	cart2, err := svc.GetCart(context.Background(), 200)
+	cart2, err := svc.GetCart(context.Background(), domcart.UserOwner(200), "")
 
 	require.NoError(t, err)
 	require.NotNil(t, cart2)
@@ -377,9 +421,9 @@ func TestGetCart_EmptyCart(t *testing.T) {
 	productRepo := newMockProductRepository()
 	orderRepo := &mockOrderRepository{}
 
-	svc := NewService(cartRepo, productRepo, orderRepo)
+	svc := NewService(cartRepo, productRepo, orderRepo, nil)
 
-	cart, err := svc.GetCart(context.Background(), 100)
+	cart, err := svc.GetCart(context.Background(), domcart.UserOwner(100), "")
 
 	require.NoError(t, err)
 	require.NotNil(t, cart)
@@ -396,37 +440,37 @@ func TestGetCart_WithMultipleProducts(t *testing.T) {
 	productRepo.products[1] = &domproduct.Product{
 		ID:       1,
 		Name:     "Laptop",
-		Price:    999.99,
+		Price:    money.MustParse("999.99", "SAR"),
 		Stock:    10,
 		IsActive: true,
 	}
 	productRepo.products[2] = &domproduct.Product{
 		ID:       2,
 		Name:     "Mouse",
-		Price:    29.99,
+		Price:    money.MustParse("29.99", "SAR"),
 		Stock:    50,
 		IsActive: true,
 	}
 	productRepo.products[3] = &domproduct.Product{
 		ID:       3,
 		Name:     "Keyboard",
-		Price:    79.99,
+		Price:    money.MustParse("79.99", "SAR"),
 		Stock:    30,
 		IsActive: true,
 	}
 
-	svc := NewService(cartRepo, productRepo, orderRepo)
+	svc := NewService(cartRepo, productRepo, orderRepo, nil)
 
 	// Add multiple items
-	err := svc.AddToCart(context.Background(), 100, 1, 1)
+	err := svc.AddToCart(context.Background(), domcart.UserOwner(100), 1, 1)
 	require.NoError(t, err)
-	err = svc.AddToCart(context.Background(), 100, 2, 2)
+	err = svc.AddToCart(context.Background(), domcart.UserOwner(100), 2, 2)
 	require.NoError(t, err)
-	err = svc.AddToCart(context.Background(), 100, 3, 1)
+	err = svc.AddToCart(context.Background(), domcart.UserOwner(100), 3, 1)
 	require.NoError(t, err)
 
 	// Get cart
-	cart, err := svc.GetCart(context.Background(), 100)
+	cart, err := svc.GetCart(context.Background(), domcart.UserOwner(100), "")
 
 	require.NoError(t, err)
 	require.NotNil(t, cart)
@@ -439,15 +483,15 @@ func TestGetCart_WithMultipleProducts(t *testing.T) {
 	}
 
 	require.Equal(t, "Laptop", itemMap[1].ProductName)
-	require.Equal(t, 999.99, itemMap[1].ProductPrice)
+	require.Equal(t, money.MustParse("999.99", "SAR"), itemMap[1].ProductPrice)
 	require.Equal(t, int64(1), itemMap[1].Quantity)
 
 	require.Equal(t, "Mouse", itemMap[2].ProductName)
-	require.Equal(t, 29.99, itemMap[2].ProductPrice)
+	require.Equal(t, money.MustParse("29.99", "SAR"), itemMap[2].ProductPrice)
 	require.Equal(t, int64(2), itemMap[2].Quantity)
 
 	require.Equal(t, "Keyboard", itemMap[3].ProductName)
-	require.Equal(t, 79.99, itemMap[3].ProductPrice)
+	require.Equal(t, money.MustParse("79.99", "SAR"), itemMap[3].ProductPrice)
 	require.Equal(t, int64(1), itemMap[3].Quantity)
 }
 
@@ -460,20 +504,20 @@ func TestAddItem_ExactStockLimit(t *testing.T) {
 	productRepo.products[1] = &domproduct.Product{
 		ID:       1,
 		Name:     "Exact Stock Product",
-		Price:    99.99,
+		Price:    money.MustParse("99.99", "SAR"),
 		Stock:    5,
 		IsActive: true,
 	}
 
-	svc := NewService(cartRepo, productRepo, orderRepo)
+	svc := NewService(cartRepo, productRepo, orderRepo, nil)
 
 	// Add exactly the available stock
-	err := svc.AddToCart(context.Background(), 100, 1, 5)
+	err := svc.AddToCart(context.Background(), domcart.UserOwner(100), 1, 5)
 
 	require.NoError(t, err)
 
 	// Verify item was added
-	items, err := cartRepo.ListItems(context.Background(), 100)
+	items, err := cartRepo.ListItems(context.Background(), domcart.UserOwner(100))
 	require.NoError(t, err)
 	require.Len(t, items, 1)
 	require.Equal(t, int64(5), items[0].Quantity)
@@ -488,30 +532,378 @@ func TestAddItem_DifferentUsersIsolated(t *testing.T) {
 	productRepo.products[1] = &domproduct.Product{
 		ID:       1,
 		Name:     "Shared Product",
-		Price:    99.99,
+		Price:    money.MustParse("99.99", "SAR"),
 		Stock:    100,
 		IsActive: true,
 	}
 
-	svc := NewService(cartRepo, productRepo, orderRepo)
+	svc := NewService(cartRepo, productRepo, orderRepo, nil)
 
 	// Add item for user 100
-	err := svc.AddToCart(context.Background(), 100, 1, 3)
+	err := svc.AddToCart(context.Background(), domcart.UserOwner(100), 1, 3)
 	require.NoError(t, err)
 
 	// Add item for user 200
-	err = svc.AddToCart(context.Background(), 200, 1, 7)
+	err = svc.AddToCart(context.Background(), domcart.UserOwner(200), 1, 7)
 	require.NoError(t, err)
 
 	// Verify each user has their own cart
-	cart100, err := svc.GetCart(context.Background(), 100)
+	cart100, err := svc.GetCart(context.Background(), domcart.UserOwner(100), "")
 	require.NoError(t, err)
 	require.Len(t, cart100.Items, 1)
 	require.Equal(t, int64(3), cart100.Items[0].Quantity)
 
-	cart200, err := svc.GetCart(context.Background(), 200)
+	cart200, err := svc.GetCart(context.Background(), domcart.UserOwner(200), "")
 	require.NoError(t, err)
 	require.Len(t, cart200.Items, 1)
 	require.Equal(t, int64(7), cart200.Items[0].Quantity)
 }
 
+
+func TestUpdateItemQuantity_SetsAbsoluteQuantity(t *testing.T) {
+	cartRepo := newMockCartRepository()
+	productRepo := newMockProductRepository()
+	productRepo.products[1] = &domproduct.Product{ID: 1, Name: "Laptop", Price: money.MustParse("999.99", "SAR"), Stock: 10, IsActive: true}
+	cartRepo.itemsByUser[domcart.UserOwner(100)] = []domcart.Item{{ProductID: 1, Quantity: 4}}
+
+	svc := NewService(cartRepo, productRepo, &mockOrderRepository{}, nil)
+
+	err := svc.UpdateItemQuantity(context.Background(), domcart.UserOwner(100), 1, 2)
+
+	require.NoError(t, err)
+	require.Equal(t, int64(2), cartRepo.itemsByUser[domcart.UserOwner(100)][0].Quantity)
+}
+
+func TestUpdateItemQuantity_ZeroRemovesItem(t *testing.T) {
+	cartRepo := newMockCartRepository()
+	productRepo := newMockProductRepository()
+	cartRepo.itemsByUser[domcart.UserOwner(100)] = []domcart.Item{{ProductID: 1, Quantity: 4}, {ProductID: 2, Quantity: 1}}
+
+	svc := NewService(cartRepo, productRepo, &mockOrderRepository{}, nil)
+
+	err := svc.UpdateItemQuantity(context.Background(), domcart.UserOwner(100), 1, 0)
+
+	require.NoError(t, err)
+	require.Equal(t, []domcart.Item{{ProductID: 2, Quantity: 1}}, cartRepo.itemsByUser[domcart.UserOwner(100)])
+}
+
+func TestUpdateItemQuantity_ExceedsStock(t *testing.T) {
+	cartRepo := newMockCartRepository()
+	productRepo := newMockProductRepository()
+	productRepo.products[1] = &domproduct.Product{ID: 1, Name: "Laptop", Price: money.MustParse("999.99", "SAR"), Stock: 3, IsActive: true}
+	cartRepo.itemsByUser[domcart.UserOwner(100)] = []domcart.Item{{ProductID: 1, Quantity: 1}}
+
+	svc := NewService(cartRepo, productRepo, &mockOrderRepository{}, nil)
+
+	err := svc.UpdateItemQuantity(context.Background(), domcart.UserOwner(100), 1, 4)
+
+	require.ErrorIs(t, err, domproduct.ErrOutOfStock)
+	require.Equal(t, int64(1), cartRepo.itemsByUser[domcart.UserOwner(100)][0].Quantity)
+}
+
+func TestUpdateItemQuantity_InactiveProduct(t *testing.T) {
+	cartRepo := newMockCartRepository()
+	productRepo := newMockProductRepository()
+	productRepo.products[1] = &domproduct.Product{ID: 1, Name: "Laptop", Price: money.MustParse("999.99", "SAR"), Stock: 10, IsActive: false}
+	cartRepo.itemsByUser[domcart.UserOwner(100)] = []domcart.Item{{ProductID: 1, Quantity: 1}}
+
+	svc := NewService(cartRepo, productRepo, &mockOrderRepository{}, nil)
+
+	err := svc.UpdateItemQuantity(context.Background(), domcart.UserOwner(100), 1, 2)
+
+	require.ErrorIs(t, err, domproduct.ErrProductNotFound)
+}
+
+func TestUpdateItemQuantity_ItemNotInCart(t *testing.T) {
+	cartRepo := newMockCartRepository()
+	productRepo := newMockProductRepository()
+	productRepo.products[1] = &domproduct.Product{ID: 1, Name: "Laptop", Price: money.MustParse("999.99", "SAR"), Stock: 10, IsActive: true}
+
+	svc := NewService(cartRepo, productRepo, &mockOrderRepository{}, nil)
+
+	err := svc.UpdateItemQuantity(context.Background(), domcart.UserOwner(100), 1, 2)
+
+	require.ErrorIs(t, err, domcart.ErrCartItemNotFound)
+	require.Empty(t, cartRepo.itemsByUser[domcart.UserOwner(100)])
+}
+
+func TestUpdateItemQuantity_NegativeQuantity(t *testing.T) {
+	cartRepo := newMockCartRepository()
+	cartRepo.itemsByUser[domcart.UserOwner(100)] = []domcart.Item{{ProductID: 1, Quantity: 1}}
+
+	svc := NewService(cartRepo, newMockProductRepository(), &mockOrderRepository{}, nil)
+
+	err := svc.UpdateItemQuantity(context.Background(), domcart.UserOwner(100), 1, -1)
+
+	require.Error(t, err)
+	require.Equal(t, int64(1), cartRepo.itemsByUser[domcart.UserOwner(100)][0].Quantity)
+}
+
+func TestRemoveItem_NotInCart(t *testing.T) {
+	svc := NewService(newMockCartRepository(), newMockProductRepository(), &mockOrderRepository{}, nil)
+
+	err := svc.RemoveItem(context.Background(), domcart.UserOwner(100), 1)
+
+	require.ErrorIs(t, err, domcart.ErrCartItemNotFound)
+}
+
+func TestClearCart_RemovesAllItems(t *testing.T) {
+	cartRepo := newMockCartRepository()
+	cartRepo.itemsByUser[domcart.UserOwner(100)] = []domcart.Item{{ProductID: 1, Quantity: 1}, {ProductID: 2, Quantity: 3}}
+
+	svc := NewService(cartRepo, newMockProductRepository(), &mockOrderRepository{}, nil)
+
+	err := svc.ClearCart(context.Background(), domcart.UserOwner(100))
+
+	require.NoError(t, err)
+	require.Empty(t, cartRepo.itemsByUser[domcart.UserOwner(100)])
+}
+
+func TestGetCart_ComputesSubtotalsAndTotals(t *testing.T) {
+	cartRepo := newMockCartRepository()
+	productRepo := newMockProductRepository()
+	productRepo.products[1] = &domproduct.Product{ID: 1, Name: "Laptop", Price: money.MustParse("1000", "SAR"), Stock: 10, IsActive: true}
+	productRepo.products[2] = &domproduct.Product{ID: 2, Name: "Mouse", Price: money.MustParse("25", "SAR"), Stock: 50, IsActive: true}
+	cartRepo.itemsByUser[domcart.UserOwner(100)] = []domcart.Item{
+		{ProductID: 1, Quantity: 1, UnitPrice: money.MustParse("1000", "SAR")},
+		{ProductID: 2, Quantity: 3, UnitPrice: money.MustParse("25", "SAR")},
+	}
+
+	svc := NewService(cartRepo, productRepo, &mockOrderRepository{}, nil)
+
+	cart, err := svc.GetCart(context.Background(), domcart.UserOwner(100), "")
+
+	require.NoError(t, err)
+	require.Len(t, cart.Items, 2)
+	require.Equal(t, money.MustParse("1000.0", "SAR"), cart.Items[0].Subtotal)
+	require.Equal(t, money.MustParse("75.0", "SAR"), cart.Items[1].Subtotal)
+	require.Equal(t, money.MustParse("1075.0", "SAR"), cart.Total)
+	require.Equal(t, int64(4), cart.ItemCount)
+	require.Empty(t, cart.Warnings)
+}
+
+func TestGetCart_TotalsAreExact(t *testing.T) {
+	cartRepo := newMockCartRepository()
+	productRepo := newMockProductRepository()
+	productRepo.products[1] = &domproduct.Product{ID: 1, Name: "Sticker", Price: money.MustParse("0.10", "SAR"), Stock: 100, IsActive: true}
+	productRepo.products[2] = &domproduct.Product{ID: 2, Name: "Pen", Price: money.MustParse("0.20", "SAR"), Stock: 100, IsActive: true}
+	cartRepo.itemsByUser[domcart.UserOwner(100)] = []domcart.Item{
+		{ProductID: 1, Quantity: 3},
+		{ProductID: 2, Quantity: 1},
+	}
+
+	svc := NewService(cartRepo, productRepo, &mockOrderRepository{}, nil)
+
+	cart, err := svc.GetCart(context.Background(), domcart.UserOwner(100), "")
+
+	require.NoError(t, err)
+	// 0.1*3 + 0.2 is 0.5000000000000001 in float64
+	require.Equal(t, money.New(50, "SAR"), cart.Total)
+	require.Equal(t, "0.50", cart.Total.String())
+}
+
+func TestGetCart_ReportsStaleItems(t *testing.T) {
+	cartRepo := newMockCartRepository()
+	productRepo := newMockProductRepository()
+	productRepo.products[1] = &domproduct.Product{ID: 1, Name: "Laptop", Price: money.MustParse("1200", "SAR"), Stock: 10, IsActive: true}
+	productRepo.products[2] = &domproduct.Product{ID: 2, Name: "Mouse", Price: money.MustParse("25", "SAR"), Stock: 2, IsActive: true}
+	productRepo.products[3] = &domproduct.Product{ID: 3, Name: "Keyboard", Price: money.MustParse("80", "SAR"), Stock: 5, IsActive: false}
+	cartRepo.itemsByUser[domcart.UserOwner(100)] = []domcart.Item{
+		{ProductID: 1, Quantity: 1, UnitPrice: money.MustParse("1000", "SAR")}, // repriced
+		{ProductID: 2, Quantity: 3, UnitPrice: money.MustParse("25", "SAR")},   // over stock
+		{ProductID: 3, Quantity: 1, UnitPrice: money.MustParse("80", "SAR")},   // inactive
+		{ProductID: 4, Quantity: 1, UnitPrice: money.MustParse("10", "SAR")},   // deleted
+	}
+
+	svc := NewService(cartRepo, productRepo, &mockOrderRepository{}, nil)
+
+	cart, err := svc.GetCart(context.Background(), domcart.UserOwner(100), "")
+
+	require.NoError(t, err)
+
+	codes := make(map[int64]domcart.WarningCode)
+	for _, w := range cart.Warnings {
+		codes[w.ProductID] = w.Code
+		require.NotEmpty(t, w.Message)
+	}
+	require.Equal(t, map[int64]domcart.WarningCode{
+		1: domcart.WarningPriceChanged,
+		2: domcart.WarningInsufficientStock,
+		3: domcart.WarningProductInactive,
+		4: domcart.WarningProductDeleted,
+	}, codes)
+
+	// Inactive and deleted products are not part of the totals
+	require.Len(t, cart.Items, 2)
+	require.Equal(t, money.MustParse("1275", "SAR"), cart.Total)
+	require.Equal(t, int64(4), cart.ItemCount)
+}
+
+func TestGetCart_UnknownAddPriceIsNotRepriced(t *testing.T) {
+	cartRepo := newMockCartRepository()
+	productRepo := newMockProductRepository()
+	productRepo.products[1] = &domproduct.Product{ID: 1, Name: "Laptop", Price: money.MustParse("1200", "SAR"), Stock: 10, IsActive: true}
+	cartRepo.itemsByUser[domcart.UserOwner(100)] = []domcart.Item{{ProductID: 1, Quantity: 1}}
+
+	svc := NewService(cartRepo, productRepo, &mockOrderRepository{}, nil)
+
+	cart, err := svc.GetCart(context.Background(), domcart.UserOwner(100), "")
+
+	require.NoError(t, err)
+	require.Empty(t, cart.Warnings)
+}
+
+func TestGetCart_PricesInRequestedCurrency(t *testing.T) {
+	cartRepo := newMockCartRepository()
+	productRepo := newMockProductRepository()
+	productRepo.products[1] = &domproduct.Product{
+		ID: 1, Name: "Laptop", Price: money.MustParse("1000", "SAR"), Stock: 10, IsActive: true,
+		Prices: []money.Money{money.MustParse("270", "USD")},
+	}
+	productRepo.products[2] = &domproduct.Product{
+		ID: 2, Name: "Mouse", Price: money.MustParse("25", "SAR"), Stock: 50, IsActive: true,
+		Prices: []money.Money{money.MustParse("6.75", "USD")},
+	}
+	cartRepo.itemsByUser[domcart.UserOwner(100)] = []domcart.Item{
+		{ProductID: 1, Quantity: 1, UnitPrice: money.MustParse("1000", "SAR")},
+		{ProductID: 2, Quantity: 2, UnitPrice: money.MustParse("25", "SAR")},
+	}
+
+	svc := NewService(cartRepo, productRepo, &mockOrderRepository{}, nil)
+
+	cart, err := svc.GetCart(context.Background(), domcart.UserOwner(100), "USD")
+
+	require.NoError(t, err)
+	require.Empty(t, cart.Warnings)
+	require.Equal(t, money.MustParse("270", "USD"), cart.Items[0].ProductPrice)
+	require.Equal(t, money.MustParse("13.50", "USD"), cart.Items[1].Subtotal)
+	require.Equal(t, money.MustParse("283.50", "USD"), cart.Total)
+}
+
+func TestGetCart_WarnsWhenPriceUnavailable(t *testing.T) {
+	cartRepo := newMockCartRepository()
+	productRepo := newMockProductRepository()
+	productRepo.products[1] = &domproduct.Product{
+		ID: 1, Name: "Laptop", Price: money.MustParse("1000", "SAR"), Stock: 10, IsActive: true,
+		Prices: []money.Money{money.MustParse("270", "USD")},
+	}
+	productRepo.products[2] = &domproduct.Product{ID: 2, Name: "Mouse", Price: money.MustParse("25", "SAR"), Stock: 50, IsActive: true}
+	cartRepo.itemsByUser[domcart.UserOwner(100)] = []domcart.Item{
+		{ProductID: 1, Quantity: 1},
+		{ProductID: 2, Quantity: 1},
+	}
+
+	svc := NewService(cartRepo, productRepo, &mockOrderRepository{}, nil)
+
+	cart, err := svc.GetCart(context.Background(), domcart.UserOwner(100), "USD")
+
+	require.NoError(t, err)
+	require.Len(t, cart.Warnings, 1)
+	require.Equal(t, int64(2), cart.Warnings[0].ProductID)
+	require.Equal(t, domcart.WarningPriceUnavailable, cart.Warnings[0].Code)
+	require.Len(t, cart.Items, 1)
+	require.Equal(t, money.MustParse("270", "USD"), cart.Total)
+}
+
+func setupMergeService(rules MergeRules) (*Service, *mockCartRepository) {
+	cartRepo := newMockCartRepository()
+	productRepo := newMockProductRepository()
+	productRepo.products[1] = &domproduct.Product{ID: 1, Name: "Laptop", Price: money.MustParse("1200", "SAR"), Stock: 10, IsActive: true}
+	productRepo.products[2] = &domproduct.Product{ID: 2, Name: "Mouse", Price: money.MustParse("25", "SAR"), Stock: 3, IsActive: true}
+	productRepo.products[3] = &domproduct.Product{ID: 3, Name: "Old", Price: money.MustParse("5", "SAR"), Stock: 10, IsActive: false}
+
+	cartRepo.itemsByUser[domcart.UserOwner(100)] = []domcart.Item{{ProductID: 1, Quantity: 2}, {ProductID: 2, Quantity: 2}}
+	cartRepo.itemsByUser[domcart.GuestOwner("guest-token")] = []domcart.Item{
+		{ProductID: 1, Quantity: 3},
+		{ProductID: 2, Quantity: 2},
+		{ProductID: 3, Quantity: 1},
+	}
+
+	return NewService(cartRepo, productRepo, &mockOrderRepository{}, nil, WithMergeRules(rules)), cartRepo
+}
+
+func TestMergeGuestCart_SumCapsAtStock(t *testing.T) {
+	svc, cartRepo := setupMergeService(DefaultMergeRules)
+
+	err := svc.MergeGuestCart(context.Background(), "guest-token", 100)
+
+	require.NoError(t, err)
+	// 2+3 for the laptop, 2+2 capped at 3 for the mouse, inactive product dropped
+	require.Equal(t, []domcart.Item{{ProductID: 1, Quantity: 5}, {ProductID: 2, Quantity: 3}}, cartRepo.itemsByUser[domcart.UserOwner(100)])
+	require.NotContains(t, cartRepo.itemsByUser, domcart.GuestOwner("guest-token"))
+}
+
+func TestMergeGuestCart_FailureCanBeRetried(t *testing.T) {
+	svc, cartRepo := setupMergeService(DefaultMergeRules)
+	cartRepo.mergeErr = errors.New("deadlock")
+
+	err := svc.MergeGuestCart(context.Background(), "guest-token", 100)
+
+	require.Error(t, err)
+	require.Equal(t, []domcart.Item{{ProductID: 1, Quantity: 2}, {ProductID: 2, Quantity: 2}}, cartRepo.itemsByUser[domcart.UserOwner(100)])
+	require.Len(t, cartRepo.itemsByUser[domcart.GuestOwner("guest-token")], 3)
+
+	cartRepo.mergeErr = nil
+	require.NoError(t, svc.MergeGuestCart(context.Background(), "guest-token", 100))
+	require.NoError(t, svc.MergeGuestCart(context.Background(), "guest-token", 100))
+	require.Equal(t, []domcart.Item{{ProductID: 1, Quantity: 5}, {ProductID: 2, Quantity: 3}}, cartRepo.itemsByUser[domcart.UserOwner(100)], "quantities are added once")
+}
+
+func TestMergeGuestCart_SumWithoutCap(t *testing.T) {
+	svc, cartRepo := setupMergeService(MergeRules{Strategy: MergeSum})
+
+	err := svc.MergeGuestCart(context.Background(), "guest-token", 100)
+
+	require.NoError(t, err)
+	require.Equal(t, []domcart.Item{{ProductID: 1, Quantity: 5}, {ProductID: 2, Quantity: 4}}, cartRepo.itemsByUser[domcart.UserOwner(100)])
+}
+
+func TestMergeGuestCart_Strategies(t *testing.T) {
+	tests := []struct {
+		strategy MergeStrategy
+		laptop   int64
+	}{
+		{MergeMax, 3},
+		{MergeKeepUser, 2},
+		{MergeKeepGuest, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.strategy), func(t *testing.T) {
+			svc, cartRepo := setupMergeService(MergeRules{Strategy: tt.strategy, CapAtStock: true})
+
+			err := svc.MergeGuestCart(context.Background(), "guest-token", 100)
+
+			require.NoError(t, err)
+			require.Equal(t, tt.laptop, cartRepo.itemsByUser[domcart.UserOwner(100)][0].Quantity)
+		})
+	}
+}
+
+func TestMergeGuestCart_IntoEmptyUserCart(t *testing.T) {
+	svc, cartRepo := setupMergeService(DefaultMergeRules)
+	delete(cartRepo.itemsByUser, domcart.UserOwner(100))
+
+	err := svc.MergeGuestCart(context.Background(), "guest-token", 100)
+
+	require.NoError(t, err)
+	require.Equal(t, []domcart.Item{{ProductID: 1, Quantity: 3}, {ProductID: 2, Quantity: 2}}, cartRepo.itemsByUser[domcart.UserOwner(100)])
+}
+
+func TestMergeGuestCart_EmptyTokenIsNoop(t *testing.T) {
+	svc, cartRepo := setupMergeService(DefaultMergeRules)
+
+	err := svc.MergeGuestCart(context.Background(), "", 100)
+
+	require.NoError(t, err)
+	require.Len(t, cartRepo.itemsByUser[domcart.GuestOwner("guest-token")], 3)
+}
+
+func TestParseMergeStrategy(t *testing.T) {
+	st, err := ParseMergeStrategy("keep_user")
+	require.NoError(t, err)
+	require.Equal(t, MergeKeepUser, st)
+
+	_, err = ParseMergeStrategy("average")
+	require.Error(t, err)
+}
@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	dom "example.com/my-golang-sample/app/internal/domain/category"
+	"example.com/my-golang-sample/app/internal/domain/page"
 )
 
 const maxSlugLength = 64
@@ -108,8 +109,12 @@ func (s *Service) GetByID(ctx context.Context, id int64) (*dom.Category, error)
 	return s.repo.GetByID(ctx, id)
 }
 
-func (s *Service) List(ctx context.Context, filter dom.ListFilter) ([]*dom.Category, error) {
-	return s.repo.List(ctx, filter)
+func (s *Service) List(ctx context.Context, filter dom.ListFilter, p page.Request) ([]*dom.Category, page.Info, error) {
+	p, err := p.Normalize(dom.SortFields)
+	if err != nil {
+		return nil, page.Info{}, err
+	}
+	return s.repo.List(ctx, filter, p)
 }
 
 func sanitizeName(name string) (string, error) {
@@ -7,6 +7,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	dom "example.com/my-golang-sample/app/internal/domain/category"
+	"example.com/my-golang-sample/app/internal/domain/page"
 )
 
 type mockCategoryRepository struct {
@@ -89,7 +90,7 @@ func (m *mockCategoryRepository) GetByID(ctx context.Context, id int64) (*dom.Ca
 	return nil, dom.ErrCategoryNotFound
 }
 
-func (m *mockCategoryRepository) List(ctx context.Context, filter dom.ListFilter) ([]*dom.Category, error) {
+func (m *mockCategoryRepository) List(ctx context.Context, filter dom.ListFilter, req page.Request) ([]*dom.Category, page.Info, error) {
 	var result []*dom.Category
 	for _, cat := range m.categories {
 		if filter.OnlyActive && !cat.IsActive {
@@ -98,7 +99,7 @@ func (m *mockCategoryRepository) List(ctx context.Context, filter dom.ListFilter
 		cloned := *cat
 		result = append(result, &cloned)
 	}
-	return result, nil
+	return result, page.Info{Total: int64(len(result)), Page: req.Page, Size: req.Size}, nil
 }
 
 func TestCreateCategory_Valid(t *testing.T) {
@@ -448,7 +449,7 @@ func TestListCategories_AllCategories(t *testing.T) {
 	require.NoError(t, err)
 
 	// List all
-	categories, err := svc.List(context.Background(), dom.ListFilter{})
+	categories, _, err := svc.List(context.Background(), dom.ListFilter{}, page.Request{})
 
 	require.NoError(t, err)
 	require.Len(t, categories, 3)
@@ -472,9 +473,9 @@ func TestListCategories_OnlyActive(t *testing.T) {
 	require.NoError(t, err)
 
 	// List only active
-	categories, err := svc.List(context.Background(), dom.ListFilter{
+	categories, _, err := svc.List(context.Background(), dom.ListFilter{
 		OnlyActive: true,
-	})
+	}, page.Request{})
 
 	require.NoError(t, err)
 	require.Len(t, categories, 1)
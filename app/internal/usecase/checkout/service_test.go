@@ -7,6 +7,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	domcart "example.com/my-golang-sample/app/internal/domain/cart"
+	"example.com/my-golang-sample/app/internal/domain/money"
 	domorder "example.com/my-golang-sample/app/internal/domain/order"
 )
 
@@ -68,7 +69,7 @@ func (m *mockOrderRepository) CreateFromCart(ctx context.Context, userID int64,
 		UserID:        userID,
 		Status:        domorder.StatusPending,
 		PaymentMethod: payment,
-		TotalAmount:   0,
+		TotalAmount:   money.Zero("SAR"),
 		Items:         []domorder.OrderItem{},
 	}, nil
 }
@@ -144,7 +145,7 @@ func TestCheckout_WithCOD_Succeeds(t *testing.T) {
 		UserID:        100,
 		Status:        domorder.StatusPending,
 		PaymentMethod: domorder.PaymentCOD,
-		TotalAmount:   199.98,
+		TotalAmount:   money.MustParse("199.98", "SAR"),
 		Items: []domorder.OrderItem{
 			{ProductID: 1, Quantity: 2},
 			{ProductID: 2, Quantity: 1},
@@ -174,7 +175,7 @@ func TestCheckout_WithTamara_Succeeds(t *testing.T) {
 		UserID:        100,
 		Status:        domorder.StatusPending,
 		PaymentMethod: domorder.PaymentTamara,
-		TotalAmount:   299.97,
+		TotalAmount:   money.MustParse("299.97", "SAR"),
 		Items: []domorder.OrderItem{
 			{ProductID: 1, Quantity: 3},
 		},
@@ -234,7 +235,7 @@ func TestCheckout_CartClearError(t *testing.T) {
 		UserID:        100,
 		Status:        domorder.StatusPending,
 		PaymentMethod: domorder.PaymentCOD,
-		TotalAmount:   199.98,
+		TotalAmount:   money.MustParse("199.98", "SAR"),
 		Items:         []domorder.OrderItem{},
 	}
 
@@ -287,7 +288,7 @@ func TestCheckout_WithMultipleItems(t *testing.T) {
 		UserID:        100,
 		Status:        domorder.StatusPending,
 		PaymentMethod: domorder.PaymentCOD,
-		TotalAmount:   499.95,
+		TotalAmount:   money.MustParse("499.95", "SAR"),
 		Items: []domorder.OrderItem{
 			{ProductID: 1, Quantity: 2},
 			{ProductID: 2, Quantity: 1},
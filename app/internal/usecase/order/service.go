@@ -2,30 +2,152 @@ package order
 
 import (
 	"context"
+	"errors"
 
 	domorder "example.com/my-golang-sample/app/internal/domain/order"
+	"example.com/my-golang-sample/app/internal/domain/page"
+	dompayment "example.com/my-golang-sample/app/internal/domain/payment"
 )
 
+// PaymentSettler keeps the online payment of an order in step with it: the
+// payment is captured when the order ships and given back when it is canceled.
+type PaymentSettler interface {
+	Capture(ctx context.Context, orderID int64) (*dompayment.Payment, error)
+	// Cancel voids an open or authorised payment and refunds a captured one.
+	Cancel(ctx context.Context, orderID int64) (*dompayment.Payment, error)
+}
+
 type Service struct {
-	repo domorder.Repository
+	repo     domorder.Repository
+	payments PaymentSettler
 }
 
-func NewService(repo domorder.Repository) *Service {
-	return &Service{repo: repo}
+type Option func(*Service)
+
+// WithPayments settles the order's payment before every status change that
+// moves money: shipping captures it, canceling voids or refunds it.
+func WithPayments(payments PaymentSettler) Option {
+	return func(s *Service) {
+		s.payments = payments
+	}
 }
 
-func (s *Service) List(ctx context.Context) ([]*domorder.Order, error) {
-	return s.repo.List(ctx)
+func NewService(repo domorder.Repository, opts ...Option) *Service {
+	s := &Service{repo: repo}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+func (s *Service) List(ctx context.Context, filter domorder.ListFilter, p page.Request) ([]*domorder.Order, page.Info, error) {
+	if filter.CreatedFrom != nil && filter.CreatedTo != nil && !filter.CreatedFrom.Before(*filter.CreatedTo) {
+		return nil, page.Info{}, domorder.ErrInvalidDateRange
+	}
+	if filter.MinTotal != nil && filter.MaxTotal != nil &&
+		(filter.MinTotal.Currency != filter.MaxTotal.Currency || filter.MinTotal.Amount > filter.MaxTotal.Amount) {
+		return nil, page.Info{}, domorder.ErrInvalidTotalRange
+	}
+	p, err := p.Normalize(domorder.SortFields)
+	if err != nil {
+		return nil, page.Info{}, err
+	}
+	return s.repo.List(ctx, filter, p)
 }
 
 func (s *Service) GetByID(ctx context.Context, id int64) (*domorder.Order, error) {
 	return s.repo.GetByID(ctx, id)
 }
 
-func (s *Service) UpdateStatus(ctx context.Context, id int64, status domorder.Status) (*domorder.Order, error) {
-	if !status.IsValid() {
+func (s *Service) ListForUser(ctx context.Context, userID int64, p page.Request) ([]*domorder.Order, page.Info, error) {
+	p, err := p.Normalize(domorder.SortFields)
+	if err != nil {
+		return nil, page.Info{}, err
+	}
+	return s.repo.ListByUser(ctx, userID, p)
+}
+
+func (s *Service) GetForUser(ctx context.Context, id, userID int64) (*domorder.Order, error) {
+	return s.repo.GetByIDForUser(ctx, id, userID)
+}
+
+type UpdateStatusInput struct {
+	Status    domorder.Status
+	ChangedBy int64 // 0 for system-initiated changes
+	Note      string
+}
+
+func (s *Service) UpdateStatus(ctx context.Context, id int64, in UpdateStatusInput) (*domorder.Order, error) {
+	if !in.Status.IsValid() {
 		return nil, domorder.ErrInvalidStatus
 	}
-	return s.repo.UpdateStatus(ctx, id, status)
+
+	order, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !order.Status.CanTransitionTo(in.Status) {
+		return nil, domorder.ErrInvalidTransition
+	}
+	if err := s.settlePayment(ctx, id, in.Status); err != nil {
+		return nil, err
+	}
+
+	return s.repo.UpdateStatus(ctx, domorder.StatusChange{
+		OrderID:   id,
+		From:      order.Status,
+		To:        in.Status,
+		ChangedBy: in.ChangedBy,
+		Note:      in.Note,
+	})
+}
+
+// CancelForUser lets the owner cancel an order that has not been paid yet.
+// An open payment session is voided first; stock is released by the
+// repository as part of the transition.
+func (s *Service) CancelForUser(ctx context.Context, id, userID int64) (*domorder.Order, error) {
+	order, err := s.repo.GetByIDForUser(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if order.Status != domorder.StatusPending {
+		return nil, domorder.ErrNotCancelable
+	}
+
+	if err := s.settlePayment(ctx, id, domorder.StatusCanceled); err != nil {
+		return nil, err
+	}
+
+	return s.repo.UpdateStatus(ctx, domorder.StatusChange{
+		OrderID:   id,
+		From:      order.Status,
+		To:        domorder.StatusCanceled,
+		ChangedBy: userID,
+		Note:      "canceled by customer",
+	})
+}
+
+// settlePayment runs before the status change it belongs to.
+func (s *Service) settlePayment(ctx context.Context, id int64, to domorder.Status) error {
+	if s.payments == nil {
+		return nil
+	}
+	// Gọi provider trước: nếu provider từ chối thì đơn giữ trạng thái cũ để thử lại,
+	// và webhook đến sau sẽ bỏ qua payment đã VOIDED. Đơn COD không có payment.
+	var err error
+	switch to {
+	case domorder.StatusShipped:
+		_, err = s.payments.Capture(ctx, id)
+	case domorder.StatusCanceled:
+		_, err = s.payments.Cancel(ctx, id)
+	}
+	if errors.Is(err, dompayment.ErrPaymentNotFound) {
+		return nil
+	}
+	return err
+}
+
+func (s *Service) ListStatusHistory(ctx context.Context, id int64) ([]domorder.StatusHistory, error) {
+	return s.repo.ListStatusHistory(ctx, id)
 }
 
@@ -8,13 +8,17 @@ import (
 	"github.com/stretchr/testify/require"
 
 	domcart "example.com/my-golang-sample/app/internal/domain/cart"
+	"example.com/my-golang-sample/app/internal/domain/money"
 	domorder "example.com/my-golang-sample/app/internal/domain/order"
+	"example.com/my-golang-sample/app/internal/domain/page"
+	dompayment "example.com/my-golang-sample/app/internal/domain/payment"
 )
 
 type mockOrderRepository struct {
 	orders     map[int64]*domorder.Order
 	nextID     int64
 	updated    map[int64]*domorder.Order
+	changes    []domorder.StatusChange
 	listErr    error
 	getErr     error
 	updateErr  error
@@ -28,20 +32,20 @@ func newMockOrderRepository() *mockOrderRepository {
 	}
 }
 
-func (m *mockOrderRepository) CreateFromCart(ctx context.Context, userID int64, items []domcart.Item, payment domorder.PaymentMethod) (*domorder.Order, error) {
+func (m *mockOrderRepository) CreateFromCart(ctx context.Context, userID int64, items []domcart.Item, payment domorder.PaymentMethod, currency string) (*domorder.Order, error) {
 	return nil, nil
 }
 
-func (m *mockOrderRepository) List(ctx context.Context) ([]*domorder.Order, error) {
+func (m *mockOrderRepository) List(ctx context.Context, filter domorder.ListFilter, req page.Request) ([]*domorder.Order, page.Info, error) {
 	if m.listErr != nil {
-		return nil, m.listErr
+		return nil, page.Info{}, m.listErr
 	}
 	result := make([]*domorder.Order, 0, len(m.orders))
 	for _, order := range m.orders {
 		cloned := *order
 		result = append(result, &cloned)
 	}
-	return result, nil
+	return result, page.Info{Total: int64(len(result)), Page: req.Page, Size: req.Size}, nil
 }
 
 func (m *mockOrderRepository) GetByID(ctx context.Context, id int64) (*domorder.Order, error) {
@@ -55,21 +59,59 @@ func (m *mockOrderRepository) GetByID(ctx context.Context, id int64) (*domorder.
 	return nil, domorder.ErrOrderNotFound
 }
 
-func (m *mockOrderRepository) UpdateStatus(ctx context.Context, id int64, status domorder.Status) (*domorder.Order, error) {
+func (m *mockOrderRepository) ListByUser(ctx context.Context, userID int64, req page.Request) ([]*domorder.Order, page.Info, error) {
+	if m.listErr != nil {
+		return nil, page.Info{}, m.listErr
+	}
+	result := make([]*domorder.Order, 0)
+	for _, order := range m.orders {
+		if order.UserID == userID {
+			cloned := *order
+			result = append(result, &cloned)
+		}
+	}
+	return result, page.Info{Total: int64(len(result)), Page: req.Page, Size: req.Size}, nil
+}
+
+func (m *mockOrderRepository) GetByIDForUser(ctx context.Context, id, userID int64) (*domorder.Order, error) {
+	if m.getErr != nil {
+		return nil, m.getErr
+	}
+	if order, ok := m.orders[id]; ok && order.UserID == userID {
+		cloned := *order
+		return &cloned, nil
+	}
+	return nil, domorder.ErrOrderNotFound
+}
+
+func (m *mockOrderRepository) UpdateStatus(ctx context.Context, change domorder.StatusChange) (*domorder.Order, error) {
 	if m.updateErr != nil {
 		return nil, m.updateErr
 	}
-	order, ok := m.orders[id]
+	order, ok := m.orders[change.OrderID]
 	if !ok {
 		return nil, domorder.ErrOrderNotFound
 	}
-	order.Status = status
-	m.orders[id] = order
-	m.updated[id] = order
+	order.Status = change.To
+	m.orders[change.OrderID] = order
+	m.updated[change.OrderID] = order
+	m.changes = append(m.changes, change)
 	cloned := *order
 	return &cloned, nil
 }
 
+func (m *mockOrderRepository) ListStatusHistory(ctx context.Context, orderID int64) ([]domorder.StatusHistory, error) {
+	var history []domorder.StatusHistory
+	for i, c := range m.changes {
+		if c.OrderID == orderID {
+			history = append(history, domorder.StatusHistory{
+				ID: int64(i + 1), OrderID: c.OrderID, From: c.From, To: c.To, ChangedBy: c.ChangedBy, Note: c.Note,
+			})
+		}
+	}
+	return history, nil
+}
+
 func TestGetOrder_NotFound(t *testing.T) {
 	repo := newMockOrderRepository()
 	svc := NewService(repo)
@@ -87,14 +129,14 @@ func TestGetOrder_Found(t *testing.T) {
 		UserID:        100,
 		Status:        domorder.StatusPending,
 		PaymentMethod: domorder.PaymentCOD,
-		TotalAmount:   199.98,
+		TotalAmount:   money.MustParse("199.98", "SAR"),
 		Items: []domorder.OrderItem{
 			{
 				ID:        1,
 				OrderID:   1,
 				ProductID: 1,
 				Name:      "Laptop",
-				Price:     999.99,
+				Price:     money.MustParse("999.99", "SAR"),
 				Quantity:  2,
 			},
 		},
@@ -112,7 +154,7 @@ func TestGetOrder_Found(t *testing.T) {
 	require.Equal(t, int64(100), order.UserID)
 	require.Equal(t, domorder.StatusPending, order.Status)
 	require.Equal(t, domorder.PaymentCOD, order.PaymentMethod)
-	require.Equal(t, 199.98, order.TotalAmount)
+	require.Equal(t, money.MustParse("199.98", "SAR"), order.TotalAmount)
 	require.Len(t, order.Items, 1)
 	require.Equal(t, "Laptop", order.Items[0].Name)
 }
@@ -134,10 +176,6 @@ func TestUpdateOrderStatus_InvalidStatus(t *testing.T) {
 			name:  "Processing status (not allowed)",
 			status: domorder.Status("PROCESSING"),
 		},
-		{
-			name:  "Delivered status (not allowed)",
-			status: domorder.Status("DELIVERED"),
-		},
 		{
 			name:  "Lowercase pending",
 			status: domorder.Status("pending"),
@@ -155,7 +193,7 @@ func TestUpdateOrderStatus_InvalidStatus(t *testing.T) {
 
 			svc := NewService(repo)
 
-			order, err := svc.UpdateStatus(context.Background(), 1, tt.status)
+			order, err := svc.UpdateStatus(context.Background(), 1, UpdateStatusInput{Status: tt.status})
 
 			require.ErrorIs(t, err, domorder.ErrInvalidStatus)
 			require.Nil(t, order)
@@ -169,7 +207,7 @@ func TestUpdateOrderStatus_OrderNotFound(t *testing.T) {
 	repo := newMockOrderRepository()
 	svc := NewService(repo)
 
-	order, err := svc.UpdateStatus(context.Background(), 999, domorder.StatusPaid)
+	order, err := svc.UpdateStatus(context.Background(), 999, UpdateStatusInput{Status: domorder.StatusPaid})
 
 	require.ErrorIs(t, err, domorder.ErrOrderNotFound)
 	require.Nil(t, order)
@@ -203,9 +241,9 @@ func TestUpdateOrderStatus_Valid(t *testing.T) {
 			newStatus:     domorder.StatusCanceled,
 		},
 		{
-			name:          "Same status (PENDING to PENDING)",
-			initialStatus: domorder.StatusPending,
-			newStatus:     domorder.StatusPending,
+			name:          "SHIPPED to DELIVERED",
+			initialStatus: domorder.StatusShipped,
+			newStatus:     domorder.StatusDelivered,
 		},
 	}
 
@@ -217,14 +255,14 @@ func TestUpdateOrderStatus_Valid(t *testing.T) {
 				UserID:        100,
 				Status:        tt.initialStatus,
 				PaymentMethod: domorder.PaymentCOD,
-				TotalAmount:   199.98,
+				TotalAmount:   money.MustParse("199.98", "SAR"),
 				Items:         []domorder.OrderItem{},
 				CreatedAt:     time.Now(),
 			}
 
 			svc := NewService(repo)
 
-			order, err := svc.UpdateStatus(context.Background(), 1, tt.newStatus)
+			order, err := svc.UpdateStatus(context.Background(), 1, UpdateStatusInput{Status: tt.newStatus})
 
 			require.NoError(t, err)
 			require.NotNil(t, order)
@@ -239,7 +277,7 @@ func TestListOrders_Empty(t *testing.T) {
 	repo := newMockOrderRepository()
 	svc := NewService(repo)
 
-	orders, err := svc.List(context.Background())
+	orders, _, err := svc.List(context.Background(), domorder.ListFilter{}, page.Request{})
 
 	require.NoError(t, err)
 	require.NotNil(t, orders)
@@ -253,7 +291,7 @@ func TestListOrders_WithMultipleOrders(t *testing.T) {
 		UserID:        100,
 		Status:        domorder.StatusPending,
 		PaymentMethod: domorder.PaymentCOD,
-		TotalAmount:   99.99,
+		TotalAmount:   money.MustParse("99.99", "SAR"),
 		Items:         []domorder.OrderItem{},
 		CreatedAt:     time.Now(),
 	}
@@ -262,7 +300,7 @@ func TestListOrders_WithMultipleOrders(t *testing.T) {
 		UserID:        200,
 		Status:        domorder.StatusPaid,
 		PaymentMethod: domorder.PaymentTamara,
-		TotalAmount:   199.98,
+		TotalAmount:   money.MustParse("199.98", "SAR"),
 		Items:         []domorder.OrderItem{},
 		CreatedAt:     time.Now(),
 	}
@@ -271,14 +309,14 @@ func TestListOrders_WithMultipleOrders(t *testing.T) {
 		UserID:        100,
 		Status:        domorder.StatusShipped,
 		PaymentMethod: domorder.PaymentCOD,
-		TotalAmount:   299.97,
+		TotalAmount:   money.MustParse("299.97", "SAR"),
 		Items:         []domorder.OrderItem{},
 		CreatedAt:     time.Now(),
 	}
 
 	svc := NewService(repo)
 
-	orders, err := svc.List(context.Background())
+	orders, _, err := svc.List(context.Background(), domorder.ListFilter{}, page.Request{})
 
 	require.NoError(t, err)
 	require.NotNil(t, orders)
@@ -303,14 +341,14 @@ func TestGetOrder_WithItems(t *testing.T) {
 		UserID:        100,
 		Status:        domorder.StatusPending,
 		PaymentMethod: domorder.PaymentCOD,
-		TotalAmount:   499.95,
+		TotalAmount:   money.MustParse("499.95", "SAR"),
 		Items: []domorder.OrderItem{
 			{
 				ID:        1,
 				OrderID:   1,
 				ProductID: 1,
 				Name:      "Laptop",
-				Price:     999.99,
+				Price:     money.MustParse("999.99", "SAR"),
 				Quantity:  1,
 			},
 			{
@@ -318,7 +356,7 @@ func TestGetOrder_WithItems(t *testing.T) {
 				OrderID:   1,
 				ProductID: 2,
 				Name:      "Mouse",
-				Price:     29.99,
+				Price:     money.MustParse("29.99", "SAR"),
 				Quantity:  2,
 			},
 		},
@@ -339,45 +377,99 @@ func TestGetOrder_WithItems(t *testing.T) {
 	require.Equal(t, int64(2), order.Items[1].Quantity)
 }
 
-func TestUpdateOrderStatus_AllValidStatuses(t *testing.T) {
-	validStatuses := []domorder.Status{
-		domorder.StatusPending,
-		domorder.StatusPaid,
-		domorder.StatusShipped,
-		domorder.StatusCanceled,
+func TestUpdateOrderStatus_IllegalTransition(t *testing.T) {
+	tests := []struct {
+		name          string
+		initialStatus domorder.Status
+		newStatus     domorder.Status
+	}{
+		{name: "Same status (PENDING to PENDING)", initialStatus: domorder.StatusPending, newStatus: domorder.StatusPending},
+		{name: "PENDING to SHIPPED skips payment", initialStatus: domorder.StatusPending, newStatus: domorder.StatusShipped},
+		{name: "PENDING to DELIVERED", initialStatus: domorder.StatusPending, newStatus: domorder.StatusDelivered},
+		{name: "SHIPPED back to PENDING", initialStatus: domorder.StatusShipped, newStatus: domorder.StatusPending},
+		{name: "SHIPPED to CANCELED", initialStatus: domorder.StatusShipped, newStatus: domorder.StatusCanceled},
+		{name: "CANCELED revived", initialStatus: domorder.StatusCanceled, newStatus: domorder.StatusPending},
+		{name: "DELIVERED is terminal", initialStatus: domorder.StatusDelivered, newStatus: domorder.StatusShipped},
 	}
 
-	for _, status := range validStatuses {
-		t.Run(string(status), func(t *testing.T) {
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
 			repo := newMockOrderRepository()
 			repo.orders[1] = &domorder.Order{
 				ID:     1,
 				UserID: 100,
-				Status: domorder.StatusPending,
+				Status: tt.initialStatus,
 			}
 
 			svc := NewService(repo)
 
-			order, err := svc.UpdateStatus(context.Background(), 1, status)
+			order, err := svc.UpdateStatus(context.Background(), 1, UpdateStatusInput{Status: tt.newStatus})
 
-			require.NoError(t, err)
-			require.NotNil(t, order)
-			require.Equal(t, status, order.Status)
+			require.ErrorIs(t, err, domorder.ErrInvalidTransition)
+			require.Nil(t, order)
+			require.Equal(t, tt.initialStatus, repo.orders[1].Status, "order status should not be updated")
+			require.Empty(t, repo.changes, "no transition should be recorded")
 		})
 	}
 }
 
+func TestUpdateOrderStatus_RecordsTransition(t *testing.T) {
+	repo := newMockOrderRepository()
+	repo.orders[1] = &domorder.Order{
+		ID:     1,
+		UserID: 100,
+		Status: domorder.StatusPaid,
+	}
+	svc := NewService(repo)
+
+	_, err := svc.UpdateStatus(context.Background(), 1, UpdateStatusInput{
+		Status:    domorder.StatusShipped,
+		ChangedBy: 7,
+		Note:      "handed to courier",
+	})
+	require.NoError(t, err)
+
+	history, err := svc.ListStatusHistory(context.Background(), 1)
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	require.Equal(t, domorder.StatusPaid, history[0].From)
+	require.Equal(t, domorder.StatusShipped, history[0].To)
+	require.Equal(t, int64(7), history[0].ChangedBy)
+	require.Equal(t, "handed to courier", history[0].Note)
+}
+
 func TestListOrders_RepositoryError(t *testing.T) {
 	repo := newMockOrderRepository()
 	repo.listErr = domorder.ErrOrderNotFound
 	svc := NewService(repo)
 
-	orders, err := svc.List(context.Background())
+	orders, _, err := svc.List(context.Background(), domorder.ListFilter{}, page.Request{})
 
 	require.ErrorIs(t, err, domorder.ErrOrderNotFound)
 	require.Nil(t, orders)
 }
 
+func TestListOrders_RejectsInvertedRanges(t *testing.T) {
+	svc := NewService(newMockOrderRepository())
+	from := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(-24 * time.Hour)
+	minTotal := money.MustParse("50", "SAR")
+	maxTotal := money.MustParse("10", "SAR")
+	otherCurrency := money.MustParse("100", "USD")
+
+	_, _, err := svc.List(context.Background(), domorder.ListFilter{CreatedFrom: &from, CreatedTo: &to}, page.Request{})
+	require.ErrorIs(t, err, domorder.ErrInvalidDateRange)
+
+	_, _, err = svc.List(context.Background(), domorder.ListFilter{MinTotal: &minTotal, MaxTotal: &maxTotal}, page.Request{})
+	require.ErrorIs(t, err, domorder.ErrInvalidTotalRange)
+
+	_, _, err = svc.List(context.Background(), domorder.ListFilter{MinTotal: &maxTotal, MaxTotal: &otherCurrency}, page.Request{})
+	require.ErrorIs(t, err, domorder.ErrInvalidTotalRange)
+
+	_, _, err = svc.List(context.Background(), domorder.ListFilter{MinTotal: &maxTotal, MaxTotal: &minTotal}, page.Request{})
+	require.NoError(t, err)
+}
+
 func TestGetOrder_RepositoryError(t *testing.T) {
 	repo := newMockOrderRepository()
 	repo.getErr = domorder.ErrOrderNotFound
@@ -399,9 +491,192 @@ func TestUpdateOrderStatus_RepositoryError(t *testing.T) {
 	repo.updateErr = domorder.ErrOrderNotFound
 	svc := NewService(repo)
 
-	order, err := svc.UpdateStatus(context.Background(), 1, domorder.StatusPaid)
+	order, err := svc.UpdateStatus(context.Background(), 1, UpdateStatusInput{Status: domorder.StatusPaid})
+
+	require.ErrorIs(t, err, domorder.ErrOrderNotFound)
+	require.Nil(t, order)
+}
+
+
+func TestListForUser_OnlyReturnsOwnOrders(t *testing.T) {
+	repo := newMockOrderRepository()
+	repo.orders[1] = &domorder.Order{ID: 1, UserID: 100, Status: domorder.StatusPending}
+	repo.orders[2] = &domorder.Order{ID: 2, UserID: 200, Status: domorder.StatusPaid}
+	repo.orders[3] = &domorder.Order{ID: 3, UserID: 100, Status: domorder.StatusShipped}
+	svc := NewService(repo)
+
+	orders, _, err := svc.ListForUser(context.Background(), 100, page.Request{})
+
+	require.NoError(t, err)
+	require.Len(t, orders, 2)
+	for _, order := range orders {
+		require.Equal(t, int64(100), order.UserID)
+	}
+}
+
+func TestGetForUser_OtherUsersOrderNotFound(t *testing.T) {
+	repo := newMockOrderRepository()
+	repo.orders[1] = &domorder.Order{ID: 1, UserID: 100, Status: domorder.StatusPending}
+	svc := NewService(repo)
+
+	order, err := svc.GetForUser(context.Background(), 1, 200)
+
+	require.ErrorIs(t, err, domorder.ErrOrderNotFound)
+	require.Nil(t, order)
+}
+
+func TestGetForUser_Found(t *testing.T) {
+	repo := newMockOrderRepository()
+	repo.orders[1] = &domorder.Order{ID: 1, UserID: 100, Status: domorder.StatusPending}
+	svc := NewService(repo)
+
+	order, err := svc.GetForUser(context.Background(), 1, 100)
+
+	require.NoError(t, err)
+	require.Equal(t, int64(1), order.ID)
+}
+
+func TestCancelForUser_PendingOrder(t *testing.T) {
+	repo := newMockOrderRepository()
+	repo.orders[1] = &domorder.Order{ID: 1, UserID: 100, Status: domorder.StatusPending}
+	svc := NewService(repo)
+
+	order, err := svc.CancelForUser(context.Background(), 1, 100)
+
+	require.NoError(t, err)
+	require.Equal(t, domorder.StatusCanceled, order.Status)
+	require.Len(t, repo.changes, 1)
+	require.Equal(t, domorder.StatusPending, repo.changes[0].From)
+	require.Equal(t, int64(100), repo.changes[0].ChangedBy)
+	require.True(t, repo.changes[0].ReleasesStock(), "cancel should release reserved stock")
+}
+
+func TestCancelForUser_ProgressedOrderNotCancelable(t *testing.T) {
+	for _, status := range []domorder.Status{domorder.StatusPaid, domorder.StatusShipped, domorder.StatusDelivered, domorder.StatusCanceled} {
+		t.Run(string(status), func(t *testing.T) {
+			repo := newMockOrderRepository()
+			repo.orders[1] = &domorder.Order{ID: 1, UserID: 100, Status: status}
+			svc := NewService(repo)
+
+			order, err := svc.CancelForUser(context.Background(), 1, 100)
+
+			require.ErrorIs(t, err, domorder.ErrNotCancelable)
+			require.Nil(t, order)
+			require.Empty(t, repo.changes)
+		})
+	}
+}
+
+func TestCancelForUser_OtherUsersOrderNotFound(t *testing.T) {
+	repo := newMockOrderRepository()
+	repo.orders[1] = &domorder.Order{ID: 1, UserID: 100, Status: domorder.StatusPending}
+	svc := NewService(repo)
+
+	order, err := svc.CancelForUser(context.Background(), 1, 200)
 
 	require.ErrorIs(t, err, domorder.ErrOrderNotFound)
 	require.Nil(t, order)
+	require.Equal(t, domorder.StatusPending, repo.orders[1].Status)
+}
+
+type mockPaymentSettler struct {
+	captured []int64
+	canceled []int64
+	err      error
+}
+
+func (m *mockPaymentSettler) Capture(ctx context.Context, orderID int64) (*dompayment.Payment, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	m.captured = append(m.captured, orderID)
+	return &dompayment.Payment{OrderID: orderID, Status: dompayment.StatusCaptured}, nil
+}
+
+func (m *mockPaymentSettler) Cancel(ctx context.Context, orderID int64) (*dompayment.Payment, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	m.canceled = append(m.canceled, orderID)
+	return &dompayment.Payment{OrderID: orderID, Status: dompayment.StatusVoided}, nil
 }
 
+func TestCancelForUser_VoidsOpenPaymentSession(t *testing.T) {
+	repo := newMockOrderRepository()
+	repo.orders[1] = &domorder.Order{ID: 1, UserID: 100, Status: domorder.StatusPending, PaymentMethod: domorder.PaymentTamara}
+	payments := &mockPaymentSettler{}
+	svc := NewService(repo, WithPayments(payments))
+
+	order, err := svc.CancelForUser(context.Background(), 1, 100)
+
+	require.NoError(t, err)
+	require.Equal(t, domorder.StatusCanceled, order.Status)
+	require.Equal(t, []int64{1}, payments.canceled)
+}
+
+func TestCancelForUser_VoidFailureKeepsOrderPending(t *testing.T) {
+	repo := newMockOrderRepository()
+	repo.orders[1] = &domorder.Order{ID: 1, UserID: 100, Status: domorder.StatusPending, PaymentMethod: domorder.PaymentTamara}
+	svc := NewService(repo, WithPayments(&mockPaymentSettler{err: dompayment.ErrGatewayRequest}))
+
+	order, err := svc.CancelForUser(context.Background(), 1, 100)
+
+	require.ErrorIs(t, err, dompayment.ErrGatewayRequest)
+	require.Nil(t, order)
+	require.Empty(t, repo.changes)
+	require.Equal(t, domorder.StatusPending, repo.orders[1].Status)
+}
+
+func TestCancelForUser_OrderWithoutPaymentIsCanceled(t *testing.T) {
+	repo := newMockOrderRepository()
+	repo.orders[1] = &domorder.Order{ID: 1, UserID: 100, Status: domorder.StatusPending, PaymentMethod: domorder.PaymentCOD}
+	svc := NewService(repo, WithPayments(&mockPaymentSettler{err: dompayment.ErrPaymentNotFound}))
+
+	order, err := svc.CancelForUser(context.Background(), 1, 100)
+
+	require.NoError(t, err)
+	require.Equal(t, domorder.StatusCanceled, order.Status)
+}
+
+func TestUpdateOrderStatus_SettlesPaymentFirst(t *testing.T) {
+	tests := []struct {
+		name     string
+		from     domorder.Status
+		to       domorder.Status
+		captured []int64
+		canceled []int64
+	}{
+		{"cancel pending order", domorder.StatusPending, domorder.StatusCanceled, nil, []int64{1}},
+		{"cancel paid order", domorder.StatusPaid, domorder.StatusCanceled, nil, []int64{1}},
+		{"ship paid order", domorder.StatusPaid, domorder.StatusShipped, []int64{1}, nil},
+		{"deliver", domorder.StatusShipped, domorder.StatusDelivered, nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMockOrderRepository()
+			repo.orders[1] = &domorder.Order{ID: 1, UserID: 100, Status: tt.from, PaymentMethod: domorder.PaymentTamara}
+			payments := &mockPaymentSettler{}
+			svc := NewService(repo, WithPayments(payments))
+
+			order, err := svc.UpdateStatus(context.Background(), 1, UpdateStatusInput{Status: tt.to, ChangedBy: 1})
+
+			require.NoError(t, err)
+			require.Equal(t, tt.to, order.Status)
+			require.Equal(t, tt.captured, payments.captured)
+			require.Equal(t, tt.canceled, payments.canceled)
+		})
+	}
+}
+
+func TestUpdateOrderStatus_PaymentFailureKeepsStatus(t *testing.T) {
+	repo := newMockOrderRepository()
+	repo.orders[1] = &domorder.Order{ID: 1, UserID: 100, Status: domorder.StatusPaid, PaymentMethod: domorder.PaymentTamara}
+	svc := NewService(repo, WithPayments(&mockPaymentSettler{err: dompayment.ErrGatewayRequest}))
+
+	order, err := svc.UpdateStatus(context.Background(), 1, UpdateStatusInput{Status: domorder.StatusCanceled, ChangedBy: 1})
+
+	require.ErrorIs(t, err, dompayment.ErrGatewayRequest)
+	require.Nil(t, order)
+	require.Empty(t, repo.changes)
+	require.Equal(t, domorder.StatusPaid, repo.orders[1].Status)
+}
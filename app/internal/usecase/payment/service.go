@@ -0,0 +1,319 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"example.com/my-golang-sample/app/internal/domain/money"
+	domorder "example.com/my-golang-sample/app/internal/domain/order"
+	dompayment "example.com/my-golang-sample/app/internal/domain/payment"
+	domuser "example.com/my-golang-sample/app/internal/domain/user"
+	orderuc "example.com/my-golang-sample/app/internal/usecase/order"
+)
+
+type UserReader interface {
+	GetByID(ctx context.Context, id int64) (*domuser.User, error)
+}
+
+// OrderStatusUpdater is the order usecase as seen from payment webhooks.
+type OrderStatusUpdater interface {
+	GetByID(ctx context.Context, id int64) (*domorder.Order, error)
+	UpdateStatus(ctx context.Context, id int64, in orderuc.UpdateStatusInput) (*domorder.Order, error)
+}
+
+type Service struct {
+	repo     dompayment.Repository
+	users    UserReader
+	orders   OrderStatusUpdater
+	gateways map[dompayment.Provider]dompayment.Gateway
+}
+
+func NewService(repo dompayment.Repository, users UserReader, orders OrderStatusUpdater, gateways ...dompayment.Gateway) *Service {
+	registry := make(map[dompayment.Provider]dompayment.Gateway, len(gateways))
+	for _, g := range gateways {
+		registry[g.Provider()] = g
+	}
+	return &Service{
+		repo:     repo,
+		users:    users,
+		orders:   orders,
+		gateways: registry,
+	}
+}
+
+// ProviderFor maps an order payment method to the online provider that settles it.
+// COD has no provider and is settled offline.
+func ProviderFor(method domorder.PaymentMethod) (dompayment.Provider, bool) {
+	switch method {
+	case domorder.PaymentTamara:
+		return dompayment.ProviderTamara, true
+	default:
+		return "", false
+	}
+}
+
+// StartCheckout opens a provider session for the order and stores it.
+// It returns nil for orders that are settled offline.
+func (s *Service) StartCheckout(ctx context.Context, order *domorder.Order) (*dompayment.Payment, error) {
+	provider, online := ProviderFor(order.PaymentMethod)
+	if !online {
+		return nil, nil
+	}
+	gateway, err := s.gateway(provider)
+	if err != nil {
+		return nil, err
+	}
+
+	u, err := s.users.GetByID(ctx, order.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]dompayment.SessionItem, 0, len(order.Items))
+	for _, item := range order.Items {
+		items = append(items, dompayment.SessionItem{
+			ProductID: item.ProductID,
+			Name:      item.Name,
+			UnitPrice: item.Price,
+			Quantity:  item.Quantity,
+		})
+	}
+
+	session, err := gateway.CreateSession(ctx, dompayment.SessionRequest{
+		OrderID:  order.ID,
+		Amount:   order.TotalAmount,
+		Customer: dompayment.Customer{ID: u.ID, Name: u.Name, Email: u.Email},
+		Items:    items,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.Create(ctx, &dompayment.Payment{
+		OrderID:     order.ID,
+		Provider:    provider,
+		Reference:   session.Reference,
+		RedirectURL: session.RedirectURL,
+		Amount:      order.TotalAmount,
+		Status:      dompayment.StatusPending,
+	})
+}
+
+func (s *Service) Capture(ctx context.Context, orderID int64) (*dompayment.Payment, error) {
+	return s.settle(ctx, orderID, dompayment.StatusCaptured, dompayment.Gateway.Capture, dompayment.StatusPending, dompayment.StatusAuthorized)
+}
+
+func (s *Service) Refund(ctx context.Context, orderID int64) (*dompayment.Payment, error) {
+	return s.settle(ctx, orderID, dompayment.StatusRefunded, dompayment.Gateway.Refund, dompayment.StatusCaptured)
+}
+
+func (s *Service) Void(ctx context.Context, orderID int64) (*dompayment.Payment, error) {
+	return s.settle(ctx, orderID, dompayment.StatusVoided, dompayment.Gateway.Void, dompayment.StatusPending, dompayment.StatusAuthorized)
+}
+
+// Cancel gives the money of a canceled order back: an open or authorised
+// payment is voided, a captured one refunded. Closed payments are left as is.
+func (s *Service) Cancel(ctx context.Context, orderID int64) (*dompayment.Payment, error) {
+	p, err := s.repo.GetByOrderID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	switch p.Status {
+	case dompayment.StatusPending, dompayment.StatusAuthorized:
+		return s.Void(ctx, orderID)
+	case dompayment.StatusCaptured:
+		return s.Refund(ctx, orderID)
+	default:
+		return p, nil
+	}
+}
+
+func (s *Service) GetByOrderID(ctx context.Context, orderID int64) (*dompayment.Payment, error) {
+	return s.repo.GetByOrderID(ctx, orderID)
+}
+
+type gatewayCall func(g dompayment.Gateway, ctx context.Context, reference string, amount money.Money) error
+
+func (s *Service) settle(ctx context.Context, orderID int64, to dompayment.Status, call gatewayCall, from ...dompayment.Status) (*dompayment.Payment, error) {
+	p, err := s.repo.GetByOrderID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	// Đã ở trạng thái đích: lần trước gọi provider xong nhưng bước sau lỗi, thử lại không gọi lần nữa
+	if p.Status == to {
+		return p, nil
+	}
+	if !slices.Contains(from, p.Status) {
+		return nil, dompayment.ErrInvalidTransition
+	}
+
+	gateway, err := s.gateway(p.Provider)
+	if err != nil {
+		return nil, err
+	}
+	if err := call(gateway, ctx, p.Reference, p.Amount); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.UpdateStatus(ctx, p.ID, to); err != nil {
+		return nil, err
+	}
+	p.Status = to
+	return p, nil
+}
+
+type WebhookResult string
+
+const (
+	WebhookProcessed WebhookResult = "processed"
+	WebhookDuplicate WebhookResult = "duplicate"
+	WebhookIgnored   WebhookResult = "ignored"
+)
+
+// SignatureHeader names the request header the provider's webhooks are
+// signed in.
+func (s *Service) SignatureHeader(provider dompayment.Provider) (string, error) {
+	gateway, err := s.gateway(provider)
+	if err != nil {
+		return "", err
+	}
+	return gateway.SignatureHeader(), nil
+}
+
+// HandleWebhook verifies a provider notification and applies it to the payment
+// and its order. Every event is logged; replays of a processed event are no-ops.
+func (s *Service) HandleWebhook(ctx context.Context, provider dompayment.Provider, payload []byte, signature string) (WebhookResult, error) {
+	gateway, err := s.gateway(provider)
+	if err != nil {
+		return "", err
+	}
+
+	event, err := gateway.ParseWebhook(payload, signature)
+	if err != nil {
+		return "", err
+	}
+
+	processed, err := s.repo.RecordEvent(ctx, event)
+	if err != nil {
+		return "", err
+	}
+	if processed {
+		return WebhookDuplicate, nil
+	}
+
+	result, err := s.applyEvent(ctx, event)
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.repo.MarkEventProcessed(ctx, event.Provider, event.ID); err != nil {
+		return "", err
+	}
+	return result, nil
+}
+
+func (s *Service) applyEvent(ctx context.Context, event *dompayment.WebhookEvent) (WebhookResult, error) {
+	switch event.Type {
+	case dompayment.EventApproved, dompayment.EventDeclined, dompayment.EventCanceled, dompayment.EventExpired:
+	default:
+		return WebhookIgnored, nil
+	}
+
+	p, err := s.repo.GetByOrderID(ctx, event.OrderID)
+	if err != nil {
+		return "", err
+	}
+	if p.Provider != event.Provider || p.Reference != event.Reference {
+		return "", dompayment.ErrPaymentNotFound
+	}
+
+	order, err := s.orders.GetByID(ctx, event.OrderID)
+	if err != nil {
+		return "", err
+	}
+	if event.Type == dompayment.EventApproved {
+		return s.applyApproval(ctx, event, p, order)
+	}
+	return s.applyFailure(ctx, event, p, order)
+}
+
+// applyApproval authorises the payment with the provider, then marks the
+// order paid. An order that was canceled in the meantime has its payment
+// voided instead, so the customer is not charged for it.
+func (s *Service) applyApproval(ctx context.Context, event *dompayment.WebhookEvent, p *dompayment.Payment, order *domorder.Order) (WebhookResult, error) {
+	// Payment AUTHORIZED nghĩa là lần trước đã authorise xong nhưng lỗi ở bước cập nhật đơn
+	if p.Status != dompayment.StatusPending && p.Status != dompayment.StatusAuthorized {
+		return WebhookIgnored, nil
+	}
+	if _, err := s.settle(ctx, event.OrderID, dompayment.StatusAuthorized, dompayment.Gateway.Authorise, dompayment.StatusPending); err != nil {
+		return "", err
+	}
+
+	switch order.Status {
+	case domorder.StatusPending:
+		if _, err := s.orders.UpdateStatus(ctx, event.OrderID, orderuc.UpdateStatusInput{
+			Status: domorder.StatusPaid,
+			Note:   string(event.Provider) + " webhook " + string(event.Type),
+		}); err != nil {
+			// Đơn vừa bị hủy: UPDATE có điều kiện từ chối, trả lại tiền cho khách
+			if errors.Is(err, domorder.ErrInvalidTransition) {
+				return s.voidLateApproval(ctx, event.OrderID)
+			}
+			return "", err
+		}
+	case domorder.StatusPaid:
+	case domorder.StatusCanceled:
+		return s.voidLateApproval(ctx, event.OrderID)
+	default:
+		return WebhookIgnored, nil
+	}
+	return WebhookProcessed, nil
+}
+
+func (s *Service) voidLateApproval(ctx context.Context, orderID int64) (WebhookResult, error) {
+	if _, err := s.Void(ctx, orderID); err != nil {
+		return "", err
+	}
+	return WebhookProcessed, nil
+}
+
+// applyFailure cancels the order of a declined, canceled or expired payment.
+func (s *Service) applyFailure(ctx context.Context, event *dompayment.WebhookEvent, p *dompayment.Payment, order *domorder.Order) (WebhookResult, error) {
+	// Chỉ payment còn PENDING mới được webhook chuyển trạng thái
+	if p.Status != dompayment.StatusPending {
+		return WebhookIgnored, nil
+	}
+
+	// Đơn được cập nhật trước payment: nếu bước này lỗi, payment vẫn PENDING nên lần retry
+	// của provider chạy lại từ đầu. Đơn đã bị hủy nghĩa là lần trước lỗi ở bước payment,
+	// chỉ cần hoàn tất payment.
+	switch order.Status {
+	case domorder.StatusPending:
+		if _, err := s.orders.UpdateStatus(ctx, event.OrderID, orderuc.UpdateStatusInput{
+			Status: domorder.StatusCanceled,
+			Note:   string(event.Provider) + " webhook " + string(event.Type),
+		}); err != nil {
+			if errors.Is(err, domorder.ErrInvalidTransition) {
+				return WebhookIgnored, nil
+			}
+			return "", err
+		}
+	case domorder.StatusCanceled:
+	default:
+		return WebhookIgnored, nil
+	}
+
+	if err := s.repo.UpdateStatus(ctx, p.ID, dompayment.StatusFailed); err != nil {
+		return "", err
+	}
+	return WebhookProcessed, nil
+}
+
+func (s *Service) gateway(provider dompayment.Provider) (dompayment.Gateway, error) {
+	g, ok := s.gateways[provider]
+	if !ok {
+		return nil, dompayment.ErrUnsupportedProvider
+	}
+	return g, nil
+}
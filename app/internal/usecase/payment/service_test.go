@@ -0,0 +1,436 @@
+package payment
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"example.com/my-golang-sample/app/internal/domain/money"
+	domorder "example.com/my-golang-sample/app/internal/domain/order"
+	dompayment "example.com/my-golang-sample/app/internal/domain/payment"
+	domuser "example.com/my-golang-sample/app/internal/domain/user"
+	paymentgw "example.com/my-golang-sample/app/internal/infra/payment"
+	orderuc "example.com/my-golang-sample/app/internal/usecase/order"
+)
+
+type mockPaymentRepository struct {
+	payments  map[int64]*dompayment.Payment
+	events    map[string]bool
+	nextID    int64
+	updateErr error
+}
+
+func newMockPaymentRepository() *mockPaymentRepository {
+	return &mockPaymentRepository{
+		payments: make(map[int64]*dompayment.Payment),
+		events:   make(map[string]bool),
+		nextID:   1,
+	}
+}
+
+func (m *mockPaymentRepository) Create(ctx context.Context, p *dompayment.Payment) (*dompayment.Payment, error) {
+	p.ID = m.nextID
+	m.nextID++
+	cloned := *p
+	m.payments[p.ID] = &cloned
+	return p, nil
+}
+
+func (m *mockPaymentRepository) GetByOrderID(ctx context.Context, orderID int64) (*dompayment.Payment, error) {
+	for _, p := range m.payments {
+		if p.OrderID == orderID {
+			cloned := *p
+			return &cloned, nil
+		}
+	}
+	return nil, dompayment.ErrPaymentNotFound
+}
+
+func (m *mockPaymentRepository) UpdateStatus(ctx context.Context, id int64, status dompayment.Status) error {
+	if m.updateErr != nil {
+		return m.updateErr
+	}
+	p, ok := m.payments[id]
+	if !ok {
+		return dompayment.ErrPaymentNotFound
+	}
+	p.Status = status
+	return nil
+}
+
+func (m *mockPaymentRepository) RecordEvent(ctx context.Context, e *dompayment.WebhookEvent) (bool, error) {
+	processed, ok := m.events[e.ID]
+	if !ok {
+		m.events[e.ID] = false
+	}
+	return processed, nil
+}
+
+func (m *mockPaymentRepository) MarkEventProcessed(ctx context.Context, provider dompayment.Provider, eventID string) error {
+	m.events[eventID] = true
+	return nil
+}
+
+type mockOrderUpdater struct {
+	orders    map[int64]*domorder.Order
+	updates   []domorder.Status
+	updateErr error
+}
+
+func (m *mockOrderUpdater) GetByID(ctx context.Context, id int64) (*domorder.Order, error) {
+	if o, ok := m.orders[id]; ok {
+		cloned := *o
+		return &cloned, nil
+	}
+	return nil, domorder.ErrOrderNotFound
+}
+
+func (m *mockOrderUpdater) UpdateStatus(ctx context.Context, id int64, in orderuc.UpdateStatusInput) (*domorder.Order, error) {
+	if m.updateErr != nil {
+		return nil, m.updateErr
+	}
+	o, ok := m.orders[id]
+	if !ok {
+		return nil, domorder.ErrOrderNotFound
+	}
+	o.Status = in.Status
+	m.updates = append(m.updates, in.Status)
+	cloned := *o
+	return &cloned, nil
+}
+
+type mockUserReader struct{}
+
+func (mockUserReader) GetByID(ctx context.Context, id int64) (*domuser.User, error) {
+	return &domuser.User{ID: id, Name: "Jane Doe", Email: "jane@example.com"}, nil
+}
+
+func newTamaraOrder() *domorder.Order {
+	return &domorder.Order{
+		ID:            10,
+		UserID:        100,
+		Status:        domorder.StatusPending,
+		PaymentMethod: domorder.PaymentTamara,
+		TotalAmount:   money.MustParse("40.0", "SAR"),
+		Items: []domorder.OrderItem{
+			{ProductID: 1, Name: "Product 1", Price: money.MustParse("10.0", "SAR"), Quantity: 2},
+			{ProductID: 2, Name: "Product 2", Price: money.MustParse("20.0", "SAR"), Quantity: 1},
+		},
+	}
+}
+
+func setupPaymentService() (*Service, *mockPaymentRepository, *paymentgw.FakeGateway) {
+	repo := newMockPaymentRepository()
+	gateway := paymentgw.NewFakeGateway(dompayment.ProviderTamara, "https://pay.test/checkout")
+	svc := NewService(repo, mockUserReader{}, nil, gateway)
+	return svc, repo, gateway
+}
+
+func TestStartCheckout_TamaraCreatesSessionAndStoresPayment(t *testing.T) {
+	svc, repo, gateway := setupPaymentService()
+
+	p, err := svc.StartCheckout(context.Background(), newTamaraOrder())
+
+	require.NoError(t, err)
+	require.NotNil(t, p)
+	require.Equal(t, dompayment.ProviderTamara, p.Provider)
+	require.Equal(t, dompayment.StatusPending, p.Status)
+	require.Equal(t, int64(10), p.OrderID)
+	require.Equal(t, money.MustParse("40.00", "SAR"), p.Amount)
+	require.Contains(t, p.RedirectURL, "https://pay.test/checkout")
+	require.NotEmpty(t, p.Reference)
+
+	require.Len(t, repo.payments, 1)
+	require.Len(t, gateway.Calls, 1)
+	require.Equal(t, "create", gateway.Calls[0].Op)
+}
+
+func TestStartCheckout_CODSkipsGateway(t *testing.T) {
+	svc, repo, gateway := setupPaymentService()
+	order := newTamaraOrder()
+	order.PaymentMethod = domorder.PaymentCOD
+
+	p, err := svc.StartCheckout(context.Background(), order)
+
+	require.NoError(t, err)
+	require.Nil(t, p)
+	require.Empty(t, repo.payments)
+	require.Empty(t, gateway.Calls)
+}
+
+func TestStartCheckout_NoGatewayRegistered(t *testing.T) {
+	svc := NewService(newMockPaymentRepository(), mockUserReader{}, nil)
+
+	p, err := svc.StartCheckout(context.Background(), newTamaraOrder())
+
+	require.ErrorIs(t, err, dompayment.ErrUnsupportedProvider)
+	require.Nil(t, p)
+}
+
+func TestStartCheckout_GatewayFailureStoresNothing(t *testing.T) {
+	svc, repo, gateway := setupPaymentService()
+	gateway.Err = dompayment.ErrGatewayRequest
+
+	p, err := svc.StartCheckout(context.Background(), newTamaraOrder())
+
+	require.ErrorIs(t, err, dompayment.ErrGatewayRequest)
+	require.Nil(t, p)
+	require.Empty(t, repo.payments)
+}
+
+func TestCaptureThenRefund(t *testing.T) {
+	svc, _, gateway := setupPaymentService()
+	_, err := svc.StartCheckout(context.Background(), newTamaraOrder())
+	require.NoError(t, err)
+
+	captured, err := svc.Capture(context.Background(), 10)
+	require.NoError(t, err)
+	require.Equal(t, dompayment.StatusCaptured, captured.Status)
+
+	refunded, err := svc.Refund(context.Background(), 10)
+	require.NoError(t, err)
+	require.Equal(t, dompayment.StatusRefunded, refunded.Status)
+
+	require.Len(t, gateway.Calls, 3)
+	require.Equal(t, "capture", gateway.Calls[1].Op)
+	require.Equal(t, "refund", gateway.Calls[2].Op)
+	require.Equal(t, money.MustParse("40.00", "SAR"), gateway.Calls[2].Amount)
+}
+
+func TestVoid_PendingPayment(t *testing.T) {
+	svc, _, gateway := setupPaymentService()
+	_, err := svc.StartCheckout(context.Background(), newTamaraOrder())
+	require.NoError(t, err)
+
+	voided, err := svc.Void(context.Background(), 10)
+
+	require.NoError(t, err)
+	require.Equal(t, dompayment.StatusVoided, voided.Status)
+	require.Equal(t, "void", gateway.Calls[len(gateway.Calls)-1].Op)
+}
+
+func TestRefund_NotCapturedReturnsInvalidTransition(t *testing.T) {
+	svc, _, gateway := setupPaymentService()
+	_, err := svc.StartCheckout(context.Background(), newTamaraOrder())
+	require.NoError(t, err)
+
+	p, err := svc.Refund(context.Background(), 10)
+
+	require.ErrorIs(t, err, dompayment.ErrInvalidTransition)
+	require.Nil(t, p)
+	require.Len(t, gateway.Calls, 1)
+}
+
+func TestCapture_UnknownOrderReturnsNotFound(t *testing.T) {
+	svc, _, _ := setupPaymentService()
+
+	p, err := svc.Capture(context.Background(), 999)
+
+	require.ErrorIs(t, err, dompayment.ErrPaymentNotFound)
+	require.Nil(t, p)
+}
+
+func setupWebhookService(t *testing.T) (*Service, *mockPaymentRepository, *mockOrderUpdater, *paymentgw.FakeGateway, string) {
+	repo := newMockPaymentRepository()
+	orders := &mockOrderUpdater{orders: map[int64]*domorder.Order{10: newTamaraOrder()}}
+	gateway := paymentgw.NewFakeGateway(dompayment.ProviderTamara, "https://pay.test/checkout")
+	gateway.WebhookSecret = "whsec"
+	svc := NewService(repo, mockUserReader{}, orders, gateway)
+
+	p, err := svc.StartCheckout(context.Background(), newTamaraOrder())
+	require.NoError(t, err)
+	return svc, repo, orders, gateway, p.Reference
+}
+
+func signedWebhook(t *testing.T, gateway *paymentgw.FakeGateway, body paymentgw.FakeWebhook) ([]byte, string) {
+	payload, err := json.Marshal(body)
+	require.NoError(t, err)
+	return payload, gateway.SignWebhook(payload)
+}
+
+func TestHandleWebhook_ApprovedMarksOrderPaid(t *testing.T) {
+	svc, repo, orders, gateway, ref := setupWebhookService(t)
+	payload, sig := signedWebhook(t, gateway, paymentgw.FakeWebhook{ID: "evt_1", Type: dompayment.EventApproved, Reference: ref, OrderID: 10})
+
+	result, err := svc.HandleWebhook(context.Background(), dompayment.ProviderTamara, payload, sig)
+
+	require.NoError(t, err)
+	require.Equal(t, WebhookProcessed, result)
+	require.Equal(t, domorder.StatusPaid, orders.orders[10].Status)
+	require.Equal(t, dompayment.StatusAuthorized, repo.payments[1].Status)
+	require.Equal(t, []string{"authorise"}, fakeOps(gateway))
+	require.True(t, repo.events["evt_1"])
+}
+
+func TestHandleWebhook_DeclinedCancelsOrder(t *testing.T) {
+	svc, repo, orders, gateway, ref := setupWebhookService(t)
+	payload, sig := signedWebhook(t, gateway, paymentgw.FakeWebhook{ID: "evt_2", Type: dompayment.EventDeclined, Reference: ref, OrderID: 10})
+
+	result, err := svc.HandleWebhook(context.Background(), dompayment.ProviderTamara, payload, sig)
+
+	require.NoError(t, err)
+	require.Equal(t, WebhookProcessed, result)
+	require.Equal(t, domorder.StatusCanceled, orders.orders[10].Status)
+	require.Equal(t, dompayment.StatusFailed, repo.payments[1].Status)
+}
+
+func TestHandleWebhook_ReplayIsIdempotent(t *testing.T) {
+	svc, _, orders, gateway, ref := setupWebhookService(t)
+	payload, sig := signedWebhook(t, gateway, paymentgw.FakeWebhook{ID: "evt_1", Type: dompayment.EventApproved, Reference: ref, OrderID: 10})
+
+	first, err := svc.HandleWebhook(context.Background(), dompayment.ProviderTamara, payload, sig)
+	require.NoError(t, err)
+	second, err := svc.HandleWebhook(context.Background(), dompayment.ProviderTamara, payload, sig)
+	require.NoError(t, err)
+
+	require.Equal(t, WebhookProcessed, first)
+	require.Equal(t, WebhookDuplicate, second)
+	require.Len(t, orders.updates, 1)
+}
+
+func TestHandleWebhook_InvalidSignature(t *testing.T) {
+	svc, repo, orders, gateway, ref := setupWebhookService(t)
+	payload, _ := signedWebhook(t, gateway, paymentgw.FakeWebhook{ID: "evt_1", Type: dompayment.EventApproved, Reference: ref, OrderID: 10})
+
+	_, err := svc.HandleWebhook(context.Background(), dompayment.ProviderTamara, payload, "bogus")
+
+	require.ErrorIs(t, err, dompayment.ErrInvalidSignature)
+	require.Empty(t, repo.events)
+	require.Empty(t, orders.updates)
+}
+
+func TestHandleWebhook_ReferenceMismatchReturnsNotFound(t *testing.T) {
+	svc, _, orders, gateway, _ := setupWebhookService(t)
+	payload, sig := signedWebhook(t, gateway, paymentgw.FakeWebhook{ID: "evt_1", Type: dompayment.EventApproved, Reference: "other", OrderID: 10})
+
+	_, err := svc.HandleWebhook(context.Background(), dompayment.ProviderTamara, payload, sig)
+
+	require.ErrorIs(t, err, dompayment.ErrPaymentNotFound)
+	require.Empty(t, orders.updates)
+}
+
+func TestHandleWebhook_FailureForProgressedOrderIsIgnored(t *testing.T) {
+	svc, repo, orders, gateway, ref := setupWebhookService(t)
+	orders.orders[10].Status = domorder.StatusPaid
+	payload, sig := signedWebhook(t, gateway, paymentgw.FakeWebhook{ID: "evt_2", Type: dompayment.EventExpired, Reference: ref, OrderID: 10})
+
+	result, err := svc.HandleWebhook(context.Background(), dompayment.ProviderTamara, payload, sig)
+
+	require.NoError(t, err)
+	require.Equal(t, WebhookIgnored, result)
+	require.Empty(t, orders.updates)
+	require.Equal(t, dompayment.StatusPending, repo.payments[1].Status)
+}
+
+func TestHandleWebhook_ApprovalForCanceledOrderVoidsPayment(t *testing.T) {
+	svc, repo, orders, gateway, ref := setupWebhookService(t)
+	orders.orders[10].Status = domorder.StatusCanceled
+	payload, sig := signedWebhook(t, gateway, paymentgw.FakeWebhook{ID: "evt_1", Type: dompayment.EventApproved, Reference: ref, OrderID: 10})
+
+	result, err := svc.HandleWebhook(context.Background(), dompayment.ProviderTamara, payload, sig)
+
+	require.NoError(t, err)
+	require.Equal(t, WebhookProcessed, result)
+	require.Empty(t, orders.updates)
+	require.Equal(t, dompayment.StatusVoided, repo.payments[1].Status)
+	require.Equal(t, []string{"authorise", "void"}, fakeOps(gateway))
+}
+
+func TestHandleWebhook_OrderUpdateFailureIsRetried(t *testing.T) {
+	svc, repo, orders, gateway, ref := setupWebhookService(t)
+	payload, sig := signedWebhook(t, gateway, paymentgw.FakeWebhook{ID: "evt_1", Type: dompayment.EventApproved, Reference: ref, OrderID: 10})
+	dbErr := errors.New("db down")
+	orders.updateErr = dbErr
+
+	_, err := svc.HandleWebhook(context.Background(), dompayment.ProviderTamara, payload, sig)
+
+	require.ErrorIs(t, err, dbErr)
+	require.Equal(t, domorder.StatusPending, orders.orders[10].Status)
+	require.Equal(t, dompayment.StatusAuthorized, repo.payments[1].Status)
+	require.False(t, repo.events["evt_1"])
+
+	orders.updateErr = nil
+	result, err := svc.HandleWebhook(context.Background(), dompayment.ProviderTamara, payload, sig)
+
+	require.NoError(t, err)
+	require.Equal(t, WebhookProcessed, result)
+	require.Equal(t, domorder.StatusPaid, orders.orders[10].Status)
+	require.Equal(t, []string{"authorise"}, fakeOps(gateway), "an authorised payment is not authorised again")
+}
+
+func TestHandleWebhook_PaymentUpdateFailureIsRetried(t *testing.T) {
+	svc, repo, orders, gateway, ref := setupWebhookService(t)
+	payload, sig := signedWebhook(t, gateway, paymentgw.FakeWebhook{ID: "evt_1", Type: dompayment.EventApproved, Reference: ref, OrderID: 10})
+	dbErr := errors.New("db down")
+	repo.updateErr = dbErr
+
+	_, err := svc.HandleWebhook(context.Background(), dompayment.ProviderTamara, payload, sig)
+	require.ErrorIs(t, err, dbErr)
+	require.Equal(t, domorder.StatusPending, orders.orders[10].Status, "the order is only paid once the payment is authorised")
+
+	repo.updateErr = nil
+	result, err := svc.HandleWebhook(context.Background(), dompayment.ProviderTamara, payload, sig)
+
+	require.NoError(t, err)
+	require.Equal(t, WebhookProcessed, result)
+	require.Equal(t, dompayment.StatusAuthorized, repo.payments[1].Status)
+	require.Equal(t, domorder.StatusPaid, orders.orders[10].Status)
+	require.Len(t, orders.updates, 1)
+}
+
+func TestHandleWebhook_CustomerCancelRaceVoidsPayment(t *testing.T) {
+	svc, repo, orders, gateway, ref := setupWebhookService(t)
+	payload, sig := signedWebhook(t, gateway, paymentgw.FakeWebhook{ID: "evt_1", Type: dompayment.EventApproved, Reference: ref, OrderID: 10})
+	orders.updateErr = domorder.ErrInvalidTransition
+
+	result, err := svc.HandleWebhook(context.Background(), dompayment.ProviderTamara, payload, sig)
+
+	require.NoError(t, err)
+	require.Equal(t, WebhookProcessed, result)
+	require.Equal(t, dompayment.StatusVoided, repo.payments[1].Status)
+	require.Equal(t, []string{"authorise", "void"}, fakeOps(gateway))
+}
+
+func TestCancel_VoidsOrRefundsByPaymentStatus(t *testing.T) {
+	tests := []struct {
+		status dompayment.Status
+		want   dompayment.Status
+		ops    []string
+	}{
+		{dompayment.StatusPending, dompayment.StatusVoided, []string{"void"}},
+		{dompayment.StatusAuthorized, dompayment.StatusVoided, []string{"void"}},
+		{dompayment.StatusCaptured, dompayment.StatusRefunded, []string{"refund"}},
+		{dompayment.StatusFailed, dompayment.StatusFailed, nil},
+		{dompayment.StatusVoided, dompayment.StatusVoided, nil},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.status), func(t *testing.T) {
+			svc, repo, gateway := setupPaymentService()
+			_, err := svc.StartCheckout(context.Background(), newTamaraOrder())
+			require.NoError(t, err)
+			repo.payments[1].Status = tt.status
+
+			p, err := svc.Cancel(context.Background(), 10)
+
+			require.NoError(t, err)
+			require.Equal(t, tt.want, p.Status)
+			require.Equal(t, tt.want, repo.payments[1].Status)
+			require.Equal(t, tt.ops, fakeOps(gateway))
+		})
+	}
+}
+
+// fakeOps lists the settlement calls made to the gateway, without the
+// checkout session created during setup.
+func fakeOps(gateway *paymentgw.FakeGateway) []string {
+	var ops []string
+	for _, c := range gateway.Calls {
+		if c.Op != "create" {
+			ops = append(ops, c.Op)
+		}
+	}
+	return ops
+}
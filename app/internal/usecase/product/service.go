@@ -2,16 +2,34 @@ package product
 
 import (
 	"context"
+	"slices"
 
+	"example.com/my-golang-sample/app/internal/domain/money"
+	"example.com/my-golang-sample/app/internal/domain/page"
 	dom "example.com/my-golang-sample/app/internal/domain/product"
 )
 
 type Service struct {
-	repo dom.Repository
+	repo     dom.Repository
+	searcher dom.Searcher
 }
 
-func NewService(repo dom.Repository) *Service {
-	return &Service{repo: repo}
+type Option func(*Service)
+
+// WithSearcher ranks searches by relevance. Without it a search only
+// filters the list.
+func WithSearcher(searcher dom.Searcher) Option {
+	return func(s *Service) {
+		s.searcher = searcher
+	}
+}
+
+func NewService(repo dom.Repository, opts ...Option) *Service {
+	s := &Service{repo: repo}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 func (s *Service) Create(ctx context.Context, p *dom.Product) (*dom.Product, error) {
@@ -30,7 +48,7 @@ func (s *Service) Update(ctx context.Context, p *dom.Product) (*dom.Product, err
 	if p.Description != "" {
 		existed.Description = p.Description
 	}
-	if p.Price > 0 {
+	if p.Price.IsPositive() {
 		existed.Price = p.Price
 	}
 	if p.Stock >= 0 {
@@ -52,7 +70,59 @@ func (s *Service) GetByID(ctx context.Context, id int64) (*dom.Product, error) {
 	return s.repo.GetByID(ctx, id)
 }
 
-func (s *Service) List(ctx context.Context, filter dom.ListFilter) ([]*dom.Product, error) {
-	return s.repo.List(ctx, filter)
+// List returns one page of products. Searches are ordered by relevance
+// unless another sort is asked for.
+func (s *Service) List(ctx context.Context, filter dom.ListFilter, p page.Request) ([]*dom.Product, page.Info, error) {
+	allowed := dom.SortFields
+	ranked := s.searcher != nil && len(dom.SearchTerms(filter.Search)) > 0
+	if ranked {
+		allowed = append(slices.Clone(dom.SortFields), dom.SortRelevance)
+		if p.Sort == "" || p.Sort == dom.SortRelevance {
+			p.Sort, p.Desc = dom.SortRelevance, true
+		}
+	}
+	p, err := p.Normalize(allowed)
+	if err != nil {
+		return nil, page.Info{}, err
+	}
+	if ranked && p.Sort == dom.SortRelevance {
+		if p.After != nil {
+			return nil, page.Info{}, page.ErrInvalidCursor
+		}
+		return s.searcher.Search(ctx, filter, p)
+	}
+	return s.repo.List(ctx, filter, p)
+}
+
+// SetPrice sets what the product costs in price.Currency. A price in the
+// product's base currency replaces the base price.
+func (s *Service) SetPrice(ctx context.Context, id int64, price money.Money) (*dom.Product, error) {
+	// Cột price chỉ có 2 chữ số thập phân, lưu KWD... sẽ bị làm tròn
+	if money.Exponent(price.Currency) > money.MaxExponent {
+		return nil, money.ErrUnsupportedCurrency
+	}
+	p, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if price.Currency == p.Price.Currency {
+		p.Price = price
+		if _, err := s.repo.Update(ctx, p); err != nil {
+			return nil, err
+		}
+	} else if err := s.repo.SetPrice(ctx, id, price); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(ctx, id)
 }
 
+func (s *Service) DeletePrice(ctx context.Context, id int64, currency string) error {
+	p, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if currency == p.Price.Currency {
+		return dom.ErrBasePriceRequired
+	}
+	return s.repo.DeletePrice(ctx, id, currency)
+}
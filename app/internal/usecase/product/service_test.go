@@ -9,7 +9,10 @@ import (
 	"github.com/stretchr/testify/require"
 
 	domcategory "example.com/my-golang-sample/app/internal/domain/category"
+	"example.com/my-golang-sample/app/internal/domain/money"
+	"example.com/my-golang-sample/app/internal/domain/page"
 	domproduct "example.com/my-golang-sample/app/internal/domain/product"
+	"example.com/my-golang-sample/app/internal/infra/search"
 )
 
 type mockProductRepository struct {
@@ -41,7 +44,7 @@ func (m *mockProductRepository) Create(ctx context.Context, p *domproduct.Produc
 	if name == "" {
 		return nil, errors.New("product name is required")
 	}
-	if p.Price <= 0 {
+	if !p.Price.IsPositive() {
 		return nil, errors.New("product price must be greater than 0")
 	}
 	if p.Stock < 0 {
@@ -75,7 +78,7 @@ func (m *mockProductRepository) Update(ctx context.Context, p *domproduct.Produc
 		}
 		existing.Name = p.Name
 	}
-	if p.Price > 0 {
+	if p.Price.IsPositive() {
 		existing.Price = p.Price
 	}
 	if p.Stock >= 0 {
@@ -114,7 +117,7 @@ func (m *mockProductRepository) GetByID(ctx context.Context, id int64) (*domprod
 	return nil, domproduct.ErrProductNotFound
 }
 
-func (m *mockProductRepository) List(ctx context.Context, filter domproduct.ListFilter) ([]*domproduct.Product, error) {
+func (m *mockProductRepository) List(ctx context.Context, filter domproduct.ListFilter, req page.Request) ([]*domproduct.Product, page.Info, error) {
 	var result []*domproduct.Product
 	for _, p := range m.products {
 		if filter.OnlyActive && !p.IsActive {
@@ -132,7 +135,7 @@ func (m *mockProductRepository) List(ctx context.Context, filter domproduct.List
 		cloned := *p
 		result = append(result, &cloned)
 	}
-	return result, nil
+	return result, page.Info{Total: int64(len(result)), Page: req.Page, Size: req.Size}, nil
 }
 
 func (m *mockProductRepository) GetByIDs(ctx context.Context, ids []int64) ([]*domproduct.Product, error) {
@@ -146,6 +149,35 @@ func (m *mockProductRepository) GetByIDs(ctx context.Context, ids []int64) ([]*d
 	return result, nil
 }
 
+func (m *mockProductRepository) SetPrice(ctx context.Context, productID int64, price money.Money) error {
+	p, ok := m.products[productID]
+	if !ok {
+		return domproduct.ErrProductNotFound
+	}
+	for i, existing := range p.Prices {
+		if existing.Currency == price.Currency {
+			p.Prices[i] = price
+			return nil
+		}
+	}
+	p.Prices = append(p.Prices, price)
+	return nil
+}
+
+func (m *mockProductRepository) DeletePrice(ctx context.Context, productID int64, currency string) error {
+	p, ok := m.products[productID]
+	if !ok {
+		return domproduct.ErrProductNotFound
+	}
+	for i, existing := range p.Prices {
+		if existing.Currency == currency {
+			p.Prices = append(p.Prices[:i], p.Prices[i+1:]...)
+			return nil
+		}
+	}
+	return domproduct.ErrPriceNotFound
+}
+
 func contains(s, substr string) bool {
 	if len(substr) == 0 {
 		return true
@@ -169,7 +201,7 @@ func TestCreateProduct_Valid(t *testing.T) {
 	product, err := svc.Create(context.Background(), &domproduct.Product{
 		Name:        "Laptop",
 		Description: "High-performance laptop",
-		Price:       999.99,
+		Price:       money.MustParse("999.99", "SAR"),
 		Stock:       10,
 		CategoryID:  1,
 		IsActive:    true,
@@ -179,7 +211,7 @@ func TestCreateProduct_Valid(t *testing.T) {
 	require.NotNil(t, product)
 	require.Equal(t, "Laptop", product.Name)
 	require.Equal(t, "High-performance laptop", product.Description)
-	require.Equal(t, 999.99, product.Price)
+	require.Equal(t, money.MustParse("999.99", "SAR"), product.Price)
 	require.Equal(t, int64(10), product.Stock)
 	require.Equal(t, int64(1), product.CategoryID)
 	require.True(t, product.IsActive)
@@ -214,7 +246,7 @@ func TestCreateProduct_EmptyName(t *testing.T) {
 
 			product, err := svc.Create(context.Background(), &domproduct.Product{
 				Name:       tt.inputName,
-				Price:      99.99,
+				Price:      money.MustParse("99.99", "SAR"),
 				Stock:      5,
 				CategoryID: 1,
 			})
@@ -230,15 +262,15 @@ func TestCreateProduct_EmptyName(t *testing.T) {
 func TestCreateProduct_InvalidPrice(t *testing.T) {
 	tests := []struct {
 		name  string
-		price float64
+		price money.Money
 	}{
 		{
 			name:  "Zero price",
-			price: 0,
+			price: money.Zero("SAR"),
 		},
 		{
 			name:  "Negative price",
-			price: -10.50,
+			price: money.MustParse("-10.50", "SAR"),
 		},
 	}
 
@@ -270,7 +302,7 @@ func TestCreateProduct_NegativeStock(t *testing.T) {
 
 	product, err := svc.Create(context.Background(), &domproduct.Product{
 		Name:       "Test Product",
-		Price:      99.99,
+		Price:      money.MustParse("99.99", "SAR"),
 		Stock:      -5,
 		CategoryID: 1,
 	})
@@ -288,7 +320,7 @@ func TestCreateProduct_ZeroStock(t *testing.T) {
 
 	product, err := svc.Create(context.Background(), &domproduct.Product{
 		Name:       "Out of Stock Product",
-		Price:      99.99,
+		Price:      money.MustParse("99.99", "SAR"),
 		Stock:      0,
 		CategoryID: 1,
 	})
@@ -305,7 +337,7 @@ func TestCreateProduct_InvalidCategory(t *testing.T) {
 
 	product, err := svc.Create(context.Background(), &domproduct.Product{
 		Name:       "Test Product",
-		Price:      99.99,
+		Price:      money.MustParse("99.99", "SAR"),
 		Stock:      5,
 		CategoryID: 999, // Non-existent category
 	})
@@ -318,19 +350,19 @@ func TestCreateProduct_InvalidCategory(t *testing.T) {
 func TestCreateProduct_ValidWithDifferentPrices(t *testing.T) {
 	tests := []struct {
 		name  string
-		price float64
+		price money.Money
 	}{
 		{
 			name:  "Small price",
-			price: 0.01,
+			price: money.MustParse("0.01", "SAR"),
 		},
 		{
 			name:  "Medium price",
-			price: 99.99,
+			price: money.MustParse("99.99", "SAR"),
 		},
 		{
 			name:  "Large price",
-			price: 9999.99,
+			price: money.MustParse("9999.99", "SAR"),
 		},
 	}
 
@@ -361,7 +393,7 @@ func TestUpdateProduct_ChangePriceAndStock(t *testing.T) {
 	// Create a product first
 	created, err := svc.Create(context.Background(), &domproduct.Product{
 		Name:       "Original Product",
-		Price:      50.00,
+		Price:      money.MustParse("50.00", "SAR"),
 		Stock:      20,
 		CategoryID:  1,
 		IsActive:    true,
@@ -372,13 +404,13 @@ func TestUpdateProduct_ChangePriceAndStock(t *testing.T) {
 	// Update price and stock
 	updated, err := svc.Update(context.Background(), &domproduct.Product{
 		ID:    created.ID,
-		Price: 75.50,
+		Price: money.MustParse("75.50", "SAR"),
 		Stock: 15,
 	})
 
 	require.NoError(t, err)
 	require.NotNil(t, updated)
-	require.Equal(t, 75.50, updated.Price)
+	require.Equal(t, money.MustParse("75.50", "SAR"), updated.Price)
 	require.Equal(t, int64(15), updated.Stock)
 	require.Equal(t, "Original Product", updated.Name, "name should remain unchanged")
 	require.Equal(t, int64(1), updated.CategoryID, "category should remain unchanged")
@@ -391,7 +423,7 @@ func TestUpdateProduct_NotFound(t *testing.T) {
 	product, err := svc.Update(context.Background(), &domproduct.Product{
 		ID:    999,
 		Name:  "Updated Name",
-		Price: 99.99,
+		Price: money.MustParse("99.99", "SAR"),
 	})
 
 	require.ErrorIs(t, err, domproduct.ErrProductNotFound)
@@ -407,7 +439,7 @@ func TestUpdateProduct_InvalidCategory(t *testing.T) {
 	// Create a product with valid category
 	created, err := svc.Create(context.Background(), &domproduct.Product{
 		Name:       "Test Product",
-		Price:      50.00,
+		Price:      money.MustParse("50.00", "SAR"),
 		Stock:      10,
 		CategoryID: 1,
 	})
@@ -431,7 +463,7 @@ func TestUpdateProduct_InvalidPrice(t *testing.T) {
 	// Create a product first
 	created, err := svc.Create(context.Background(), &domproduct.Product{
 		Name:       "Test Product",
-		Price:      50.00,
+		Price:      money.MustParse("50.00", "SAR"),
 		Stock:      10,
 		CategoryID: 1,
 	})
@@ -440,11 +472,11 @@ func TestUpdateProduct_InvalidPrice(t *testing.T) {
 	// Try to update with zero price (should not update due to condition p.Price > 0)
 	updated, err := svc.Update(context.Background(), &domproduct.Product{
 		ID:    created.ID,
-		Price: 0,
+		Price: money.Zero("SAR"),
 	})
 
 	require.NoError(t, err)
-	require.Equal(t, 50.00, updated.Price, "price should remain unchanged when set to 0")
+	require.Equal(t, money.MustParse("50.00", "SAR"), updated.Price, "price should remain unchanged when set to 0")
 }
 
 func TestUpdateProduct_NegativeStock(t *testing.T) {
@@ -455,7 +487,7 @@ func TestUpdateProduct_NegativeStock(t *testing.T) {
 	// Create a product first
 	created, err := svc.Create(context.Background(), &domproduct.Product{
 		Name:       "Test Product",
-		Price:      50.00,
+		Price:      money.MustParse("50.00", "SAR"),
 		Stock:      10,
 		CategoryID: 1,
 	})
@@ -480,7 +512,7 @@ func TestUpdateProduct_PartialUpdate(t *testing.T) {
 	created, err := svc.Create(context.Background(), &domproduct.Product{
 		Name:        "Original Product",
 		Description: "Original description",
-		Price:       50.00,
+		Price:       money.MustParse("50.00", "SAR"),
 		Stock:       10,
 		CategoryID:  1,
 		IsActive:    true,
@@ -497,7 +529,7 @@ func TestUpdateProduct_PartialUpdate(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, "Updated Name", updated.Name)
 	require.Equal(t, "Original description", updated.Description, "description should remain unchanged")
-	require.Equal(t, 50.00, updated.Price, "price should remain unchanged")
+	require.Equal(t, money.MustParse("50.00", "SAR"), updated.Price, "price should remain unchanged")
 	require.Equal(t, int64(10), updated.Stock, "stock should remain unchanged")
 }
 
@@ -509,7 +541,7 @@ func TestGetProduct_Found(t *testing.T) {
 	// Create a product first
 	created, err := svc.Create(context.Background(), &domproduct.Product{
 		Name:       "Test Product",
-		Price:      99.99,
+		Price:      money.MustParse("99.99", "SAR"),
 		Stock:      5,
 		CategoryID: 1,
 	})
@@ -542,7 +574,7 @@ func TestDeleteProduct_Success(t *testing.T) {
 	// Create a product first
 	created, err := svc.Create(context.Background(), &domproduct.Product{
 		Name:       "To Be Deleted",
-		Price:      99.99,
+		Price:      money.MustParse("99.99", "SAR"),
 		Stock:      5,
 		CategoryID: 1,
 	})
@@ -569,3 +601,147 @@ func TestDeleteProduct_NotFound(t *testing.T) {
 	require.Zero(t, repo.deletedID)
 }
 
+func TestSetPrice_AddsListPrice(t *testing.T) {
+	repo := newMockProductRepository()
+	repo.products[1] = &domproduct.Product{ID: 1, Name: "Laptop", Price: money.MustParse("1000", "SAR"), Stock: 5}
+	svc := NewService(repo)
+
+	product, err := svc.SetPrice(context.Background(), 1, money.MustParse("270", "USD"))
+
+	require.NoError(t, err)
+	require.Equal(t, money.MustParse("1000", "SAR"), product.Price)
+	require.Equal(t, []money.Money{money.MustParse("270", "USD")}, product.Prices)
+	require.Nil(t, repo.updated)
+}
+
+func TestSetPrice_RejectsCurrencyWithMoreDecimalsThanStored(t *testing.T) {
+	repo := newMockProductRepository()
+	repo.products[1] = &domproduct.Product{ID: 1, Name: "Laptop", Price: money.MustParse("1000", "SAR"), Stock: 5}
+	svc := NewService(repo)
+
+	product, err := svc.SetPrice(context.Background(), 1, money.MustParse("82.125", "KWD"))
+
+	require.ErrorIs(t, err, money.ErrUnsupportedCurrency)
+	require.Nil(t, product)
+	require.Empty(t, repo.products[1].Prices)
+}
+
+func TestSetPrice_BaseCurrencyReplacesBasePrice(t *testing.T) {
+	repo := newMockProductRepository()
+	repo.products[1] = &domproduct.Product{ID: 1, Name: "Laptop", Price: money.MustParse("1000", "SAR"), Stock: 5}
+	svc := NewService(repo)
+
+	product, err := svc.SetPrice(context.Background(), 1, money.MustParse("950", "SAR"))
+
+	require.NoError(t, err)
+	require.Equal(t, money.MustParse("950", "SAR"), product.Price)
+	require.Empty(t, product.Prices)
+}
+
+func TestDeletePrice_BaseCurrencyIsRejected(t *testing.T) {
+	repo := newMockProductRepository()
+	repo.products[1] = &domproduct.Product{
+		ID: 1, Name: "Laptop", Price: money.MustParse("1000", "SAR"), Stock: 5,
+		Prices: []money.Money{money.MustParse("270", "USD")},
+	}
+	svc := NewService(repo)
+
+	err := svc.DeletePrice(context.Background(), 1, "SAR")
+	require.ErrorIs(t, err, domproduct.ErrBasePriceRequired)
+
+	require.NoError(t, svc.DeletePrice(context.Background(), 1, "USD"))
+	require.Empty(t, repo.products[1].Prices)
+
+	err = svc.DeletePrice(context.Background(), 1, "USD")
+	require.ErrorIs(t, err, domproduct.ErrPriceNotFound)
+}
+
+func TestService_List_OnlyWhitelistedSortFields(t *testing.T) {
+	svc := NewService(newMockProductRepository())
+
+	_, info, err := svc.List(context.Background(), domproduct.ListFilter{}, page.Request{Sort: "price", Size: 500})
+	require.NoError(t, err)
+	require.Equal(t, page.MaxSize, info.Size)
+
+	_, _, err = svc.List(context.Background(), domproduct.ListFilter{}, page.Request{Sort: "description"})
+	require.ErrorIs(t, err, page.ErrInvalidSort)
+}
+
+func newSearchCatalog() []*domproduct.Product {
+	sar := func(s string) money.Money { return money.MustParse(s, "SAR") }
+	return []*domproduct.Product{
+		{ID: 1, Name: "Mechanical Keyboard", Description: "Hot-swappable switches", Price: sar("350"), CategoryID: 1, IsActive: true},
+		{ID: 2, Name: "Wrist Rest", Description: "Foam support for any keyboard", Price: sar("40"), CategoryID: 1, IsActive: true},
+		{ID: 3, Name: "Wireless Mouse", Description: "Quiet clicks", Price: sar("90"), CategoryID: 1, IsActive: true},
+		{ID: 4, Name: "Wireless Keyboard", Description: "Bluetooth keyboard with numpad", Price: sar("220"), CategoryID: 2, IsActive: true},
+		{ID: 5, Name: "Keyboard Cleaner", Description: "Retired model", Price: sar("15"), CategoryID: 1, IsActive: false},
+	}
+}
+
+func productIDs(products []*domproduct.Product) []int64 {
+	ids := make([]int64, 0, len(products))
+	for _, p := range products {
+		ids = append(ids, p.ID)
+	}
+	return ids
+}
+
+func TestService_List_SearchRanksByRelevance(t *testing.T) {
+	svc := NewService(newMockProductRepository(), WithSearcher(search.NewMemoryIndex(newSearchCatalog()...)))
+	active := domproduct.ListFilter{OnlyActive: true}
+
+	active.Search = "keyboard"
+	products, info, err := svc.List(context.Background(), active, page.Request{})
+	require.NoError(t, err)
+	require.Equal(t, []int64{4, 1, 2}, productIDs(products), "name matches outrank description matches")
+	require.Equal(t, int64(3), info.Total)
+	require.Empty(t, info.NextCursor)
+
+	active.Search = "wireless keyboard"
+	products, _, err = svc.List(context.Background(), active, page.Request{})
+	require.NoError(t, err)
+	require.Equal(t, int64(4), products[0].ID, "matching every word ranks first")
+	require.Len(t, products, 4)
+
+	active.Search = "keybaord"
+	products, _, err = svc.List(context.Background(), active, page.Request{})
+	require.NoError(t, err)
+	require.Equal(t, []int64{4, 1, 2}, productIDs(products), "a typo still matches by prefix")
+
+	category := int64(1)
+	products, _, err = svc.List(context.Background(), domproduct.ListFilter{Search: "keyb", CategoryID: &category}, page.Request{Size: 1, Page: 2})
+	require.NoError(t, err)
+	require.Equal(t, []int64{1}, productIDs(products), "filters and page numbers apply to ranked results")
+}
+
+func TestService_List_RelevanceSortNeedsSearch(t *testing.T) {
+	svc := NewService(newMockProductRepository(), WithSearcher(search.NewMemoryIndex(newSearchCatalog()...)))
+
+	_, _, err := svc.List(context.Background(), domproduct.ListFilter{}, page.Request{Sort: domproduct.SortRelevance})
+	require.ErrorIs(t, err, page.ErrInvalidSort)
+
+	_, _, err = svc.List(context.Background(), domproduct.ListFilter{Search: "?!"}, page.Request{Sort: domproduct.SortRelevance})
+	require.ErrorIs(t, err, page.ErrInvalidSort, "punctuation alone is not a search")
+
+	cursor := page.EncodeCursor(page.Cursor{Sort: domproduct.SortRelevance, Desc: true, ID: 4})
+	_, _, err = svc.List(context.Background(), domproduct.ListFilter{Search: "keyboard"}, page.Request{Cursor: cursor})
+	require.ErrorIs(t, err, page.ErrInvalidCursor)
+}
+
+func TestService_List_SearchWithExplicitSortUsesRepository(t *testing.T) {
+	repo := newMockProductRepository()
+	repo.products[7] = &domproduct.Product{ID: 7, Name: "Keyboard Stand", Price: money.MustParse("60", "SAR"), IsActive: true}
+	svc := NewService(repo, WithSearcher(search.NewMemoryIndex(newSearchCatalog()...)))
+
+	products, _, err := svc.List(context.Background(), domproduct.ListFilter{Search: "Keyboard"}, page.Request{Sort: "price"})
+	require.NoError(t, err)
+	require.Equal(t, []int64{7}, productIDs(products))
+}
+
+func TestSearchTerms(t *testing.T) {
+	require.Equal(t, []string{"usb", "c", "cable"}, domproduct.SearchTerms("  USB-C cable, usb! "))
+	require.Equal(t, "keyb", domproduct.SearchPrefix("keybaord"))
+	require.Equal(t, "headp", domproduct.SearchPrefix("headphnes"))
+	require.Equal(t, "mous", domproduct.SearchPrefix("mouse"))
+	require.Equal(t, "cab", domproduct.SearchPrefix("cab"))
+}
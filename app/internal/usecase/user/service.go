@@ -2,21 +2,89 @@ package user
 
 import (
 	"context"
+	"errors"
+	"strings"
+	"time"
 
+	"example.com/my-golang-sample/app/internal/domain/page"
 	dom "example.com/my-golang-sample/app/internal/domain/user"
+	domrole "example.com/my-golang-sample/app/internal/domain/userrole"
 )
 
 type PasswordHasher interface {
 	Hash(password string) (string, error)
 }
 
+// PasswordComparer checks a plain password against a stored hash.
+type PasswordComparer interface {
+	Compare(hash string, password string) error
+}
+
+// PasswordVerifier checks a user's current password and throttles repeated
+// failures like failed logins.
+type PasswordVerifier interface {
+	VerifyPassword(ctx context.Context, u *dom.User, password string) error
+}
+
+// TokenRevoker signs a user out everywhere, access tokens included.
+type TokenRevoker interface {
+	RevokeUserTokens(ctx context.Context, userID int64) error
+}
+
+var (
+	ErrPasswordChangeDisabled = errors.New("password change is not enabled")
+	ErrEmailChangeDisabled    = errors.New("email change is not enabled")
+)
+
 type Service struct {
-	repo   dom.Repository
-	hasher PasswordHasher
+	repo     dom.Repository
+	hasher   PasswordHasher
+	comparer PasswordComparer
+	verifier PasswordVerifier
+	policy   *domrole.AssignmentPolicy
+	revoker  TokenRevoker
+}
+
+type Option func(*Service)
+
+// WithPasswordComparer enables ChangePassword, which needs the current
+// password to be checked.
+func WithPasswordComparer(c PasswordComparer) Option {
+	return func(s *Service) {
+		s.comparer = c
+	}
+}
+
+// WithPasswordVerifier checks the current password in UpdateProfile and
+// ChangePassword through v, so wrong guesses count towards the login lockout.
+func WithPasswordVerifier(v PasswordVerifier) Option {
+	return func(s *Service) {
+		s.verifier = v
+	}
 }
 
-func NewService(repo dom.Repository, hasher PasswordHasher) *Service {
-	return &Service{repo: repo, hasher: hasher}
+// WithAssignmentPolicy decides which roles an executor may assign. The
+// default policy uses the built-in ranks of the system roles.
+func WithAssignmentPolicy(p *domrole.AssignmentPolicy) Option {
+	return func(s *Service) {
+		s.policy = p
+	}
+}
+
+// WithTokenRevoker signs users out when an admin changes their role or
+// password or deletes them, so tokens issued earlier stop working.
+func WithTokenRevoker(r TokenRevoker) Option {
+	return func(s *Service) {
+		s.revoker = r
+	}
+}
+
+func NewService(repo dom.Repository, hasher PasswordHasher, opts ...Option) *Service {
+	s := &Service{repo: repo, hasher: hasher, policy: domrole.NewAssignmentPolicy(nil)}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 type CreateUserInput struct {
@@ -27,6 +95,22 @@ type CreateUserInput struct {
 	RoleCode     dom.RoleCode
 }
 
+// UpdateProfileInput is what users may change about themselves; it has no
+// role on purpose.
+type UpdateProfileInput struct {
+	ID    int64
+	Name  *string
+	Email *string
+	// CurrentPassword is required when Email changes.
+	CurrentPassword string
+}
+
+type ChangePasswordInput struct {
+	ID              int64
+	CurrentPassword string
+	NewPassword     string
+}
+
 type UpdateUserInput struct {
 	ExecutorRole dom.RoleCode
 	ID           int64
@@ -45,15 +129,9 @@ func (s *Service) CreateUser(ctx context.Context, in CreateUserInput) (*dom.User
 		return nil, dom.ErrInvalidRoleCode
 	}
 
-	// Business rule: ADMIN cannot create users with ADMIN role
-	if in.ExecutorRole == dom.RoleCodeAdmin && in.RoleCode == dom.RoleCodeAdmin {
-		// Rule đặc biệt: admin không được tạo admin
-		return nil, dom.ErrCannotAssignRole
-	}
-
-	// General RBAC rule: check executor có quyền gán role này không
-	if !dom.CanAssignRole(in.ExecutorRole, in.RoleCode) {
-		return nil, dom.ErrCannotAssignRole
+	// RBAC rule: chỉ được gán role có rank thấp hơn role của executor
+	if err := s.checkAssign(ctx, in.ExecutorRole, in.RoleCode); err != nil {
+		return nil, err
 	}
 
 	roleID, err := s.repo.GetRoleIDByCode(ctx, in.RoleCode)
@@ -66,12 +144,15 @@ func (s *Service) CreateUser(ctx context.Context, in CreateUserInput) (*dom.User
 		return nil, err
 	}
 
+	// Admin tạo user thì coi như email đã xác thực
+	verifiedAt := time.Now()
 	u := &dom.User{
-		Name:         in.Name,
-		Email:        in.Email,
-		PasswordHash: hash,
-		UserRoleID:   roleID,
-		RoleCode:     in.RoleCode,
+		Name:            in.Name,
+		Email:           in.Email,
+		PasswordHash:    hash,
+		UserRoleID:      roleID,
+		RoleCode:        in.RoleCode,
+		EmailVerifiedAt: &verifiedAt,
 	}
 
 	return s.repo.Create(ctx, u)
@@ -81,8 +162,12 @@ func (s *Service) GetUser(ctx context.Context, id int64) (*dom.User, error) {
 	return s.repo.GetByID(ctx, id)
 }
 
-func (s *Service) ListUsers(ctx context.Context, filter dom.ListUsersFilter) ([]*dom.User, error) {
-	return s.repo.List(ctx, filter)
+func (s *Service) ListUsers(ctx context.Context, filter dom.ListUsersFilter, p page.Request) ([]*dom.User, page.Info, error) {
+	p, err := p.Normalize(dom.SortFields)
+	if err != nil {
+		return nil, page.Info{}, err
+	}
+	return s.repo.List(ctx, filter, p)
 }
 
 func (s *Service) UpdateUser(ctx context.Context, in UpdateUserInput) (*dom.User, error) {
@@ -91,21 +176,22 @@ func (s *Service) UpdateUser(ctx context.Context, in UpdateUserInput) (*dom.User
 		return nil, err
 	}
 
+	// RBAC rule: chỉ được sửa user có role thấp hơn executor, kể cả khi chỉ
+	// đổi email hay mật khẩu, để không ai chiếm được tài khoản ngang hoặc cao hơn mình
+	if err := s.checkAssign(ctx, in.ExecutorRole, u.RoleCode); err != nil {
+		return nil, err
+	}
+
+	roleChanged := in.RoleCode != nil && *in.RoleCode != u.RoleCode
 	if in.RoleCode != nil {
 		// validate role code
 		if !in.RoleCode.IsValid() {
 			return nil, dom.ErrInvalidRoleCode
 		}
 
-		// Business rule: ADMIN cannot update users to ADMIN role
-		if in.ExecutorRole == dom.RoleCodeAdmin && *in.RoleCode == dom.RoleCodeAdmin {
-			// Rule đặc biệt: admin không được promote lên admin
-			return nil, dom.ErrCannotAssignRole
-		}
-
-		// General RBAC rule
-		if !dom.CanAssignRole(in.ExecutorRole, *in.RoleCode) {
-			return nil, dom.ErrCannotAssignRole
+		// Role mới cũng phải thấp hơn executor
+		if err := s.checkAssign(ctx, in.ExecutorRole, *in.RoleCode); err != nil {
+			return nil, err
 		}
 
 		roleID, err := s.repo.GetRoleIDByCode(ctx, *in.RoleCode)
@@ -123,16 +209,127 @@ func (s *Service) UpdateUser(ctx context.Context, in UpdateUserInput) (*dom.User
 		u.Email = *in.Email
 	}
 	if in.Password != nil {
-		hash, err := s.hasher.Hash(*in.Password)
-		if err != nil {
+		if err := s.setPassword(u, *in.Password); err != nil {
 			return nil, err
 		}
-		u.PasswordHash = hash
 	}
 
+	updated, err := s.repo.Update(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+	// Mật khẩu do admin đặt lại cũng phải đá các phiên cũ ra
+	if roleChanged || in.Password != nil {
+		if err := s.revokeTokens(ctx, u.ID); err != nil {
+			return nil, err
+		}
+	}
+	return updated, nil
+}
+
+// revokeTokens ends the user's sessions; access tokens issued before a role
+// change, password change or deletion would otherwise stay valid until expiry.
+func (s *Service) revokeTokens(ctx context.Context, userID int64) error {
+	if s.revoker == nil {
+		return nil
+	}
+	return s.revoker.RevokeUserTokens(ctx, userID)
+}
+
+func (s *Service) setPassword(u *dom.User, password string) error {
+	hash, err := s.hasher.Hash(password)
+	if err != nil {
+		return err
+	}
+	u.PasswordHash = hash
+	return nil
+}
+
+func (s *Service) checkAssign(ctx context.Context, executor, target dom.RoleCode) error {
+	ok, err := s.policy.CanAssign(ctx, executor, target)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return dom.ErrCannotAssignRole
+	}
+	return nil
+}
+
+// DeleteUser removes a user ranked below the executor.
+func (s *Service) DeleteUser(ctx context.Context, executor dom.RoleCode, id int64) error {
+	u, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.checkAssign(ctx, executor, u.RoleCode); err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	return s.revokeTokens(ctx, id)
+}
+
+// UpdateProfile is the self-service variant of UpdateUser. It skips the
+// rank check, since users always may edit themselves. A new email needs the
+// current password and leaves the account unverified until the new address
+// is confirmed.
+func (s *Service) UpdateProfile(ctx context.Context, in UpdateProfileInput) (*dom.User, error) {
+	u, err := s.repo.GetByID(ctx, in.ID)
+	if err != nil {
+		return nil, err
+	}
+	if in.Name != nil {
+		u.Name = *in.Name
+	}
+	if in.Email != nil {
+		email := strings.TrimSpace(strings.ToLower(*in.Email))
+		if email != u.Email {
+			// Token bị lộ không đủ để chuyển tài khoản sang hộp mail khác
+			if s.comparer == nil && s.verifier == nil {
+				return nil, ErrEmailChangeDisabled
+			}
+			if err := s.checkCurrentPassword(ctx, u, in.CurrentPassword); err != nil {
+				return nil, err
+			}
+			u.Email = email
+			u.EmailVerifiedAt = nil
+		}
+	}
 	return s.repo.Update(ctx, u)
 }
 
-func (s *Service) DeleteUser(ctx context.Context, id int64) error {
-	return s.repo.Delete(ctx, id)
+// ChangePassword sets a new password after checking the current one.
+func (s *Service) ChangePassword(ctx context.Context, in ChangePasswordInput) error {
+	if s.comparer == nil && s.verifier == nil {
+		return ErrPasswordChangeDisabled
+	}
+	if in.NewPassword == "" {
+		return dom.ErrInvalidCredential
+	}
+
+	u, err := s.repo.GetByID(ctx, in.ID)
+	if err != nil {
+		return err
+	}
+	if err := s.checkCurrentPassword(ctx, u, in.CurrentPassword); err != nil {
+		return err
+	}
+
+	if err := s.setPassword(u, in.NewPassword); err != nil {
+		return err
+	}
+	_, err = s.repo.Update(ctx, u)
+	return err
+}
+
+func (s *Service) checkCurrentPassword(ctx context.Context, u *dom.User, password string) error {
+	if s.verifier != nil {
+		return s.verifier.VerifyPassword(ctx, u, password)
+	}
+	if err := s.comparer.Compare(u.PasswordHash, password); err != nil {
+		return dom.ErrInvalidCredential
+	}
+	return nil
 }
@@ -7,6 +7,7 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"example.com/my-golang-sample/app/internal/domain/page"
 	domuser "example.com/my-golang-sample/app/internal/domain/user"
 	domrole "example.com/my-golang-sample/app/internal/domain/userrole"
 )
@@ -73,7 +74,7 @@ func (m *enhancedMockUserRepository) GetByEmail(ctx context.Context, email strin
 	return nil, domuser.ErrUserNotFound
 }
 
-func (m *enhancedMockUserRepository) List(ctx context.Context, filter domuser.ListUsersFilter) ([]*domuser.User, error) {
+func (m *enhancedMockUserRepository) List(ctx context.Context, filter domuser.ListUsersFilter, req page.Request) ([]*domuser.User, page.Info, error) {
 	var result []*domuser.User
 	for _, user := range m.users {
 		if filter.RoleCode != nil && user.RoleCode != *filter.RoleCode {
@@ -81,7 +82,7 @@ func (m *enhancedMockUserRepository) List(ctx context.Context, filter domuser.Li
 		}
 		result = append(result, m.clone(user))
 	}
-	return result, nil
+	return result, page.Info{Total: int64(len(result)), Page: req.Page, Size: req.Size}, nil
 }
 
 func (m *enhancedMockUserRepository) Update(ctx context.Context, u *domuser.User) (*domuser.User, error) {
@@ -796,7 +797,7 @@ func TestListUsers_Success(t *testing.T) {
 	hasher := &enhancedMockHasher{}
 	svc := NewService(repo, hasher)
 
-	users, err := svc.ListUsers(context.Background(), domuser.ListUsersFilter{})
+	users, _, err := svc.ListUsers(context.Background(), domuser.ListUsersFilter{}, page.Request{})
 
 	require.NoError(t, err)
 	require.Len(t, users, 2)
@@ -829,9 +830,9 @@ func TestListUsers_WithRoleFilter_Success(t *testing.T) {
 	svc := NewService(repo, hasher)
 
 	roleCode := domuser.RoleCodeCustomer
-	users, err := svc.ListUsers(context.Background(), domuser.ListUsersFilter{
+	users, _, err := svc.ListUsers(context.Background(), domuser.ListUsersFilter{
 		RoleCode: &roleCode,
-	})
+	}, page.Request{})
 
 	require.NoError(t, err)
 	require.Len(t, users, 1)
@@ -854,7 +855,7 @@ func TestDeleteUser_Success(t *testing.T) {
 	hasher := &enhancedMockHasher{}
 	svc := NewService(repo, hasher)
 
-	err := svc.DeleteUser(context.Background(), 1)
+	err := svc.DeleteUser(context.Background(), domuser.RoleCodeSuperAdmin, 1)
 
 	require.NoError(t, err)
 	require.Len(t, repo.users, 0, "user should be deleted")
@@ -866,7 +867,7 @@ func TestDeleteUser_NotFound_ReturnsError(t *testing.T) {
 	hasher := &enhancedMockHasher{}
 	svc := NewService(repo, hasher)
 
-	err := svc.DeleteUser(context.Background(), 999)
+	err := svc.DeleteUser(context.Background(), domuser.RoleCodeSuperAdmin, 999)
 
 	require.ErrorIs(t, err, domuser.ErrUserNotFound)
 }
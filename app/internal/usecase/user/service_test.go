@@ -3,16 +3,20 @@ package user
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
+	"example.com/my-golang-sample/app/internal/domain/page"
 	domuser "example.com/my-golang-sample/app/internal/domain/user"
+	domrole "example.com/my-golang-sample/app/internal/domain/userrole"
 )
 
 type mockUserRepository struct {
 	created          bool
 	updated          bool
 	roleLookupCalled bool
+	deleted          bool
 	userByID         *domuser.User
 }
 
@@ -33,8 +37,8 @@ func (m *mockUserRepository) GetByEmail(ctx context.Context, email string) (*dom
 	return nil, domuser.ErrUserNotFound
 }
 
-func (m *mockUserRepository) List(ctx context.Context, filter domuser.ListUsersFilter) ([]*domuser.User, error) {
-	return nil, nil
+func (m *mockUserRepository) List(ctx context.Context, filter domuser.ListUsersFilter, req page.Request) ([]*domuser.User, page.Info, error) {
+	return nil, page.Info{}, nil
 }
 
 func (m *mockUserRepository) Update(ctx context.Context, u *domuser.User) (*domuser.User, error) {
@@ -43,6 +47,7 @@ func (m *mockUserRepository) Update(ctx context.Context, u *domuser.User) (*domu
 }
 
 func (m *mockUserRepository) Delete(ctx context.Context, id int64) error {
+	m.deleted = true
 	return nil
 }
 
@@ -127,3 +132,295 @@ func TestService_CreateUser_SuperAdminCanCreateAdmin(t *testing.T) {
 	require.Equal(t, "hashed:strongpass", user.PasswordHash)
 }
 
+
+type mockComparer struct{}
+
+func (mockComparer) Compare(hash string, password string) error {
+	if hash != "hashed:"+password {
+		return domuser.ErrInvalidCredential
+	}
+	return nil
+}
+
+func TestService_UpdateProfile_KeepsRole(t *testing.T) {
+	repo := &mockUserRepository{userByID: &domuser.User{ID: 7, Name: "Jane", Email: "jane@example.com", UserRoleID: 3, RoleCode: domuser.RoleCodeCustomer}}
+	svc := NewService(repo, mockHasher{})
+
+	name := "Jane Doe"
+	u, err := svc.UpdateProfile(context.Background(), UpdateProfileInput{ID: 7, Name: &name})
+
+	require.NoError(t, err)
+	require.Equal(t, "Jane Doe", u.Name)
+	require.Equal(t, "jane@example.com", u.Email)
+	require.Equal(t, domuser.RoleCodeCustomer, u.RoleCode)
+	require.False(t, repo.roleLookupCalled)
+}
+
+func TestService_UpdateProfile_EmailChangeNeedsPasswordAndReverification(t *testing.T) {
+	verifiedAt := time.Now()
+	repo := &mockUserRepository{userByID: &domuser.User{ID: 7, Email: "jane@example.com", PasswordHash: "hashed:secret", RoleCode: domuser.RoleCodeCustomer, EmailVerifiedAt: &verifiedAt}}
+	svc := NewService(repo, mockHasher{}, WithPasswordComparer(mockComparer{}))
+	email := "Attacker@Example.com"
+
+	_, err := svc.UpdateProfile(context.Background(), UpdateProfileInput{ID: 7, Email: &email})
+	require.ErrorIs(t, err, domuser.ErrInvalidCredential, "a stolen access token is not enough")
+	_, err = svc.UpdateProfile(context.Background(), UpdateProfileInput{ID: 7, Email: &email, CurrentPassword: "wrong"})
+	require.ErrorIs(t, err, domuser.ErrInvalidCredential)
+	require.False(t, repo.updated)
+
+	same := "JANE@example.com"
+	u, err := svc.UpdateProfile(context.Background(), UpdateProfileInput{ID: 7, Email: &same})
+	require.NoError(t, err, "resubmitting the current email needs no password")
+	require.True(t, u.IsEmailVerified())
+
+	u, err = svc.UpdateProfile(context.Background(), UpdateProfileInput{ID: 7, Email: &email, CurrentPassword: "secret"})
+	require.NoError(t, err)
+	require.Equal(t, "attacker@example.com", u.Email)
+	require.False(t, u.IsEmailVerified(), "the new address must be verified again")
+}
+
+func TestService_UpdateProfile_EmailChangeDisabledWithoutComparer(t *testing.T) {
+	repo := &mockUserRepository{userByID: &domuser.User{ID: 7, Email: "jane@example.com", RoleCode: domuser.RoleCodeCustomer}}
+	svc := NewService(repo, mockHasher{})
+	email := "new@example.com"
+
+	_, err := svc.UpdateProfile(context.Background(), UpdateProfileInput{ID: 7, Email: &email, CurrentPassword: "secret"})
+
+	require.ErrorIs(t, err, ErrEmailChangeDisabled)
+}
+
+func TestService_ChangePassword(t *testing.T) {
+	repo := &mockUserRepository{userByID: &domuser.User{ID: 7, PasswordHash: "hashed:old-password", RoleCode: domuser.RoleCodeCustomer}}
+	svc := NewService(repo, mockHasher{}, WithPasswordComparer(mockComparer{}))
+
+	err := svc.ChangePassword(context.Background(), ChangePasswordInput{ID: 7, CurrentPassword: "wrong", NewPassword: "new-password"})
+	require.ErrorIs(t, err, domuser.ErrInvalidCredential)
+	require.False(t, repo.updated)
+
+	err = svc.ChangePassword(context.Background(), ChangePasswordInput{ID: 7, CurrentPassword: "old-password", NewPassword: "new-password"})
+	require.NoError(t, err)
+	require.True(t, repo.updated)
+	require.Equal(t, "hashed:new-password", repo.userByID.PasswordHash)
+}
+
+func TestService_ChangePassword_DisabledWithoutComparer(t *testing.T) {
+	svc := NewService(&mockUserRepository{}, mockHasher{})
+
+	err := svc.ChangePassword(context.Background(), ChangePasswordInput{ID: 7, CurrentPassword: "a", NewPassword: "b"})
+
+	require.ErrorIs(t, err, ErrPasswordChangeDisabled)
+}
+
+// mockRanks resolves ranks from a map, like roles stored in user_roles.
+type mockRanks map[domuser.RoleCode]int
+
+func (m mockRanks) RankOf(ctx context.Context, code domuser.RoleCode) (int, error) {
+	rank, ok := m[code]
+	if !ok {
+		return 0, domrole.ErrRoleNotFound
+	}
+	return rank, nil
+}
+
+func newRankedService(repo domuser.Repository) *Service {
+	ranks := mockRanks{
+		domuser.RoleCodeSuperAdmin: 100,
+		"MANAGER":                  75,
+		domuser.RoleCodeAdmin:      50,
+		"SUPPORT":                  10,
+		domuser.RoleCodeCustomer:   0,
+	}
+	return NewService(repo, mockHasher{}, WithAssignmentPolicy(domrole.NewAssignmentPolicy(ranks)))
+}
+
+func TestService_CreateUser_OnlyRolesBelowExecutor(t *testing.T) {
+	tests := []struct {
+		executor domuser.RoleCode
+		target   domuser.RoleCode
+		allowed  bool
+	}{
+		{"MANAGER", domuser.RoleCodeAdmin, true},
+		{"MANAGER", "SUPPORT", true},
+		{"MANAGER", "MANAGER", false},
+		{domuser.RoleCodeAdmin, "SUPPORT", true},
+		{domuser.RoleCodeAdmin, "MANAGER", false},
+		{"SUPPORT", domuser.RoleCodeCustomer, true},
+		{"SUPPORT", "SUPPORT", false},
+		{domuser.RoleCodeSuperAdmin, domuser.RoleCodeSuperAdmin, false},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.executor)+"->"+string(tt.target), func(t *testing.T) {
+			repo := newEnhancedMockUserRepository()
+			repo.roleIDs["MANAGER"] = 5
+			repo.roleIDs["SUPPORT"] = 6
+			svc := newRankedService(repo)
+
+			_, err := svc.CreateUser(context.Background(), CreateUserInput{
+				ExecutorRole: tt.executor,
+				Name:         "New",
+				Email:        "new@example.com",
+				Password:     "secret123",
+				RoleCode:     tt.target,
+			})
+
+			if tt.allowed {
+				require.NoError(t, err)
+				require.True(t, repo.createCalled)
+			} else {
+				require.ErrorIs(t, err, domuser.ErrCannotAssignRole)
+				require.False(t, repo.createCalled)
+			}
+		})
+	}
+}
+
+func TestService_CreateUser_UnknownRoleRank(t *testing.T) {
+	svc := newRankedService(&mockUserRepository{})
+
+	_, err := svc.CreateUser(context.Background(), CreateUserInput{
+		ExecutorRole: domuser.RoleCodeSuperAdmin,
+		Password:     "secret123",
+		RoleCode:     "GHOST",
+	})
+
+	require.ErrorIs(t, err, domrole.ErrRoleNotFound)
+}
+
+func TestService_UpdateUser_CannotDemoteHigherRankedUser(t *testing.T) {
+	repo := &mockUserRepository{userByID: &domuser.User{ID: 9, RoleCode: "MANAGER"}}
+	svc := newRankedService(repo)
+	target := domuser.RoleCodeCustomer
+
+	_, err := svc.UpdateUser(context.Background(), UpdateUserInput{ExecutorRole: domuser.RoleCodeAdmin, ID: 9, RoleCode: &target})
+	require.ErrorIs(t, err, domuser.ErrCannotAssignRole)
+	require.False(t, repo.updated)
+
+	u, err := svc.UpdateUser(context.Background(), UpdateUserInput{ExecutorRole: domuser.RoleCodeSuperAdmin, ID: 9, RoleCode: &target})
+	require.NoError(t, err)
+	require.Equal(t, domuser.RoleCodeCustomer, u.RoleCode)
+}
+
+func TestService_UpdateUser_CannotEditPeerOrSuperior(t *testing.T) {
+	email := "attacker@example.com"
+	password := "taken-over"
+	name := "Renamed"
+
+	tests := []struct {
+		name   string
+		target domuser.RoleCode
+		in     UpdateUserInput
+	}{
+		{"email of super admin", domuser.RoleCodeSuperAdmin, UpdateUserInput{Email: &email}},
+		{"password of super admin", domuser.RoleCodeSuperAdmin, UpdateUserInput{Password: &password}},
+		{"name of another admin", domuser.RoleCodeAdmin, UpdateUserInput{Name: &name}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockUserRepository{userByID: &domuser.User{ID: 1, Email: "root@example.com", RoleCode: tt.target}}
+			svc := NewService(repo, mockHasher{})
+
+			tt.in.ExecutorRole = domuser.RoleCodeAdmin
+			tt.in.ID = 1
+			_, err := svc.UpdateUser(context.Background(), tt.in)
+
+			require.ErrorIs(t, err, domuser.ErrCannotAssignRole)
+			require.False(t, repo.updated)
+		})
+	}
+}
+
+func TestService_UpdateUser_AdminCanEditCustomer(t *testing.T) {
+	repo := &mockUserRepository{userByID: &domuser.User{ID: 1, RoleCode: domuser.RoleCodeCustomer}}
+	svc := NewService(repo, mockHasher{})
+	password := "reset-by-admin"
+
+	u, err := svc.UpdateUser(context.Background(), UpdateUserInput{ExecutorRole: domuser.RoleCodeAdmin, ID: 1, Password: &password})
+
+	require.NoError(t, err)
+	require.Equal(t, "hashed:reset-by-admin", u.PasswordHash)
+}
+
+type mockTokenRevoker struct {
+	revoked []int64
+}
+
+func (m *mockTokenRevoker) RevokeUserTokens(ctx context.Context, userID int64) error {
+	m.revoked = append(m.revoked, userID)
+	return nil
+}
+
+func TestService_UpdateUser_RevokesTokens(t *testing.T) {
+	customer := domuser.RoleCodeCustomer
+	admin := domuser.RoleCodeAdmin
+	name := "Renamed"
+	password := "new-secret"
+	tests := []struct {
+		name   string
+		in     UpdateUserInput
+		revoke bool
+	}{
+		{"demotion", UpdateUserInput{RoleCode: &customer}, true},
+		{"same role", UpdateUserInput{RoleCode: &admin}, false},
+		{"name only", UpdateUserInput{Name: &name}, false},
+		{"password", UpdateUserInput{Password: &password}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockUserRepository{userByID: &domuser.User{ID: 5, RoleCode: domuser.RoleCodeAdmin}}
+			revoker := &mockTokenRevoker{}
+			svc := NewService(repo, mockHasher{}, WithTokenRevoker(revoker))
+			tt.in.ExecutorRole = domuser.RoleCodeSuperAdmin
+			tt.in.ID = 5
+
+			_, err := svc.UpdateUser(context.Background(), tt.in)
+
+			require.NoError(t, err)
+			if tt.revoke {
+				require.Equal(t, []int64{5}, revoker.revoked)
+			} else {
+				require.Empty(t, revoker.revoked)
+			}
+		})
+	}
+}
+
+func TestService_DeleteUser_RevokesTokens(t *testing.T) {
+	repo := &mockUserRepository{userByID: &domuser.User{ID: 5, RoleCode: domuser.RoleCodeAdmin}}
+	revoker := &mockTokenRevoker{}
+	svc := NewService(repo, mockHasher{}, WithTokenRevoker(revoker))
+
+	require.ErrorIs(t, svc.DeleteUser(context.Background(), domuser.RoleCodeAdmin, 5), domuser.ErrCannotAssignRole)
+	require.Empty(t, revoker.revoked, "a refused delete signs nobody out")
+
+	require.NoError(t, svc.DeleteUser(context.Background(), domuser.RoleCodeSuperAdmin, 5))
+	require.Equal(t, []int64{5}, revoker.revoked)
+}
+
+func TestService_DeleteUser_OnlyBelowExecutor(t *testing.T) {
+	tests := []struct {
+		executor domuser.RoleCode
+		target   domuser.RoleCode
+		allowed  bool
+	}{
+		{domuser.RoleCodeAdmin, domuser.RoleCodeSuperAdmin, false},
+		{domuser.RoleCodeAdmin, domuser.RoleCodeAdmin, false},
+		{domuser.RoleCodeAdmin, domuser.RoleCodeCustomer, true},
+		{domuser.RoleCodeSuperAdmin, domuser.RoleCodeAdmin, true},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.executor)+" deletes "+string(tt.target), func(t *testing.T) {
+			repo := &mockUserRepository{userByID: &domuser.User{ID: 1, RoleCode: tt.target}}
+			svc := NewService(repo, mockHasher{})
+
+			err := svc.DeleteUser(context.Background(), tt.executor, 1)
+
+			if tt.allowed {
+				require.NoError(t, err)
+				require.True(t, repo.deleted)
+			} else {
+				require.ErrorIs(t, err, domuser.ErrCannotAssignRole)
+				require.False(t, repo.deleted)
+			}
+		})
+	}
+}
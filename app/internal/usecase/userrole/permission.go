@@ -0,0 +1,105 @@
+package userrole
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	domuser "example.com/my-golang-sample/app/internal/domain/user"
+	domrole "example.com/my-golang-sample/app/internal/domain/userrole"
+)
+
+var ErrPermissionsDisabled = errors.New("permission management is not enabled")
+
+// WithPermissions stores role permissions in repo. Without it every role
+// keeps its domrole.DefaultPermissions and grants are refused.
+func WithPermissions(repo domrole.PermissionRepository) Option {
+	return func(s *Service) {
+		s.permissions = repo
+	}
+}
+
+type ChangePermissionInput struct {
+	ExecutorRole domuser.RoleCode
+	RoleID       int64
+	Permission   string
+}
+
+// HasPermission reports whether users with the role code may use p.
+func (s *Service) HasPermission(ctx context.Context, code domuser.RoleCode, p domrole.Permission) (bool, error) {
+	perms, err := s.permissionsOf(ctx, code)
+	if err != nil {
+		return false, err
+	}
+	return slices.Contains(perms, p), nil
+}
+
+func (s *Service) ListPermissions(ctx context.Context, roleID int64) ([]domrole.Permission, error) {
+	role, err := s.repo.GetByID(ctx, roleID)
+	if err != nil {
+		return nil, err
+	}
+	return s.permissionsOf(ctx, role.Code)
+}
+
+// GrantPermission adds p to a role ranked below the executor's. Executors can
+// only hand out permissions they hold themselves, and SUPER_ADMIN cannot be
+// changed.
+func (s *Service) GrantPermission(ctx context.Context, in ChangePermissionInput) error {
+	role, p, err := s.checkPermissionChange(ctx, in)
+	if err != nil {
+		return err
+	}
+	return s.permissions.Grant(ctx, role.ID, p)
+}
+
+func (s *Service) RevokePermission(ctx context.Context, in ChangePermissionInput) error {
+	role, p, err := s.checkPermissionChange(ctx, in)
+	if err != nil {
+		return err
+	}
+	return s.permissions.Revoke(ctx, role.ID, p)
+}
+
+func (s *Service) checkPermissionChange(ctx context.Context, in ChangePermissionInput) (*domrole.UserRole, domrole.Permission, error) {
+	if s.permissions == nil {
+		return nil, "", ErrPermissionsDisabled
+	}
+	p, err := domrole.ParsePermission(in.Permission)
+	if err != nil {
+		return nil, "", err
+	}
+	role, err := s.repo.GetByID(ctx, in.RoleID)
+	if err != nil {
+		return nil, "", err
+	}
+	if role.Code.IsSuperAdmin() {
+		return nil, "", domrole.ErrRoleImmutable
+	}
+
+	// Chỉ được đổi quyền của role thấp hơn mình
+	below, err := s.policy.CanAssign(ctx, in.ExecutorRole, role.Code)
+	if err != nil {
+		return nil, "", err
+	}
+	if !below {
+		return nil, "", domrole.ErrCannotGrantPermission
+	}
+
+	held, err := s.HasPermission(ctx, in.ExecutorRole, p)
+	if err != nil {
+		return nil, "", err
+	}
+	if !held {
+		return nil, "", domrole.ErrCannotGrantPermission
+	}
+	return role, p, nil
+}
+
+func (s *Service) permissionsOf(ctx context.Context, code domuser.RoleCode) ([]domrole.Permission, error) {
+	// SUPER_ADMIN luôn có toàn quyền, không lưu trong DB
+	if code.IsSuperAdmin() || s.permissions == nil {
+		return domrole.DefaultPermissions(code), nil
+	}
+	return s.permissions.ListByRoleCode(ctx, code)
+}
@@ -4,28 +4,42 @@ import (
 	"context"
 	"strings"
 
+	"example.com/my-golang-sample/app/internal/domain/page"
 	domuser "example.com/my-golang-sample/app/internal/domain/user"
 	domrole "example.com/my-golang-sample/app/internal/domain/userrole"
 )
 
 type Service struct {
-	repo domrole.Repository
+	repo        domrole.Repository
+	permissions domrole.PermissionRepository
+	policy      *domrole.AssignmentPolicy
 }
 
-func NewService(repo domrole.Repository) *Service {
-	return &Service{repo: repo}
+type Option func(*Service)
+
+func NewService(repo domrole.Repository, opts ...Option) *Service {
+	s := &Service{repo: repo, policy: domrole.NewAssignmentPolicy(domrole.RanksFrom(repo))}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 type CreateInput struct {
-	Code        string
-	Name        string
-	Description string
+	ExecutorRole domuser.RoleCode
+	Code         string
+	Name         string
+	Description  string
+	// Rank must be below the executor's own rank.
+	Rank int
 }
 
 type UpdateInput struct {
-	ID          int64
-	Name        *string
-	Description *string
+	ExecutorRole domuser.RoleCode
+	ID           int64
+	Name         *string
+	Description  *string
+	Rank         *int
 }
 
 func (s *Service) Create(ctx context.Context, in CreateInput) (*domrole.UserRole, error) {
@@ -35,10 +49,15 @@ func (s *Service) Create(ctx context.Context, in CreateInput) (*domrole.UserRole
 		return nil, err
 	}
 
+	if err := s.checkRank(ctx, in.ExecutorRole, in.Rank); err != nil {
+		return nil, err
+	}
+
 	role := &domrole.UserRole{
 		Code:        roleCode,
 		Name:        in.Name,
 		Description: in.Description,
+		Rank:        in.Rank,
 	}
 
 	return s.repo.Create(ctx, role)
@@ -49,6 +68,10 @@ func (s *Service) Update(ctx context.Context, in UpdateInput) (*domrole.UserRole
 	if err != nil {
 		return nil, err
 	}
+	// Chỉ được sửa role đang thấp hơn executor, kể cả khi chỉ đổi tên/mô tả
+	if err := s.checkRank(ctx, in.ExecutorRole, role.Rank); err != nil {
+		return nil, err
+	}
 
 	if in.Name != nil {
 		role.Name = *in.Name
@@ -56,11 +79,29 @@ func (s *Service) Update(ctx context.Context, in UpdateInput) (*domrole.UserRole
 	if in.Description != nil {
 		role.Description = *in.Description
 	}
+	if in.Rank != nil && *in.Rank != role.Rank {
+		// Rank của role hệ thống cố định; rank mới cũng phải thấp hơn executor
+		if role.IsSystem {
+			return nil, domrole.ErrRoleImmutable
+		}
+		if err := s.checkRank(ctx, in.ExecutorRole, *in.Rank); err != nil {
+			return nil, err
+		}
+		role.Rank = *in.Rank
+	}
 
 	return s.repo.Update(ctx, role)
 }
 
-func (s *Service) Delete(ctx context.Context, id int64) error {
+// Delete removes a role ranked below the executor.
+func (s *Service) Delete(ctx context.Context, executor domuser.RoleCode, id int64) error {
+	role, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.checkRank(ctx, executor, role.Rank); err != nil {
+		return err
+	}
 	return s.repo.Delete(ctx, id)
 }
 
@@ -68,7 +109,22 @@ func (s *Service) GetByID(ctx context.Context, id int64) (*domrole.UserRole, err
 	return s.repo.GetByID(ctx, id)
 }
 
-func (s *Service) List(ctx context.Context, filter domrole.ListFilter) ([]*domrole.UserRole, error) {
-	return s.repo.List(ctx, filter)
+func (s *Service) List(ctx context.Context, filter domrole.ListFilter, p page.Request) ([]*domrole.UserRole, page.Info, error) {
+	p, err := p.Normalize(domrole.SortFields)
+	if err != nil {
+		return nil, page.Info{}, err
+	}
+	return s.repo.List(ctx, filter, p)
 }
 
+
+func (s *Service) checkRank(ctx context.Context, executor domuser.RoleCode, rank int) error {
+	ok, err := s.policy.CanSetRank(ctx, executor, rank)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return domrole.ErrRankTooHigh
+	}
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"example.com/my-golang-sample/app/internal/domain/page"
 	domuser "example.com/my-golang-sample/app/internal/domain/user"
 	domrole "example.com/my-golang-sample/app/internal/domain/userrole"
 )
@@ -21,9 +22,9 @@ type mockRoleRepository struct {
 func newMockRoleRepository() *mockRoleRepository {
 	return &mockRoleRepository{
 		roles: map[int64]*domrole.UserRole{
-			1: {ID: 1, Code: domuser.RoleCodeSuperAdmin, Name: "Super Admin", IsSystem: true},
-			2: {ID: 2, Code: domuser.RoleCodeAdmin, Name: "Admin", IsSystem: true},
-			3: {ID: 3, Code: domuser.RoleCodeCustomer, Name: "Customer", IsSystem: true},
+			1: {ID: 1, Code: domuser.RoleCodeSuperAdmin, Name: "Super Admin", IsSystem: true, Rank: domrole.RankSuperAdmin},
+			2: {ID: 2, Code: domuser.RoleCodeAdmin, Name: "Admin", IsSystem: true, Rank: domrole.RankAdmin},
+			3: {ID: 3, Code: domuser.RoleCodeCustomer, Name: "Customer", IsSystem: true, Rank: domrole.RankCustomer},
 		},
 		nextID: 4,
 	}
@@ -79,13 +80,13 @@ func (m *mockRoleRepository) GetByCode(ctx context.Context, code string) (*domro
 	return nil, domrole.ErrRoleNotFound
 }
 
-func (m *mockRoleRepository) List(ctx context.Context, filter domrole.ListFilter) ([]*domrole.UserRole, error) {
+func (m *mockRoleRepository) List(ctx context.Context, filter domrole.ListFilter, req page.Request) ([]*domrole.UserRole, page.Info, error) {
 	result := make([]*domrole.UserRole, 0, len(m.roles))
 	for _, role := range m.roles {
 		cloned := *role
 		result = append(result, &cloned)
 	}
-	return result, nil
+	return result, page.Info{Total: int64(len(result)), Page: req.Page, Size: req.Size}, nil
 }
 
 func TestService_Create_NormalizesCode(t *testing.T) {
@@ -93,9 +94,10 @@ func TestService_Create_NormalizesCode(t *testing.T) {
 	svc := NewService(repo)
 
 	role, err := svc.Create(context.Background(), CreateInput{
-		Code:        "  support_agent ",
-		Name:        "Support Agent",
-		Description: "Handle tickets",
+		ExecutorRole: domuser.RoleCodeSuperAdmin,
+		Code:         "  support_agent ",
+		Name:         "Support Agent",
+		Description:  "Handle tickets",
 	})
 
 	require.NoError(t, err)
@@ -123,9 +125,10 @@ func TestService_Update_MergesFields(t *testing.T) {
 	newName := "Renamed Admin"
 	newDesc := "Updated description"
 	role, err := svc.Update(context.Background(), UpdateInput{
-		ID:          2,
-		Name:        &newName,
-		Description: &newDesc,
+		ExecutorRole: domuser.RoleCodeSuperAdmin,
+		ID:           2,
+		Name:         &newName,
+		Description:  &newDesc,
 	})
 
 	require.NoError(t, err)
@@ -138,10 +141,189 @@ func TestService_Delete_ForwardsToRepo(t *testing.T) {
 	repo := newMockRoleRepository()
 	svc := NewService(repo)
 
-	err := svc.Delete(context.Background(), 3)
+	err := svc.Delete(context.Background(), domuser.RoleCodeSuperAdmin, 3)
 
 	require.NoError(t, err)
 	require.Equal(t, int64(3), repo.deletedID)
 }
 
+func TestService_UpdateAndDelete_OnlyRolesBelowExecutor(t *testing.T) {
+	repo := newMockRoleRepository()
+	repo.roles[4] = &domrole.UserRole{ID: 4, Code: "MANAGER", Name: "Manager", Rank: 75}
+	repo.roles[5] = &domrole.UserRole{ID: 5, Code: "SUPPORT", Name: "Support", Rank: 10}
+	svc := NewService(repo)
+	name := "Renamed"
+
+	_, err := svc.Update(context.Background(), UpdateInput{ExecutorRole: domuser.RoleCodeAdmin, ID: 4, Name: &name})
+	require.ErrorIs(t, err, domrole.ErrRankTooHigh, "MANAGER outranks ADMIN")
+	_, err = svc.Update(context.Background(), UpdateInput{ExecutorRole: domuser.RoleCodeAdmin, ID: 2, Description: &name})
+	require.ErrorIs(t, err, domrole.ErrRankTooHigh, "ADMIN cannot edit its own role")
+	require.Nil(t, repo.updated)
+
+	err = svc.Delete(context.Background(), domuser.RoleCodeAdmin, 4)
+	require.ErrorIs(t, err, domrole.ErrRankTooHigh)
+	require.Zero(t, repo.deletedID)
+
+	role, err := svc.Update(context.Background(), UpdateInput{ExecutorRole: domuser.RoleCodeAdmin, ID: 5, Name: &name})
+	require.NoError(t, err)
+	require.Equal(t, name, role.Name)
+	require.NoError(t, svc.Delete(context.Background(), domuser.RoleCodeAdmin, 5))
+	require.Equal(t, int64(5), repo.deletedID)
+}
+
+type mockPermissionRepository struct {
+	roles  *mockRoleRepository
+	grants map[int64][]domrole.Permission
+}
+
+func newMockPermissionRepository(roles *mockRoleRepository) *mockPermissionRepository {
+	return &mockPermissionRepository{
+		roles:  roles,
+		grants: map[int64][]domrole.Permission{2: {domrole.PermUsersRead, domrole.PermOrdersRead}},
+	}
+}
+
+func (m *mockPermissionRepository) ListByRole(ctx context.Context, roleID int64) ([]domrole.Permission, error) {
+	return m.grants[roleID], nil
+}
+
+func (m *mockPermissionRepository) ListByRoleCode(ctx context.Context, code domuser.RoleCode) ([]domrole.Permission, error) {
+	for id, role := range m.roles.roles {
+		if role.Code == code {
+			return m.grants[id], nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockPermissionRepository) Grant(ctx context.Context, roleID int64, p domrole.Permission) error {
+	m.grants[roleID] = append(m.grants[roleID], p)
+	return nil
+}
+
+func (m *mockPermissionRepository) Revoke(ctx context.Context, roleID int64, p domrole.Permission) error {
+	kept := m.grants[roleID][:0]
+	for _, g := range m.grants[roleID] {
+		if g != p {
+			kept = append(kept, g)
+		}
+	}
+	m.grants[roleID] = kept
+	return nil
+}
+
+func TestService_HasPermission(t *testing.T) {
+	repo := newMockRoleRepository()
+	svc := NewService(repo, WithPermissions(newMockPermissionRepository(repo)))
+
+	ok, err := svc.HasPermission(context.Background(), domuser.RoleCodeSuperAdmin, domrole.PermRolesWrite)
+	require.NoError(t, err)
+	require.True(t, ok, "super admin holds every permission")
+
+	ok, err = svc.HasPermission(context.Background(), domuser.RoleCodeAdmin, domrole.PermUsersRead)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	ok, err = svc.HasPermission(context.Background(), domuser.RoleCodeAdmin, domrole.PermUsersWrite)
+	require.NoError(t, err)
+	require.False(t, ok)
+}
+
+func TestService_HasPermission_DefaultsWithoutStore(t *testing.T) {
+	svc := NewService(newMockRoleRepository())
+
+	ok, err := svc.HasPermission(context.Background(), domuser.RoleCodeAdmin, domrole.PermProductsWrite)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	ok, err = svc.HasPermission(context.Background(), domuser.RoleCodeCustomer, domrole.PermProductsRead)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	err = svc.GrantPermission(context.Background(), ChangePermissionInput{ExecutorRole: domuser.RoleCodeSuperAdmin, RoleID: 3, Permission: "orders:read"})
+	require.ErrorIs(t, err, ErrPermissionsDisabled)
+}
+
+func TestService_GrantAndRevokePermission(t *testing.T) {
+	repo := newMockRoleRepository()
+	perms := newMockPermissionRepository(repo)
+	svc := NewService(repo, WithPermissions(perms))
+	support, err := svc.Create(context.Background(), CreateInput{ExecutorRole: domuser.RoleCodeAdmin, Code: "SUPPORT", Name: "Support", Rank: 10})
+	require.NoError(t, err)
 
+	err = svc.GrantPermission(context.Background(), ChangePermissionInput{ExecutorRole: domuser.RoleCodeAdmin, RoleID: support.ID, Permission: " Orders:Read "})
+	require.NoError(t, err)
+	got, err := svc.ListPermissions(context.Background(), support.ID)
+	require.NoError(t, err)
+	require.Equal(t, []domrole.Permission{domrole.PermOrdersRead}, got)
+
+	err = svc.GrantPermission(context.Background(), ChangePermissionInput{ExecutorRole: domuser.RoleCodeAdmin, RoleID: support.ID, Permission: "orders:update_status"})
+	require.ErrorIs(t, err, domrole.ErrCannotGrantPermission, "admin does not hold it")
+
+	err = svc.GrantPermission(context.Background(), ChangePermissionInput{ExecutorRole: domuser.RoleCodeSuperAdmin, RoleID: support.ID, Permission: "orders:delete"})
+	require.ErrorIs(t, err, domrole.ErrUnknownPermission)
+
+	err = svc.RevokePermission(context.Background(), ChangePermissionInput{ExecutorRole: domuser.RoleCodeSuperAdmin, RoleID: support.ID, Permission: "orders:read"})
+	require.NoError(t, err)
+	got, err = svc.ListPermissions(context.Background(), support.ID)
+	require.NoError(t, err)
+	require.Empty(t, got)
+}
+
+func TestService_GrantPermission_SuperAdminImmutable(t *testing.T) {
+	repo := newMockRoleRepository()
+	svc := NewService(repo, WithPermissions(newMockPermissionRepository(repo)))
+
+	err := svc.RevokePermission(context.Background(), ChangePermissionInput{ExecutorRole: domuser.RoleCodeSuperAdmin, RoleID: 1, Permission: "roles:write"})
+
+	require.ErrorIs(t, err, domrole.ErrRoleImmutable)
+	got, err := svc.ListPermissions(context.Background(), 1)
+	require.NoError(t, err)
+	require.ElementsMatch(t, domrole.AllPermissions(), got)
+}
+
+func TestService_Create_RankMustBeBelowExecutor(t *testing.T) {
+	repo := newMockRoleRepository()
+	svc := NewService(repo)
+
+	_, err := svc.Create(context.Background(), CreateInput{ExecutorRole: domuser.RoleCodeAdmin, Code: "MANAGER", Name: "Manager", Rank: domrole.RankAdmin})
+	require.ErrorIs(t, err, domrole.ErrRankTooHigh)
+	require.Nil(t, repo.created)
+
+	role, err := svc.Create(context.Background(), CreateInput{ExecutorRole: domuser.RoleCodeSuperAdmin, Code: "MANAGER", Name: "Manager", Rank: 75})
+	require.NoError(t, err)
+	require.Equal(t, 75, role.Rank)
+}
+
+func TestService_Update_Rank(t *testing.T) {
+	repo := newMockRoleRepository()
+	repo.roles[4] = &domrole.UserRole{ID: 4, Code: "MANAGER", Name: "Manager", Rank: 75}
+	repo.roles[5] = &domrole.UserRole{ID: 5, Code: "SUPPORT", Name: "Support", Rank: 10}
+	svc := NewService(repo)
+	rank := 20
+
+	_, err := svc.Update(context.Background(), UpdateInput{ExecutorRole: domuser.RoleCodeAdmin, ID: 4, Rank: &rank})
+	require.ErrorIs(t, err, domrole.ErrRankTooHigh, "MANAGER already outranks ADMIN")
+
+	tooHigh := domrole.RankAdmin
+	_, err = svc.Update(context.Background(), UpdateInput{ExecutorRole: domuser.RoleCodeAdmin, ID: 5, Rank: &tooHigh})
+	require.ErrorIs(t, err, domrole.ErrRankTooHigh)
+
+	role, err := svc.Update(context.Background(), UpdateInput{ExecutorRole: domuser.RoleCodeAdmin, ID: 5, Rank: &rank})
+	require.NoError(t, err)
+	require.Equal(t, 20, role.Rank)
+
+	_, err = svc.Update(context.Background(), UpdateInput{ExecutorRole: domuser.RoleCodeSuperAdmin, ID: 3, Rank: &rank})
+	require.ErrorIs(t, err, domrole.ErrRoleImmutable)
+}
+
+func TestService_GrantPermission_OnlyToLowerRoles(t *testing.T) {
+	repo := newMockRoleRepository()
+	svc := NewService(repo, WithPermissions(newMockPermissionRepository(repo)))
+
+	err := svc.GrantPermission(context.Background(), ChangePermissionInput{ExecutorRole: domuser.RoleCodeAdmin, RoleID: 2, Permission: "users:read"})
+	require.ErrorIs(t, err, domrole.ErrCannotGrantPermission, "admin cannot change its own role")
+
+	err = svc.GrantPermission(context.Background(), ChangePermissionInput{ExecutorRole: domuser.RoleCodeSuperAdmin, RoleID: 2, Permission: "users:write"})
+	require.NoError(t, err)
+}